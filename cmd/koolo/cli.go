@@ -0,0 +1,172 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/hectorgimenez/koolo/internal/config/secret"
+	"github.com/hectorgimenez/koolo/internal/pickit"
+)
+
+// cliCommand is a command line subcommand, name holds the words following koolo (e.g. "pickit lint")
+type cliCommand struct {
+	name  string
+	usage string
+	run   func(args []string, stdout, stderr io.Writer) int
+}
+
+func commands() []cliCommand {
+	return append([]cliCommand{
+		{name: "pickit lint", usage: "[-json] <path>  validate a .nip file or every .nip file of a directory", run: runPickitLint},
+		{name: "secret set", usage: "<name>  store the value read from stdin in the vault, use it as ${vault:name}", run: runSecretSet},
+		{name: "secret delete", usage: "<name>  remove a value from the vault", run: runSecretDelete},
+		{name: "secret list", usage: "  list the names stored in the vault", run: runSecretList},
+		{name: "map render", usage: "-dump <file> | -map <file> -area <id> [-from x,y] [-to x,y]  draw the grid, costs, path and teleport landings as PNGs", run: runMapRender},
+	}, platformCommands()...)
+}
+
+// runCommand runs a command line subcommand and returns the process exit code
+func runCommand(args []string) int {
+	attachParentConsole()
+
+	for _, cmd := range commands() {
+		words := strings.Fields(cmd.name)
+		if len(args) >= len(words) && strings.Join(args[:len(words)], " ") == cmd.name {
+			return cmd.run(args[len(words):], os.Stdout, os.Stderr)
+		}
+	}
+
+	printUsage(os.Stderr)
+	return 2
+}
+
+func printUsage(w io.Writer) {
+	fmt.Fprintln(w, "Usage:")
+	fmt.Fprintln(w, "  koolo [-headless] [-listen host:port]  start Koolo, -headless runs without the window for services")
+	for _, cmd := range commands() {
+		fmt.Fprintf(w, "  koolo %s %s\n", cmd.name, cmd.usage)
+	}
+}
+
+// runPickitLint exits with 1 when any error or warning is found, so it can be used as a pre-commit check
+func runPickitLint(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("pickit lint", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	asJSON := fs.Bool("json", false, "print the report as JSON")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() == 0 {
+		fmt.Fprintln(stderr, "Usage: koolo pickit lint [-json] <path> [path...]")
+		return 2
+	}
+
+	failed := false
+	reports := make([]pickit.LintReport, 0, fs.NArg())
+	for _, path := range fs.Args() {
+		report, err := pickit.LintNIPPath(path)
+		if err != nil {
+			fmt.Fprintf(stderr, "%s: %v\n", path, err)
+			return 1
+		}
+		failed = failed || report.Failed()
+		reports = append(reports, report)
+	}
+
+	if *asJSON {
+		enc := json.NewEncoder(stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(reports); err != nil {
+			return 1
+		}
+	} else {
+		for i, report := range reports {
+			for _, issue := range report.Issues {
+				fmt.Fprintln(stdout, issue.String())
+			}
+			fmt.Fprintf(stdout, "%s: %d files, %d rules, %d issues\n", fs.Arg(i), report.Files, report.Rules, len(report.Issues))
+		}
+	}
+
+	if failed {
+		return 1
+	}
+
+	return 0
+}
+
+// runSecretSet reads the value from stdin so it doesn't end up in the shell history, the vault master password comes
+// from KOOLO_VAULT_PASSWORD or KOOLO_VAULT_PASSWORD_FILE
+func runSecretSet(args []string, stdout, stderr io.Writer) int {
+	if len(args) != 1 {
+		fmt.Fprintln(stderr, "Usage: koolo secret set <name>")
+		return 2
+	}
+
+	vault, err := secret.NewStore(secret.DefaultVaultFile).Vault()
+	if err != nil {
+		fmt.Fprintf(stderr, "%v\n", err)
+		return 1
+	}
+
+	fmt.Fprintf(stderr, "Value for %s: ", args[0])
+	value, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	value = strings.TrimRight(value, "\r\n")
+	if err != nil && (err != io.EOF || value == "") {
+		fmt.Fprintln(stderr, "error reading the value from stdin")
+		return 1
+	}
+
+	if err = vault.Set(args[0], value); err == nil {
+		err = vault.Save()
+	}
+	if err != nil {
+		fmt.Fprintf(stderr, "%v\n", err)
+		return 1
+	}
+	fmt.Fprintf(stdout, "%s stored, reference it as %s\n", args[0], secret.Ref{Scheme: secret.SchemeVault, Key: args[0]})
+
+	return 0
+}
+
+func runSecretDelete(args []string, stdout, stderr io.Writer) int {
+	if len(args) != 1 {
+		fmt.Fprintln(stderr, "Usage: koolo secret delete <name>")
+		return 2
+	}
+
+	vault, err := secret.NewStore(secret.DefaultVaultFile).Vault()
+	if err != nil {
+		fmt.Fprintf(stderr, "%v\n", err)
+		return 1
+	}
+	if !vault.Delete(args[0]) {
+		fmt.Fprintf(stderr, "%s is not in the vault\n", args[0])
+		return 1
+	}
+	if err = vault.Save(); err != nil {
+		fmt.Fprintf(stderr, "%v\n", err)
+		return 1
+	}
+	fmt.Fprintf(stdout, "%s deleted\n", args[0])
+
+	return 0
+}
+
+func runSecretList(_ []string, stdout, stderr io.Writer) int {
+	vault, err := secret.NewStore(secret.DefaultVaultFile).Vault()
+	if err != nil {
+		fmt.Fprintf(stderr, "%v\n", err)
+		return 1
+	}
+	for _, name := range vault.Names() {
+		fmt.Fprintln(stdout, name)
+	}
+
+	return 0
+}
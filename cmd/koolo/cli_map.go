@@ -0,0 +1,191 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"image"
+	"io"
+	"os"
+
+	"github.com/hectorgimenez/d2go/pkg/data"
+	"github.com/hectorgimenez/d2go/pkg/data/area"
+	"github.com/hectorgimenez/koolo/internal/game/map_client"
+	"github.com/hectorgimenez/koolo/internal/game/mapgrid"
+	"github.com/hectorgimenez/koolo/internal/pather/astar"
+	"github.com/hectorgimenez/koolo/internal/pather/render"
+)
+
+// mapInput is the map state the renderer draws, from a grid dump or map_client output
+type mapInput struct {
+	scene  mapgrid.Scene
+	exits  []data.Level
+	rooms  []data.Room
+	player data.Position
+}
+
+// runMapRender draws <out>_collision.png and <out>_cost.png, with the path and the teleport landing points when -to
+// is set. It doesn't need the game so it runs anywhere, bug reports can attach the output of an exported grid dump.
+func runMapRender(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("map render", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	dumpPath := fs.String("dump", "", "grid dump exported from the debug page ("+mapgrid.DumpExtension+")")
+	mapPath := fs.String("map", "", "map_client output: koolo-map.exe <D2LoDPath> -s <seed> -d <difficulty> > map.json")
+	areaID := fs.Int("area", 0, "area to draw from the -map levels")
+	teleport := fs.Bool("teleport", false, "path as a teleporting character, defaults to the dump value")
+	fromFlag := fs.String("from", "", "path start as x,y world coordinates, defaults to the dump player position")
+	toFlag := fs.String("to", "", "path destination as x,y world coordinates, no path is drawn when empty")
+	planner := fs.String("planner", astar.PlannerAStar, "path planner: astar or hierarchical")
+	teleportRange := fs.Int("range", 17, "teleport range in cells for the landing points, 17 fits a 1280x720 window")
+	scale := fs.Int("scale", 2, "pixels per cell")
+	out := fs.String("out", "map", "output file prefix")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if (*dumpPath == "") == (*mapPath == "") {
+		fmt.Fprintln(stderr, "Usage: koolo map render (-dump <file> | -map <file> -area <id>) [-from x,y] [-to x,y] [...]")
+		return 2
+	}
+
+	var in mapInput
+	var err error
+	if *dumpPath != "" {
+		in, err = loadDumpInput(*dumpPath)
+	} else {
+		in, err = loadMapClientInput(*mapPath, area.ID(*areaID))
+	}
+	if err != nil {
+		fmt.Fprintf(stderr, "%v\n", err)
+		return 1
+	}
+	fs.Visit(func(f *flag.Flag) {
+		if f.Name == "teleport" {
+			in.scene.CanTeleport = *teleport
+		}
+	})
+
+	layers := render.Layers{
+		Grid:        in.scene.Grid,
+		CanTeleport: in.scene.CanTeleport,
+		Objects:     in.scene.Objects,
+		Exits:       in.exits,
+		Monsters:    in.scene.Monsters,
+		Rooms:       in.rooms,
+	}
+	if *toFlag != "" {
+		from := in.player
+		if *fromFlag != "" {
+			if from, err = parsePosition(*fromFlag); err != nil {
+				fmt.Fprintf(stderr, "-from: %v\n", err)
+				return 2
+			}
+		}
+		to, err := parsePosition(*toFlag)
+		if err != nil {
+			fmt.Fprintf(stderr, "-to: %v\n", err)
+			return 2
+		}
+
+		grid, relFrom, relTo, err := in.scene.PathGrid(from, to)
+		if err != nil {
+			fmt.Fprintf(stderr, "%v\n", err)
+			return 1
+		}
+		layers.Grid, layers.From, layers.To = grid, relFrom, relTo
+
+		path, distance, found := astar.NewPlanner(*planner).CalculatePath(grid, relFrom, relTo, in.scene.CanTeleport)
+		if found {
+			layers.Path = path
+			fmt.Fprintf(stdout, "path: %d cells\n", distance)
+		} else {
+			fmt.Fprintf(stdout, "path: not found from %v to %v\n", from, to)
+		}
+		if in.scene.CanTeleport {
+			if landings, found := astar.CalculateTeleportPath(grid, relFrom, relTo, *teleportRange, nil); found {
+				layers.Landings = landings
+				fmt.Fprintf(stdout, "teleports: %d\n", len(landings)-1)
+			} else {
+				fmt.Fprintln(stdout, "teleports: no landing points found")
+			}
+		}
+	}
+
+	files := []struct {
+		name  string
+		image func(render.Layers, int) *image.RGBA
+	}{
+		{name: *out + "_collision.png", image: render.Collision},
+		{name: *out + "_cost.png", image: render.Cost},
+	}
+	for _, f := range files {
+		if err = render.Save(f.image(layers, *scale), f.name); err != nil {
+			fmt.Fprintf(stderr, "%v\n", err)
+			return 1
+		}
+		fmt.Fprintf(stdout, "%s written\n", f.name)
+	}
+
+	return 0
+}
+
+func loadDumpInput(path string) (mapInput, error) {
+	d, err := mapgrid.LoadDump(path)
+	if err != nil {
+		return mapInput{}, err
+	}
+	s, err := d.Scene()
+	if err != nil {
+		return mapInput{}, err
+	}
+
+	return mapInput{scene: s, exits: d.Areas[0].AdjacentLevels, rooms: d.Areas[0].Rooms, player: d.Player}, nil
+}
+
+// loadMapClientInput builds the grids of the map_client levels like the bot does when it fetches the map data
+func loadMapClientInput(path string, current area.ID) (mapInput, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return mapInput{}, err
+	}
+	defer f.Close()
+	levels, err := map_client.ParseMapData(f)
+	if err != nil {
+		return mapInput{}, err
+	}
+
+	var in mapInput
+	grids := make(map[area.ID]*mapgrid.Grid, len(levels))
+	for _, lvl := range levels {
+		npcs, exits, objects, rooms := lvl.NPCsExitsAndObjects()
+		exitPositions := make([]data.Position, 0, len(exits))
+		for _, e := range exits {
+			exitPositions = append(exitPositions, e.Position)
+		}
+		grids[area.ID(lvl.ID)] = mapgrid.NewLevelGrid(lvl.CollisionGrid(), lvl.Offset.X, lvl.Offset.Y, area.ID(lvl.ID), exitPositions)
+
+		if area.ID(lvl.ID) == current {
+			in.scene = mapgrid.Scene{Area: current, Objects: objects, NPCs: npcs}
+			in.exits, in.rooms = exits, rooms
+		}
+	}
+	in.scene.Grid = grids[current]
+	if in.scene.Grid == nil {
+		return mapInput{}, fmt.Errorf("area %d is not in %s", current, path)
+	}
+	for _, e := range in.exits {
+		if g, found := grids[e.Area]; found {
+			in.scene.Adjacent = append(in.scene.Adjacent, mapgrid.Level{Area: e.Area, Grid: g})
+		}
+	}
+
+	return in, nil
+}
+
+func parsePosition(s string) (data.Position, error) {
+	var p data.Position
+	if _, err := fmt.Sscanf(s, "%d,%d", &p.X, &p.Y); err != nil {
+		return data.Position{}, errors.New("expected x,y world coordinates")
+	}
+
+	return p, nil
+}
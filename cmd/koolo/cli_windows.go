@@ -0,0 +1,242 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"text/tabwriter"
+	"time"
+
+	"github.com/hectorgimenez/d2go/pkg/nip"
+	"github.com/hectorgimenez/koolo/internal/config"
+	"github.com/hectorgimenez/koolo/internal/remote/droplog"
+	"github.com/hectorgimenez/koolo/internal/utils/winproc"
+)
+
+// platformCommands returns the subcommands that need the Windows only packages
+func platformCommands() []cliCommand {
+	return []cliCommand{
+		{name: "pickit replay", usage: "[-candidate path] [...]  replay stashed drops against a candidate pickit file or directory", run: runPickitReplay},
+		{name: "config migrate", usage: "[-dry-run] [supervisor...]  upgrade character configs to the current schema version", run: runConfigMigrate},
+		{name: "config check", usage: "[-json] [-strict] [supervisor]  validate koolo.yaml and the character configs", run: runConfigCheck},
+	}
+}
+
+func runPickitReplay(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("pickit replay", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	candidatePath := fs.String("candidate", "", "candidate .nip file or pickit directory (required)")
+	baselinePath := fs.String("baseline", "", "baseline .nip file or pickit directory, defaults to the rule that stashed each drop")
+	supervisor := fs.String("supervisor", "", "only replay drops of this supervisor")
+	from := fs.String("from", "", "first day to replay (YYYY-MM-DD)")
+	to := fs.String("to", "", "last day to replay (YYYY-MM-DD)")
+	asJSON := fs.Bool("json", false, "print the report as JSON")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if *candidatePath == "" {
+		fmt.Fprintln(stderr, "-candidate is required")
+		fs.Usage()
+		return 2
+	}
+
+	if err := config.Load(); err != nil {
+		fmt.Fprintf(stderr, "Error loading configuration: %v\n", err)
+		return 1
+	}
+
+	q := droplog.Query{Supervisor: *supervisor}
+	var err error
+	if q.From, err = parseCLIDate(*from, false); err != nil {
+		fmt.Fprintf(stderr, "Invalid -from date: %v\n", err)
+		return 2
+	}
+	if q.To, err = parseCLIDate(*to, true); err != nil {
+		fmt.Fprintf(stderr, "Invalid -to date: %v\n", err)
+		return 2
+	}
+
+	candidate, err := config.LoadPickitRules(*candidatePath)
+	if err != nil {
+		fmt.Fprintf(stderr, "Error loading candidate rules: %v\n", err)
+		return 1
+	}
+	var baseline nip.Rules
+	if *baselinePath != "" {
+		if baseline, err = config.LoadPickitRules(*baselinePath); err != nil {
+			fmt.Fprintf(stderr, "Error loading baseline rules: %v\n", err)
+			return 1
+		}
+	}
+
+	records, err := droplog.Find(cliDroplogDir(), q)
+	if err != nil {
+		fmt.Fprintf(stderr, "Error reading droplogs: %v\n", err)
+		return 1
+	}
+	report := droplog.Replay(records, baseline, candidate)
+
+	if *asJSON {
+		enc := json.NewEncoder(stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(report); err != nil {
+			return 1
+		}
+		return 0
+	}
+
+	fmt.Fprintf(stdout, "Replayed %d drops: %d kept before, %d kept after\n", report.Records, report.KeptBefore, report.KeptAfter)
+	printReplayItems(stdout, "Newly rejected", report.NewlyRejected, false)
+	printReplayItems(stdout, "Newly kept", report.NewlyKept, true)
+
+	return 0
+}
+
+// runConfigCheck exits with 1 when any error is found, warnings only fail the check with -strict
+func runConfigCheck(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("config check", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	asJSON := fs.Bool("json", false, "print the report as JSON")
+	strict := fs.Bool("strict", false, "fail on warnings too")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	if err := config.Load(); err != nil {
+		fmt.Fprintf(stderr, "Error loading configuration: %v\n", err)
+		return 1
+	}
+
+	report := config.Validate()
+	if supervisor := fs.Arg(0); supervisor != "" {
+		if _, found := config.GetCharacter(supervisor); !found {
+			fmt.Fprintf(stderr, "Supervisor %s not found\n", supervisor)
+			return 2
+		}
+		report = report.Supervisor(supervisor)
+	}
+
+	if *asJSON {
+		enc := json.NewEncoder(stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(report); err != nil {
+			return 1
+		}
+	} else {
+		for _, issue := range report.Issues {
+			fmt.Fprintln(stdout, issue.String())
+		}
+		fmt.Fprintf(stdout, "%d errors, %d warnings\n", report.Errors(), report.Warnings())
+	}
+
+	if report.Errors() > 0 || (*strict && report.Warnings() > 0) {
+		return 1
+	}
+
+	return 0
+}
+
+// runConfigMigrate upgrades the character configs without loading them, Load only migrates them in memory
+func runConfigMigrate(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("config migrate", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	dryRun := fs.Bool("dry-run", false, "print the changes without writing them")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	results, err := config.MigrateCharacters(*dryRun, fs.Args()...)
+	migrated := 0
+	for _, result := range results {
+		if !result.Changed() {
+			continue
+		}
+		migrated++
+		fmt.Fprintf(stdout, "%s: schema version %d -> %d\n", result.File, result.From, result.To)
+		for _, applied := range result.Applied {
+			fmt.Fprintf(stdout, "  - %s\n", applied)
+		}
+		if result.Backup != "" {
+			fmt.Fprintf(stdout, "  backup: %s\n", result.Backup)
+		}
+		if result.Diff != "" {
+			fmt.Fprintln(stdout, result.Diff)
+		}
+	}
+	if *dryRun {
+		fmt.Fprintf(stdout, "%d of %d configs need migration, nothing was written\n", migrated, len(results))
+	} else {
+		fmt.Fprintf(stdout, "%d of %d configs migrated\n", migrated, len(results))
+	}
+
+	if err != nil {
+		fmt.Fprintf(stderr, "%v\n", err)
+		return 1
+	}
+
+	return 0
+}
+
+func printReplayItems(w io.Writer, title string, items []droplog.ReplayItem, candidate bool) {
+	fmt.Fprintf(w, "\n%s (%d)\n", title, len(items))
+	if len(items) == 0 {
+		return
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "TIME\tSUPERVISOR\tITEM\tQUALITY\tRULE")
+	for _, it := range items {
+		rule := fmt.Sprintf("%s: %s", it.BaselineFile, it.BaselineRule)
+		if candidate {
+			rule = fmt.Sprintf("%s:%d: %s", it.CandidateFile, it.CandidateLine, it.CandidateRule)
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", it.Time, it.Supervisor, it.Item, it.Quality, rule)
+	}
+	tw.Flush()
+}
+
+// attachParentConsole makes the output visible when run from a terminal, Koolo is built as a GUI application so
+// it doesn't get a console of its own. Redirected output is left untouched.
+func attachParentConsole() {
+	if ret, _, _ := winproc.AttachConsole.Call(uintptr(winproc.ATTACH_PARENT_PROCESS)); ret == 0 {
+		return
+	}
+
+	if _, err := os.Stdout.Stat(); err != nil {
+		if f, err := os.OpenFile("CONOUT$", os.O_WRONLY, 0); err == nil {
+			os.Stdout = f
+		}
+	}
+	if _, err := os.Stderr.Stat(); err != nil {
+		if f, err := os.OpenFile("CONOUT$", os.O_WRONLY, 0); err == nil {
+			os.Stderr = f
+		}
+	}
+}
+
+func parseCLIDate(value string, endOfDay bool) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	t, err := time.ParseInLocation("2006-01-02", value, time.Local)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if endOfDay {
+		t = t.Add(24*time.Hour - time.Nanosecond)
+	}
+
+	return t, nil
+}
+
+func cliDroplogDir() string {
+	base := config.Koolo.LogSaveDirectory
+	if base == "" {
+		base = "logs"
+	}
+
+	return filepath.Join(base, "droplogs")
+}
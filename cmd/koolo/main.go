@@ -1,14 +1,21 @@
+//go:build windows
+
 package main
 
 import (
 	"context"
+	"errors"
+	"flag"
 	"fmt"
 	"log"
 	"log/slog"
+	"net"
 	_ "net/http/pprof"
 	"os"
+	"os/signal"
 	"path/filepath"
 	"runtime/debug"
+	"strings"
 	"syscall"
 	"time"
 	"unsafe"
@@ -19,7 +26,9 @@ import (
 	"github.com/hectorgimenez/koolo/internal/event"
 	"github.com/hectorgimenez/koolo/internal/remote/discord"
 	"github.com/hectorgimenez/koolo/internal/remote/droplog"
+	"github.com/hectorgimenez/koolo/internal/remote/history"
 	ngrokremote "github.com/hectorgimenez/koolo/internal/remote/ngrok"
+	"github.com/hectorgimenez/koolo/internal/remote/notify"
 	"github.com/hectorgimenez/koolo/internal/remote/telegram"
 	"github.com/hectorgimenez/koolo/internal/server"
 	"github.com/hectorgimenez/koolo/internal/utils"
@@ -33,6 +42,43 @@ var (
 	buildTime string
 )
 
+// startOptions are the flags accepted when starting Koolo, anything not starting with a dash is a CLI subcommand
+type startOptions struct {
+	headless bool
+	listen   string
+}
+
+func parseStartOptions(args []string) (startOptions, error) {
+	var opts startOptions
+	fs := flag.NewFlagSet("koolo", flag.ContinueOnError)
+	fs.BoolVar(&opts.headless, "headless", false, "run without the embedded window and dialogs, errors go to the log and the exit code")
+	fs.StringVar(&opts.listen, "listen", "", "web UI and API listen address (host:port), overrides server in koolo.yaml")
+	if err := fs.Parse(args); err != nil {
+		return opts, err
+	}
+	if fs.NArg() > 0 {
+		err := fmt.Errorf("unexpected argument %q", fs.Arg(0))
+		fmt.Fprintln(fs.Output(), err)
+		fs.Usage()
+		return opts, err
+	}
+
+	return opts, nil
+}
+
+// localURL returns the URL to reach the server listening on addr from this computer
+func localURL(addr string) string {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return "http://" + addr
+	}
+	if host == "" || host == "0.0.0.0" || host == "::" {
+		host = "localhost"
+	}
+
+	return "http://" + net.JoinHostPort(host, port)
+}
+
 // wrapWithRecover wraps a function with panic recovery logic
 func wrapWithRecover(logger *slog.Logger, f func() error) func() error {
 	return func() error {
@@ -53,13 +99,38 @@ func main() {
 	_ = buildID
 	_ = buildTime
 
-	err := config.Load()
+	if len(os.Args) > 1 && !strings.HasPrefix(os.Args[1], "-") {
+		os.Exit(runCommand(os.Args[1:]))
+	}
+
+	if len(os.Args) > 1 {
+		attachParentConsole()
+	}
+	opts, err := parseStartOptions(os.Args[1:])
+	if errors.Is(err, flag.ErrHelp) {
+		os.Exit(0)
+	}
+	if err != nil {
+		os.Exit(2)
+	}
+	if opts.headless {
+		utils.DisableDialogs(slog.Default())
+	}
+
+	err = config.Load()
 	if err != nil {
-		utils.ShowDialog("Error loading configuration", err.Error())
+		if !opts.headless {
+			utils.ShowDialog("Error loading configuration", err.Error())
+		}
 		log.Fatalf("Error loading configuration: %s", err.Error())
 		return
 	}
 
+	listenAddr := config.Koolo.ListenAddr()
+	if opts.listen != "" {
+		listenAddr = opts.listen
+	}
+
 	// Ensure a sensible default delay for Auto Start if not configured
 	if config.Koolo.AutoStart.DelaySeconds <= 0 {
 		config.Koolo.AutoStart.DelaySeconds = 60
@@ -70,6 +141,14 @@ func main() {
 		log.Fatalf("Error starting logger: %s", err.Error())
 	}
 	defer sloggger.FlushAndClose()
+	if opts.headless {
+		utils.DisableDialogs(logger)
+		logger.Info("Koolo running headless", slog.String("listen", listenAddr))
+	}
+
+	for _, issue := range config.LastValidation().Issues {
+		logger.Warn("Configuration issue", slog.String("file", issue.File), slog.String("path", issue.Path), slog.String("severity", string(issue.Severity)), slog.String("message", issue.Message))
+	}
 
 	defer func() {
 		if r := recover(); r != nil {
@@ -77,10 +156,14 @@ func main() {
 			logger.Error(err.Error())
 			sloggger.FlushAndClose()
 			utils.ShowDialog("Koolo error :(", fmt.Sprintf("Koolo will close due to an expected error, please check the latest log file for more info!\n %s", err.Error()))
+			os.Exit(1)
 		}
 	}()
 
-	ctx, cancel := context.WithCancel(context.Background())
+	// SIGTERM is also what Windows sends on console close, logoff and shutdown
+	signalCtx, stopSignals := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stopSignals()
+	ctx, cancel := context.WithCancel(signalCtx)
 	defer cancel()
 
 	g, ctx := errgroup.WithContext(ctx)
@@ -89,7 +172,7 @@ func main() {
 
 	eventListener := event.NewListener(logger)
 
-	// Centralized droplog writer registration
+	// Centralized droplog and run history writers registration
 	dropBase := config.Koolo.LogSaveDirectory
 	if dropBase == "" {
 		dropBase = "logs"
@@ -97,6 +180,8 @@ func main() {
 	dropDir := filepath.Join(dropBase, "droplogs")
 	dropWriter := droplog.NewWriter(dropDir, logger)
 	eventListener.Register(dropWriter.Handle)
+	historyWriter := history.NewWriter(filepath.Join(dropBase, "history"), logger)
+	eventListener.Register(historyWriter.Handle)
 	manager := bot.NewSupervisorManager(logger, eventListener)
 	scheduler := bot.NewScheduler(manager, logger)
 	go scheduler.Start()
@@ -105,13 +190,14 @@ func main() {
 		log.Fatalf("Error starting local server: %s", err.Error())
 	}
 	eventListener.Register(srv.HandleRunewordHistory)
+	eventListener.Register(srv.HandleMetricsEvent)
 	var ngrokTunnel *ngrokremote.Tunnel
 	if config.Koolo.Ngrok.Enabled {
 		if config.Koolo.Ngrok.Authtoken == "" && os.Getenv("NGROK_AUTHTOKEN") == "" {
 			logger.Warn("ngrok enabled but no authtoken set; skipping tunnel start")
 		} else {
 			opts := ngrokremote.Options{
-				LocalAddr:     "http://localhost:8087",
+				LocalAddr:     localURL(listenAddr),
 				Authtoken:     config.Koolo.Ngrok.Authtoken,
 				Region:        config.Koolo.Ngrok.Region,
 				Domain:        config.Koolo.Ngrok.Domain,
@@ -131,7 +217,8 @@ func main() {
 		}
 	}
 
-	g.Go(wrapWithRecover(logger, func() error {
+	// The embedded window, closing it stops Koolo
+	showWindow := func() error {
 		defer cancel()
 		displayScale := config.GetCurrentDisplayScale()
 
@@ -145,7 +232,7 @@ func main() {
 			height = 720
 		}
 
-		w, err := gowebview.New(&gowebview.Config{URL: "http://localhost:8087", WindowConfig: &gowebview.WindowConfig{
+		w, err := gowebview.New(&gowebview.Config{URL: localURL(listenAddr), WindowConfig: &gowebview.WindowConfig{
 			Title: "Koolo Resurrected",
 			Size: &gowebview.Point{
 				X: int64(float64(width) * displayScale),
@@ -210,7 +297,13 @@ func main() {
 		w.Run()
 
 		return nil
-	}))
+	}
+	if !opts.headless {
+		g.Go(wrapWithRecover(logger, showWindow))
+	}
+
+	// Notification sinks, Discord and Telegram are provided by their bots when enabled
+	notifySinks := make(map[string]notify.Sink)
 
 	// Discord Bot initialization
 	if config.Koolo.Discord.Enabled {
@@ -222,11 +315,12 @@ func main() {
 			config.Koolo.Discord.UseWebhook,
 			config.Koolo.Discord.WebhookURL,
 			config.Koolo.Discord.ItemWebhookURL,
+			logger,
 		)
 		if err != nil {
 			logger.Warn("Discord could not be initialized, continuing without Discord", slog.Any("error", err))
 		} else {
-			eventListener.Register(discordBot.Handle)
+			notifySinks[notify.SinkDiscord] = notify.EventSink(discordBot.Notify)
 			if !config.Koolo.Discord.UseWebhook {
 				g.Go(wrapWithRecover(logger, func() error {
 					return discordBot.Start(ctx)
@@ -237,20 +331,26 @@ func main() {
 
 	// Telegram Bot initialization
 	if config.Koolo.Telegram.Enabled {
-		telegramBot, err := telegram.NewBot(config.Koolo.Telegram.Token, config.Koolo.Telegram.ChatID, logger)
+		telegramBot, err := telegram.NewBot(config.Koolo.Telegram.Token, config.Koolo.Telegram.ChatID, config.Koolo.Telegram.AllowedIDs, manager, logger)
 		if err != nil {
 			logger.Warn("Telegram could not be initialized, continuing without Telegram", slog.Any("error", err))
 		} else {
-			eventListener.Register(telegramBot.Handle)
+			notifySinks[notify.SinkTelegram] = telegramBot
 			g.Go(wrapWithRecover(logger, func() error {
 				return telegramBot.Start(ctx)
 			}))
 		}
 	}
 
+	notifyRouter := notify.NewRouter(logger)
+	if err = notifyRouter.Configure(notify.Sinks(config.Koolo), notifySinks); err != nil {
+		logger.Warn("Some notification sinks could not be initialized", slog.Any("error", err))
+	}
+	eventListener.Register(notifyRouter.Handle)
+
 	g.Go(wrapWithRecover(logger, func() error {
 		defer cancel()
-		return srv.Listen(8087)
+		return srv.Listen(listenAddr)
 	}))
 
 	g.Go(wrapWithRecover(logger, func() error {
@@ -261,9 +361,12 @@ func main() {
 	g.Go(wrapWithRecover(logger, func() error {
 		<-ctx.Done()
 		logger.Info("Koolo shutting down...")
+		// A second signal kills the process if stopping the supervisors hangs
+		stopSignals()
 		cancel()
 		manager.StopAll()
 		scheduler.Stop()
+		notifyRouter.Close()
 		err = srv.Stop()
 		if err != nil {
 			logger.Error("error stopping local server", slog.Any("error", err))
@@ -281,7 +384,8 @@ func main() {
 	if err != nil {
 		cancel()
 		logger.Error("Error running Koolo", slog.Any("error", err))
-		return
+		sloggger.FlushAndClose()
+		os.Exit(1)
 	}
 
 	sloggger.FlushAndClose()
@@ -0,0 +1,25 @@
+//go:build !windows
+
+package main
+
+import (
+	"fmt"
+	"os"
+)
+
+// Koolo only runs on Windows, other platforms get the command line tools that don't touch the game
+func main() {
+	if len(os.Args) > 1 {
+		os.Exit(runCommand(os.Args[1:]))
+	}
+
+	fmt.Fprintln(os.Stderr, "Koolo only runs on Windows, only the command line tools are available on this platform.")
+	printUsage(os.Stderr)
+	os.Exit(2)
+}
+
+func platformCommands() []cliCommand {
+	return nil
+}
+
+func attachParentConsole() {}
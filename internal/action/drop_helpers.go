@@ -25,7 +25,7 @@ func IsDropProtected(i data.Item) bool {
 		if ctx.Context.Drop != nil {
 			filtersEnabled = ctx.Context.Drop.DropFiltersEnabled()
 			if filtersEnabled {
-				selected = ctx.Context.Drop.ShouldDropperItem(string(i.Name), i.Quality, i.Type().Code, i.IsRuneword)
+				selected = ctx.Context.Drop.ShouldDropperItem(string(i.Name), i.Quality, i.Type().Code, i.IsRuneword) || ctx.Context.Drop.IsSelectedItem(i)
 				DropperOnly = ctx.Context.Drop.DropperOnlySelected()
 			}
 		}
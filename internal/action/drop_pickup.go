@@ -0,0 +1,116 @@
+package action
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/hectorgimenez/d2go/pkg/data"
+	"github.com/hectorgimenez/d2go/pkg/data/item"
+	"github.com/hectorgimenez/d2go/pkg/data/object"
+
+	"github.com/hectorgimenez/koolo/internal/action/step"
+	"github.com/hectorgimenez/koolo/internal/context"
+	"github.com/hectorgimenez/koolo/internal/pather"
+	"github.com/hectorgimenez/koolo/internal/utils"
+)
+
+// PickupDroppedItems picks up every item lying on the ground around the stash, the pickit rules are ignored. It's the
+// receiving side of an item transfer, the dropper drops its items next to the stash of the same town. Everything
+// picked up is stashed, it returns the number of items picked up.
+func PickupDroppedItems(maxDistance int) (int, error) {
+	ctx := context.Get()
+	ctx.SetLastAction("PickupDroppedItems")
+
+	const (
+		maxItemAttempts = 3
+		// Ground items may take a moment to show up after joining
+		waitForItems = 5 * time.Second
+		maxTotalTime = 5 * time.Minute
+	)
+
+	bank, found := ctx.Data.Objects.FindOne(object.Bank)
+	if !found {
+		return 0, errors.New("stash not found")
+	}
+	if err := MoveToCoords(bank.Position, step.WithDistanceToFinish(3), step.WithIgnoreItems()); err != nil {
+		return 0, fmt.Errorf("failed to move to the stash: %w", err)
+	}
+
+	picked := 0
+	seen := false
+	stashedForSpace := false
+	attempts := make(map[data.UnitID]int)
+	start := time.Now()
+	for time.Since(start) < maxTotalTime {
+		ctx.PauseIfNotPriority()
+		ctx.RefreshGameData()
+
+		var target data.Item
+		skipped := 0
+		for _, it := range ctx.Data.Inventory.ByLocation(item.LocationGround) {
+			if pather.DistanceFromPoint(bank.Position, it.Position) > maxDistance {
+				continue
+			}
+			if attempts[it.UnitID] >= maxItemAttempts {
+				skipped++
+				continue
+			}
+			if target.UnitID == 0 {
+				target = it
+			}
+		}
+
+		if target.UnitID == 0 {
+			if !seen {
+				if time.Since(start) < waitForItems {
+					utils.Sleep(250)
+					continue
+				}
+				return 0, fmt.Errorf("no items found next to the stash in %s", ctx.Data.PlayerUnit.Area.Area().Name)
+			}
+			if err := Stash(true); err != nil {
+				return picked, fmt.Errorf("failed to stash the items picked up: %w", err)
+			}
+			if skipped > 0 {
+				return picked, fmt.Errorf("%d item(s) could not be picked up", skipped)
+			}
+			return picked, nil
+		}
+		seen = true
+
+		if itemNeedsInventorySpace(target) && !itemFitsInventory(target) {
+			// Stashing once makes room for the rest, a second time means the stash is full too
+			if stashedForSpace {
+				return picked, fmt.Errorf("no room left for %s", target.Desc().Name)
+			}
+			if err := Stash(true); err != nil {
+				return picked, fmt.Errorf("failed to stash the items picked up: %w", err)
+			}
+			stashedForSpace = true
+			continue
+		}
+
+		attempts[target.UnitID]++
+		if ctx.PathFinder.DistanceFromMe(target.Position) >= 7 {
+			if err := MoveToCoords(target.Position, step.WithDistanceToFinish(2), step.WithIgnoreItems()); err != nil {
+				ctx.Logger.Debug("Failed to move to dropped item", "item", target.Name, "error", err)
+				continue
+			}
+		}
+		if err := step.PickupItem(target, attempts[target.UnitID]); err != nil {
+			ctx.Logger.Debug("Failed to pick up dropped item", "item", target.Name, "attempt", attempts[target.UnitID], "error", err)
+			continue
+		}
+
+		picked++
+		stashedForSpace = false
+		ctx.Logger.Debug("Picked up dropped item", "item", target.Name)
+	}
+
+	if err := Stash(true); err != nil {
+		ctx.Logger.Warn("Failed to stash the items picked up", "error", err)
+	}
+
+	return picked, fmt.Errorf("timed out after picking up %d item(s)", picked)
+}
@@ -9,8 +9,10 @@ import (
 	"github.com/hectorgimenez/d2go/pkg/data"
 	"github.com/hectorgimenez/d2go/pkg/data/skill"
 	"github.com/hectorgimenez/d2go/pkg/data/state"
+	"github.com/hectorgimenez/koolo/internal/config"
 	"github.com/hectorgimenez/koolo/internal/context"
 	"github.com/hectorgimenez/koolo/internal/game"
+	"github.com/hectorgimenez/koolo/internal/pather"
 	"github.com/hectorgimenez/koolo/internal/ui"
 	"github.com/hectorgimenez/koolo/internal/utils"
 )
@@ -347,8 +349,15 @@ func MoveTo(dest data.Position, options ...MoveOption) error {
 			}
 		}
 
-		//Compute path to reach destination
-		path, _, found := ctx.PathFinder.GetPath(currentDest)
+		//Compute path to reach destination, with teleport the landing points of the fewest casts when enabled
+		var path pather.Path
+		found := false
+		if ctx.Data.CanTeleport() && config.Koolo.Pathfinding.MinimizeTeleports {
+			path, _, found = ctx.PathFinder.GetTeleportPath(currentDest)
+		}
+		if !found {
+			path, _, found = ctx.PathFinder.GetPath(currentDest)
+		}
 		if !found {
 			//Couldn't find path, abort movement
 			ctx.Logger.Warn("path could not be calculated. Current area: [" + ctx.Data.PlayerUnit.Area.Area().Name + "]. Trying to path to Destination: [" + fmt.Sprintf("%d,%d", currentDest.X, currentDest.Y) + "]")
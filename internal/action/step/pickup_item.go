@@ -144,7 +144,7 @@ func PickupItemMouse(it data.Item, itemPickupAttempt int) error {
 		utils.Sleep(utils.RandRng(15, 40))
 
 		// Click on item if mouse is hovering over
-		if currentItem.UnitID == ctx.GameReader.GameReader.GetData().HoverData.UnitID {
+		if currentItem.UnitID == ctx.GameReader.HoveredData().UnitID {
 			ctx.HID.Click(game.LeftButton, cursorX, cursorY)
 			utils.PingSleep(utils.Light, 150)
 
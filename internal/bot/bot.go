@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
+	"path/filepath"
 	"sync"
 	"time"
 
@@ -14,9 +15,11 @@ import (
 	"github.com/hectorgimenez/d2go/pkg/data/stat"
 	"github.com/hectorgimenez/koolo/internal/action"
 	"github.com/hectorgimenez/koolo/internal/action/step"
+	"github.com/hectorgimenez/koolo/internal/config"
 	botCtx "github.com/hectorgimenez/koolo/internal/context"
 	"github.com/hectorgimenez/koolo/internal/drop"
 	"github.com/hectorgimenez/koolo/internal/event"
+	"github.com/hectorgimenez/koolo/internal/game"
 	"github.com/hectorgimenez/koolo/internal/health"
 	"github.com/hectorgimenez/koolo/internal/run"
 	"github.com/hectorgimenez/koolo/internal/utils"
@@ -115,6 +118,11 @@ func (b *Bot) Run(ctx context.Context, firstRun bool, runs []run.Run) error {
 	// Let's make sure we have updated game data also fully loaded before performing anything
 	b.ctx.WaitForGameToLoad()
 
+	if config.Koolo.Debug.RecordGameState {
+		b.startGameRecording()
+		defer b.stopGameRecording()
+	}
+
 	// Cleanup the current game helper structure
 	b.ctx.Cleanup()
 
@@ -494,3 +502,29 @@ type MuleManager interface {
 type StatsReporter interface {
 	ReportStats()
 }
+
+// startGameRecording records the game data of the current game, failures only disable the recording
+func (b *Bot) startGameRecording() {
+	dir := config.Koolo.LogSaveDirectory
+	if dir == "" {
+		dir = "logs"
+	}
+
+	rec, err := game.NewGameRecorder(filepath.Join(dir, "recordings"), b.ctx.Name, b.ctx.GameReader, game.DefaultRecordInterval)
+	if err != nil {
+		b.ctx.Logger.Warn("Game state recording could not be started", slog.Any("error", err))
+		return
+	}
+	b.ctx.Recorder.Store(rec)
+	b.ctx.Logger.Debug("Recording game state", slog.String("file", rec.Path()))
+}
+
+func (b *Bot) stopGameRecording() {
+	rec := b.ctx.Recorder.Swap(nil)
+	if rec == nil {
+		return
+	}
+	if err := rec.Close(); err != nil {
+		b.ctx.Logger.Warn("Error closing game state recording", slog.Any("error", err))
+	}
+}
@@ -116,7 +116,7 @@ func AutoCreateCharacter(class, name string) error {
 		if ctx.GameReader.IsInCharacterSelectionScreen() {
 			// Give it a moment to update selection state
 			utils.Sleep(500)
-			selected := ctx.GameReader.GameReader.GetSelectedCharacterName()
+			selected := ctx.GameReader.GetSelectedCharacterName()
 			ctx.Logger.Info("[AutoCreate] Back at selection screen",
 				slog.String("selected", selected),
 				slog.String("expected", name))
@@ -30,6 +30,7 @@ type SupervisorManager struct {
 	crashDetectors map[string]*game.CrashDetector
 	eventListener  *event.Listener
 	Drop           *drop.Service // Drop: Service façade to manage Drop domain
+	transfers      transfers
 }
 
 func NewSupervisorManager(logger *slog.Logger, eventListener *event.Listener) *SupervisorManager {
@@ -459,6 +460,59 @@ func (mng *SupervisorManager) DropService() *drop.Service {
 	return mng.Drop
 }
 
+// RequestDrop queues a Drop request for a running supervisor, it's kept for a while in case the supervisor restarts
+func (mng *SupervisorManager) RequestDrop(supervisor, room, password string, filters drop.Filters) (*drop.Request, error) {
+	sup := mng.GetSupervisor(supervisor)
+	if sup == nil {
+		return nil, fmt.Errorf("unknown supervisor %s", supervisor)
+	}
+
+	ctx := sup.GetContext()
+	if ctx == nil {
+		return nil, fmt.Errorf("failed to get context for %s", supervisor)
+	}
+
+	if ctx.Drop == nil {
+		ctx.Drop = drop.NewManager(ctx.Name, ctx.Logger)
+	}
+
+	// Avoid overwriting active Drop filters; apply per-request filters when the run starts.
+	if ctx.Drop.Active() == nil {
+		ctx.Drop.UpdateFilters(filters)
+	}
+
+	req := ctx.Drop.RequestDrop(room, password)
+	req.Filters = filters
+	ctx.Logger.Info("Drop request queued", "supervisor", supervisor, "room", room)
+
+	mng.Drop.StorePersistentRequest(supervisor, req)
+
+	return req, nil
+}
+
+// RequestPickup makes a running supervisor join the room and pick up the items dropped there, the result is reported
+// like the one of a Drop
+func (mng *SupervisorManager) RequestPickup(supervisor, room, password string) (*drop.Request, error) {
+	sup := mng.GetSupervisor(supervisor)
+	if sup == nil {
+		return nil, fmt.Errorf("supervisor %s is not running", supervisor)
+	}
+
+	ctx := sup.GetContext()
+	if ctx == nil {
+		return nil, fmt.Errorf("failed to get context for %s", supervisor)
+	}
+
+	if ctx.Drop == nil {
+		ctx.Drop = drop.NewManager(ctx.Name, ctx.Logger)
+	}
+
+	req := ctx.Drop.RequestPickup(room, password)
+	ctx.Logger.Info("Pickup request queued", "supervisor", supervisor, "room", room)
+
+	return req, nil
+}
+
 func (mng *SupervisorManager) GetSupervisorStats(supervisor string) Stats {
 	mng.mu.RLock()
 	sup, ok := mng.supervisors[supervisor]
@@ -383,6 +383,7 @@ func (s *SinglePlayerSupervisor) Start() error {
 		}
 
 		pingMonitor := health.NewPingMonitor(
+			s.name,
 			s.bot.ctx.Logger,
 			pingThreshold,
 			sustainedDuration,
@@ -153,7 +153,7 @@ func (s *baseSupervisor) waitUntilCharacterSelectionScreen() error {
 		// Auto-create: scan character list first, select if exists, create only if not found
 		if s.bot.ctx.CharacterCfg.AutoCreateCharacter {
 			targetName := s.bot.ctx.CharacterCfg.CharacterName
-			currentName := s.bot.ctx.GameReader.GameReader.GetSelectedCharacterName()
+			currentName := s.bot.ctx.GameReader.GetSelectedCharacterName()
 			originalName := currentName
 
 			s.bot.ctx.Logger.Debug(fmt.Sprintf("Auto-create enabled, starting scan. Current selected character: %s", currentName))
@@ -176,7 +176,7 @@ func (s *baseSupervisor) waitUntilCharacterSelectionScreen() error {
 				s.bot.ctx.HID.PressKey(win.VK_DOWN)
 				utils.Sleep(250)
 
-				currentName = s.bot.ctx.GameReader.GameReader.GetSelectedCharacterName()
+				currentName = s.bot.ctx.GameReader.GetSelectedCharacterName()
 				s.bot.ctx.Logger.Debug(fmt.Sprintf("Auto-create scan, checking character: %s", currentName))
 
 				if strings.EqualFold(currentName, targetName) {
@@ -224,7 +224,7 @@ func (s *baseSupervisor) waitUntilCharacterSelectionScreen() error {
 
 		// Auto-create disabled: try to select the character up to 25 times.
 		for i := 0; i < 25; i++ {
-			characterName := s.bot.ctx.GameReader.GameReader.GetSelectedCharacterName()
+			characterName := s.bot.ctx.GameReader.GetSelectedCharacterName()
 
 			s.bot.ctx.Logger.Debug(fmt.Sprintf("Checking character: %s", characterName))
 
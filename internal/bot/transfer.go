@@ -0,0 +1,403 @@
+package bot
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hectorgimenez/koolo/internal/config"
+	"github.com/hectorgimenez/koolo/internal/drop"
+)
+
+const (
+	transferTimeout = 15 * time.Minute
+	maxTransfers    = 50
+)
+
+type TransferStatus string
+
+const (
+	TransferDropping   TransferStatus = "Dropping"
+	TransferCollecting TransferStatus = "Collecting"
+	TransferCompleted  TransferStatus = "Completed"
+	TransferPartial    TransferStatus = "Partial"
+	TransferFailed     TransferStatus = "Failed"
+)
+
+// TransferItem is a group of identical items, identical meaning same name, quality and ethereal flag
+type TransferItem struct {
+	Name     string `json:"name"`
+	Quality  string `json:"quality"`
+	Ethereal bool   `json:"ethereal"`
+	Count    int    `json:"count"`
+}
+
+func (t TransferItem) String() string {
+	name := t.Name
+	if t.Quality != "" && t.Quality != "Normal" {
+		name = t.Quality + " " + name
+	}
+	if t.Ethereal {
+		name = "Ethereal " + name
+	}
+	if t.Count > 1 {
+		name += " x" + strconv.Itoa(t.Count)
+	}
+
+	return name
+}
+
+// Transfer moves items between two of our own characters: the dropper drops them in a room, then the receiver joins
+// it and picks up every item dropped next to the stash. What left and what arrived is found by diffing the armory
+// snapshots of both characters taken before and after.
+type Transfer struct {
+	ID         int            `json:"id"`
+	Dropper    string         `json:"dropper"`
+	Receiver   string         `json:"receiver"`
+	Room       string         `json:"room"`
+	Password   string         `json:"-"`
+	Filters    drop.Filters   `json:"filters"`
+	Status     TransferStatus `json:"status"`
+	Error      string         `json:"error,omitempty"`
+	Left       []TransferItem `json:"left"`
+	Arrived    []TransferItem `json:"arrived"`
+	Missing    []TransferItem `json:"missing"`
+	CreatedAt  time.Time      `json:"createdAt"`
+	FinishedAt time.Time      `json:"finishedAt"`
+
+	dropperBefore  *ArmoryCharacter
+	receiverBefore *ArmoryCharacter
+}
+
+// Finished reports whether the transfer reached a final status
+func (t Transfer) Finished() bool {
+	return t.Status == TransferCompleted || t.Status == TransferPartial || t.Status == TransferFailed
+}
+
+// transfers keeps the transfer jobs of the manager, newest last
+type transfers struct {
+	mu       sync.Mutex
+	jobs     []*Transfer
+	nextID   int
+	onResult func(Transfer)
+}
+
+// SetTransferResultCallback registers a callback invoked once per transfer when it finishes
+func (mng *SupervisorManager) SetTransferResultCallback(callback func(Transfer)) {
+	mng.transfers.mu.Lock()
+	defer mng.transfers.mu.Unlock()
+	mng.transfers.onResult = callback
+}
+
+// Transfers returns a copy of the known transfer jobs, newest first
+func (mng *SupervisorManager) Transfers() []Transfer {
+	mng.transfers.mu.Lock()
+	defer mng.transfers.mu.Unlock()
+
+	result := make([]Transfer, 0, len(mng.transfers.jobs))
+	for i := len(mng.transfers.jobs) - 1; i >= 0; i-- {
+		result = append(result, *mng.transfers.jobs[i])
+	}
+
+	return result
+}
+
+// StartTransfer makes the dropper drop the items matching the filters in the given room and, once it's done, the
+// receiver join it to pick them up. Both supervisors are started when they aren't running. The room must exist, same as for a regular
+// Drop request.
+func (mng *SupervisorManager) StartTransfer(dropper, receiver, room, password string, filters drop.Filters) (Transfer, error) {
+	if dropper == receiver {
+		return Transfer{}, errors.New("dropper and receiver must be different supervisors")
+	}
+	if strings.TrimSpace(room) == "" {
+		return Transfer{}, errors.New("room name is required")
+	}
+	if _, found := config.GetCharacter(dropper); !found {
+		return Transfer{}, fmt.Errorf("unknown supervisor %s", dropper)
+	}
+	if _, found := config.GetCharacter(receiver); !found {
+		return Transfer{}, fmt.Errorf("unknown supervisor %s", receiver)
+	}
+	if mng.transferInProgress(dropper, receiver) {
+		return Transfer{}, fmt.Errorf("a transfer involving %s or %s is already in progress", dropper, receiver)
+	}
+
+	dropperBefore, err := mng.armorySnapshot(dropper)
+	if err != nil {
+		return Transfer{}, err
+	}
+	receiverBefore, err := mng.armorySnapshot(receiver)
+	if err != nil {
+		return Transfer{}, err
+	}
+
+	// The pickup is requested from the running receiver once the items are dropped
+	if mng.GetSupervisor(receiver) == nil {
+		if err = mng.Start(receiver, false, false); err != nil {
+			return Transfer{}, fmt.Errorf("failed to start receiver %s: %w", receiver, err)
+		}
+	}
+
+	// Listening before the Drop is requested, so a quick one can't be missed
+	dropDone, removeListener := mng.waitDropResult(dropper, room, "drop")
+
+	if mng.GetSupervisor(dropper) != nil {
+		if _, err = mng.RequestDrop(dropper, room, password, filters); err != nil {
+			removeListener()
+			return Transfer{}, err
+		}
+	} else {
+		mng.Drop.QueueStartDrop(dropper, room, password, filters, 0, "")
+		if err = mng.Start(dropper, false, false); err != nil {
+			removeListener()
+			return Transfer{}, fmt.Errorf("failed to start dropper %s: %w", dropper, err)
+		}
+	}
+
+	mng.transfers.mu.Lock()
+	mng.transfers.nextID++
+	t := &Transfer{
+		ID:             mng.transfers.nextID,
+		Dropper:        dropper,
+		Receiver:       receiver,
+		Room:           room,
+		Password:       password,
+		Filters:        filters,
+		Status:         TransferDropping,
+		CreatedAt:      time.Now(),
+		dropperBefore:  dropperBefore,
+		receiverBefore: receiverBefore,
+	}
+	mng.transfers.jobs = append(mng.transfers.jobs, t)
+	if len(mng.transfers.jobs) > maxTransfers {
+		mng.transfers.jobs = mng.transfers.jobs[len(mng.transfers.jobs)-maxTransfers:]
+	}
+	job := *t
+	mng.transfers.mu.Unlock()
+
+	mng.logger.Info("Item transfer started", slog.Int("id", t.ID), slog.String("dropper", dropper), slog.String("receiver", receiver), slog.String("room", room))
+	go func() {
+		defer removeListener()
+		mng.watchTransfer(t, dropDone)
+	}()
+
+	return job, nil
+}
+
+func (mng *SupervisorManager) transferInProgress(supervisors ...string) bool {
+	mng.transfers.mu.Lock()
+	defer mng.transfers.mu.Unlock()
+
+	for _, t := range mng.transfers.jobs {
+		if !t.Finished() && (slices.Contains(supervisors, t.Dropper) || slices.Contains(supervisors, t.Receiver)) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// waitDropResult returns a channel receiving the outcome of the next Drop or pickup of the supervisor in the room,
+// action names it in the error
+func (mng *SupervisorManager) waitDropResult(supervisor, room, action string) (<-chan error, func()) {
+	done := make(chan error, 1)
+	remove := mng.Drop.AddDropResultListener(func(supervisorName, dropRoom, result string, _ int, _ time.Duration, errorMsg string, _ drop.Filters) {
+		if supervisorName != supervisor || !strings.EqualFold(dropRoom, room) {
+			return
+		}
+		var err error
+		if result != "Success" {
+			err = fmt.Errorf("%s failed to %s the items: %s", supervisor, action, errorMsg)
+		}
+		select {
+		case done <- err:
+		default:
+		}
+	})
+
+	return done, remove
+}
+
+// watchTransfer waits for the Drop result of the dropper, then has the receiver pick the items up and waits for its
+// result before diffing the armory snapshots of both characters
+func (mng *SupervisorManager) watchTransfer(t *Transfer, dropDone <-chan error) {
+	timeout := time.After(time.Until(t.CreatedAt.Add(transferTimeout)))
+	select {
+	case err := <-dropDone:
+		if err != nil {
+			mng.finishTransfer(t, err, nil)
+			return
+		}
+	case <-timeout:
+		mng.finishTransfer(t, fmt.Errorf("timed out waiting for %s to drop the items", t.Dropper), nil)
+		return
+	}
+
+	mng.setTransferStatus(t, TransferCollecting)
+	pickupDone, removeListener := mng.waitDropResult(t.Receiver, t.Room, "pick up")
+	defer removeListener()
+	if _, err := mng.RequestPickup(t.Receiver, t.Room, t.Password); err != nil {
+		mng.finishTransfer(t, err, nil)
+		return
+	}
+
+	// A failed pickup may still have moved some items, the snapshots tell which ones
+	var pickupErr error
+	select {
+	case pickupErr = <-pickupDone:
+	case <-timeout:
+		pickupErr = fmt.Errorf("timed out waiting for %s to pick up the items", t.Receiver)
+	}
+
+	mng.finishTransfer(t, nil, pickupErr)
+}
+
+func (mng *SupervisorManager) setTransferStatus(t *Transfer, status TransferStatus) {
+	mng.transfers.mu.Lock()
+	defer mng.transfers.mu.Unlock()
+	t.Status = status
+}
+
+// finishTransfer diffs the snapshots unless err is set, pickupErr tells why items are missing
+func (mng *SupervisorManager) finishTransfer(t *Transfer, err, pickupErr error) {
+	var left, arrived, missing []TransferItem
+	if err == nil {
+		dropperAfter, dErr := mng.armorySnapshot(t.Dropper)
+		receiverAfter, rErr := mng.armorySnapshot(t.Receiver)
+		err = errors.Join(dErr, rErr)
+		if err == nil {
+			left, _ = diffArmory(t.dropperBefore, dropperAfter)
+			_, arrived = diffArmory(t.receiverBefore, receiverAfter)
+			missing = subtractTransferItems(left, arrived)
+		}
+	}
+
+	mng.transfers.mu.Lock()
+	t.Left, t.Arrived, t.Missing = left, arrived, missing
+	t.FinishedAt = time.Now()
+	switch {
+	case err != nil:
+		t.Status = TransferFailed
+		t.Error = err.Error()
+	case len(left) == 0:
+		t.Status = TransferFailed
+		t.Error = fmt.Sprintf("no items left %s", t.Dropper)
+	case len(missing) > 0:
+		t.Status = TransferPartial
+		t.Error = fmt.Sprintf("%d item(s) did not arrive", countTransferItems(missing))
+		if pickupErr != nil {
+			t.Error += " (" + pickupErr.Error() + ")"
+		}
+	default:
+		t.Status = TransferCompleted
+	}
+	result := *t
+	callback := mng.transfers.onResult
+	mng.transfers.mu.Unlock()
+
+	mng.logger.Info("Item transfer finished", slog.Int("id", result.ID), slog.String("status", string(result.Status)), slog.Int("left", countTransferItems(left)), slog.Int("arrived", countTransferItems(arrived)))
+	if callback != nil {
+		callback(result)
+	}
+}
+
+// armorySnapshot refreshes the armory file of a character that is in game and loads it, the last dump is used
+// otherwise
+func (mng *SupervisorManager) armorySnapshot(supervisor string) (*ArmoryCharacter, error) {
+	if ctx := mng.GetContext(supervisor); ctx != nil && ctx.Data != nil && ctx.Manager != nil && ctx.Manager.InGame() {
+		if err := dumpArmoryData(supervisor, ctx.Data, ctx.GameReader.LastGameName()); err != nil {
+			mng.logger.Warn("Failed to refresh armory data", slog.String("supervisor", supervisor), slog.Any("error", err))
+		}
+	}
+
+	armory, err := LoadArmoryData(supervisor)
+	if err != nil {
+		return nil, fmt.Errorf("no armory snapshot for %s, it has to play a game first: %w", supervisor, err)
+	}
+
+	return armory, nil
+}
+
+// diffArmory returns the items gone and the items new between two snapshots of the same character
+func diffArmory(before, after *ArmoryCharacter) (removed, added []TransferItem) {
+	beforeCounts, afterCounts := countArmoryItems(before), countArmoryItems(after)
+	for key, count := range beforeCounts {
+		if diff := count - afterCounts[key]; diff > 0 {
+			key.Count = diff
+			removed = append(removed, key)
+		}
+	}
+	for key, count := range afterCounts {
+		if diff := count - beforeCounts[key]; diff > 0 {
+			key.Count = diff
+			added = append(added, key)
+		}
+	}
+	sortTransferItems(removed)
+	sortTransferItems(added)
+
+	return removed, added
+}
+
+// countArmoryItems counts the items of a snapshot grouped by name, quality and ethereal flag. The belt is left out,
+// potions come and go all the time.
+func countArmoryItems(a *ArmoryCharacter) map[TransferItem]int {
+	counts := make(map[TransferItem]int)
+	if a == nil {
+		return counts
+	}
+
+	containers := [][]ArmoryItem{
+		a.Equipped, a.Inventory, a.Stash, a.SharedStash1, a.SharedStash2, a.SharedStash3, a.SharedStash4,
+		a.SharedStash5, a.SharedStash6, a.GemsTab, a.MaterialsTab, a.RunesTab, a.Cube,
+	}
+	for _, items := range containers {
+		for _, it := range items {
+			counts[TransferItem{Name: it.Name, Quality: it.Quality, Ethereal: it.Ethereal}]++
+		}
+	}
+
+	return counts
+}
+
+// subtractTransferItems returns the items of a not found in b
+func subtractTransferItems(a, b []TransferItem) []TransferItem {
+	found := make(map[TransferItem]int, len(b))
+	for _, it := range b {
+		count := it.Count
+		it.Count = 0
+		found[it] += count
+	}
+
+	var result []TransferItem
+	for _, it := range a {
+		key := it
+		key.Count = 0
+		if diff := it.Count - found[key]; diff > 0 {
+			it.Count = diff
+			result = append(result, it)
+		}
+	}
+
+	return result
+}
+
+func countTransferItems(items []TransferItem) int {
+	total := 0
+	for _, it := range items {
+		total += it.Count
+	}
+
+	return total
+}
+
+func sortTransferItems(items []TransferItem) {
+	slices.SortFunc(items, func(a, b TransferItem) int {
+		return strings.Compare(a.String(), b.String())
+	})
+}
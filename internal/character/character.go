@@ -8,6 +8,7 @@ import (
 	"github.com/hectorgimenez/d2go/pkg/data"
 	"github.com/hectorgimenez/d2go/pkg/data/npc"
 	"github.com/hectorgimenez/d2go/pkg/data/stat"
+	"github.com/hectorgimenez/koolo/internal/config"
 	"github.com/hectorgimenez/koolo/internal/context"
 )
 
@@ -18,21 +19,21 @@ func BuildCharacter(ctx *context.Context) (context.Character, error) {
 
 	if len(ctx.CharacterCfg.Game.Runs) > 0 && (ctx.CharacterCfg.Game.Runs[0] == "leveling" || ctx.CharacterCfg.Game.Runs[0] == "leveling_sequence") {
 		switch strings.ToLower(ctx.CharacterCfg.Character.Class) {
-		case "barb", "barb_leveling":
+		case config.BarbClass, config.BarbLevelingClass:
 			return BarbLeveling{BaseCharacter: bc}, nil
-		case "sorceress_leveling":
+		case config.SorceressLevelingClass:
 			return SorceressLeveling{BaseCharacter: bc}, nil
-		case "necromancer":
+		case config.NecromancerClass:
 			return &NecromancerLeveling{BaseCharacter: bc}, nil
-		case "paladin":
+		case config.PaladinClass:
 			return PaladinLeveling{BaseCharacter: bc}, nil
-		case "assassin":
+		case config.AssassinClass:
 			return AssassinLeveling{BaseCharacter: bc}, nil
-		case "druid_leveling":
+		case config.DruidLevelingClass:
 			return DruidLeveling{BaseCharacter: bc}, nil
-		case "amazon_leveling":
+		case config.AmazonLevelingClass:
 			return AmazonLeveling{BaseCharacter: bc}, nil
-		case "warlock_leveling":
+		case config.WarlockLevelingClass:
 			return WarlockLeveling{BaseCharacter: bc}, nil
 		}
 
@@ -40,41 +41,41 @@ func BuildCharacter(ctx *context.Context) (context.Character, error) {
 	}
 
 	switch strings.ToLower(ctx.CharacterCfg.Character.Class) {
-	case "sorceress":
+	case config.SorceressClass:
 		return BlizzardSorceress{BaseCharacter: bc}, nil
-	case "fireballsorc":
+	case config.FireballSorcClass:
 		return FireballSorceress{BaseCharacter: bc}, nil
-	case "mule":
+	case config.MuleClass:
 		return MuleCharacter{BaseCharacter: bc}, nil
-	case "nova":
+	case config.NovaClass:
 		return NovaSorceress{BaseCharacter: bc}, nil
-	case "hydraorb":
+	case config.HydraOrbClass:
 		return HydraOrbSorceress{BaseCharacter: bc}, nil
-	case "lightsorc":
+	case config.LightSorcClass:
 		return LightningSorceress{BaseCharacter: bc}, nil
-	case "hammerdin":
+	case config.HammerdinClass:
 		return Hammerdin{BaseCharacter: bc}, nil
-	case "foh":
+	case config.FohClass:
 		return Foh{BaseCharacter: bc}, nil
-	case "dragondin":
+	case config.DragondinClass:
 		return Dragondin{BaseCharacter: bc}, nil
-	case "smiter":
+	case config.SmiterClass:
 		return Smiter{BaseCharacter: bc}, nil
-	case "trapsin":
+	case config.TrapsinClass:
 		return Trapsin{BaseCharacter: bc}, nil
-	case "mosaic":
+	case config.MosaicClass:
 		return MosaicSin{BaseCharacter: bc}, nil
-	case "winddruid":
+	case config.WindDruidClass:
 		return WindDruid{BaseCharacter: bc}, nil
-	case "javazon":
+	case config.JavazonClass:
 		return Javazon{BaseCharacter: bc}, nil
-	case "berserker":
+	case config.BerserkerClass:
 		return &Berserker{BaseCharacter: bc}, nil // Return a pointer to Berserker
-	case "warcry_barb":
+	case config.WarcryBarbClass:
 		return &WarcryBarb{BaseCharacter: bc}, nil
-	case "whirlwind_barb":
+	case config.WhirlwindBarbClass:
 		return &WhirlwindBarb{BaseCharacter: bc}, nil
-	case "development":
+	case config.DevelopmentClass:
 		return DevelopmentCharacter{BaseCharacter: bc}, nil
 	}
 
@@ -0,0 +1,44 @@
+package config
+
+// Character classes of character.class, the leveling ones are only available with a leveling run
+const (
+	SorceressClass     = "sorceress"
+	FireballSorcClass  = "fireballsorc"
+	MuleClass          = "mule"
+	NovaClass          = "nova"
+	HydraOrbClass      = "hydraorb"
+	LightSorcClass     = "lightsorc"
+	HammerdinClass     = "hammerdin"
+	FohClass           = "foh"
+	DragondinClass     = "dragondin"
+	SmiterClass        = "smiter"
+	TrapsinClass       = "trapsin"
+	MosaicClass        = "mosaic"
+	WindDruidClass     = "winddruid"
+	JavazonClass       = "javazon"
+	BerserkerClass     = "berserker"
+	WarcryBarbClass    = "warcry_barb"
+	WhirlwindBarbClass = "whirlwind_barb"
+	DevelopmentClass   = "development"
+
+	BarbClass              = "barb"
+	BarbLevelingClass      = "barb_leveling"
+	SorceressLevelingClass = "sorceress_leveling"
+	NecromancerClass       = "necromancer"
+	PaladinClass           = "paladin"
+	AssassinClass          = "assassin"
+	DruidLevelingClass     = "druid_leveling"
+	AmazonLevelingClass    = "amazon_leveling"
+	WarlockLevelingClass   = "warlock_leveling"
+)
+
+var AvailableClasses = []string{
+	SorceressClass, FireballSorcClass, MuleClass, NovaClass, HydraOrbClass, LightSorcClass, HammerdinClass, FohClass,
+	DragondinClass, SmiterClass, TrapsinClass, MosaicClass, WindDruidClass, JavazonClass, BerserkerClass,
+	WarcryBarbClass, WhirlwindBarbClass, DevelopmentClass,
+}
+
+var LevelingClasses = []string{
+	BarbClass, BarbLevelingClass, SorceressLevelingClass, NecromancerClass, PaladinClass, AssassinClass,
+	DruidLevelingClass, AmazonLevelingClass, WarlockLevelingClass,
+}
@@ -4,12 +4,17 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"maps"
+	"net"
 	"path/filepath"
 	"slices"
+	"strconv"
 	"sync"
 	"time"
 
 	"github.com/hectorgimenez/d2go/pkg/data"
+	"github.com/hectorgimenez/koolo/internal/health/policy"
+	"github.com/hectorgimenez/koolo/internal/pickit"
 	"github.com/hectorgimenez/koolo/internal/utils"
 
 	"os"
@@ -30,12 +35,11 @@ var (
 	Koolo      *KooloCfg
 	Characters map[string]*CharacterCfg
 	Version    = "dev"
-
-	// NIP rules cache - stores compiled rules by path to avoid recompiling for multiple characters
-	nipRulesCacheMux sync.RWMutex
-	nipRulesCache    = make(map[string]nip.Rules)
 )
 
+// DefaultServerPort is the web UI and API port when koolo.yaml doesn't set one
+const DefaultServerPort = 8087
+
 const (
 	GameVersionReignOfTheWarlock = "reign_of_the_warlock"
 	GameVersionExpansion         = "expansion"
@@ -47,6 +51,7 @@ type KooloCfg struct {
 		Screenshots               bool `yaml:"screenshots"`
 		RenderMap                 bool `yaml:"renderMap"`
 		OpenOverlayMapOnGameStart bool `yaml:"openOverlayMapOnGameStart"`
+		RecordGameState           bool `yaml:"recordGameState"`
 	} `yaml:"debug"`
 	FirstRun              bool   `yaml:"firstRun"`
 	UseCustomSettings     bool   `yaml:"useCustomSettings"`
@@ -78,7 +83,16 @@ type KooloCfg struct {
 		Enabled bool   `yaml:"enabled"`
 		ChatID  int64  `yaml:"chatId"`
 		Token   string `yaml:"token"`
+		// AllowedIDs are the chat and user IDs allowed to send commands, only ChatID is allowed when empty
+		AllowedIDs []int64 `yaml:"allowedIds"`
 	}
+	Notifications struct {
+		Sinks []NotificationSink `yaml:"sinks"`
+	} `yaml:"notifications"`
+	Server struct {
+		Host string `yaml:"host"` // Empty listens on every interface
+		Port int    `yaml:"port"` // DefaultServerPort when not set
+	} `yaml:"server"`
 	Ngrok struct {
 		Enabled       bool   `yaml:"enabled"`
 		SendURL       bool   `yaml:"sendUrl"`
@@ -97,8 +111,22 @@ type KooloCfg struct {
 		Enabled      bool `yaml:"enabled"`
 		DelaySeconds int  `yaml:"delaySeconds"`
 	} `yaml:"autoStart"`
+	Droplog struct {
+		RetentionDays int    `yaml:"retentionDays"` // Files older than this are compressed or deleted, 0 keeps them forever
+		RetentionMode string `yaml:"retentionMode"` // "compress" (default) or "delete"
+	} `yaml:"droplog"`
+	Pathfinding struct {
+		Planner string `yaml:"planner"` // "astar" (default) or "hierarchical"
+		// MinimizeTeleports teleports to the landing points of the fewest casts instead of along the walking path
+		MinimizeTeleports bool `yaml:"minimizeTeleports"`
+		// Cache keeps the computed paths and room orders on disk, see pathcache. Off unless set
+		Cache bool `yaml:"cache"`
+	} `yaml:"pathfinding"`
 	RunewordFavoriteRecipes []string `yaml:"runewordFavoriteRecipes"`
 	RunFavoriteRuns         []string `yaml:"runFavoriteRuns"`
+
+	// secretRefs keeps the ${...} references of the secret fields, they are written back instead of the secrets
+	secretRefs map[string]string
 }
 
 type Day struct {
@@ -213,6 +241,7 @@ type AutoRespecConfig struct {
 }
 
 type CharacterCfg struct {
+	SchemaVersion        int    `yaml:"schemaVersion"`
 	MaxGameLength        int    `yaml:"maxGameLength"`
 	Username             string `yaml:"username"`
 	Password             string `yaml:"password"`
@@ -228,7 +257,12 @@ type CharacterCfg struct {
 	HidePortraits        bool   `yaml:"hidePortraits"`
 	AutoStart            bool   `yaml:"autoStart"`
 
-	ConfigFolderName string `yaml:"-"`
+	// Profile is the base profile in config/profiles, values not set by the character are inherited from it
+	Profile          string             `yaml:"profile,omitempty"`
+	ConfigFolderName string             `yaml:"-"`
+	Inheritance      ProfileInheritance `yaml:"-"`
+	// secretRefs keeps the ${...} references of the secret fields, they are written back instead of the secrets
+	secretRefs map[string]string
 
 	PacketCasting struct {
 		UseForEntranceInteraction bool `yaml:"useForEntranceInteraction"`
@@ -239,18 +273,8 @@ type CharacterCfg struct {
 		UseForSkillSelection      bool `yaml:"useForSkillSelection"`
 	} `yaml:"packetCasting"`
 
-	Scheduler Scheduler `yaml:"scheduler"`
-	Health    struct {
-		HealingPotionAt     int `yaml:"healingPotionAt"`
-		ManaPotionAt        int `yaml:"manaPotionAt"`
-		RejuvPotionAtLife   int `yaml:"rejuvPotionAtLife"`
-		RejuvPotionAtMana   int `yaml:"rejuvPotionAtMana"`
-		MercHealingPotionAt int `yaml:"mercHealingPotionAt"`
-		MercRejuvPotionAt   int `yaml:"mercRejuvPotionAt"`
-		ChickenAt           int `yaml:"chickenAt"`
-		TownChickenAt       int `yaml:"townChickenAt"`
-		MercChickenAt       int `yaml:"mercChickenAt"`
-	} `yaml:"health"`
+	Scheduler       Scheduler       `yaml:"scheduler"`
+	Health          policy.Settings `yaml:"health"`
 	ChickenOnCurses struct {
 		AmplifyDamage bool `yaml:"amplifyDamage"`
 		Decrepify     bool `yaml:"decrepify"`
@@ -428,8 +452,7 @@ type CharacterCfg struct {
 		Andariel struct {
 			ClearRoom bool `yaml:"clearRoom"`
 			// Deprecated: kept for backwards compatibility with older configs; can be removed in the future.
-			UseAntidoesDeprecated bool `yaml:"useAntidoes,omitempty"`
-			UseAntidotes          bool `yaml:"useAntidotes"`
+			UseAntidotes bool `yaml:"useAntidotes"`
 		}
 		Duriel struct {
 			UseThawing bool `yaml:"useThawing"`
@@ -500,6 +523,18 @@ type CharacterCfg struct {
 			SkipOtherRuns     bool          `yaml:"skipOtherRuns"`
 			Areas             []area.ID     `yaml:"areas"`
 			OpenChests        bool          `yaml:"openChests"`
+			Scoring           struct {
+				Enabled     bool          `yaml:"enabled"`
+				DamageTypes []stat.Resist `yaml:"damageTypes"` // Defaults to skipOnImmunities
+				// The minimum score and the weights left unset keep their default, see terrorzone.DefaultScoreWeights
+				MinScore               *float64 `yaml:"minScore,omitempty"`
+				ExpWeight              *float64 `yaml:"expWeight,omitempty"`
+				LootWeight             *float64 `yaml:"lootWeight,omitempty"`
+				ImmunityWeight         *float64 `yaml:"immunityWeight,omitempty"`
+				ClearTimeWeight        *float64 `yaml:"clearTimeWeight,omitempty"`
+				DeathWeight            *float64 `yaml:"deathWeight,omitempty"`
+				TargetClearTimeSeconds int      `yaml:"targetClearTimeSeconds"`
+			} `yaml:"scoring"`
 		} `yaml:"terror_zone"`
 		Leveling struct {
 			EnsurePointsAllocation   bool     `yaml:"ensurePointsAllocation"`
@@ -630,6 +665,7 @@ func Load() error {
 	cfgMux.Lock()
 	defer cfgMux.Unlock()
 	Characters = make(map[string]*CharacterCfg)
+	pendingMigrations = make(map[string]MigrationResult)
 
 	_, err := os.Getwd()
 	if err != nil {
@@ -648,6 +684,9 @@ func Load() error {
 		return fmt.Errorf("error reading config %s: %w", kooloPath, err)
 	}
 	if Koolo != nil {
+		if Koolo.secretRefs, err = resolveSecrets(kooloSecretFields(Koolo)); err != nil {
+			return fmt.Errorf("error resolving secret in %s: %w", kooloPath, err)
+		}
 		sanitizeDiscordConfig(Koolo)
 	}
 
@@ -658,30 +697,40 @@ func Load() error {
 	}
 
 	for _, entry := range entries {
-		if !entry.IsDir() {
+		if !entry.IsDir() || entry.Name() == ProfilesDir {
 			continue
 		}
 
 		charCfg := CharacterCfg{}
 
 		charConfigPath := getAbsPath(filepath.Join("config", entry.Name(), "config.yaml"))
-		r, err = os.Open(charConfigPath)
+		original, err := os.ReadFile(charConfigPath)
 		if err != nil {
-			return fmt.Errorf("error loading config.yaml: %w", err)
+			return fmt.Errorf("error loading %s character config: %w", entry.Name(), err)
 		}
 
-		d := yaml.NewDecoder(r)
-		if err = d.Decode(&charCfg); err != nil {
-			_ = r.Close()
-			return fmt.Errorf("error reading %s character config: %w", charConfigPath, err)
+		// Outdated files are only migrated in memory, "koolo config migrate" is the one writing them
+		migrated, _, migration, err := migrateCharacterConfig(charConfigPath, original)
+		if err != nil {
+			return fmt.Errorf("error migrating %s character config: %w", entry.Name(), err)
+		}
+		if migration.Changed() {
+			pendingMigrations[entry.Name()] = migration
 		}
-		_ = r.Close()
 
-		// Deprecated: kept for backwards compatibility with older configs; can be removed in the future.
-		if !charCfg.Game.Andariel.UseAntidotes && charCfg.Game.Andariel.UseAntidoesDeprecated {
-			charCfg.Game.Andariel.UseAntidotes = true
+		content, inheritance, err := resolveCharacterConfig(charConfigPath, migrated)
+		if err != nil {
+			return fmt.Errorf("error loading %s character config: %w", entry.Name(), err)
 		}
-		charCfg.Game.Andariel.UseAntidoesDeprecated = false
+
+		if err = yaml.Unmarshal(content, &charCfg); err != nil {
+			return fmt.Errorf("error reading %s character config: %w", charConfigPath, err)
+		}
+		charCfg.Inheritance = inheritance
+		if charCfg.secretRefs, err = resolveSecrets(characterSecretFields(&charCfg)); err != nil {
+			return fmt.Errorf("error resolving secret in %s: %w", charConfigPath, err)
+		}
+
 		charCfg.Game.GameVersion = NormalizeGameVersion(charCfg.Game.GameVersion)
 
 		charCfg.ConfigFolderName = entry.Name()
@@ -739,6 +788,10 @@ func Load() error {
 		charCfg.Validate()
 	}
 
+	loadTerrorZoneData()
+
+	lastValidation = validateLoaded()
+
 	return nil
 }
 
@@ -753,6 +806,16 @@ func NormalizeGameVersion(version string) string {
 	}
 }
 
+// ListenAddr returns the host:port the web UI and API listen on
+func (c *KooloCfg) ListenAddr() string {
+	port := c.Server.Port
+	if port == 0 {
+		port = DefaultServerPort
+	}
+
+	return net.JoinHostPort(c.Server.Host, strconv.Itoa(port))
+}
+
 func sanitizeDiscordConfig(cfg *KooloCfg) {
 	if !cfg.Discord.Enabled {
 		return
@@ -769,85 +832,23 @@ func sanitizeDiscordConfig(cfg *KooloCfg) {
 
 // ClearNIPCache clears the compiled NIP rules cache, forcing recompilation on next load
 func ClearNIPCache() {
-	nipRulesCacheMux.Lock()
-	nipRulesCache = make(map[string]nip.Rules)
-	nipRulesCacheMux.Unlock()
+	pickit.ClearRulesCache()
 }
 
 // getCachedRulesDir returns cached NIP rules for a directory, compiling only if not cached
 func getCachedRulesDir(pickitPath string) (nip.Rules, error) {
-	nipRulesCacheMux.RLock()
-	if cached, ok := nipRulesCache[pickitPath]; ok {
-		nipRulesCacheMux.RUnlock()
-		return cached, nil
-	}
-	nipRulesCacheMux.RUnlock()
-
-	// Not cached, compile the rules
-	rules, err := nip.ReadDir(pickitPath)
-	if err != nil {
-		return nil, err
-	}
-
-	// Store in cache
-	nipRulesCacheMux.Lock()
-	nipRulesCache[pickitPath] = rules
-	nipRulesCacheMux.Unlock()
-
-	return rules, nil
-}
-
-// getCachedRulesFile returns cached NIP rules for a single file, compiling only if not cached
-func getCachedRulesFile(filePath string) (nip.Rules, error) {
-	nipRulesCacheMux.RLock()
-	if cached, ok := nipRulesCache[filePath]; ok {
-		nipRulesCacheMux.RUnlock()
-		return cached, nil
-	}
-	nipRulesCacheMux.RUnlock()
-
-	// Not cached, compile via temp directory workaround
-	rules, err := readSinglePickitFileUncached(filePath)
-	if err != nil {
-		return nil, err
-	}
-
-	// Store in cache
-	nipRulesCacheMux.Lock()
-	nipRulesCache[filePath] = rules
-	nipRulesCacheMux.Unlock()
-
-	return rules, nil
+	return pickit.LoadRulesDir(pickitPath)
 }
 
-// Helper function to read a single NIP file using the temp directory workaround (uncached)
-func readSinglePickitFileUncached(filePath string) (nip.Rules, error) {
-	tempDir := filepath.Join(filepath.Dir(filePath), "temp_single_read")
-	if err := os.MkdirAll(tempDir, 0755); err != nil {
-		return nil, fmt.Errorf("failed to create temp pickit directory: %w", err)
-	}
-	defer os.RemoveAll(tempDir)
-
-	destFile := filepath.Join(tempDir, filepath.Base(filePath))
-	sourceData, err := os.ReadFile(filePath)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read source pickit file %s: %w", filePath, err)
-	}
-	if err := os.WriteFile(destFile, sourceData, 0644); err != nil {
-		return nil, fmt.Errorf("failed to write to temp pickit file: %w", err)
-	}
-
-	rules, err := nip.ReadDir(tempDir + "\\")
-	if err != nil {
-		return nil, fmt.Errorf("error reading from temp pickit directory %s: %w", tempDir, err)
-	}
-
-	return rules, nil
+// LoadPickitRules compiles the NIP rules of a pickit directory or a single .nip file, bypassing the cache so
+// files being edited are always read from disk
+func LoadPickitRules(path string) (nip.Rules, error) {
+	return pickit.ReadRules(path)
 }
 
 // readSinglePickitFile returns cached NIP rules for a single file (for backwards compatibility)
 func readSinglePickitFile(filePath string) (nip.Rules, error) {
-	return getCachedRulesFile(filePath)
+	return pickit.LoadRulesFile(filePath)
 }
 
 func CreateFromTemplate(name string) error {
@@ -883,9 +884,11 @@ func ValidateAndSaveConfig(config KooloCfg) error {
 		sanitizeDiscordConfig(&config)
 	}
 
-	text, err := yaml.Marshal(config)
+	// The copy shares the sinks with the loaded config, hiding their secrets must not touch the live ones
+	config.Notifications.Sinks = slices.Clone(config.Notifications.Sinks)
+	text, err := marshalKooloConfig(&config)
 	if err != nil {
-		return fmt.Errorf("error parsing koolo config: %w", err)
+		return err
 	}
 
 	err = os.WriteFile("config/koolo.yaml", text, 0644)
@@ -900,9 +903,9 @@ func SaveKooloConfig(config *KooloCfg) error {
 	if config == nil {
 		return errors.New("koolo config is nil")
 	}
-	text, err := yaml.Marshal(config)
+	text, err := marshalKooloConfig(config)
 	if err != nil {
-		return fmt.Errorf("error parsing koolo config: %w", err)
+		return err
 	}
 	if err := os.WriteFile("config/koolo.yaml", text, 0644); err != nil {
 		return fmt.Errorf("error writing koolo config: %w", err)
@@ -910,12 +913,45 @@ func SaveKooloConfig(config *KooloCfg) error {
 	return nil
 }
 
+// marshalKooloConfig encodes the config with the secret references in place of the secrets, cfg keeps its secrets
+func marshalKooloConfig(cfg *KooloCfg) ([]byte, error) {
+	restore, err := hideSecrets(kooloSecretFields(cfg), cfg.secretRefs)
+	if err != nil {
+		return nil, fmt.Errorf("error saving secret: %w", err)
+	}
+	defer restore()
+
+	text, err := yaml.Marshal(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing koolo config: %w", err)
+	}
+
+	return text, nil
+}
+
 func SaveSupervisorConfig(supervisorName string, config *CharacterCfg) error {
 	filePath := filepath.Join("config", supervisorName, "config.yaml")
 	// Validate before marshalling so any field corrections (e.g. NovaSorceress
 	// BossStaticThreshold) are present in the written YAML.
 	config.Validate()
-	d, err := yaml.Marshal(config)
+	config.SchemaVersion = CurrentSchemaVersion
+	restore, err := hideSecrets(characterSecretFields(config), config.secretRefs)
+	if err != nil {
+		return fmt.Errorf("error saving secret: %w", err)
+	}
+	defer restore()
+
+	var d []byte
+	if config.Profile != "" {
+		// Only the values differing from the profile are written, the rest keeps following it
+		var overrides map[string]any
+		if overrides, err = profileOverrides(config); err != nil {
+			return fmt.Errorf("error resolving profile %s: %w", config.Profile, err)
+		}
+		d, err = yaml.Marshal(overrides)
+	} else {
+		d, err = yaml.Marshal(config)
+	}
 	if err != nil {
 		return err
 	}
@@ -928,6 +964,22 @@ func SaveSupervisorConfig(supervisorName string, config *CharacterCfg) error {
 	return Load()
 }
 
+// Clone returns a copy of the saved settings of the config, the runtime data is left out. The secret references are
+// kept so saving the copy writes them instead of the secrets.
+func (c *CharacterCfg) Clone() (*CharacterCfg, error) {
+	raw, err := yaml.Marshal(c)
+	if err != nil {
+		return nil, err
+	}
+	var out CharacterCfg
+	if err = yaml.Unmarshal(raw, &out); err != nil {
+		return nil, err
+	}
+	out.secretRefs = maps.Clone(c.secretRefs)
+
+	return &out, nil
+}
+
 func (c *CharacterCfg) Validate() {
 	if c.Character.Class == "nova" || c.Character.Class == "lightsorc" {
 		minThreshold := 65 // Default
@@ -0,0 +1,363 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// CurrentSchemaVersion is the schema version of the character config.yaml files written by this build, older files
+// are upgraded by the migrations when loaded
+const CurrentSchemaVersion = 3
+
+// Migration upgrades a character config from Version-1 to Version. Migrations work on the YAML tree so comments and
+// unknown keys are kept.
+type Migration struct {
+	Version     int
+	Description string
+	Apply       func(root *yaml.Node) error
+}
+
+// Migrations are applied in order, a file gets every migration with a Version above its schemaVersion
+var Migrations = []Migration{
+	{
+		Version:     1,
+		Description: "rename game.andariel.useAntidoes to useAntidotes",
+		Apply: func(root *yaml.Node) error {
+			andariel := yamlPath(root, "game", "andariel")
+			old := yamlValue(andariel, "useAntidoes")
+			if old == nil {
+				return nil
+			}
+			if cur := yamlValue(andariel, "useAntidotes"); cur == nil || (cur.Value != "true" && old.Value == "true") {
+				setYamlScalar(andariel, "useAntidotes", old.Value, "!!bool")
+			}
+			deleteYamlKey(andariel, "useAntidoes")
+			return nil
+		},
+	},
+	{
+		Version:     2,
+		Description: "normalize game.gameVersion",
+		Apply: func(root *yaml.Node) error {
+			game := yamlPath(root, "game")
+			if game == nil {
+				return nil
+			}
+			current := ""
+			if v := yamlValue(game, "gameVersion"); v != nil {
+				current = v.Value
+			}
+			if normalized := NormalizeGameVersion(current); normalized != current {
+				setYamlScalar(game, "gameVersion", normalized, "!!str")
+			}
+			return nil
+		},
+	},
+	{
+		Version:     3,
+		Description: "set defaults for game.maxFailedMenuAttempts and gambling.items",
+		Apply: func(root *yaml.Node) error {
+			// The defaults would override the values of the profile
+			if profile := yamlValue(root, "profile"); profile != nil && profile.Value != "" {
+				return nil
+			}
+			if game := yamlPath(root, "game"); game != nil {
+				if v := yamlValue(game, "maxFailedMenuAttempts"); v == nil || v.Value == "0" {
+					setYamlScalar(game, "maxFailedMenuAttempts", "10", "!!int")
+				}
+			}
+			gambling := yamlPath(root, "gambling")
+			if gambling == nil {
+				gambling = &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+				setYamlNode(root, "gambling", gambling)
+			}
+			if items := yamlValue(gambling, "items"); items == nil || len(items.Content) == 0 {
+				seq := &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq", Style: yaml.FlowStyle}
+				for _, name := range []string{"coronet", "circlet", "amulet"} {
+					seq.Content = append(seq.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: name})
+				}
+				setYamlNode(gambling, "items", seq)
+			}
+			return nil
+		},
+	},
+}
+
+// pendingMigrations are the character configs Load migrated in memory, by supervisor name
+var pendingMigrations = make(map[string]MigrationResult)
+
+// MigrationResult describes the upgrade of a single file
+type MigrationResult struct {
+	File    string   `json:"file"`
+	From    int      `json:"from"`
+	To      int      `json:"to"`
+	Applied []string `json:"applied"`
+	Diff    string   `json:"diff,omitempty"`
+	Backup  string   `json:"backup,omitempty"` // Copy of the file before it was rewritten
+}
+
+// Changed reports whether the file needed any migration
+func (r MigrationResult) Changed() bool {
+	return r.From != r.To
+}
+
+// MigrateCharacterFile upgrades a character config.yaml to CurrentSchemaVersion. The original file is copied next to
+// it before being rewritten, with dryRun nothing is written and the result holds the diff instead.
+func MigrateCharacterFile(path string, dryRun bool) (MigrationResult, error) {
+	original, err := os.ReadFile(path)
+	if err != nil {
+		return MigrationResult{File: path}, err
+	}
+
+	migrated, base, result, err := migrateCharacterConfig(path, original)
+	if err != nil || !result.Changed() {
+		return result, err
+	}
+
+	if dryRun {
+		result.Diff = lineDiff(string(base), string(migrated))
+		return result, nil
+	}
+
+	result.Backup = fmt.Sprintf("%s.v%d.%s.bak", path, result.From, time.Now().Format("20060102-150405"))
+	if err = os.WriteFile(result.Backup, original, 0644); err != nil {
+		return result, fmt.Errorf("error writing backup of %s: %w", path, err)
+	}
+	if err = os.WriteFile(path, migrated, 0644); err != nil {
+		return result, fmt.Errorf("error writing migrated %s: %w", path, err)
+	}
+
+	return result, nil
+}
+
+// migrateCharacterConfig upgrades the content of a character config.yaml in memory. The changes are applied as edits
+// of the original text, when that isn't possible the whole document is encoded again and base is the original encoded
+// the same way, so a diff of base and migrated only shows what the migrations changed.
+func migrateCharacterConfig(path string, original []byte) (migrated, base []byte, result MigrationResult, err error) {
+	result = MigrationResult{File: path}
+
+	var doc, originalDoc yaml.Node
+	if err = yaml.Unmarshal(original, &doc); err != nil {
+		return nil, nil, result, fmt.Errorf("error reading %s: %w", path, err)
+	}
+	if doc.Kind != yaml.DocumentNode || len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+		return nil, nil, result, fmt.Errorf("%s is not a YAML mapping", path)
+	}
+	root := doc.Content[0]
+
+	if v := yamlValue(root, "schemaVersion"); v != nil {
+		if result.From, err = strconv.Atoi(v.Value); err != nil {
+			return nil, nil, result, fmt.Errorf("%s: invalid schemaVersion %q", path, v.Value)
+		}
+	}
+	result.To = result.From
+	if result.From > CurrentSchemaVersion {
+		return nil, nil, result, fmt.Errorf("%s has schema version %d, this build only knows up to %d", path, result.From, CurrentSchemaVersion)
+	}
+	if result.From == CurrentSchemaVersion {
+		return original, original, result, nil
+	}
+
+	for _, m := range Migrations {
+		if m.Version <= result.From {
+			continue
+		}
+		if err = m.Apply(root); err != nil {
+			return nil, nil, result, fmt.Errorf("%s: migration %d (%s) failed: %w", path, m.Version, m.Description, err)
+		}
+		result.Applied = append(result.Applied, m.Description)
+		result.To = m.Version
+	}
+	if yamlValue(root, "schemaVersion") == nil {
+		// First key of the file, it's the first thing to look at when a config misbehaves
+		root.Content = append([]*yaml.Node{{Kind: yaml.ScalarNode, Tag: "!!str", Value: "schemaVersion"}, {Kind: yaml.ScalarNode, Tag: "!!int"}}, root.Content...)
+	}
+	setYamlScalar(root, "schemaVersion", strconv.Itoa(result.To), "!!int")
+
+	_ = yaml.Unmarshal(original, &originalDoc)
+	if migrated, ok := patchYAML(original, &originalDoc, &doc); ok {
+		return migrated, original, result, nil
+	}
+
+	if base, err = encodeYAML(&originalDoc); err != nil {
+		return nil, nil, result, fmt.Errorf("error encoding %s: %w", path, err)
+	}
+	if migrated, err = encodeYAML(&doc); err != nil {
+		return nil, nil, result, fmt.Errorf("error encoding %s: %w", path, err)
+	}
+
+	return migrated, base, result, nil
+}
+
+func encodeYAML(doc *yaml.Node) ([]byte, error) {
+	buf := new(bytes.Buffer)
+	enc := yaml.NewEncoder(buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(doc); err != nil {
+		return nil, err
+	}
+	_ = enc.Close()
+
+	return buf.Bytes(), nil
+}
+
+// MigrateCharacters upgrades the config.yaml of the given character folders, every folder when names is empty
+func MigrateCharacters(dryRun bool, names ...string) ([]MigrationResult, error) {
+	if len(names) == 0 {
+		entries, err := os.ReadDir(getAbsPath("config"))
+		if err != nil {
+			return nil, fmt.Errorf("error reading config directory: %w", err)
+		}
+		for _, entry := range entries {
+			if entry.IsDir() && entry.Name() != ProfilesDir {
+				names = append(names, entry.Name())
+			}
+		}
+	}
+
+	results := make([]MigrationResult, 0, len(names))
+	var errs []error
+	for _, name := range names {
+		path := getAbsPath(filepath.Join("config", name, "config.yaml"))
+		if _, err := os.Stat(path); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", name, err))
+			continue
+		}
+		result, err := MigrateCharacterFile(path, dryRun)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		results = append(results, result)
+	}
+
+	return results, errors.Join(errs...)
+}
+
+// yamlValue returns the value of a key of a mapping node
+func yamlValue(mapping *yaml.Node, key string) *yaml.Node {
+	if mapping == nil || mapping.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			return mapping.Content[i+1]
+		}
+	}
+
+	return nil
+}
+
+// yamlPath walks nested mappings, nil when any key is missing
+func yamlPath(node *yaml.Node, keys ...string) *yaml.Node {
+	for _, key := range keys {
+		if node = yamlValue(node, key); node == nil {
+			return nil
+		}
+	}
+
+	return node
+}
+
+// setYamlNode sets the value of a key, appending it when missing
+func setYamlNode(mapping *yaml.Node, key string, value *yaml.Node) {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			mapping.Content[i+1] = value
+			return
+		}
+	}
+	mapping.Content = append(mapping.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key}, value)
+}
+
+// setYamlScalar sets a scalar value keeping the comments of an existing one
+func setYamlScalar(mapping *yaml.Node, key, value, tag string) {
+	if existing := yamlValue(mapping, key); existing != nil && existing.Kind == yaml.ScalarNode {
+		existing.Value, existing.Tag, existing.Style = value, tag, 0
+		return
+	}
+	setYamlNode(mapping, key, &yaml.Node{Kind: yaml.ScalarNode, Tag: tag, Value: value})
+}
+
+func deleteYamlKey(mapping *yaml.Node, key string) {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			mapping.Content = append(mapping.Content[:i], mapping.Content[i+2:]...)
+			return
+		}
+	}
+}
+
+// lineDiff returns a unified style diff of two texts, with a couple of context lines around each change
+func lineDiff(a, b string) string {
+	const context = 2
+	before, after := strings.Split(a, "\n"), strings.Split(b, "\n")
+
+	// Longest common subsequence table, lcs[i][j] is the LCS length of before[i:] and after[j:]
+	lcs := make([][]int, len(before)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(after)+1)
+	}
+	for i := len(before) - 1; i >= 0; i-- {
+		for j := len(after) - 1; j >= 0; j-- {
+			if before[i] == after[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	type line struct {
+		op   byte
+		text string
+		num  int // Line number in the original file
+	}
+	var lines []line
+	i, j := 0, 0
+	for i < len(before) || j < len(after) {
+		switch {
+		case i < len(before) && j < len(after) && before[i] == after[j]:
+			lines = append(lines, line{' ', before[i], i + 1})
+			i++
+			j++
+		case i < len(before) && (j == len(after) || lcs[i+1][j] >= lcs[i][j+1]):
+			lines = append(lines, line{'-', before[i], i + 1})
+			i++
+		default:
+			lines = append(lines, line{'+', after[j], i + 1})
+			j++
+		}
+	}
+
+	var sb strings.Builder
+	lastPrinted := -1
+	for k, l := range lines {
+		if l.op == ' ' {
+			continue
+		}
+		start := max(k-context, lastPrinted+1)
+		if start > lastPrinted+1 || lastPrinted == -1 {
+			fmt.Fprintf(&sb, "@@ line %d @@\n", lines[start].num)
+		}
+		for c := start; c < k; c++ {
+			sb.WriteString("  " + lines[c].text + "\n")
+		}
+		sb.WriteString(string(l.op) + " " + l.text + "\n")
+		lastPrinted = k
+		for c := k + 1; c < len(lines) && c <= k+context && lines[c].op == ' '; c++ {
+			sb.WriteString("  " + lines[c].text + "\n")
+			lastPrinted = c
+		}
+	}
+
+	return sb.String()
+}
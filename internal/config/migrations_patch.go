@@ -0,0 +1,265 @@
+package config
+
+import (
+	"bytes"
+	"reflect"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"gopkg.in/yaml.v3"
+)
+
+// yamlEdit replaces src[start:end] with text, inserts have start == end
+type yamlEdit struct {
+	start, end int
+	text       string
+	seq        int
+}
+
+// yamlPatcher turns the changes between two YAML trees into edits of the source text of the first one, so comments,
+// quoting and the layout of everything the migrations didn't touch are kept as they were
+type yamlPatcher struct {
+	src   []byte
+	lines []int // Offset of every line start, plus one past the end
+	edits []yamlEdit
+}
+
+// patchYAML rewrites the changes from original (parsed from src) to migrated as edits of src. ok is false when a
+// change can't be expressed as a text edit, the caller has to encode the whole migrated document then.
+func patchYAML(src []byte, original, migrated *yaml.Node) ([]byte, bool) {
+	if len(src) > 0 && src[len(src)-1] != '\n' {
+		src = append(slices.Clip(src), '\n')
+	}
+	p := &yamlPatcher{src: src, lines: []int{0}}
+	for i, c := range src {
+		if c == '\n' {
+			p.lines = append(p.lines, i+1)
+		}
+	}
+
+	if len(original.Content) == 0 || len(migrated.Content) == 0 || !p.mapping(original.Content[0], migrated.Content[0]) {
+		return nil, false
+	}
+
+	// Applied from the end of the file, inserts at the same offset keep the order they were added in
+	slices.SortFunc(p.edits, func(a, b yamlEdit) int {
+		if a.start != b.start {
+			return b.start - a.start
+		}
+		return b.seq - a.seq
+	})
+	out := slices.Clone(src)
+	for _, e := range p.edits {
+		out = slices.Concat(out[:e.start], []byte(e.text), out[e.end:])
+	}
+
+	// The edits are only trusted when they read back as the migrated document
+	var want, got any
+	if err := migrated.Decode(&want); err != nil {
+		return nil, false
+	}
+	if err := yaml.Unmarshal(out, &got); err != nil || !reflect.DeepEqual(want, got) {
+		return nil, false
+	}
+
+	return out, true
+}
+
+func (p *yamlPatcher) add(start, end int, text string) {
+	p.edits = append(p.edits, yamlEdit{start: start, end: end, text: text, seq: len(p.edits)})
+}
+
+// lineStart returns the offset of a 1-based line, lines past the end of the file start at its end
+func (p *yamlPatcher) lineStart(line int) int {
+	if line-1 >= len(p.lines) {
+		return len(p.src)
+	}
+
+	return p.lines[line-1]
+}
+
+func (p *yamlPatcher) mapping(orig, migrated *yaml.Node) bool {
+	if yamlEqual(orig, migrated) {
+		return true
+	}
+	if orig.Kind != yaml.MappingNode || migrated.Kind != yaml.MappingNode || orig.Style&yaml.FlowStyle != 0 || len(orig.Content) == 0 {
+		return false
+	}
+
+	for i := 0; i+1 < len(orig.Content); i += 2 {
+		key, value := orig.Content[i], orig.Content[i+1]
+		newValue := yamlValue(migrated, key.Value)
+		if newValue == nil {
+			p.add(p.lineStart(key.Line), p.lineStart(yamlEndLine(value)+1), "")
+			continue
+		}
+		if !p.value(value, newValue) {
+			return false
+		}
+	}
+
+	// New keys go after the last line of the mapping, or before its first key when they were prepended
+	indent := strings.Repeat(" ", orig.Content[0].Column-1)
+	for i := 0; i+1 < len(migrated.Content); i += 2 {
+		key := migrated.Content[i]
+		if yamlValue(orig, key.Value) != nil {
+			continue
+		}
+		entry, ok := encodeYAMLEntry(key, migrated.Content[i+1], indent)
+		if !ok {
+			return false
+		}
+		offset := p.lineStart(yamlEndLine(orig) + 1)
+		if i == 0 {
+			offset = p.lineStart(orig.Content[0].Line)
+		}
+		p.add(offset, offset, entry)
+	}
+
+	return true
+}
+
+// value replaces a changed value, nested mappings are patched key by key while scalars and flow collections are
+// replaced in place
+func (p *yamlPatcher) value(orig, migrated *yaml.Node) bool {
+	switch {
+	case yamlEqual(orig, migrated):
+		return true
+	case orig.Kind == yaml.MappingNode && migrated.Kind == yaml.MappingNode && orig.Style&yaml.FlowStyle == 0:
+		return p.mapping(orig, migrated)
+	case orig.Kind == yaml.ScalarNode && (orig.Style&(yaml.LiteralStyle|yaml.FoldedStyle) != 0 || orig.Style == 0 && orig.Value == ""):
+		return false
+	case orig.Kind != yaml.ScalarNode && orig.Style&yaml.FlowStyle == 0:
+		return false
+	}
+
+	start, end, ok := p.token(orig)
+	if !ok {
+		return false
+	}
+	// The comments of the original are still in the source
+	plain := *migrated
+	plain.HeadComment, plain.LineComment, plain.FootComment = "", "", ""
+	text, err := yaml.Marshal(&plain)
+	if err != nil || bytes.Count(text, []byte("\n")) != 1 {
+		return false
+	}
+	p.add(start, end, strings.TrimSuffix(string(text), "\n"))
+
+	return true
+}
+
+// token returns the offsets of a single line scalar or flow collection in the source
+func (p *yamlPatcher) token(n *yaml.Node) (int, int, bool) {
+	if n.Line < 1 || n.Line >= len(p.lines) {
+		return 0, 0, false
+	}
+	lineStart := p.lines[n.Line-1]
+	line := string(p.src[lineStart : p.lines[n.Line]-1])
+
+	// Columns count characters, not bytes
+	start := 0
+	for col := 1; col < n.Column; col++ {
+		if start >= len(line) {
+			return 0, 0, false
+		}
+		_, size := utf8.DecodeRuneInString(line[start:])
+		start += size
+	}
+
+	end := -1
+	switch {
+	case n.Kind != yaml.ScalarNode:
+		depth := 0
+		for i := start; i < len(line) && end < 0; i++ {
+			switch line[i] {
+			case '[', '{':
+				depth++
+			case ']', '}':
+				if depth--; depth == 0 {
+					end = i + 1
+				}
+			}
+		}
+	case n.Style&yaml.DoubleQuotedStyle != 0:
+		for i := start + 1; i < len(line) && end < 0; i++ {
+			if line[i] == '\\' {
+				i++
+			} else if line[i] == '"' {
+				end = i + 1
+			}
+		}
+	case n.Style&yaml.SingleQuotedStyle != 0:
+		for i := start + 1; i < len(line) && end < 0; i++ {
+			if line[i] == '\'' {
+				if i+1 < len(line) && line[i+1] == '\'' {
+					i++
+				} else {
+					end = i + 1
+				}
+			}
+		}
+	default:
+		end = len(line)
+		if i := strings.Index(line[start:], " #"); i >= 0 {
+			end = start + i
+		}
+		end = start + len(strings.TrimRight(line[start:end], " \t"))
+	}
+	if end <= start {
+		return 0, 0, false
+	}
+
+	return lineStart + start, lineStart + end, true
+}
+
+// encodeYAMLEntry encodes a single key of a mapping at the given indentation
+func encodeYAMLEntry(key, value *yaml.Node, indent string) (string, bool) {
+	buf := new(bytes.Buffer)
+	enc := yaml.NewEncoder(buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(&yaml.Node{Kind: yaml.MappingNode, Tag: "!!map", Content: []*yaml.Node{key, value}}); err != nil {
+		return "", false
+	}
+	_ = enc.Close()
+
+	var sb strings.Builder
+	for _, line := range strings.SplitAfter(buf.String(), "\n") {
+		if line != "" {
+			sb.WriteString(indent + line)
+		}
+	}
+
+	return sb.String(), true
+}
+
+// yamlEndLine returns the last line used by a node and its children
+func yamlEndLine(n *yaml.Node) int {
+	end := n.Line
+	for _, c := range n.Content {
+		end = max(end, yamlEndLine(c))
+	}
+
+	return end
+}
+
+// yamlEqual compares the values of two trees, ignoring styles, comments and positions
+func yamlEqual(a, b *yaml.Node) bool {
+	if a.Kind != b.Kind || len(a.Content) != len(b.Content) {
+		return false
+	}
+	if a.Kind == yaml.ScalarNode && (a.Value != b.Value || a.ShortTag() != b.ShortTag()) {
+		return false
+	}
+	if a.Kind == yaml.AliasNode {
+		return a.Value == b.Value
+	}
+	for i := range a.Content {
+		if !yamlEqual(a.Content[i], b.Content[i]) {
+			return false
+		}
+	}
+
+	return true
+}
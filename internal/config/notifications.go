@@ -0,0 +1,21 @@
+package config
+
+// NotificationSink is a destination of the notification router (see remote/notify) with its own event filters
+type NotificationSink struct {
+	Name    string `yaml:"name"`
+	Type    string `yaml:"type"` // discord, telegram, webhook, slack, ntfy or gotify
+	Enabled bool   `yaml:"enabled"`
+	// URL is the webhook URL (webhook, slack), the topic URL (ntfy) or the server URL (gotify). Discord and Telegram
+	// sinks use the settings of their own section.
+	URL   string `yaml:"url"`
+	Token string `yaml:"token"` // Sent as bearer token (webhook, ntfy) or application token (gotify)
+	// Events sent to the sink, empty means the default set: chicken, death, error, item_stashed, runeword_reroll, ngrok
+	Events         []string `yaml:"events"`
+	Supervisors    []string `yaml:"supervisors"`    // Empty means all supervisors
+	MinItemQuality string   `yaml:"minItemQuality"` // Lowest quality of stashed items, e.g. "Set", empty means all
+	RateLimit      int      `yaml:"rateLimit"`      // Max notifications per minute, 0 means unlimited
+	Screenshots    bool     `yaml:"screenshots"`    // Attach screenshots when the sink supports it
+	// IncludeGamePassword adds the game password to game_created notifications, off by default as webhooks and ntfy
+	// topics are often shared or public
+	IncludeGamePassword bool `yaml:"includeGamePassword"`
+}
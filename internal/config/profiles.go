@@ -0,0 +1,233 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ProfilesDir is the folder inside config holding the base profiles, a character config.yaml declaring
+// "profile: name" inherits every value of config/profiles/name.yaml it doesn't set itself. Profiles can inherit from
+// another profile the same way.
+const ProfilesDir = "profiles"
+
+// ProfileInheritance keeps the values a character got from its profile and the ones set by its own config.yaml
+type ProfileInheritance struct {
+	Profile       string
+	profileValues map[string]any
+	ownValues     map[string]any
+}
+
+// InheritedValue is a single setting of a character using a profile, Path is the YAML path (e.g. "health.chickenAt")
+type InheritedValue struct {
+	Path         string `json:"path"`
+	Value        any    `json:"value"`
+	ProfileValue any    `json:"profileValue,omitempty"`
+	Overridden   bool   `json:"overridden"`
+}
+
+// Values lists the settings defined by the profile, telling which ones the character overrides
+func (i ProfileInheritance) Values() []InheritedValue {
+	own := flattenMap(i.ownValues)
+	values := make([]InheritedValue, 0)
+	for path, profileValue := range flattenMap(i.profileValues) {
+		v := InheritedValue{Path: path, Value: profileValue}
+		if ownValue, found := own[path]; found {
+			v.Value, v.ProfileValue = ownValue, profileValue
+			v.Overridden = !reflect.DeepEqual(ownValue, profileValue)
+		}
+		values = append(values, v)
+	}
+	sort.Slice(values, func(a, b int) bool { return values[a].Path < values[b].Path })
+
+	return values
+}
+
+// AvailableProfiles returns the profile names found in config/profiles
+func AvailableProfiles() []string {
+	entries, err := os.ReadDir(getAbsPath(filepath.Join("config", ProfilesDir)))
+	if err != nil {
+		return nil
+	}
+
+	profiles := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		if !entry.IsDir() && filepath.Ext(entry.Name()) == ".yaml" {
+			profiles = append(profiles, strings.TrimSuffix(entry.Name(), ".yaml"))
+		}
+	}
+
+	return profiles
+}
+
+func profilePath(name string) string {
+	return getAbsPath(filepath.Join("config", ProfilesDir, name+".yaml"))
+}
+
+// resolveProfile reads a profile merged with the profiles it inherits from, the "profile" key is left out
+func resolveProfile(name string, seen ...string) (map[string]any, error) {
+	if slices.Contains(seen, name) {
+		return nil, fmt.Errorf("profile inheritance loop: %s -> %s", strings.Join(seen, " -> "), name)
+	}
+
+	values, err := readYAMLMap(profilePath(name))
+	if err != nil {
+		return nil, fmt.Errorf("error reading profile %s: %w", name, err)
+	}
+
+	parent, _ := values["profile"].(string)
+	delete(values, "profile")
+	if parent == "" {
+		return values, nil
+	}
+
+	base, err := resolveProfile(parent, append(seen, name)...)
+	if err != nil {
+		return nil, err
+	}
+
+	return mergeMaps(base, values), nil
+}
+
+// resolveCharacterConfig returns the effective YAML of the content of a character config.yaml, its profile values
+// merged with its own
+func resolveCharacterConfig(path string, data []byte) ([]byte, ProfileInheritance, error) {
+	own := make(map[string]any)
+	if err := yaml.Unmarshal(data, &own); err != nil {
+		return nil, ProfileInheritance{}, fmt.Errorf("error reading %s: %w", path, err)
+	}
+
+	profile, _ := own["profile"].(string)
+	if profile == "" {
+		return data, ProfileInheritance{}, nil
+	}
+
+	base, err := resolveProfile(profile)
+	if err != nil {
+		return nil, ProfileInheritance{}, err
+	}
+	ownValues := mergeMaps(nil, own)
+	delete(ownValues, "profile")
+
+	merged, err := yaml.Marshal(mergeMaps(base, own))
+	if err != nil {
+		return nil, ProfileInheritance{}, err
+	}
+
+	return merged, ProfileInheritance{Profile: profile, profileValues: base, ownValues: ownValues}, nil
+}
+
+// profileOverrides returns the values of cfg that differ from its profile, it's what gets written to its config.yaml
+func profileOverrides(cfg *CharacterCfg) (map[string]any, error) {
+	base, err := resolveProfile(cfg.Profile)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := yaml.Marshal(cfg)
+	if err != nil {
+		return nil, err
+	}
+	full := make(map[string]any)
+	if err = yaml.Unmarshal(data, &full); err != nil {
+		return nil, err
+	}
+
+	overrides := diffMaps(full, base)
+	overrides["profile"] = cfg.Profile
+
+	return overrides, nil
+}
+
+func readYAMLMap(path string) (map[string]any, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	values := make(map[string]any)
+	if err = yaml.Unmarshal(data, &values); err != nil {
+		return nil, fmt.Errorf("error reading %s: %w", path, err)
+	}
+
+	return values, nil
+}
+
+// mergeMaps returns a deep copy of base with override on top, nested maps are merged and anything else is replaced
+func mergeMaps(base, override map[string]any) map[string]any {
+	merged := make(map[string]any, len(base)+len(override))
+	for k, v := range base {
+		if m, ok := v.(map[string]any); ok {
+			v = mergeMaps(nil, m)
+		}
+		merged[k] = v
+	}
+	for k, v := range override {
+		overrideMap, isMap := v.(map[string]any)
+		baseMap, baseIsMap := merged[k].(map[string]any)
+		switch {
+		case isMap && baseIsMap:
+			merged[k] = mergeMaps(baseMap, overrideMap)
+		case isMap:
+			merged[k] = mergeMaps(nil, overrideMap)
+		default:
+			merged[k] = v
+		}
+	}
+
+	return merged
+}
+
+// diffMaps returns the values of full missing from base or different from it
+func diffMaps(full, base map[string]any) map[string]any {
+	diff := make(map[string]any)
+	for k, v := range full {
+		baseValue, found := base[k]
+		if !found {
+			diff[k] = v
+			continue
+		}
+
+		fullMap, isMap := v.(map[string]any)
+		baseMap, baseIsMap := baseValue.(map[string]any)
+		if isMap && baseIsMap {
+			if nested := diffMaps(fullMap, baseMap); len(nested) > 0 {
+				diff[k] = nested
+			}
+			continue
+		}
+		if !reflect.DeepEqual(v, baseValue) {
+			diff[k] = v
+		}
+	}
+
+	return diff
+}
+
+// flattenMap returns the leaf values of nested maps keyed by their dotted path, lists are leaves
+func flattenMap(values map[string]any) map[string]any {
+	flat := make(map[string]any)
+	var walk func(prefix string, m map[string]any)
+	walk = func(prefix string, m map[string]any) {
+		for k, v := range m {
+			path := k
+			if prefix != "" {
+				path = prefix + "." + k
+			}
+			if nested, ok := v.(map[string]any); ok && len(nested) > 0 {
+				walk(path, nested)
+				continue
+			}
+			flat[path] = v
+		}
+	}
+	walk("", values)
+
+	return flat
+}
@@ -0,0 +1,151 @@
+// Package secret resolves secret references used in place of plaintext passwords and tokens in the config files.
+// A reference is a whole value of the form ${scheme:key}:
+//
+//	${env:KOOLO_DISCORD_TOKEN}   environment variable
+//	${file:secrets/discord.txt}  content of a file, trailing newlines are trimmed
+//	${vault:discord}             entry of the encrypted local vault
+//
+// Any other value is a plain value and is returned as is.
+package secret
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+const (
+	SchemeEnv   = "env"
+	SchemeFile  = "file"
+	SchemeVault = "vault"
+
+	// DefaultVaultFile is the vault holding the ${vault:name} secrets, relative to the Koolo folder
+	DefaultVaultFile = "config/secrets.vault"
+)
+
+var refRe = regexp.MustCompile(`^\$\{(env|file|vault):([^}]+)\}$`)
+
+// Ref is a parsed secret reference
+type Ref struct {
+	Scheme string
+	Key    string
+}
+
+func (r Ref) String() string {
+	return "${" + r.Scheme + ":" + r.Key + "}"
+}
+
+// Parse returns the reference held by value, ok is false for plain values
+func Parse(value string) (ref Ref, ok bool) {
+	m := refRe.FindStringSubmatch(strings.TrimSpace(value))
+	if m == nil {
+		return Ref{}, false
+	}
+
+	return Ref{Scheme: m[1], Key: strings.TrimSpace(m[2])}, true
+}
+
+// IsRef reports whether value is a secret reference
+func IsRef(value string) bool {
+	_, ok := Parse(value)
+	return ok
+}
+
+// Store resolves references, the vault is opened on first use
+type Store struct {
+	VaultPath string
+	// Password returns the master password of the vault
+	Password  func() (string, error)
+	LookupEnv func(string) (string, bool)
+	ReadFile  func(string) ([]byte, error)
+
+	mu    sync.Mutex
+	vault *Vault
+}
+
+// NewStore creates a store reading the environment and the file system, the vault password is read from
+// KOOLO_VAULT_PASSWORD or from the file KOOLO_VAULT_PASSWORD_FILE points to
+func NewStore(vaultPath string) *Store {
+	return &Store{
+		VaultPath: vaultPath,
+		Password:  PasswordFromEnv,
+		LookupEnv: os.LookupEnv,
+		ReadFile:  os.ReadFile,
+	}
+}
+
+// PasswordFromEnv returns the vault master password configured in the environment
+func PasswordFromEnv() (string, error) {
+	if password, found := os.LookupEnv("KOOLO_VAULT_PASSWORD"); found {
+		return password, nil
+	}
+	if path, found := os.LookupEnv("KOOLO_VAULT_PASSWORD_FILE"); found {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return "", fmt.Errorf("error reading vault password file: %w", err)
+		}
+		return strings.TrimRight(string(content), "\r\n"), nil
+	}
+
+	return "", errors.New("the vault master password is not set, set KOOLO_VAULT_PASSWORD or KOOLO_VAULT_PASSWORD_FILE")
+}
+
+// Resolve returns the secret a value references, plain values are returned unchanged
+func (s *Store) Resolve(value string) (string, error) {
+	ref, ok := Parse(value)
+	if !ok {
+		return value, nil
+	}
+
+	switch ref.Scheme {
+	case SchemeEnv:
+		v, found := s.LookupEnv(ref.Key)
+		if !found {
+			return "", fmt.Errorf("%s: environment variable %s is not set", ref, ref.Key)
+		}
+		return v, nil
+	case SchemeFile:
+		content, err := s.ReadFile(ref.Key)
+		if err != nil {
+			return "", fmt.Errorf("%s: %w", ref, err)
+		}
+		return strings.TrimRight(string(content), "\r\n"), nil
+	default:
+		vault, err := s.Vault()
+		if err != nil {
+			return "", fmt.Errorf("%s: %w", ref, err)
+		}
+		v, found := vault.Get(ref.Key)
+		if !found {
+			return "", fmt.Errorf("%s: no such entry in the vault", ref)
+		}
+		return v, nil
+	}
+}
+
+// Vault opens the vault with the master password, it's created when the file doesn't exist
+func (s *Store) Vault() (*Vault, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.vault != nil {
+		return s.vault, nil
+	}
+
+	password, err := s.Password()
+	if err != nil {
+		return nil, err
+	}
+	vault, err := OpenVault(s.VaultPath, password)
+	if errors.Is(err, os.ErrNotExist) {
+		vault, err = NewVault(s.VaultPath, password)
+	}
+	if err != nil {
+		return nil, err
+	}
+	s.vault = vault
+
+	return vault, nil
+}
@@ -0,0 +1,114 @@
+package secret
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		value string
+		ref   Ref
+		ok    bool
+	}{
+		{"${env:KOOLO_TOKEN}", Ref{SchemeEnv, "KOOLO_TOKEN"}, true},
+		{" ${file:secrets/token.txt} ", Ref{SchemeFile, "secrets/token.txt"}, true},
+		{"${vault:discord}", Ref{SchemeVault, "discord"}, true},
+		{"${other:discord}", Ref{}, false},
+		{"my${env:X}password", Ref{}, false},
+		{"hunter2", Ref{}, false},
+		{"", Ref{}, false},
+	}
+	for _, tt := range tests {
+		ref, ok := Parse(tt.value)
+		if ok != tt.ok || ref != tt.ref {
+			t.Errorf("Parse(%q) = %v, %v; want %v, %v", tt.value, ref, ok, tt.ref, tt.ok)
+		}
+	}
+}
+
+func TestStoreResolve(t *testing.T) {
+	dir := t.TempDir()
+	tokenFile := filepath.Join(dir, "token.txt")
+	if err := os.WriteFile(tokenFile, []byte("file-token\r\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	s := NewStore(filepath.Join(dir, "secrets.vault"))
+	s.LookupEnv = func(name string) (string, bool) {
+		if name == "KOOLO_TOKEN" {
+			return "env-token", true
+		}
+		return "", false
+	}
+	s.Password = func() (string, error) { return "master", nil }
+
+	vault, err := s.Vault()
+	if err != nil {
+		t.Fatalf("creating vault: %v", err)
+	}
+	if err = vault.Set("discord", "vault-token"); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		value   string
+		want    string
+		wantErr bool
+	}{
+		{"plain", "plain", false},
+		{"${env:KOOLO_TOKEN}", "env-token", false},
+		{"${env:MISSING}", "", true},
+		{"${file:" + tokenFile + "}", "file-token", false},
+		{"${file:" + filepath.Join(dir, "missing.txt") + "}", "", true},
+		{"${vault:discord}", "vault-token", false},
+		{"${vault:telegram}", "", true},
+	}
+	for _, tt := range tests {
+		got, err := s.Resolve(tt.value)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("Resolve(%q) = %q, %v; want %q, error %v", tt.value, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestVaultRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "secrets.vault")
+
+	vault, err := NewVault(path, "master")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = vault.Set("bnet", "p4ssw0rd"); err != nil {
+		t.Fatal(err)
+	}
+	if err = vault.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(content, []byte("p4ssw0rd")) {
+		t.Fatal("the vault file contains the secret in clear")
+	}
+
+	if _, err = OpenVault(path, "wrong"); !errors.Is(err, ErrWrongPassword) {
+		t.Fatalf("expected ErrWrongPassword, got %v", err)
+	}
+
+	reopened, err := OpenVault(path, "master")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v, found := reopened.Get("bnet"); !found || v != "p4ssw0rd" {
+		t.Fatalf("Get(bnet) = %q, %v", v, found)
+	}
+	if !reopened.Delete("bnet") || len(reopened.Names()) != 0 {
+		t.Fatalf("expected an empty vault after delete, got %v", reopened.Names())
+	}
+}
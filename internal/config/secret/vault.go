@@ -0,0 +1,201 @@
+package secret
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"sync"
+)
+
+const (
+	vaultVersion    = 1
+	vaultIterations = 600_000
+	// Encrypted with the key to tell a wrong master password from a corrupted entry
+	vaultCheck = "koolo-vault"
+)
+
+var ErrWrongPassword = errors.New("wrong vault master password")
+
+// vaultFile is the on-disk format, values are AES-256-GCM sealed with a PBKDF2-SHA256 key of the master password
+type vaultFile struct {
+	Version    int               `json:"version"`
+	Salt       []byte            `json:"salt"`
+	Iterations int               `json:"iterations"`
+	Check      []byte            `json:"check"`
+	Entries    map[string][]byte `json:"entries"`
+}
+
+// Vault is an encrypted file of named secrets
+type Vault struct {
+	path string
+	aead cipher.AEAD
+
+	mu   sync.Mutex
+	file vaultFile
+}
+
+// NewVault creates an empty vault, it's written on the first Save
+func NewVault(path, password string) (*Vault, error) {
+	if password == "" {
+		return nil, errors.New("the vault master password can't be empty")
+	}
+
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	v := &Vault{path: path, file: vaultFile{Version: vaultVersion, Salt: salt, Iterations: vaultIterations, Entries: map[string][]byte{}}}
+	if err := v.deriveKey(password); err != nil {
+		return nil, err
+	}
+	check, err := v.seal(vaultCheck)
+	if err != nil {
+		return nil, err
+	}
+	v.file.Check = check
+
+	return v, nil
+}
+
+// OpenVault reads a vault file, ErrWrongPassword is returned when the password doesn't match
+func OpenVault(path, password string) (*Vault, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	v := &Vault{path: path}
+	if err = json.Unmarshal(content, &v.file); err != nil {
+		return nil, fmt.Errorf("error reading vault %s: %w", path, err)
+	}
+	if v.file.Version != vaultVersion {
+		return nil, fmt.Errorf("unsupported vault version %d", v.file.Version)
+	}
+	if v.file.Entries == nil {
+		v.file.Entries = map[string][]byte{}
+	}
+	if err = v.deriveKey(password); err != nil {
+		return nil, err
+	}
+	if check, err := v.open(v.file.Check); err != nil || check != vaultCheck {
+		return nil, ErrWrongPassword
+	}
+
+	return v, nil
+}
+
+// Get returns the value of an entry
+func (v *Vault) Get(name string) (string, bool) {
+	v.mu.Lock()
+	sealed, found := v.file.Entries[name]
+	v.mu.Unlock()
+	if !found {
+		return "", false
+	}
+
+	value, err := v.open(sealed)
+	if err != nil {
+		return "", false
+	}
+
+	return value, true
+}
+
+// Set adds or replaces an entry, call Save to persist it
+func (v *Vault) Set(name, value string) error {
+	sealed, err := v.seal(value)
+	if err != nil {
+		return err
+	}
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.file.Entries[name] = sealed
+
+	return nil
+}
+
+// Delete removes an entry, call Save to persist it
+func (v *Vault) Delete(name string) bool {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	_, found := v.file.Entries[name]
+	delete(v.file.Entries, name)
+
+	return found
+}
+
+// Names returns the entry names, sorted
+func (v *Vault) Names() []string {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	names := make([]string, 0, len(v.file.Entries))
+	for name := range v.file.Entries {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	return names
+}
+
+// Save writes the vault file, readable by the current user only
+func (v *Vault) Save() error {
+	v.mu.Lock()
+	content, err := json.MarshalIndent(v.file, "", "  ")
+	v.mu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	tmp := v.path + ".tmp"
+	if err = os.WriteFile(tmp, content, 0600); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp, v.path)
+}
+
+func (v *Vault) deriveKey(password string) error {
+	key, err := pbkdf2.Key(sha256.New, password, v.file.Salt, v.file.Iterations, 32)
+	if err != nil {
+		return err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return err
+	}
+	v.aead, err = cipher.NewGCM(block)
+
+	return err
+}
+
+// seal returns the nonce followed by the ciphertext
+func (v *Vault) seal(value string) ([]byte, error) {
+	nonce := make([]byte, v.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+
+	return v.aead.Seal(nonce, nonce, []byte(value), nil), nil
+}
+
+func (v *Vault) open(sealed []byte) (string, error) {
+	if len(sealed) < v.aead.NonceSize() {
+		return "", errors.New("invalid vault entry")
+	}
+	nonce, ciphertext := sealed[:v.aead.NonceSize()], sealed[v.aead.NonceSize():]
+	plain, err := v.aead.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return "", err
+	}
+
+	return string(plain), nil
+}
@@ -0,0 +1,112 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/hectorgimenez/koolo/internal/config/secret"
+)
+
+var secretStore = secret.NewStore(secret.DefaultVaultFile)
+
+// secretField is a config value that may hold a secret reference instead of the secret itself
+type secretField struct {
+	path  string
+	value *string
+}
+
+func kooloSecretFields(cfg *KooloCfg) []secretField {
+	fields := []secretField{
+		{"discord.token", &cfg.Discord.Token},
+		{"discord.webhookUrl", &cfg.Discord.WebhookURL},
+		{"discord.itemWebhookUrl", &cfg.Discord.ItemWebhookURL},
+		{"telegram.token", &cfg.Telegram.Token},
+		{"ngrok.authtoken", &cfg.Ngrok.Authtoken},
+		{"ngrok.basicAuthPass", &cfg.Ngrok.BasicAuthPass},
+	}
+	for i := range cfg.Notifications.Sinks {
+		fields = append(fields,
+			secretField{"notifications.sinks[" + strconv.Itoa(i) + "].url", &cfg.Notifications.Sinks[i].URL},
+			secretField{"notifications.sinks[" + strconv.Itoa(i) + "].token", &cfg.Notifications.Sinks[i].Token},
+		)
+	}
+
+	return fields
+}
+
+func characterSecretFields(cfg *CharacterCfg) []secretField {
+	return []secretField{
+		{"password", &cfg.Password},
+		{"authToken", &cfg.AuthToken},
+	}
+}
+
+// resolveSecrets replaces the secret references of the fields by their value, the references are returned by field
+// path so they can be written back instead of the secrets
+func resolveSecrets(fields []secretField) (map[string]string, error) {
+	refs := make(map[string]string)
+	for _, f := range fields {
+		if !secret.IsRef(*f.value) {
+			continue
+		}
+		value, err := secretStore.Resolve(*f.value)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", f.path, err)
+		}
+		refs[f.path] = *f.value
+		*f.value = value
+	}
+
+	return refs, nil
+}
+
+// hideSecrets puts the references back in place of the secrets before the config is written, the returned function
+// restores the secrets. A changed value of a vault reference updates the vault, changing a value coming from an
+// environment variable or a file is refused.
+func hideSecrets(fields []secretField, refs map[string]string) (func(), error) {
+	var hidden []secretField
+	var values []string
+	restore := func() {
+		for i, f := range hidden {
+			*f.value = values[i]
+		}
+	}
+
+	for _, f := range fields {
+		ref, found := refs[f.path]
+		if !found || secret.IsRef(*f.value) {
+			continue
+		}
+
+		current, err := secretStore.Resolve(ref)
+		if err != nil || current != *f.value {
+			if err = updateSecret(ref, *f.value); err != nil {
+				restore()
+				return nil, fmt.Errorf("%s: %w", f.path, err)
+			}
+		}
+
+		hidden = append(hidden, f)
+		values = append(values, *f.value)
+		*f.value = ref
+	}
+
+	return restore, nil
+}
+
+func updateSecret(reference, value string) error {
+	ref, _ := secret.Parse(reference)
+	if ref.Scheme != secret.SchemeVault {
+		return fmt.Errorf("the value comes from %s, change it there", ref)
+	}
+
+	vault, err := secretStore.Vault()
+	if err != nil {
+		return err
+	}
+	if err = vault.Set(ref.Key, value); err != nil {
+		return err
+	}
+
+	return vault.Save()
+}
@@ -0,0 +1,60 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMarshalKooloConfigKeepsSecrets(t *testing.T) {
+	const (
+		urlRef   = "${env:KOOLO_TEST_SINK_URL}"
+		tokenRef = "${env:KOOLO_TEST_SINK_TOKEN}"
+		url      = "https://ntfy.example.com/koolo"
+		token    = "tk_secret"
+	)
+	t.Setenv("KOOLO_TEST_SINK_URL", url)
+	t.Setenv("KOOLO_TEST_SINK_TOKEN", token)
+
+	live := &KooloCfg{secretRefs: map[string]string{
+		"notifications.sinks[0].url":   urlRef,
+		"notifications.sinks[0].token": tokenRef,
+	}}
+	live.Notifications.Sinks = []NotificationSink{{Name: "phone", Type: "ntfy", URL: url, Token: token}}
+
+	// Saved twice from a copy, the way the settings page and the window poller do
+	for i := 0; i < 2; i++ {
+		cfg := *live
+		text, err := marshalKooloConfig(&cfg)
+		if err != nil {
+			t.Fatalf("save %d: %v", i, err)
+		}
+		if !strings.Contains(string(text), urlRef) || !strings.Contains(string(text), tokenRef) {
+			t.Errorf("save %d: secret references not written:\n%s", i, text)
+		}
+		if strings.Contains(string(text), token) {
+			t.Errorf("save %d: token written in clear text", i)
+		}
+
+		sink := live.Notifications.Sinks[0]
+		if sink.URL != url || sink.Token != token {
+			t.Fatalf("save %d: live sink holds %q, %q; want the resolved values", i, sink.URL, sink.Token)
+		}
+	}
+}
+
+func TestCloneKeepsSecretRefs(t *testing.T) {
+	cfg := &CharacterCfg{Password: "hunter2", secretRefs: map[string]string{"password": "${vault:sorc}"}}
+
+	cloned, err := cfg.Clone()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cloned.Password != "hunter2" || cloned.secretRefs["password"] != "${vault:sorc}" {
+		t.Fatalf("clone has password %q and refs %v", cloned.Password, cloned.secretRefs)
+	}
+
+	cloned.secretRefs["password"] = "${vault:other}"
+	if cfg.secretRefs["password"] != "${vault:sorc}" {
+		t.Error("the clone shares its secret references with the source")
+	}
+}
@@ -0,0 +1,70 @@
+package config
+
+import (
+	"fmt"
+	"log/slog"
+	"path/filepath"
+
+	terrorzones "github.com/hectorgimenez/koolo/internal/terrorzone"
+)
+
+// TerrorZoneDataPath returns the terror zone data file of a character folder, or the global one when character is
+// empty. The returned path is empty when there is no file and the built-in data is used.
+func TerrorZoneDataPath(character string) string {
+	if character == "" {
+		return terrorzones.FindDataFile(getAbsPath("config"))
+	}
+
+	return terrorzones.FindDataFile(getAbsPath(filepath.Join("config", character)))
+}
+
+// ReloadTerrorZoneData reloads the global and per-character terror zone data files, the current data is kept if any
+// of them is invalid
+func ReloadTerrorZoneData() error {
+	cfgMux.RLock()
+	defer cfgMux.RUnlock()
+
+	return terrorzones.Load(terrorZoneDataPaths())
+}
+
+// loadTerrorZoneData activates the terror zone data files, the built-in data is used when any of them is invalid so a
+// broken file doesn't prevent loading the config. The error is reported by the validation.
+func loadTerrorZoneData() {
+	globalPath, charPaths := terrorZoneDataPaths()
+	if err := terrorzones.Load(globalPath, charPaths); err != nil {
+		slog.Error("Invalid terror zone data, using the built-in terror zone routes", slog.Any("error", err))
+		terrorzones.UseDefaults()
+	}
+}
+
+// validateTerrorZoneData reports every invalid terror zone data file
+func validateTerrorZoneData() []Issue {
+	err := terrorzones.Check(terrorZoneDataPaths())
+	if err == nil {
+		return nil
+	}
+
+	errs := []error{err}
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		errs = joined.Unwrap()
+	}
+	issues := make([]Issue, 0, len(errs))
+	for _, e := range errs {
+		issues = append(issues, Issue{
+			File:     "terrorzones",
+			Severity: SeverityError,
+			Message:  fmt.Sprintf("%s, the data files are ignored and the built-in terror zone routes are used until it's fixed", e),
+		})
+	}
+
+	return issues
+}
+
+func terrorZoneDataPaths() (string, map[string]string) {
+	charPaths := make(map[string]string, len(Characters))
+	for name := range Characters {
+		charPaths[name] = TerrorZoneDataPath(name)
+	}
+
+	return TerrorZoneDataPath(""), charPaths
+}
@@ -0,0 +1,397 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/hectorgimenez/d2go/pkg/data/area"
+	"github.com/hectorgimenez/d2go/pkg/data/difficulty"
+	"gopkg.in/yaml.v3"
+)
+
+type Severity string
+
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+)
+
+// Issue is a single validation finding, Path is the YAML path of the field (e.g. "health.chickenAt")
+type Issue struct {
+	File     string   `json:"file"`
+	Path     string   `json:"path"`
+	Severity Severity `json:"severity"`
+	Message  string   `json:"message"`
+}
+
+func (i Issue) String() string {
+	if i.Path == "" {
+		return fmt.Sprintf("%s: %s: %s", i.File, i.Severity, i.Message)
+	}
+
+	return fmt.Sprintf("%s: %s: %s: %s", i.File, i.Severity, i.Path, i.Message)
+}
+
+// ValidationReport holds the issues found in koolo.yaml and the character configs
+type ValidationReport struct {
+	Issues []Issue `json:"issues"`
+}
+
+func (r ValidationReport) Errors() int {
+	return r.count(SeverityError)
+}
+
+func (r ValidationReport) Warnings() int {
+	return r.count(SeverityWarning)
+}
+
+// Supervisor returns the issues of a single character config
+func (r ValidationReport) Supervisor(name string) ValidationReport {
+	file := characterConfigFile(name)
+	issues := make([]Issue, 0)
+	for _, i := range r.Issues {
+		if i.File == file {
+			issues = append(issues, i)
+		}
+	}
+
+	return ValidationReport{Issues: issues}
+}
+
+func (r ValidationReport) count(s Severity) int {
+	total := 0
+	for _, i := range r.Issues {
+		if i.Severity == s {
+			total++
+		}
+	}
+
+	return total
+}
+
+var (
+	notificationSinkTypes = []string{"discord", "telegram", "webhook", "slack", "ntfy", "gotify"}
+	// Matches the "field x not found in type y" errors of a strict YAML decode
+	unknownFieldRe = regexp.MustCompile(`line (\d+): field (\S+) not found`)
+)
+
+var lastValidation ValidationReport
+
+// LastValidation returns the report of the last successful Load
+func LastValidation() ValidationReport {
+	cfgMux.RLock()
+	defer cfgMux.RUnlock()
+
+	return lastValidation
+}
+
+// Validate checks the loaded configuration, nothing is modified
+func Validate() ValidationReport {
+	cfgMux.RLock()
+	defer cfgMux.RUnlock()
+
+	return validateLoaded()
+}
+
+func validateLoaded() ValidationReport {
+	var issues []Issue
+	if Koolo != nil {
+		issues = append(issues, ValidateKoolo(Koolo)...)
+		issues = append(issues, unknownFields("koolo.yaml", getAbsPath("config/koolo.yaml"), &KooloCfg{})...)
+	}
+
+	names := make([]string, 0, len(Characters))
+	for name := range Characters {
+		if name != "template" {
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names)
+	for _, name := range names {
+		issues = append(issues, ValidateCharacter(name, Characters[name])...)
+		if m, found := pendingMigrations[name]; found {
+			issues = append(issues, Issue{
+				File:     characterConfigFile(name),
+				Path:     "schemaVersion",
+				Severity: SeverityWarning,
+				Message:  fmt.Sprintf("schema version %d is migrated to %d in memory on every load, run \"koolo config migrate\" to upgrade the file", m.From, m.To),
+			})
+		}
+		issues = append(issues, unknownFields(characterConfigFile(name), getAbsPath(filepath.Join("config", name, "config.yaml")), &CharacterCfg{})...)
+	}
+
+	issues = append(issues, validateTerrorZoneData()...)
+
+	for _, profile := range AvailableProfiles() {
+		issues = append(issues, unknownFields(ProfilesDir+"/"+profile+".yaml", profilePath(profile), &CharacterCfg{})...)
+	}
+
+	if issues == nil {
+		issues = make([]Issue, 0)
+	}
+
+	return ValidationReport{Issues: issues}
+}
+
+// ValidateKoolo checks the global settings
+func ValidateKoolo(cfg *KooloCfg) []Issue {
+	v := validator{file: "koolo.yaml"}
+
+	if cfg.D2RPath == "" {
+		v.error("D2RPath", "the Diablo II: Resurrected path is not set")
+	} else if _, err := os.Stat(filepath.Join(cfg.D2RPath, "D2R.exe")); err != nil {
+		v.error("D2RPath", "D2R.exe not found in %s", cfg.D2RPath)
+	}
+	if cfg.D2LoDPath == "" {
+		v.error("D2LoDPath", "the Diablo II: LoD path is not set")
+	} else if _, err := os.Stat(cfg.D2LoDPath); err != nil {
+		v.error("D2LoDPath", "%s does not exist", cfg.D2LoDPath)
+	}
+	if cfg.CentralizedPickitPath != "" {
+		if _, err := os.Stat(cfg.CentralizedPickitPath); err != nil {
+			v.warning("centralizedPickitPath", "%s does not exist, characters using it fall back to their own pickit", cfg.CentralizedPickitPath)
+		}
+	}
+
+	if cfg.Discord.Enabled && cfg.Discord.Token == "" && !cfg.Discord.UseWebhook {
+		v.error("discord.token", "Discord is enabled without a bot token")
+	}
+	if cfg.Discord.Enabled && cfg.Discord.UseWebhook && cfg.Discord.WebhookURL == "" {
+		v.error("discord.webhookUrl", "Discord webhook mode is enabled without a webhook URL")
+	}
+	if cfg.Telegram.Enabled {
+		if cfg.Telegram.Token == "" {
+			v.error("telegram.token", "Telegram is enabled without a bot token")
+		}
+		if cfg.Telegram.ChatID == 0 {
+			v.error("telegram.chatId", "Telegram is enabled without a chat ID")
+		}
+	}
+
+	for i, sink := range cfg.Notifications.Sinks {
+		path := fmt.Sprintf("notifications.sinks[%d]", i)
+		if !slices.Contains(notificationSinkTypes, sink.Type) {
+			v.error(path+".type", "unknown sink type %q, expected one of %s", sink.Type, strings.Join(notificationSinkTypes, ", "))
+			continue
+		}
+		if sink.Type != "discord" && sink.Type != "telegram" && sink.URL == "" {
+			v.error(path+".url", "%s sinks need a URL", sink.Type)
+		}
+		if sink.RateLimit < 0 {
+			v.error(path+".rateLimit", "must be 0 (unlimited) or positive")
+		}
+	}
+
+	if cfg.Server.Port < 0 || cfg.Server.Port > 65535 {
+		v.error("server.port", "%d is not a valid port", cfg.Server.Port)
+	}
+	// The tunnel also takes the token from the NGROK_AUTHTOKEN environment variable, without any it's just not started
+	if cfg.Ngrok.Enabled && cfg.Ngrok.Authtoken == "" && os.Getenv("NGROK_AUTHTOKEN") == "" {
+		v.warning("ngrok.authtoken", "ngrok is enabled without an auth token, set it here or in the NGROK_AUTHTOKEN environment variable")
+	}
+	if cfg.PingMonitor.Enabled && cfg.PingMonitor.HighPingThreshold <= 0 {
+		v.warning("pingMonitor.highPingThreshold", "the ping monitor is enabled with no threshold")
+	}
+	if cfg.AutoStart.DelaySeconds < 0 {
+		v.error("autoStart.delaySeconds", "must not be negative")
+	}
+	if cfg.Droplog.RetentionDays < 0 {
+		v.error("droplog.retentionDays", "must be 0 (keep forever) or positive")
+	}
+	if mode := cfg.Droplog.RetentionMode; mode != "" && mode != "compress" && mode != "delete" {
+		v.error("droplog.retentionMode", "unknown mode %q, expected compress or delete", mode)
+	}
+	if planner := cfg.Pathfinding.Planner; planner != "" && planner != "astar" && planner != "hierarchical" {
+		v.error("pathfinding.planner", "unknown planner %q, expected astar or hierarchical", planner)
+	}
+
+	return v.issues
+}
+
+// ValidateCharacter checks a character config, name is its folder name
+func ValidateCharacter(name string, cfg *CharacterCfg) []Issue {
+	v := validator{file: characterConfigFile(name)}
+
+	if cfg.CharacterName == "" {
+		v.error("characterName", "the character name is not set")
+	}
+	if cfg.MaxGameLength < 0 {
+		v.error("maxGameLength", "must not be negative")
+	}
+
+	leveling := len(cfg.Game.Runs) > 0 && (cfg.Game.Runs[0] == LevelingRun || cfg.Game.Runs[0] == LevelingSequenceRun)
+	class := strings.ToLower(cfg.Character.Class)
+	switch {
+	case class == "":
+		v.error("character.class", "the class is not set")
+	case leveling && !slices.Contains(LevelingClasses, class):
+		v.error("character.class", "class %q can't level, expected one of %s", cfg.Character.Class, strings.Join(LevelingClasses, ", "))
+	case !leveling && !slices.Contains(AvailableClasses, class):
+		if slices.Contains(LevelingClasses, class) {
+			v.error("character.class", "class %q is only available with the leveling runs", cfg.Character.Class)
+		} else {
+			v.error("character.class", "unknown class %q, expected one of %s", cfg.Character.Class, strings.Join(AvailableClasses, ", "))
+		}
+	}
+
+	switch cfg.Game.Difficulty {
+	case difficulty.Normal, difficulty.Nightmare, difficulty.Hell:
+	default:
+		v.error("game.difficulty", "unknown difficulty %q, expected normal, nightmare or hell", cfg.Game.Difficulty)
+	}
+
+	v.validateHealth(cfg)
+	v.validateRuns(cfg)
+
+	for i, id := range cfg.Game.TerrorZone.Areas {
+		path := fmt.Sprintf("game.terror_zone.areas[%d]", i)
+		if _, found := area.Areas[id]; !found {
+			v.error(path, "unknown area ID %d", id)
+		} else if !id.CanBeTerrorized() {
+			v.warning(path, "%s can't be terrorized", id.Area().Name)
+		}
+	}
+
+	for i, col := range cfg.Inventory.BeltColumns {
+		if col != "" && col != "healing" && col != "mana" && col != "rejuvenation" {
+			v.error(fmt.Sprintf("inventory.beltColumns[%d]", i), "unknown potion type %q, expected healing, mana or rejuvenation", col)
+		}
+	}
+
+	if cfg.Companion.Enabled && !cfg.Companion.Leader && cfg.Companion.LeaderName != "" && !characterNameExists(cfg.Companion.LeaderName) {
+		v.warning("companion.leaderName", "no supervisor plays a character named %q", cfg.Companion.LeaderName)
+	}
+	if cfg.Muling.Enabled {
+		if len(cfg.Muling.MuleProfiles) == 0 {
+			v.error("muling.muleProfiles", "muling is enabled without mule profiles")
+		}
+		for i, profile := range cfg.Muling.MuleProfiles {
+			if _, found := Characters[profile]; !found {
+				v.error(fmt.Sprintf("muling.muleProfiles[%d]", i), "supervisor %q does not exist", profile)
+			}
+		}
+	}
+
+	return v.issues
+}
+
+func (v *validator) validateHealth(cfg *CharacterCfg) {
+	h := cfg.Health
+	percentages := []struct {
+		path  string
+		value int
+	}{
+		{"health.healingPotionAt", h.HealingPotionAt},
+		{"health.manaPotionAt", h.ManaPotionAt},
+		{"health.rejuvPotionAtLife", h.RejuvPotionAtLife},
+		{"health.rejuvPotionAtMana", h.RejuvPotionAtMana},
+		{"health.mercHealingPotionAt", h.MercHealingPotionAt},
+		{"health.mercRejuvPotionAt", h.MercRejuvPotionAt},
+		{"health.chickenAt", h.ChickenAt},
+		{"health.townChickenAt", h.TownChickenAt},
+		{"health.mercChickenAt", h.MercChickenAt},
+	}
+	for _, p := range percentages {
+		if p.value < 0 || p.value > 100 {
+			v.error(p.path, "%d is not a percentage", p.value)
+		}
+	}
+
+	if h.ChickenAt > 0 && h.HealingPotionAt > 0 && h.ChickenAt >= h.HealingPotionAt {
+		v.error("health.chickenAt", "chicken at %d%% life is above healing potions at %d%%, potions would never be used", h.ChickenAt, h.HealingPotionAt)
+	}
+	if h.ChickenAt > 0 && h.RejuvPotionAtLife > 0 && h.ChickenAt >= h.RejuvPotionAtLife {
+		v.warning("health.rejuvPotionAtLife", "rejuvenation at %d%% life is below chicken at %d%%, it would never be used", h.RejuvPotionAtLife, h.ChickenAt)
+	}
+	if h.MercChickenAt > 0 && h.MercHealingPotionAt > 0 && h.MercChickenAt >= h.MercHealingPotionAt {
+		v.warning("health.mercChickenAt", "merc chicken at %d%% life is above merc healing potions at %d%%", h.MercChickenAt, h.MercHealingPotionAt)
+	}
+}
+
+func (v *validator) validateRuns(cfg *CharacterCfg) {
+	if len(cfg.Game.Runs) == 0 {
+		v.warning("game.runs", "no runs configured, the supervisor won't do anything")
+	}
+
+	for i, r := range cfg.Game.Runs {
+		path := fmt.Sprintf("game.runs[%d]", i)
+		if _, found := AvailableRuns[r]; !found {
+			v.error(path, "unknown run %q", r)
+			continue
+		}
+		if i > 0 && (r == LevelingRun || r == LevelingSequenceRun) {
+			v.error(path, "%s must be the first run", r)
+		}
+	}
+
+	if !slices.Contains(cfg.Game.Runs, LevelingSequenceRun) {
+		return
+	}
+	file := strings.TrimSpace(cfg.Game.LevelingSequence.SequenceFile)
+	if file == "" {
+		v.error("game.leveling_sequence.sequenceFile", "the leveling sequence file is not set")
+		return
+	}
+	file = strings.TrimSuffix(file, filepath.Ext(file))
+	if _, err := os.Stat(getAbsPath(filepath.Join("config", "template", "sequences_leveling", file+".json"))); err != nil {
+		v.error("game.leveling_sequence.sequenceFile", "sequence %s.json not found in config/template/sequences_leveling", file)
+	}
+}
+
+// unknownFields decodes a file again in strict mode to find misspelled keys, they are silently dropped otherwise
+func unknownFields(file, path string, target any) []Issue {
+	r, err := os.Open(path)
+	if err != nil {
+		return []Issue{{File: file, Severity: SeverityError, Message: err.Error()}}
+	}
+	defer r.Close()
+
+	d := yaml.NewDecoder(r)
+	d.KnownFields(true)
+	err = d.Decode(target)
+
+	var typeErr *yaml.TypeError
+	if !errors.As(err, &typeErr) {
+		return nil
+	}
+	issues := make([]Issue, 0, len(typeErr.Errors))
+	for _, e := range typeErr.Errors {
+		if m := unknownFieldRe.FindStringSubmatch(e); m != nil {
+			issues = append(issues, Issue{File: file, Path: m[2], Severity: SeverityWarning, Message: fmt.Sprintf("unknown key on line %s, it is ignored", m[1])})
+		}
+	}
+
+	return issues
+}
+
+func characterNameExists(name string) bool {
+	for _, cfg := range Characters {
+		if strings.EqualFold(cfg.CharacterName, name) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func characterConfigFile(name string) string {
+	return name + "/config.yaml"
+}
+
+type validator struct {
+	file   string
+	issues []Issue
+}
+
+func (v *validator) error(path, format string, args ...any) {
+	v.issues = append(v.issues, Issue{File: v.file, Path: path, Severity: SeverityError, Message: fmt.Sprintf(format, args...)})
+}
+
+func (v *validator) warning(path, format string, args ...any) {
+	v.issues = append(v.issues, Issue{File: v.file, Path: path, Severity: SeverityWarning, Message: fmt.Sprintf(format, args...)})
+}
@@ -0,0 +1,32 @@
+package config
+
+import "testing"
+
+func TestValidateKooloNgrokToken(t *testing.T) {
+	ngrokIssue := func(cfg *KooloCfg) *Issue {
+		for _, i := range ValidateKoolo(cfg) {
+			if i.Path == "ngrok.authtoken" {
+				return &i
+			}
+		}
+		return nil
+	}
+
+	cfg := &KooloCfg{}
+	cfg.Ngrok.Enabled = true
+	t.Setenv("NGROK_AUTHTOKEN", "")
+	if i := ngrokIssue(cfg); i == nil || i.Severity != SeverityWarning {
+		t.Errorf("missing token reported as %+v, want a warning", i)
+	}
+
+	t.Setenv("NGROK_AUTHTOKEN", "tk_env")
+	if i := ngrokIssue(cfg); i != nil {
+		t.Errorf("token from the environment reported as %+v", i)
+	}
+
+	t.Setenv("NGROK_AUTHTOKEN", "")
+	cfg.Ngrok.Authtoken = "tk_config"
+	if i := ngrokIssue(cfg); i != nil {
+		t.Errorf("configured token reported as %+v", i)
+	}
+}
@@ -63,11 +63,12 @@ type Context struct {
 	RestartWithCharacter      string
 	PacketSender              *game.PacketSender
 	IsLevelingCharacter       *bool
-	ManualModeActive          bool          // Manual play mode: stops after character selection
-	LastPortalTick            time.Time     // NEW FIELD: Tracks last portal creation for spam prevention
-	IsBossEquipmentActive     bool          // flag for barb leveling
-	Drop                      *drop.Manager // Drop: Per-supervisor Drop manager
-	IsAllocatingStatsOrSkills atomic.Bool   // Prevents stuck detection during stat/skill allocation
+	ManualModeActive          bool                              // Manual play mode: stops after character selection
+	LastPortalTick            time.Time                         // NEW FIELD: Tracks last portal creation for spam prevention
+	IsBossEquipmentActive     bool                              // flag for barb leveling
+	Drop                      *drop.Manager                     // Drop: Per-supervisor Drop manager
+	IsAllocatingStatsOrSkills atomic.Bool                       // Prevents stuck detection during stat/skill allocation
+	Recorder                  atomic.Pointer[game.GameRecorder] // Records every game data refresh when set, see Debug.RecordGameState
 }
 
 type Debug struct {
@@ -192,6 +193,11 @@ func (ctx *Context) RefreshGameData() {
 	}
 	ctx.Data.IsLevelingCharacter = *ctx.IsLevelingCharacter
 
+	if rec := ctx.Recorder.Load(); rec != nil {
+		if err := rec.Record(*ctx.Data); err != nil {
+			ctx.Logger.Debug("Failed to record game data", slog.Any("error", err))
+		}
+	}
 }
 
 func (ctx *Context) RefreshInventory() {
@@ -0,0 +1,32 @@
+package context
+
+import (
+	"log/slog"
+
+	"github.com/hectorgimenez/koolo/internal/config"
+	"github.com/hectorgimenez/koolo/internal/game"
+	"github.com/hectorgimenez/koolo/internal/game/replay"
+	"github.com/hectorgimenez/koolo/internal/health"
+	"github.com/hectorgimenez/koolo/internal/pather"
+)
+
+// NewReplayContext builds a context attached to the calling goroutine that reads the game data from a recording and
+// never sends inputs to the game, they are kept in the given log so decisions taken from game data can be checked
+// offline. The character is not
+// built here (it depends on this package), callers needing it should set Char with character.BuildCharacter.
+func NewReplayContext(name string, cfg *config.CharacterCfg, r *game.ReplayReader, inputs *replay.InputLog, logger *slog.Logger) *Status {
+	s := NewContext(name)
+
+	gr := game.NewReplayMemoryReader(cfg, name, r, logger)
+	hid := game.NewNoopHID(inputs)
+	s.CharacterCfg = cfg
+	s.Logger = logger
+	s.HID = hid
+	s.GameReader = gr
+	s.PathFinder = pather.NewPathFinder(gr, s.Data, hid, cfg)
+	s.BeltManager = health.NewBeltManager(s.Data, hid, logger, name)
+	s.HealthManager = health.NewHealthManager(s.BeltManager, s.Data)
+	s.RefreshGameData()
+
+	return s
+}
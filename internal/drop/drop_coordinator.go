@@ -23,13 +23,22 @@ type Coordinator struct {
 
 	// Callback to report Drop run result back to server
 	onDropResult func(supervisorName, room, result string, itemsDroppered int, duration time.Duration, errorMsg string, filters Filters)
+
+	// Other Drop result listeners, e.g. item transfers waiting for their Drop
+	resultListeners   map[int]ResultListener
+	nextListenerID    int
+	resultListenersMu sync.RWMutex
 }
 
+// ResultListener receives the result of every finished Drop run
+type ResultListener func(supervisorName, room, result string, itemsDroppered int, duration time.Duration, errorMsg string, filters Filters)
+
 // NewCoordinator creates a new Coordinator and initializes its filter map.
 func NewCoordinator(logger *slog.Logger) *Coordinator {
 	return &Coordinator{
-		logger:  logger,
-		filters: make(map[string]Filters),
+		logger:          logger,
+		filters:         make(map[string]Filters),
+		resultListeners: make(map[int]ResultListener),
 	}
 }
 
@@ -62,6 +71,38 @@ func (c *Coordinator) SetDropResultCallback(callback func(supervisorName, room,
 	c.onDropResult = callback
 }
 
+// AddDropResultListener registers a listener for Drop results, the returned function removes it.
+func (c *Coordinator) AddDropResultListener(listener ResultListener) (remove func()) {
+	c.resultListenersMu.Lock()
+	defer c.resultListenersMu.Unlock()
+	c.nextListenerID++
+	id := c.nextListenerID
+	c.resultListeners[id] = listener
+
+	return func() {
+		c.resultListenersMu.Lock()
+		defer c.resultListenersMu.Unlock()
+		delete(c.resultListeners, id)
+	}
+}
+
+// reportDropResult forwards a Drop result to the server callback and every listener.
+func (c *Coordinator) reportDropResult(supervisorName, room, result string, itemsDroppered int, duration time.Duration, errorMsg string, filters Filters) {
+	if c.onDropResult != nil {
+		c.onDropResult(supervisorName, room, result, itemsDroppered, duration, errorMsg, filters)
+	}
+
+	c.resultListenersMu.RLock()
+	listeners := make([]ResultListener, 0, len(c.resultListeners))
+	for _, l := range c.resultListeners {
+		listeners = append(listeners, l)
+	}
+	c.resultListenersMu.RUnlock()
+	for _, l := range listeners {
+		l(supervisorName, room, result, itemsDroppered, duration, errorMsg, filters)
+	}
+}
+
 // ConfigureCallbacks wires OnComplete/OnResult callbacks into the given Manager.
 func (c *Coordinator) ConfigureCallbacks(supervisorName string, mgr *Manager) {
 	if mgr == nil {
@@ -70,7 +111,7 @@ func (c *Coordinator) ConfigureCallbacks(supervisorName string, mgr *Manager) {
 
 	mgr.SetCallbacks(Callbacks{
 		OnComplete:     c.ClearIndividualFilters,
-		OnResult:       c.onDropResult,
+		OnResult:       c.reportDropResult,
 		OnClearRequest: c.clearPersistentRequest,
 	})
 }
@@ -1,10 +1,16 @@
 package drop
 
 import (
+	"cmp"
+	"encoding/binary"
+	"hash/fnv"
+	"slices"
 	"strings"
 	"sync"
 
+	"github.com/hectorgimenez/d2go/pkg/data"
 	"github.com/hectorgimenez/d2go/pkg/data/item"
+	"github.com/hectorgimenez/d2go/pkg/data/stat"
 )
 
 // ItemQuantity represents an item name together with an optional max Drop quota.
@@ -14,16 +20,82 @@ type ItemQuantity struct {
 	Quantity int    `json:"quantity"` // 0 means unlimited
 }
 
+// ItemSelection is an exact item: its name, quality, for uniques and sets which unique or set item it is (so a
+// Stone of Jordan selection never matches a Bul-Kathos' Wedding Band) and the hash of its stats, so a rare ring only
+// matches that ring and not another rare ring. Quantity works as in ItemQuantity.
+type ItemSelection struct {
+	Name        string       `json:"name"`
+	Quality     item.Quality `json:"quality"`
+	UniqueSetID int32        `json:"uniqueSetId"` // Only checked for unique and set items
+	StatsHash   uint32       `json:"statsHash"`   // See StatsHash
+	Quantity    int          `json:"quantity"`    // 0 means unlimited
+}
+
+func (sel ItemSelection) matches(i data.Item) bool {
+	if !strings.EqualFold(sel.Name, string(i.Name)) || sel.Quality != i.Quality {
+		return false
+	}
+	if (i.Quality == item.QualityUnique || i.Quality == item.QualitySet) && sel.UniqueSetID != i.UniqueSetID {
+		return false
+	}
+
+	return sel.StatsHash == StatsHash(i)
+}
+
+// StatsHash fingerprints the rolled properties of an item: its stats, affixes and whether it's ethereal. Items without
+// rolls (runes, gems, keys...) of the same name share the same hash. Durability and quantity are left out, they change
+// while the item is used.
+func StatsHash(i data.Item) uint32 {
+	stats := make([]stat.Data, 0, len(i.Stats))
+	for _, s := range i.Stats {
+		if s.ID != stat.Durability && s.ID != stat.Quantity {
+			stats = append(stats, s)
+		}
+	}
+	slices.SortFunc(stats, func(a, b stat.Data) int {
+		if a.ID != b.ID {
+			return cmp.Compare(a.ID, b.ID)
+		}
+		if a.Layer != b.Layer {
+			return cmp.Compare(a.Layer, b.Layer)
+		}
+		return cmp.Compare(a.Value, b.Value)
+	})
+
+	h := fnv.New32a()
+	write := func(values ...int64) {
+		var buf [8]byte
+		for _, v := range values {
+			binary.LittleEndian.PutUint64(buf[:], uint64(v))
+			h.Write(buf[:])
+		}
+	}
+	for _, s := range stats {
+		write(int64(s.ID), int64(s.Layer), int64(s.Value))
+	}
+	ethereal := int64(0)
+	if i.Ethereal {
+		ethereal = 1
+	}
+	write(ethereal, int64(i.Affixes.Rare.Prefix), int64(i.Affixes.Rare.Suffix))
+	for k := range i.Affixes.Magic.Prefixes {
+		write(int64(i.Affixes.Magic.Prefixes[k]), int64(i.Affixes.Magic.Suffixes[k]))
+	}
+
+	return h.Sum32()
+}
+
 // Filters holds Drop preferences (filters/quotas) shared between UI/server and bot runtime.
 // It defines which runes/gems/custom items are considered Dropperable and in what mode.
 type Filters struct {
-	Enabled             bool           `json:"enabled"`
-	DropperOnlySelected bool           `json:"DropperOnlySelected"`
-	SelectedRunes       []ItemQuantity `json:"selectedRunes"`
-	SelectedGems        []ItemQuantity `json:"selectedGems"`
-	SelectedKeyTokens   []ItemQuantity `json:"selectedKeyTokens"`
-	CustomItems         []string       `json:"customItems"`      // Legacy: simple names without quantity information
-	AllowedQualities    []string       `json:"allowedQualities"` // e.g., base, magic, rare, set, unique, crafted, runeword
+	Enabled             bool            `json:"enabled"`
+	DropperOnlySelected bool            `json:"DropperOnlySelected"`
+	SelectedRunes       []ItemQuantity  `json:"selectedRunes"`
+	SelectedGems        []ItemQuantity  `json:"selectedGems"`
+	SelectedKeyTokens   []ItemQuantity  `json:"selectedKeyTokens"`
+	CustomItems         []string        `json:"customItems"`      // Legacy: simple names without quantity information
+	AllowedQualities    []string        `json:"allowedQualities"` // e.g., base, magic, rare, set, unique, crafted, runeword
+	SelectedItems       []ItemSelection `json:"selectedItems"`    // Exact items, e.g. the one of a stash notification
 }
 
 // Normalize trims whitespace, removes empty values and duplicates, and returns
@@ -35,6 +107,7 @@ func (f Filters) Normalize() Filters {
 	f.SelectedKeyTokens = normalizeItemQuantities(f.SelectedKeyTokens)
 	f.CustomItems = normalizeList(f.CustomItems)
 	f.AllowedQualities = normalizeList(f.AllowedQualities)
+	f.SelectedItems = normalizeItemSelections(f.SelectedItems)
 	return f
 }
 
@@ -88,6 +161,11 @@ func (f Filters) GetItemQuantity(itemName string) int {
 			return item.Quantity
 		}
 	}
+	for _, sel := range f.SelectedItems {
+		if strings.ToLower(sel.Name) == lowerName {
+			return sel.Quantity
+		}
+	}
 
 	// CustomItems don't have quantity limits
 	return 0
@@ -187,6 +265,21 @@ func (s *ContextFilters) ShouldDropperItem(name string, quality item.Quality, it
 	return nameMatch || qualityMatch
 }
 
+// IsSelectedItem reports whether the item is one of the exact SelectedItems.
+func (s *ContextFilters) IsSelectedItem(i data.Item) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if !s.filters.Enabled {
+		return false
+	}
+	for _, sel := range s.filters.SelectedItems {
+		if sel.matches(i) {
+			return true
+		}
+	}
+	return false
+}
+
 // HasRemainingDropQuota reports whether the item has not yet reached its configured quota.
 func (s *ContextFilters) HasRemainingDropQuota(name string) bool {
 	s.mu.RLock()
@@ -268,6 +361,11 @@ func (s *ContextFilters) HasDropQuotaLimits() bool {
 			return true
 		}
 	}
+	for _, sel := range s.filters.SelectedItems {
+		if sel.Quantity > 0 {
+			return true
+		}
+	}
 	return false
 }
 
@@ -306,6 +404,15 @@ func (s *ContextFilters) AreDropQuotasSatisfied() bool {
 			return false
 		}
 	}
+	for _, sel := range s.filters.SelectedItems {
+		if sel.Quantity <= 0 {
+			continue
+		}
+		hasFinite = true
+		if s.Droppered[strings.ToLower(sel.Name)] < sel.Quantity {
+			return false
+		}
+	}
 	return hasFinite
 }
 
@@ -385,6 +492,23 @@ func normalizeItemQuantities(values []ItemQuantity) []ItemQuantity {
 	return norm
 }
 
+// normalizeItemSelections trims names and removes empty selections, the same name can be selected with different
+// qualities.
+func normalizeItemSelections(values []ItemSelection) []ItemSelection {
+	norm := make([]ItemSelection, 0, len(values))
+	for _, v := range values {
+		v.Name = strings.TrimSpace(v.Name)
+		if v.Name == "" {
+			continue
+		}
+		if v.Quantity < 0 {
+			v.Quantity = 0
+		}
+		norm = append(norm, v)
+	}
+	return norm
+}
+
 // normalizeList trims names, removes empties and duplicates, and returns the normalized slice.
 func normalizeList(values []string) []string {
 	seen := make(map[string]struct{}, len(values))
@@ -0,0 +1,69 @@
+package drop
+
+import (
+	"testing"
+
+	"github.com/hectorgimenez/d2go/pkg/data"
+	"github.com/hectorgimenez/d2go/pkg/data/item"
+	"github.com/hectorgimenez/d2go/pkg/data/stat"
+)
+
+func TestItemSelectionMatches(t *testing.T) {
+	ring := func(quality item.Quality, stats ...stat.Data) data.Item {
+		return data.Item{Name: "Ring", Quality: quality, Stats: stats}
+	}
+	selected := ring(item.QualityRare, stat.Data{ID: stat.FasterCastRate, Value: 10}, stat.Data{ID: stat.Strength, Value: 12})
+	sel := ItemSelection{Name: "ring", Quality: item.QualityRare, StatsHash: StatsHash(selected)}
+
+	tests := []struct {
+		name    string
+		item    data.Item
+		matches bool
+	}{
+		{"same item", selected, true},
+		{"stats in another order", ring(item.QualityRare, stat.Data{ID: stat.Strength, Value: 12}, stat.Data{ID: stat.FasterCastRate, Value: 10}), true},
+		{"other rare ring", ring(item.QualityRare, stat.Data{ID: stat.FasterCastRate, Value: 10}, stat.Data{ID: stat.Strength, Value: 11}), false},
+		{"magic ring with the same stats", ring(item.QualityMagic, stat.Data{ID: stat.FasterCastRate, Value: 10}, stat.Data{ID: stat.Strength, Value: 12}), false},
+		{"other name", data.Item{Name: "Amulet", Quality: item.QualityRare, Stats: selected.Stats}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := sel.matches(tt.item); got != tt.matches {
+				t.Errorf("matches = %v, want %v", got, tt.matches)
+			}
+		})
+	}
+
+	soj := data.Item{Name: "Ring", Quality: item.QualityUnique, UniqueSetID: 122}
+	bk := data.Item{Name: "Ring", Quality: item.QualityUnique, UniqueSetID: 123}
+	sojSel := ItemSelection{Name: "Ring", Quality: item.QualityUnique, UniqueSetID: 122, StatsHash: StatsHash(soj)}
+	if !sojSel.matches(soj) || sojSel.matches(bk) {
+		t.Error("unique selections must match their own unique item only")
+	}
+}
+
+func TestStatsHash(t *testing.T) {
+	base := data.Item{Name: "Thresher", Stats: stat.Stats{{ID: stat.EnhancedDamage, Value: 15}, {ID: stat.Durability, Value: 40}}}
+
+	worn := base
+	worn.Stats = stat.Stats{{ID: stat.EnhancedDamage, Value: 15}, {ID: stat.Durability, Value: 12}}
+	if StatsHash(worn) != StatsHash(base) {
+		t.Error("durability changes the hash")
+	}
+
+	ethereal := base
+	ethereal.Ethereal = true
+	if StatsHash(ethereal) == StatsHash(base) {
+		t.Error("ethereal items share the hash of the non ethereal ones")
+	}
+
+	prefixed := base
+	prefixed.Affixes.Magic.Prefixes[0] = 7
+	if StatsHash(prefixed) == StatsHash(base) {
+		t.Error("affixes don't change the hash")
+	}
+
+	if StatsHash(data.Item{Name: "ElRune"}) != StatsHash(data.Item{Name: "ElRune"}) {
+		t.Error("items without rolls must share the same hash")
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/hectorgimenez/d2go/pkg/data"
 	"github.com/hectorgimenez/d2go/pkg/data/item"
 )
 
@@ -18,6 +19,8 @@ type Request struct {
 	CreatedAt time.Time
 	CardID    int
 	CardName  string
+	// Pickup makes the supervisor pick up the items dropped in the room instead of dropping its own
+	Pickup bool
 }
 
 // ErrInterrupt is used to interrupt the current run when a Drop is requested.
@@ -84,6 +87,23 @@ func (m *Manager) RequestDrop(room, passwd string) *Request {
 	return req
 }
 
+// RequestPickup enqueues a request to join a room and pick up the items dropped there, the receiving side of an
+// item transfer. It interrupts the current run the same way a Drop request does.
+func (m *Manager) RequestPickup(room, passwd string) *Request {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	req := &Request{
+		RoomName:  room,
+		Password:  passwd,
+		CreatedAt: time.Now(),
+		Pickup:    true,
+	}
+
+	m.pending = append(m.pending, req)
+	return req
+}
+
 // SetActive marks the given request as the currently active Drop.
 func (m *Manager) SetActive(req *Request) {
 	m.mu.Lock()
@@ -182,6 +202,14 @@ func (m *Manager) ShouldDropperItem(name string, quality item.Quality, itemType
 	return m.filters.ShouldDropperItem(name, quality, itemType, isRuneword)
 }
 
+// IsSelectedItem reports whether the item is one of the exact items selected for Drop.
+func (m *Manager) IsSelectedItem(i data.Item) bool {
+	if m == nil || m.filters == nil {
+		return false
+	}
+	return m.filters.IsSelectedItem(i)
+}
+
 // HasRemainingDropQuota reports whether there is remaining quota for the given item.
 func (m *Manager) HasRemainingDropQuota(name string) bool {
 	if m == nil || m.filters == nil {
@@ -144,6 +144,11 @@ func (s *Service) SetDropResultCallback(callback func(supervisorName, room, resu
 	s.coord.SetDropResultCallback(callback)
 }
 
+// Register an additional Drop result listener, the returned function removes it
+func (s *Service) AddDropResultListener(listener ResultListener) (remove func()) {
+	return s.coord.AddDropResultListener(listener)
+}
+
 // Store start-Drop request to apply when supervisor is attached
 func (s *Service) QueueStartDrop(supervisor, room, password string, filter Filters, cardID int, cardName string) {
 	if s == nil {
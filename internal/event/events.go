@@ -1,6 +1,8 @@
 package event
 
 import (
+	"time"
+
 	"github.com/hectorgimenez/d2go/pkg/data"
 )
 
@@ -152,6 +154,28 @@ func GamePaused(be BaseEvent, paused bool) GamePausedEvent {
 	}
 }
 
+// PingMonitorEvent is sent by the ping monitor when the ping goes above or back under the high ping threshold, and
+// when the high ping lasted long enough to leave the game
+type PingMonitorEvent struct {
+	BaseEvent
+	Ping      int
+	Threshold int
+	// HighPingSince is when the ping went above the threshold, zero when it's back to normal
+	HighPingSince time.Time
+	// Sustained is set once the high ping lasted the configured duration and the game is being left
+	Sustained bool
+}
+
+func PingMonitor(be BaseEvent, ping int, threshold int, highPingSince time.Time, sustained bool) PingMonitorEvent {
+	return PingMonitorEvent{
+		BaseEvent:     be,
+		Ping:          ping,
+		Threshold:     threshold,
+		HighPingSince: highPingSince,
+		Sustained:     sustained,
+	}
+}
+
 type RunewordRerollEvent struct {
 	BaseEvent
 	Runeword      string
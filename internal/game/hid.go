@@ -1,8 +1,13 @@
 package game
 
+import (
+	"github.com/hectorgimenez/koolo/internal/game/replay"
+)
+
 type HID struct {
-	gr *MemoryReader
-	gi *MemoryInjector
+	gr   *MemoryReader
+	gi   *MemoryInjector
+	noop *replay.InputLog
 }
 
 func NewHID(gr *MemoryReader, gi *MemoryInjector) *HID {
@@ -11,3 +16,28 @@ func NewHID(gr *MemoryReader, gi *MemoryInjector) *HID {
 		gi: gi,
 	}
 }
+
+// NewNoopHID returns an HID that never sends inputs to the game, used when replaying recordings. Inputs are kept in
+// the given log so tests can check what the bot would have done.
+func NewNoopHID(log *replay.InputLog) *HID {
+	return &HID{noop: log}
+}
+
+// Inputs returns the inputs received by an HID created with NewNoopHID, in order
+func (hid *HID) Inputs() []string {
+	if hid.noop == nil {
+		return nil
+	}
+
+	return hid.noop.Inputs()
+}
+
+// skip records the input and reports true when the HID is a no-op one
+func (hid *HID) skip(format string, args ...any) bool {
+	if hid.noop == nil {
+		return false
+	}
+	hid.noop.Record(format, args...)
+
+	return true
+}
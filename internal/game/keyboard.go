@@ -18,6 +18,9 @@ const (
 
 // PressKey receives an ASCII code and sends a key press event to the game window
 func (hid *HID) PressKey(key byte) {
+	if hid.skip("key %d", key) {
+		return
+	}
 	win.PostMessage(hid.gr.HWND, win.WM_KEYDOWN, uintptr(key), hid.calculatelParam(key, true))
 	sleepTime := rand.Intn(keyPressMaxTime-keyPressMinTime) + keyPressMinTime
 	time.Sleep(time.Duration(sleepTime) * time.Millisecond)
@@ -33,6 +36,9 @@ func (hid *HID) KeySequence(keysToPress ...byte) {
 
 // PressKeyWithModifier works the same as PressKey but with a modifier key (shift, ctrl, alt)
 func (hid *HID) PressKeyWithModifier(key byte, modifier ModifierKey) {
+	if hid.skip("key %d modifier %d", key, modifier) {
+		return
+	}
 	hid.gi.OverrideGetKeyState(byte(modifier))
 	hid.PressKey(key)
 	hid.gi.RestoreGetKeyState()
@@ -51,12 +57,18 @@ func (hid *HID) PressKeyBinding(kb data.KeyBinding) {
 // KeyDown sends a key down event to the game window
 func (hid *HID) KeyDown(kb data.KeyBinding) {
 	keys := getKeysForKB(kb)
+	if hid.skip("key down %d", keys[0]) {
+		return
+	}
 	win.PostMessage(hid.gr.HWND, win.WM_KEYDOWN, uintptr(keys[0]), hid.calculatelParam(keys[0], true))
 }
 
 // KeyUp sends a key up event to the game window
 func (hid *HID) KeyUp(kb data.KeyBinding) {
 	keys := getKeysForKB(kb)
+	if hid.skip("key up %d", keys[0]) {
+		return
+	}
 	win.PostMessage(hid.gr.HWND, win.WM_KEYUP, uintptr(keys[0]), hid.calculatelParam(keys[0], false))
 }
 
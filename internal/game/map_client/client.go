@@ -1,54 +1,37 @@
 package map_client
 
 import (
+	"bufio"
 	"encoding/json"
 	"fmt"
-	"os/exec"
-	"strings"
-	"syscall"
+	"io"
 
 	"github.com/hectorgimenez/d2go/pkg/data"
 	"github.com/hectorgimenez/d2go/pkg/data/area"
-	"github.com/hectorgimenez/d2go/pkg/data/difficulty"
 	"github.com/hectorgimenez/d2go/pkg/data/npc"
 	"github.com/hectorgimenez/d2go/pkg/data/object"
-	"github.com/hectorgimenez/koolo/internal/config"
 )
 
-func GetMapData(seed string, difficulty difficulty.Difficulty) (MapData, error) {
-	cmd := exec.Command("./tools/koolo-map.exe", config.Koolo.D2LoDPath, "-s", seed, "-d", getDifficultyAsNum(difficulty))
-	cmd.SysProcAttr = &syscall.SysProcAttr{HideWindow: true}
-	stdout, err := cmd.Output()
-	if err != nil {
-		return nil, fmt.Errorf("error fetching Map data from Diablo II: LoD 1.13c game: %w", err)
-	}
-
-	stdoutLines := strings.Split(string(stdout), "\r\n")
+// ParseMapData reads the levels printed by koolo-map.exe, one JSON object per line
+func ParseMapData(r io.Reader) (MapData, error) {
+	scanner := bufio.NewScanner(r)
+	// Lines hold a whole level map
+	scanner.Buffer(make([]byte, 0, 1024*1024), 64*1024*1024)
 
 	lvls := make([]serverLevel, 0)
-	for _, line := range stdoutLines {
+	for scanner.Scan() {
 		var lvl serverLevel
-		err = json.Unmarshal([]byte(line), &lvl)
+		err := json.Unmarshal(scanner.Bytes(), &lvl)
 		// Discard empty lines or lines that don't contain level information
 		if err == nil && lvl.Type != "" && len(lvl.Map) > 0 {
 			lvls = append(lvls, lvl)
 		}
 	}
-
-	return lvls, nil
-}
-
-func getDifficultyAsNum(df difficulty.Difficulty) string {
-	switch df {
-	case difficulty.Normal:
-		return "0"
-	case difficulty.Nightmare:
-		return "1"
-	case difficulty.Hell:
-		return "2"
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error reading map data: %w", err)
 	}
 
-	return "0"
+	return lvls, nil
 }
 
 type MapData []serverLevel
@@ -0,0 +1,35 @@
+package map_client
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"syscall"
+
+	"github.com/hectorgimenez/d2go/pkg/data/difficulty"
+	"github.com/hectorgimenez/koolo/internal/config"
+)
+
+func GetMapData(seed string, difficulty difficulty.Difficulty) (MapData, error) {
+	cmd := exec.Command("./tools/koolo-map.exe", config.Koolo.D2LoDPath, "-s", seed, "-d", getDifficultyAsNum(difficulty))
+	cmd.SysProcAttr = &syscall.SysProcAttr{HideWindow: true}
+	stdout, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("error fetching Map data from Diablo II: LoD 1.13c game: %w", err)
+	}
+
+	return ParseMapData(bytes.NewReader(stdout))
+}
+
+func getDifficultyAsNum(df difficulty.Difficulty) string {
+	switch df {
+	case difficulty.Normal:
+		return "0"
+	case difficulty.Nightmare:
+		return "1"
+	case difficulty.Hell:
+		return "2"
+	}
+
+	return "0"
+}
@@ -0,0 +1,151 @@
+package mapgrid
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/hectorgimenez/d2go/pkg/data"
+	"github.com/hectorgimenez/koolo/internal/game/replay"
+)
+
+// DumpVersion is increased on breaking changes of the dump format
+const DumpVersion = 1
+
+// DumpExtension is used for grid dumps: gzip compressed JSON
+const DumpExtension = ".grid.json.gz"
+
+// Dump is the map state of a live session saved to reproduce path issues offline, the areas are the grids as
+// processed by the bot (thickened, exits drilled) before any object or monster is added
+type Dump struct {
+	Version     int           `json:"version"`
+	CreatedAt   time.Time     `json:"createdAt"`
+	Supervisor  string        `json:"supervisor,omitempty"`
+	MapSeed     uint          `json:"mapSeed,omitempty"`
+	Difficulty  string        `json:"difficulty,omitempty"`
+	CanTeleport bool          `json:"canTeleport"`
+	Player      data.Position `json:"player"`
+	// Areas holds the current area first, then the adjacent areas with map data
+	Areas    []replay.Area `json:"areas"`
+	Monsters data.Monsters `json:"monsters,omitempty"`
+	NPCs     data.NPCs     `json:"npcs,omitempty"`
+}
+
+// EncodeCollision returns the grid cells run-length encoded the way replay.Area stores them
+func (g *Grid) EncodeCollision() []byte {
+	cells := make([]byte, len(g.CollisionGrid))
+	for i, c := range g.CollisionGrid {
+		cells[i] = byte(c)
+	}
+
+	return replay.EncodeGrid(cells)
+}
+
+// FromReplay returns the grid of a recorded area, nil when the area has no map data
+func FromReplay(a replay.Area) (*Grid, error) {
+	if a.Width == 0 || a.Height == 0 {
+		return nil, nil
+	}
+
+	cells, err := replay.DecodeGrid(a.Collision, a.Width*a.Height)
+	if err != nil {
+		return nil, fmt.Errorf("area %d: %w", a.ID, err)
+	}
+	grid := &Grid{OffsetX: a.OffsetX, OffsetY: a.OffsetY, Width: a.Width, Height: a.Height, CollisionGrid: make([]CollisionType, len(cells))}
+	for i, c := range cells {
+		grid.CollisionGrid[i] = CollisionType(c)
+	}
+
+	return grid, nil
+}
+
+// Scene rebuilds the path finder input of the dump
+func (d Dump) Scene() (Scene, error) {
+	if len(d.Areas) == 0 || d.Areas[0].Width == 0 {
+		return Scene{}, errors.New("the dump has no map data for the current area")
+	}
+
+	current := d.Areas[0]
+	grid, err := FromReplay(current)
+	if err != nil {
+		return Scene{}, err
+	}
+	s := Scene{
+		Area:        current.ID,
+		Grid:        grid,
+		Objects:     current.Objects,
+		Monsters:    d.Monsters,
+		NPCs:        d.NPCs,
+		CanTeleport: d.CanTeleport,
+	}
+	for _, a := range d.Areas[1:] {
+		g, err := FromReplay(a)
+		if err != nil {
+			return Scene{}, err
+		}
+		if g != nil {
+			s.Adjacent = append(s.Adjacent, Level{Area: a.ID, Grid: g})
+		}
+	}
+
+	return s, nil
+}
+
+// Write stores the dump as gzip compressed JSON
+func (d Dump) Write(w io.Writer) error {
+	d.Version = DumpVersion
+	gz := gzip.NewWriter(w)
+	if err := json.NewEncoder(gz).Encode(d); err != nil {
+		return fmt.Errorf("error encoding grid dump: %w", err)
+	}
+
+	return gz.Close()
+}
+
+// Save writes the dump to path, creating its directory
+func (d Dump) Save(path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("error creating grid dump directory: %w", err)
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("error creating grid dump: %w", err)
+	}
+
+	return errors.Join(d.Write(f), f.Close())
+}
+
+// ReadDump reads a dump written by Write
+func ReadDump(r io.Reader) (Dump, error) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return Dump{}, fmt.Errorf("invalid grid dump: %w", err)
+	}
+	defer gz.Close()
+
+	var d Dump
+	if err = json.NewDecoder(gz).Decode(&d); err != nil {
+		return Dump{}, fmt.Errorf("invalid grid dump: %w", err)
+	}
+	if d.Version > DumpVersion {
+		return Dump{}, fmt.Errorf("grid dump version %d is not supported, max version is %d", d.Version, DumpVersion)
+	}
+
+	return d, nil
+}
+
+// LoadDump reads a dump file
+func LoadDump(path string) (Dump, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return Dump{}, err
+	}
+	defer f.Close()
+
+	return ReadDump(f)
+}
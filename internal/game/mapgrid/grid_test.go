@@ -0,0 +1,261 @@
+package mapgrid
+
+import (
+	"bytes"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/hectorgimenez/d2go/pkg/data"
+	"github.com/hectorgimenez/d2go/pkg/data/area"
+	"github.com/hectorgimenez/koolo/internal/game/replay"
+)
+
+// parseGrid builds a grid from rows of '#' (non walkable), '.' (walkable) and 't' (teleport over) cells
+func parseGrid(offsetX, offsetY int, rows ...string) *Grid {
+	g := &Grid{OffsetX: offsetX, OffsetY: offsetY, Width: len(rows[0]), Height: len(rows)}
+	g.CollisionGrid = make([]CollisionType, g.Width*g.Height)
+	for y, row := range rows {
+		for x, c := range row {
+			switch c {
+			case '.':
+				g.Set(x, y, CollisionTypeWalkable)
+			case 't':
+				g.Set(x, y, CollisionTypeTeleportOver)
+			}
+		}
+	}
+
+	return g
+}
+
+func (g *Grid) rows() []string {
+	symbols := map[CollisionType]byte{
+		CollisionTypeNonWalkable:  '#',
+		CollisionTypeWalkable:     '.',
+		CollisionTypeLowPriority:  'l',
+		CollisionTypeMonster:      'm',
+		CollisionTypeObject:       'o',
+		CollisionTypeTeleportOver: 't',
+	}
+	rows := make([]string, g.Height)
+	for y := range rows {
+		var sb strings.Builder
+		for x := 0; x < g.Width; x++ {
+			sb.WriteByte(symbols[g.Get(x, y)])
+		}
+		rows[y] = sb.String()
+	}
+
+	return rows
+}
+
+func TestThickenCollisions(t *testing.T) {
+	g := parseGrid(0, 0,
+		"#######",
+		"#.....#",
+		"###.###",
+		"###.###",
+		"#.....#",
+		"#######",
+	)
+	ThickenCollisions(g)
+
+	// Cells walled on 3 sides (the ends of the 1 tile tall rooms) are closed for walkers, the corridor stays open
+	want := []string{
+		"#######",
+		"#t...t#",
+		"###.###",
+		"###.###",
+		"#t...t#",
+		"#######",
+	}
+	if got := g.rows(); !slices.Equal(got, want) {
+		t.Errorf("got\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestFillGaps(t *testing.T) {
+	g := parseGrid(0, 0,
+		"#####",
+		"##..#",
+		"#.#.#",
+		"#...#",
+		"#####",
+	)
+	FillGaps(g)
+
+	if g.Get(3, 1) == CollisionTypeWalkable {
+		t.Errorf("diagonal gap at (3,1) should be closed, got\n%s", strings.Join(g.rows(), "\n"))
+	}
+}
+
+func TestDrillExits(t *testing.T) {
+	g := parseGrid(100, 200,
+		"#####",
+		"#ttt#",
+		"#ttt#",
+		"#ttt#",
+		"#####",
+	)
+	DrillExits(g, []data.Position{{X: 102, Y: 202}})
+
+	for y := 1; y <= 3; y++ {
+		for x := 1; x <= 3; x++ {
+			if g.Get(x, y) != CollisionTypeWalkable {
+				t.Fatalf("exit surroundings should be walkable, got\n%s", strings.Join(g.rows(), "\n"))
+			}
+		}
+	}
+}
+
+func TestNewLevelGrid(t *testing.T) {
+	// Two rooms split by a 3 cells wide wall
+	walkable := make([][]bool, 9)
+	for y := range walkable {
+		walkable[y] = make([]bool, 15)
+		for x := range walkable[y] {
+			walkable[y][x] = y > 0 && y < 8 && (x > 0 && x < 6 || x > 8 && x < 14)
+		}
+	}
+
+	g := NewLevelGrid(walkable, 100, 200, area.DuranceOfHateLevel3, nil)
+	if g.OffsetX != 100 || g.OffsetY != 200 || g.Width != 15 || g.Height != 9 {
+		t.Fatalf("unexpected grid bounds %+v", g)
+	}
+	if c := g.Get(7, 4); c != CollisionTypeTeleportOver {
+		t.Errorf("wall between rooms should be teleport over, got %d", c)
+	}
+
+	town := NewLevelGrid(walkable, 100, 200, area.RogueEncampment, nil)
+	if c := town.Get(7, 4); c != CollisionTypeNonWalkable {
+		t.Errorf("town walls are never teleport over, got %d", c)
+	}
+}
+
+func TestNewGridLowPriority(t *testing.T) {
+	raw := make([][]CollisionType, 7)
+	for y := range raw {
+		raw[y] = slices.Repeat([]CollisionType{CollisionTypeWalkable}, 7)
+	}
+	raw[3][3] = CollisionTypeNonWalkable
+
+	g := NewGrid(raw, 0, 0, false)
+	if g.Get(1, 1) != CollisionTypeLowPriority || g.Get(5, 5) != CollisionTypeLowPriority {
+		t.Errorf("cells 2 tiles away from a wall should be low priority, got\n%s", strings.Join(g.rows(), "\n"))
+	}
+	if g.Get(0, 0) != CollisionTypeWalkable {
+		t.Errorf("cells 3 tiles away from a wall should stay walkable, got\n%s", strings.Join(g.rows(), "\n"))
+	}
+}
+
+func TestMerge(t *testing.T) {
+	origin := parseGrid(10, 10,
+		"......",
+		"......",
+	)
+	destination := parseGrid(16, 11,
+		"...",
+		"...",
+	)
+
+	merged := Merge(origin, destination, false)
+	if merged.OffsetX != 10 || merged.OffsetY != 10 || merged.Width != 9 || merged.Height != 3 {
+		t.Fatalf("merged grid %d,%d %dx%d, expected 10,10 9x3", merged.OffsetX, merged.OffsetY, merged.Width, merged.Height)
+	}
+	for _, p := range []data.Position{{X: 10, Y: 10}, {X: 15, Y: 11}, {X: 18, Y: 12}} {
+		if !merged.IsWalkable(p) {
+			t.Errorf("%v should be walkable", p)
+		}
+	}
+	if merged.IsWalkable(data.Position{X: 18, Y: 10}) {
+		t.Error("cells outside both grids should not be walkable")
+	}
+}
+
+func TestScenePathGrid(t *testing.T) {
+	current := parseGrid(0, 0,
+		"........",
+		"........",
+		"........",
+		"........",
+	)
+	adjacent := parseGrid(8, 0,
+		"....",
+		"....",
+		"....",
+		"....",
+	)
+	s := Scene{
+		Area:     area.BloodMoor,
+		Grid:     current,
+		Adjacent: []Level{{Area: area.ColdPlains, Grid: adjacent}},
+		Monsters: data.Monsters{{Position: data.Position{X: 2, Y: 2}}},
+	}
+
+	g, from, to, err := s.PathGrid(data.Position{X: 1, Y: 1}, data.Position{X: 10, Y: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if g.Width != 12 || from != (data.Position{X: 1, Y: 1}) || to != (data.Position{X: 10, Y: 2}) {
+		t.Errorf("unexpected merged grid width %d, from %v, to %v", g.Width, from, to)
+	}
+	if g.Get(2, 2) != CollisionTypeMonster {
+		t.Error("monsters should be added to the path grid")
+	}
+	if current.Get(2, 2) != CollisionTypeWalkable {
+		t.Error("the area grid should never be modified")
+	}
+
+	if _, _, _, err = s.PathGrid(data.Position{X: 1, Y: 1}, data.Position{X: 50, Y: 2}); err == nil {
+		t.Error("expected an error for a destination outside every grid")
+	}
+}
+
+func TestDumpRoundTrip(t *testing.T) {
+	g := parseGrid(100, 200,
+		"#..#",
+		"#.t#",
+	)
+	d := Dump{
+		CanTeleport: true,
+		Player:      data.Position{X: 101, Y: 200},
+		Areas: []replay.Area{{
+			ID: area.ArcaneSanctuary, OffsetX: g.OffsetX, OffsetY: g.OffsetY, Width: g.Width, Height: g.Height,
+			Collision: g.EncodeCollision(),
+		}},
+	}
+
+	var buf bytes.Buffer
+	if err := d.Write(&buf); err != nil {
+		t.Fatal(err)
+	}
+	read, err := ReadDump(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := read.Scene()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Area != area.ArcaneSanctuary || !s.CanTeleport || !slices.Equal(s.Grid.CollisionGrid, g.CollisionGrid) {
+		t.Errorf("scene doesn't match the dumped state: %+v", s)
+	}
+}
+
+func TestVersionSources(t *testing.T) {
+	files, err := filepath.Glob("*.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, name := range files {
+		if strings.HasSuffix(name, "_test.go") || name == "version.go" {
+			continue
+		}
+		if _, err = sources.ReadFile(name); err != nil {
+			t.Errorf("%s is not embedded in sources, changes to it won't change Version", name)
+		}
+	}
+}
@@ -0,0 +1,130 @@
+package mapgrid
+
+import (
+	"github.com/hectorgimenez/d2go/pkg/data"
+	"github.com/hectorgimenez/d2go/pkg/data/area"
+)
+
+// teleportGapDistance is how far a gap can be crossed by teleport, in cells on each side
+const teleportGapDistance = 10
+
+// NewLevelGrid builds the grid of a level from the walkable cells of its map data, processed the way the bot paths
+// on it: outside of towns the gaps a teleport can cross become teleport over cells, then walls are thickened and the
+// exits (world positions, ignored in towns) drilled back open.
+func NewLevelGrid(walkable [][]bool, offsetX, offsetY int, a area.ID, exits []data.Position) *Grid {
+	height := len(walkable)
+	width := 0
+	if height > 0 {
+		width = len(walkable[0])
+	}
+
+	raw := make([][]CollisionType, height)
+	for y := range raw {
+		raw[y] = make([]CollisionType, width)
+		for x := range raw[y] {
+			if walkable[y][x] {
+				raw[y][x] = CollisionTypeWalkable
+			}
+		}
+	}
+
+	if !a.IsTown() {
+		markTeleportGaps(raw, width, height)
+	}
+	grid := NewGrid(raw, offsetX, offsetY, false)
+	ThickenCollisions(grid)
+	if !a.IsTown() {
+		DrillExits(grid, exits)
+	}
+
+	return grid
+}
+
+// markTeleportGaps turns the non walkable cells with walkable ones on both sides within teleportGapDistance into
+// teleport over cells
+func markTeleportGaps(grid [][]CollisionType, width, height int) {
+	for y := 1; y < height-1; y++ {
+		for x := 1; x < width-1; x++ {
+			if c := grid[y][x]; c == CollisionTypeNonWalkable || c == CollisionTypeTeleportOver {
+				markTeleportGap(grid, x, y, width, height, teleportGapDistance)
+			}
+		}
+	}
+}
+
+func markTeleportGap(grid [][]CollisionType, xPos, yPos, width, height, dist int) {
+	minX := max(xPos-dist, 0)
+	maxX := min(xPos+dist, width-1)
+	minY := max(yPos-dist, 0)
+	maxY := min(yPos+dist, height-1)
+
+	if anyWalkable(grid, minX, yPos, xPos-1, yPos) && anyWalkable(grid, xPos+1, yPos, maxX, yPos) {
+		setTeleportOver(grid, minX, yPos, maxX, yPos)
+	}
+
+	if anyWalkable(grid, xPos, minY, xPos, yPos-1) && anyWalkable(grid, xPos, yPos+1, xPos, maxY) {
+		setTeleportOver(grid, xPos, minY, xPos, maxY)
+	}
+
+	diagDist := dist / 2
+	minX = max(xPos-diagDist, 0)
+	maxX = min(xPos+diagDist, width-1)
+	minY = max(yPos-diagDist, 0)
+	maxY = min(yPos+diagDist, height-1)
+
+	if anyWalkableDiagonal(grid, minX, minY, xPos-1, yPos-1, 1) && anyWalkableDiagonal(grid, xPos+1, yPos+1, maxX, maxY, 1) {
+		setTeleportOverDiagonal(grid, minX, minY, maxX, maxY, 1)
+	}
+
+	if anyWalkableDiagonal(grid, minX, maxY, xPos-1, yPos+1, -1) && anyWalkableDiagonal(grid, xPos+1, yPos-1, maxX, minY, -1) {
+		setTeleportOverDiagonal(grid, minX, maxY, maxX, minY, -1)
+	}
+}
+
+func anyWalkable(grid [][]CollisionType, xStart, yStart, xEnd, yEnd int) bool {
+	for x := xStart; x <= xEnd; x++ {
+		for y := yStart; y <= yEnd; y++ {
+			if grid[y][x] == CollisionTypeWalkable {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func anyWalkableDiagonal(grid [][]CollisionType, xStart, yStart, xEnd, yEnd, yStep int) bool {
+	y := yStart
+	minY := min(yStart, yEnd)
+	maxY := max(yStart, yEnd)
+	for x := xStart; x <= xEnd && y >= minY && y <= maxY; {
+		if grid[y][x] == CollisionTypeWalkable {
+			return true
+		}
+		x += 1
+		y += yStep
+	}
+	return false
+}
+
+func setTeleportOver(grid [][]CollisionType, xStart, yStart, xEnd, yEnd int) {
+	for x := xStart; x <= xEnd; x++ {
+		for y := yStart; y <= yEnd; y++ {
+			if grid[y][x] == CollisionTypeNonWalkable {
+				grid[y][x] = CollisionTypeTeleportOver
+			}
+		}
+	}
+}
+
+func setTeleportOverDiagonal(grid [][]CollisionType, xStart, yStart, xEnd, yEnd, yStep int) {
+	y := yStart
+	minY := min(yStart, yEnd)
+	maxY := max(yStart, yEnd)
+	for x := xStart; x <= xEnd && y >= minY && y <= maxY; {
+		if grid[y][x] == CollisionTypeNonWalkable {
+			grid[y][x] = CollisionTypeTeleportOver
+		}
+		x += 1
+		y += yStep
+	}
+}
@@ -0,0 +1,207 @@
+package mapgrid
+
+import (
+	"errors"
+
+	"github.com/hectorgimenez/d2go/pkg/data"
+	"github.com/hectorgimenez/d2go/pkg/data/area"
+	"github.com/hectorgimenez/d2go/pkg/data/npc"
+)
+
+// Level is the grid of an area the path can be extended to
+type Level struct {
+	Area area.ID
+	Grid *Grid
+}
+
+// Scene is the map state a path is computed on: the current area grid, the adjacent areas it can be merged with when
+// the destination is outside of it, and the units blocking the way
+type Scene struct {
+	Area        area.ID
+	Grid        *Grid
+	Adjacent    []Level
+	Objects     []data.Object
+	Monsters    data.Monsters
+	NPCs        data.NPCs
+	CanTeleport bool
+}
+
+var ErrDestinationNotFound = errors.New("destination grid not found")
+
+// PathGrid returns the grid to path on between from and to (world positions) and both positions relative to it. The
+// area grids are never modified, the result is always a copy.
+func (s Scene) PathGrid(from, to data.Position) (*Grid, data.Position, data.Position, error) {
+	// We don't want to modify the original grid
+	grid := s.Grid.Copy()
+
+	// Special handling for Arcane Sanctuary (to allow pathing with platforms)
+	if s.Area == area.ArcaneSanctuary && s.CanTeleport {
+		// Make all non-walkable tiles into low priority tiles for teleport pathing
+		for y := 0; y < grid.Height; y++ {
+			for x := 0; x < grid.Width; x++ {
+				if grid.Get(x, y) == CollisionTypeNonWalkable {
+					grid.Set(x, y, CollisionTypeLowPriority)
+				}
+			}
+		}
+	}
+	// Lut Gholein map is a bit bugged, we should close this fake path to avoid pathing issues.
+	if s.Area == area.LutGholein {
+		if 210 < grid.Width && 13 < grid.Height {
+			grid.Set(210, 13, CollisionTypeNonWalkable)
+		}
+	}
+
+	if !s.Grid.isInside(to) {
+		expandedGrid, err := s.mergedGrid(to)
+		if err != nil {
+			return nil, from, to, err
+		}
+		grid = expandedGrid
+	}
+
+	if !grid.IsWalkable(to) {
+		if walkableTo, found := grid.NearbyWalkable(to); found {
+			to = walkableTo
+		}
+	}
+	from = grid.RelativePosition(from)
+	to = grid.RelativePosition(to)
+
+	// Add objects to the collision grid as obstacles
+	for _, o := range s.Objects {
+		if !grid.IsWalkable(o.Position) {
+			continue
+		}
+		relativePos := grid.RelativePosition(o.Position)
+		if relativePos.X < 0 || relativePos.X >= grid.Width || relativePos.Y < 0 || relativePos.Y >= grid.Height {
+			continue
+		}
+		grid.Set(relativePos.X, relativePos.Y, CollisionTypeObject)
+		for i := -2; i <= 2; i++ {
+			for j := -2; j <= 2; j++ {
+				if i == 0 && j == 0 {
+					continue
+				}
+				ny, nx := relativePos.Y+i, relativePos.X+j
+				if ny < 0 || ny >= grid.Height || nx < 0 || nx >= grid.Width {
+					continue
+				}
+				if grid.Get(nx, ny) == CollisionTypeWalkable {
+					grid.Set(nx, ny, CollisionTypeLowPriority)
+				}
+			}
+		}
+	}
+
+	// Add monsters to the collision grid as obstacles
+	for _, m := range s.Monsters {
+		if !grid.IsWalkable(m.Position) {
+			continue
+		}
+		relativePos := grid.RelativePosition(m.Position)
+		if relativePos.X < 0 || relativePos.X >= grid.Width || relativePos.Y < 0 || relativePos.Y >= grid.Height {
+			continue
+		}
+		grid.Set(relativePos.X, relativePos.Y, CollisionTypeMonster)
+	}
+
+	// set barricade tower as non walkable in act 5
+	if s.Area == area.FrigidHighlands || s.Area == area.FrozenTundra || s.Area == area.ArreatPlateau {
+		for _, n := range s.NPCs {
+			if n.ID != npc.BarricadeTower || len(n.Positions) == 0 {
+				continue
+			}
+			relativePos := grid.RelativePosition(n.Positions[0])
+
+			// Set a 5x5 area around the barricade tower as non-walkable
+			for dy := -2; dy <= 2; dy++ {
+				for dx := -2; dx <= 2; dx++ {
+					towerY := relativePos.Y + dy
+					towerX := relativePos.X + dx
+
+					// Bounds checking to prevent array index out of bounds
+					if towerY >= 0 && towerY < grid.Height && towerX >= 0 && towerX < grid.Width {
+						grid.Set(towerX, towerY, CollisionTypeNonWalkable)
+					}
+				}
+			}
+		}
+	}
+
+	return grid, from, to, nil
+}
+
+func (s Scene) mergedGrid(to data.Position) (*Grid, error) {
+	for _, l := range s.Adjacent {
+		if l.Grid == nil || l.Grid.CollisionGrid == nil {
+			continue
+		}
+		if l.Grid.isInside(to) {
+			return Merge(s.Grid, l.Grid, s.CanTeleport), nil
+		}
+	}
+
+	return nil, ErrDestinationNotFound
+}
+
+// Merge returns a grid covering both origin and destination, destination cells win where they overlap
+func Merge(origin, destination *Grid, canTeleport bool) *Grid {
+	endX1 := origin.OffsetX + origin.Width
+	endY1 := origin.OffsetY + origin.Height
+	endX2 := destination.OffsetX + destination.Width
+	endY2 := destination.OffsetY + destination.Height
+
+	minX := min(origin.OffsetX, destination.OffsetX)
+	minY := min(origin.OffsetY, destination.OffsetY)
+	maxX := max(endX1, endX2)
+	maxY := max(endY1, endY2)
+
+	width := maxX - minX
+	height := maxY - minY
+
+	// Use 2D array for NewGrid compatibility (it converts to flat internally)
+	resultGrid := make([][]CollisionType, height)
+	for i := range resultGrid {
+		resultGrid[i] = make([]CollisionType, width)
+	}
+
+	// Let's copy both grids into the result grid
+	copyGridFlat(resultGrid, origin, origin.OffsetX-minX, origin.OffsetY-minY)
+	copyGridFlat(resultGrid, destination, destination.OffsetX-minX, destination.OffsetY-minY)
+
+	return NewGrid(resultGrid, minX, minY, canTeleport)
+}
+
+// copyGridFlat copies from a flat Grid to a 2D destination array
+func copyGridFlat(dest [][]CollisionType, src *Grid, offsetX, offsetY int) {
+	for y := 0; y < src.Height; y++ {
+		for x := 0; x < src.Width; x++ {
+			dest[offsetY+y][offsetX+x] = src.Get(x, y)
+		}
+	}
+}
+
+// NearbyWalkable returns the closest walkable position up to 3 cells around target (world position)
+func (g *Grid) NearbyWalkable(target data.Position) (data.Position, bool) {
+	// Search in expanding squares around the target position
+	for radius := 1; radius <= 3; radius++ {
+		for x := -radius; x <= radius; x++ {
+			for y := -radius; y <= radius; y++ {
+				if x == 0 && y == 0 {
+					continue
+				}
+				pos := data.Position{X: target.X + x, Y: target.Y + y}
+				if g.IsWalkable(pos) {
+					return pos, true
+				}
+			}
+		}
+	}
+	return data.Position{}, false
+}
+
+// isInside matches game.AreaData.IsInside, the grid borders are excluded
+func (g *Grid) isInside(pos data.Position) bool {
+	return pos.X > g.OffsetX && pos.Y > g.OffsetY && pos.X < g.OffsetX+g.Width && pos.Y < g.OffsetY+g.Height
+}
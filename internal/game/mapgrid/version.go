@@ -0,0 +1,27 @@
+package mapgrid
+
+import (
+	"crypto/sha256"
+	"embed"
+	"encoding/hex"
+	"io/fs"
+)
+
+// sources are the files of the package, every non test one must be listed (TestVersionSources checks it)
+//
+//go:embed grid.go scene.go dump.go level.go
+var sources embed.FS
+
+// Version identifies the grid processing code, it changes with any change of the package sources. Data computed from
+// the grids and kept between runs (like the path cache) is only valid for the version it was computed with.
+var Version = func() string {
+	h := sha256.New()
+	files, _ := fs.Glob(sources, "*.go")
+	for _, name := range files {
+		content, _ := sources.ReadFile(name)
+		h.Write([]byte(name))
+		h.Write(content)
+	}
+
+	return hex.EncodeToString(h.Sum(nil))[:12]
+}()
@@ -0,0 +1,76 @@
+package game
+
+import (
+	"github.com/hectorgimenez/d2go/pkg/data"
+	"github.com/hectorgimenez/d2go/pkg/memory"
+	"github.com/hectorgimenez/koolo/internal/game/replay"
+)
+
+var _ replay.Provider = (*memory.GameReader)(nil)
+
+// The reads below go through the provider instead of the embedded memory.GameReader, so they keep working when
+// replaying a recording.
+
+func (gd *MemoryReader) GetInventory() data.Inventory {
+	return gd.provider.GetInventory()
+}
+
+func (gd *MemoryReader) HoveredData() data.HoverData {
+	return gd.provider.HoveredData()
+}
+
+func (gd *MemoryReader) GetExpChar() uint {
+	return gd.provider.GetExpChar()
+}
+
+func (gd *MemoryReader) InGame() bool {
+	return gd.provider.InGame()
+}
+
+func (gd *MemoryReader) IsIngame() bool {
+	return gd.provider.IsIngame()
+}
+
+func (gd *MemoryReader) IsOnline() bool {
+	return gd.provider.IsOnline()
+}
+
+func (gd *MemoryReader) LegacyGraphics() bool {
+	return gd.provider.LegacyGraphics()
+}
+
+func (gd *MemoryReader) GetPanel(panelPath ...string) data.Panel {
+	return gd.provider.GetPanel(panelPath...)
+}
+
+func (gd *MemoryReader) IsDismissableModalPresent() (bool, string) {
+	return gd.provider.IsDismissableModalPresent()
+}
+
+func (gd *MemoryReader) IsInLobby() bool {
+	return gd.provider.IsInLobby()
+}
+
+func (gd *MemoryReader) IsInCharacterSelectionScreen() bool {
+	return gd.provider.IsInCharacterSelectionScreen()
+}
+
+func (gd *MemoryReader) IsInCharacterCreationScreen() bool {
+	return gd.provider.IsInCharacterCreationScreen()
+}
+
+func (gd *MemoryReader) GetCharacterList() []string {
+	return gd.provider.GetCharacterList()
+}
+
+func (gd *MemoryReader) GetSelectedCharacterName() string {
+	return gd.provider.GetSelectedCharacterName()
+}
+
+func (gd *MemoryReader) LastGameName() string {
+	return gd.provider.LastGameName()
+}
+
+func (gd *MemoryReader) LastGamePass() string {
+	return gd.provider.LastGamePass()
+}
@@ -14,6 +14,8 @@ import (
 	"github.com/hectorgimenez/d2go/pkg/utils"
 	"github.com/hectorgimenez/koolo/internal/config"
 	"github.com/hectorgimenez/koolo/internal/game/map_client"
+	"github.com/hectorgimenez/koolo/internal/game/mapgrid"
+	"github.com/hectorgimenez/koolo/internal/game/replay"
 	"github.com/lxn/win"
 	"golang.org/x/sync/errgroup"
 )
@@ -31,6 +33,9 @@ type MemoryReader struct {
 	cachedMapData  map[area.ID]AreaData
 	mapDataMu      sync.RWMutex // Protects cachedMapData from concurrent access
 	logger         *slog.Logger
+	// provider is where game data is read from: the game memory, or a recording (see NewReplayMemoryReader) in which
+	// case GameReader is nil
+	provider replay.Provider
 }
 
 func NewGameReader(cfg *config.CharacterCfg, supervisorName string, pid uint32, window win.HWND, logger *slog.Logger) (*MemoryReader, error) {
@@ -39,8 +44,10 @@ func NewGameReader(cfg *config.CharacterCfg, supervisorName string, pid uint32,
 		return nil, err
 	}
 
+	mgr := memory.NewGameReader(process)
 	gr := &MemoryReader{
-		GameReader:     memory.NewGameReader(process),
+		GameReader:     mgr,
+		provider:       mgr,
 		HWND:           window,
 		supervisorName: supervisorName,
 		cfg:            cfg,
@@ -64,6 +71,11 @@ func (gd *MemoryReader) ClearMapData() {
 }
 
 func (gd *MemoryReader) FetchMapData() error {
+	if gd.replaying() {
+		// Map data was recorded along with the game data
+		return nil
+	}
+
 	// Clear old map data before fetching new data to allow GC to reclaim memory
 	gd.mapDataMu.Lock()
 	gd.cachedMapData = nil
@@ -85,39 +97,9 @@ func (gd *MemoryReader) FetchMapData() error {
 	g := errgroup.Group{}
 	for _, lvl := range mapData {
 		g.Go(func() error {
-			cg := lvl.CollisionGrid()
-			resultGrid := make([][]CollisionType, lvl.Size.Height)
-			for i := range resultGrid {
-				resultGrid[i] = make([]CollisionType, lvl.Size.Width)
-			}
-
-			for y := 0; y < lvl.Size.Height; y++ {
-				for x := 0; x < lvl.Size.Width; x++ {
-					if cg[y][x] {
-						resultGrid[y][x] = CollisionTypeWalkable
-					} else {
-						resultGrid[y][x] = CollisionTypeNonWalkable
-					}
-				}
-			}
-
 			npcs, exits, objects, rooms := lvl.NPCsExitsAndObjects()
 			areaID := area.ID(lvl.ID)
-			// Process teleportable tiles on 2D array before flattening
-			if !areaID.IsTown() {
-				gd.TeleportPostProcess(&resultGrid, lvl.Size.Width, lvl.Size.Height)
-			}
-			grid := NewGrid(resultGrid, lvl.Offset.X, lvl.Offset.Y, false)
-
-			// Apply collision thickening to all non-town areas
-			if !areaID.IsTown() {
-				thickenCollisions(grid)
-				// Re-open exits after thickening
-				drillExits(grid, extractExitPositions(exits))
-			} else {
-				// Apply thickening to towns but don't drill exits
-				thickenCollisions(grid)
-			}
+			grid := mapgrid.NewLevelGrid(lvl.CollisionGrid(), lvl.Offset.X, lvl.Offset.Y, areaID, extractExitPositions(exits))
 
 			mu.Lock()
 			areas[areaID] = AreaData{
@@ -145,94 +127,6 @@ func (gd *MemoryReader) FetchMapData() error {
 	return nil
 }
 
-func (gd *MemoryReader) TeleportPostProcess(grid *[][]CollisionType, width int, height int) {
-	for y := 1; y < height-1; y++ {
-		for x := 1; x < width-1; x++ {
-			gridValue := (*grid)[y][x]
-			if gridValue == CollisionTypeNonWalkable || gridValue == CollisionTypeTeleportOver {
-				gd.CheckAndAdjustForTeleport(grid, x, y, width, height, 10)
-			}
-		}
-	}
-}
-
-func (gd *MemoryReader) CheckAndAdjustForTeleport(grid *[][]CollisionType, xPos int, yPos int, width int, height int, dist int) {
-	minX := max(xPos-dist, 0)
-	maxX := min(xPos+dist, width-1)
-	minY := max(yPos-dist, 0)
-	maxY := min(yPos+dist, height-1)
-
-	if gd.CheckForWalkable(grid, minX, yPos, xPos-1, yPos) && gd.CheckForWalkable(grid, xPos+1, yPos, maxX, yPos) {
-		gd.SetTeleportable(grid, minX, yPos, maxX, yPos)
-	}
-
-	if gd.CheckForWalkable(grid, xPos, minY, xPos, yPos-1) && gd.CheckForWalkable(grid, xPos, yPos+1, xPos, maxY) {
-		gd.SetTeleportable(grid, xPos, minY, xPos, maxY)
-	}
-
-	diagDist := dist / 2
-	minX = max(xPos-diagDist, 0)
-	maxX = min(xPos+diagDist, width-1)
-	minY = max(yPos-diagDist, 0)
-	maxY = min(yPos+diagDist, height-1)
-
-	if gd.CheckForWalkableDiagonal(grid, minX, minY, xPos-1, yPos-1, 1) && gd.CheckForWalkableDiagonal(grid, xPos+1, yPos+1, maxX, maxY, 1) {
-		gd.SetTeleportableDiagonal(grid, minX, minY, maxX, maxY, 1, 1)
-	}
-
-	if gd.CheckForWalkableDiagonal(grid, minX, maxY, xPos-1, yPos+1, -1) && gd.CheckForWalkableDiagonal(grid, xPos+1, yPos-1, maxX, minY, -1) {
-		gd.SetTeleportableDiagonal(grid, minX, maxY, maxX, minY, 1, -1)
-	}
-}
-
-func (gd *MemoryReader) CheckForWalkable(grid *[][]CollisionType, xStart int, yStart int, xEnd int, yEnd int) bool {
-	for x := xStart; x <= xEnd; x++ {
-		for y := yStart; y <= yEnd; y++ {
-			if (*grid)[y][x] == CollisionTypeWalkable {
-				return true
-			}
-		}
-	}
-	return false
-}
-
-func (gd *MemoryReader) CheckForWalkableDiagonal(grid *[][]CollisionType, xStart int, yStart int, xEnd int, yEnd int, yStep int) bool {
-	y := yStart
-	minY := min(yStart, yEnd)
-	maxY := max(yStart, yEnd)
-	for x := xStart; x <= xEnd && y >= minY && y <= maxY; {
-		if (*grid)[y][x] == CollisionTypeWalkable {
-			return true
-		}
-		x += 1
-		y += yStep
-	}
-	return false
-}
-
-func (gd *MemoryReader) SetTeleportable(grid *[][]CollisionType, xStart int, yStart int, xEnd int, yEnd int) {
-	for x := xStart; x <= xEnd; x++ {
-		for y := yStart; y <= yEnd; y++ {
-			if (*grid)[y][x] == CollisionTypeNonWalkable {
-				(*grid)[y][x] = CollisionTypeTeleportOver
-			}
-		}
-	}
-}
-
-func (gd *MemoryReader) SetTeleportableDiagonal(grid *[][]CollisionType, xStart int, yStart int, xEnd int, yEnd int, xStep int, yStep int) {
-	y := yStart
-	minY := min(yStart, yEnd)
-	maxY := max(yStart, yEnd)
-	for x := xStart; x <= xEnd && y >= minY && y <= maxY; {
-		if (*grid)[y][x] == CollisionTypeNonWalkable {
-			(*grid)[y][x] = CollisionTypeTeleportOver
-		}
-		x += 1
-		y += yStep
-	}
-}
-
 func (gd *MemoryReader) updateWindowPositionData() {
 	pos := win.WINDOWPLACEMENT{}
 	point := win.POINT{}
@@ -246,7 +140,7 @@ func (gd *MemoryReader) updateWindowPositionData() {
 }
 
 func (gd *MemoryReader) GetData() Data {
-	d := gd.GameReader.GetData()
+	d := gd.provider.GetData()
 
 	// Take a snapshot of cachedMapData under lock to avoid race with ClearMapData
 	gd.mapDataMu.RLock()
@@ -254,7 +148,8 @@ func (gd *MemoryReader) GetData() Data {
 	gd.mapDataMu.RUnlock()
 
 	currentArea, ok := cachedData[d.PlayerUnit.Area]
-	if ok {
+	// Recorded game data already has the map data merged
+	if ok && !gd.replaying() {
 		// This hacky thing is because sometimes if the objects are far away we can not fetch them, basically WP.
 		memObjects := gd.Objects(d.PlayerUnit.Position, d.HoverData)
 		for _, clientObject := range currentArea.Objects {
@@ -288,10 +183,24 @@ func (gd *MemoryReader) GetData() Data {
 		AreaData:     currentArea,
 		Data:         d,
 		CharacterCfg: cfgCopy,
-		ExpChar:      gd.GetExpChar(),
+		ExpChar:      gd.provider.GetExpChar(),
 	}
 }
 
+// replaying reports whether the game data comes from a recording instead of the game memory
+func (gd *MemoryReader) replaying() bool {
+	return gd.GameReader == nil
+}
+
+// GetMercList reads the merc hire list from memory, it's always empty when replaying a recording
+func (gd *MemoryReader) GetMercList() []memory.MercOption {
+	if gd.replaying() {
+		return nil
+	}
+
+	return gd.GameReader.GetMercList()
+}
+
 func (gd *MemoryReader) getMapSeed(playerUnit uintptr) (uint, error) {
 	actPtr := uintptr(gd.Process.ReadUInt(playerUnit+0x20, memory.Uint64))
 	//actMiscPtr := uintptr(gd.Process.ReadUInt(actPtr+0x78, memory.Uint64))
@@ -26,6 +26,9 @@ const pointerReleaseDelay = 150 * time.Millisecond
 // cursor position to (x, y). x and y are relative to the game window: the
 // top-left corner of the client area is (0, 0).
 func (hid *HID) MovePointer(x, y int) {
+	if hid.skip("move %d,%d", x, y) {
+		return
+	}
 	hid.gr.updateWindowPositionData()
 	absX := hid.gr.WindowLeftX + x
 	absY := hid.gr.WindowTopY + y
@@ -106,6 +109,9 @@ func (hid *HID) MovePointer(x, y int) {
 
 // Click just does a single mouse click at current pointer position
 func (hid *HID) Click(btn MouseButton, x, y int) {
+	if hid.skip("click %d %d,%d", btn, x, y) {
+		return
+	}
 	hid.MovePointer(x, y)
 	x = hid.gr.WindowLeftX + x
 	y = hid.gr.WindowTopY + y
@@ -125,6 +131,9 @@ func (hid *HID) Click(btn MouseButton, x, y int) {
 }
 
 func (hid *HID) ClickWithModifier(btn MouseButton, x, y int, modifier ModifierKey) {
+	if hid.skip("click %d %d,%d modifier %d", btn, x, y, modifier) {
+		return
+	}
 	hid.gi.OverrideGetKeyState(byte(modifier))
 	hid.Click(btn, x, y)
 	hid.gi.RestoreGetKeyState()
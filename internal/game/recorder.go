@@ -0,0 +1,131 @@
+package game
+
+import (
+	"fmt"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/hectorgimenez/d2go/pkg/data/area"
+	"github.com/hectorgimenez/koolo/internal/game/mapgrid"
+	"github.com/hectorgimenez/koolo/internal/game/replay"
+)
+
+// DefaultRecordInterval is the minimum time between two recorded snapshots
+const DefaultRecordInterval = 500 * time.Millisecond
+
+// GameRecorder writes game data snapshots and the map data of the visited areas to a replay file
+type GameRecorder struct {
+	w        *replay.Writer
+	path     string
+	interval time.Duration
+	mu       sync.Mutex
+	last     time.Time
+	areas    map[area.ID]bool
+	closed   bool
+}
+
+// NewGameRecorder creates a recording for the current game of gr inside dir
+func NewGameRecorder(dir, supervisor string, gr *MemoryReader, interval time.Duration) (*GameRecorder, error) {
+	now := time.Now()
+	path := filepath.Join(dir, fmt.Sprintf("%s_%s%s", supervisor, now.Format("2006-01-02_15-04-05"), replay.FileExtension))
+
+	h := replay.Header{
+		Supervisor:    supervisor,
+		StartedAt:     now,
+		MapSeed:       gr.MapSeed(),
+		GameAreaSizeX: gr.GameAreaSizeX,
+		GameAreaSizeY: gr.GameAreaSizeY,
+	}
+	if gr.cfg != nil {
+		h.Difficulty = string(gr.cfg.Game.Difficulty)
+	}
+
+	w, err := replay.Create(path, h)
+	if err != nil {
+		return nil, err
+	}
+	if interval <= 0 {
+		interval = DefaultRecordInterval
+	}
+
+	return &GameRecorder{w: w, path: path, interval: interval, areas: make(map[area.ID]bool)}, nil
+}
+
+// Path returns the recording file path
+func (r *GameRecorder) Path() string {
+	return r.path
+}
+
+// Record appends a snapshot unless the previous one is too recent, map data is written the first time an area is seen.
+// Snapshots taken once the recording is closed are dropped.
+func (r *GameRecorder) Record(d Data) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.closed || time.Since(r.last) < r.interval {
+		return nil
+	}
+	r.last = time.Now()
+
+	for id, ad := range d.Areas {
+		if r.areas[id] {
+			continue
+		}
+		r.areas[id] = true
+		if err := r.w.WriteArea(ad.toReplay()); err != nil {
+			return err
+		}
+	}
+
+	return r.w.WriteFrame(replay.Frame{
+		Time:                r.last,
+		Data:                d.Data,
+		IsLevelingCharacter: d.IsLevelingCharacter,
+		ExpChar:             d.ExpChar,
+	})
+}
+
+// Close finishes the recording, it must be called for the file to be complete
+func (r *GameRecorder) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.closed = true
+
+	return r.w.Close()
+}
+
+func (ad AreaData) toReplay() replay.Area {
+	a := replay.Area{
+		ID:             ad.Area,
+		Name:           ad.Name,
+		NPCs:           ad.NPCs,
+		AdjacentLevels: ad.AdjacentLevels,
+		Objects:        ad.Objects,
+		Rooms:          ad.Rooms,
+	}
+	if ad.Grid != nil {
+		a.OffsetX, a.OffsetY, a.Width, a.Height = ad.OffsetX, ad.OffsetY, ad.Width, ad.Height
+		a.Collision = ad.EncodeCollision()
+	}
+
+	return a
+}
+
+func areaDataFromReplay(a replay.Area) (AreaData, error) {
+	ad := AreaData{
+		Area:           a.ID,
+		Name:           a.Name,
+		NPCs:           a.NPCs,
+		AdjacentLevels: a.AdjacentLevels,
+		Objects:        a.Objects,
+		Rooms:          a.Rooms,
+	}
+	grid, err := mapgrid.FromReplay(a)
+	if err != nil {
+		return ad, err
+	}
+	ad.Grid = grid
+
+	return ad, nil
+}
@@ -0,0 +1,30 @@
+package replay
+
+import (
+	"fmt"
+	"sync"
+)
+
+// InputLog keeps the inputs the bot would have sent to the game while replaying a recording, so tests can check what
+// it decided to do. It is safe for concurrent use.
+type InputLog struct {
+	mu     sync.Mutex
+	inputs []string
+}
+
+// Record appends an input, described with a fmt format
+func (l *InputLog) Record(format string, args ...any) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.inputs = append(l.inputs, fmt.Sprintf(format, args...))
+}
+
+// Inputs returns the recorded inputs, in order
+func (l *InputLog) Inputs() []string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return append([]string(nil), l.inputs...)
+}
+
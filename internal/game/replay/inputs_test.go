@@ -0,0 +1,21 @@
+package replay
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestInputLog(t *testing.T) {
+	var l InputLog
+	l.Record("key %d", 49)
+	l.Record("click %d,%d", 10, 20)
+
+	inputs := l.Inputs()
+	if !slices.Equal(inputs, []string{"key 49", "click 10,20"}) {
+		t.Fatalf("inputs = %q", inputs)
+	}
+	inputs[0] = "changed"
+	if l.Inputs()[0] != "key 49" {
+		t.Error("Inputs returned the internal slice")
+	}
+}
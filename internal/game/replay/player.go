@@ -0,0 +1,181 @@
+package replay
+
+import (
+	"sync"
+
+	"github.com/hectorgimenez/d2go/pkg/data"
+)
+
+// Provider is the game state source the game package reads from: the game memory (d2go memory.GameReader) while
+// playing, or a Player when replaying a recording.
+type Provider interface {
+	GetData() data.Data
+	GetInventory() data.Inventory
+	HoveredData() data.HoverData
+	GetExpChar() uint
+	InGame() bool
+	IsIngame() bool
+	IsOnline() bool
+	LegacyGraphics() bool
+	GetPanel(panelPath ...string) data.Panel
+	IsDismissableModalPresent() (bool, string)
+	IsInLobby() bool
+	IsInCharacterSelectionScreen() bool
+	IsInCharacterCreationScreen() bool
+	GetCharacterList() []string
+	GetSelectedCharacterName() string
+	LastGameName() string
+	LastGamePass() string
+}
+
+// Player serves the frames of a recording as a Provider. Recordings only hold in game snapshots, so the player is
+// always in game and every menu or screen read reports nothing is shown.
+type Player struct {
+	mu      sync.Mutex
+	session *Session
+	frame   int
+	// AutoAdvance moves to the next frame after every GetData call, the last frame is repeated once reached
+	AutoAdvance bool
+}
+
+// NewPlayer positions a player on the first frame of the session
+func NewPlayer(s *Session) *Player {
+	return &Player{session: s}
+}
+
+// Session returns the underlying recording
+func (p *Player) Session() *Session {
+	return p.session
+}
+
+// Len returns the number of recorded frames
+func (p *Player) Len() int {
+	return len(p.session.Frames)
+}
+
+// Frame returns the index of the current frame
+func (p *Player) Frame() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.frame
+}
+
+// Next moves to the next frame, returns false when the current frame is the last one
+func (p *Player) Next() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.frame+1 >= len(p.session.Frames) {
+		return false
+	}
+	p.frame++
+
+	return true
+}
+
+// Seek moves to the given frame, returns false if it doesn't exist
+func (p *Player) Seek(frame int) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if frame < 0 || frame >= len(p.session.Frames) {
+		return false
+	}
+	p.frame = frame
+
+	return true
+}
+
+// Current returns the current frame, the zero Frame for an empty recording
+func (p *Player) Current() Frame {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.current()
+}
+
+func (p *Player) current() Frame {
+	if len(p.session.Frames) == 0 {
+		return Frame{}
+	}
+
+	return p.session.Frames[p.frame]
+}
+
+// GetData returns the game data of the current frame, then moves to the next one when AutoAdvance is set
+func (p *Player) GetData() data.Data {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	f := p.current()
+	if p.AutoAdvance && p.frame+1 < len(p.session.Frames) {
+		p.frame++
+	}
+
+	return f.Data
+}
+
+func (p *Player) GetInventory() data.Inventory {
+	return p.Current().Data.Inventory
+}
+
+func (p *Player) HoveredData() data.HoverData {
+	return p.Current().Data.HoverData
+}
+
+func (p *Player) GetExpChar() uint {
+	return p.Current().ExpChar
+}
+
+func (p *Player) InGame() bool {
+	return p.Len() > 0
+}
+
+func (p *Player) IsIngame() bool {
+	return p.InGame()
+}
+
+func (p *Player) IsOnline() bool {
+	return false
+}
+
+func (p *Player) LegacyGraphics() bool {
+	return false
+}
+
+func (p *Player) GetPanel(...string) data.Panel {
+	return data.Panel{}
+}
+
+func (p *Player) IsDismissableModalPresent() (bool, string) {
+	return false, ""
+}
+
+func (p *Player) IsInLobby() bool {
+	return false
+}
+
+func (p *Player) IsInCharacterSelectionScreen() bool {
+	return false
+}
+
+func (p *Player) IsInCharacterCreationScreen() bool {
+	return false
+}
+
+func (p *Player) GetCharacterList() []string {
+	return nil
+}
+
+func (p *Player) GetSelectedCharacterName() string {
+	return ""
+}
+
+func (p *Player) LastGameName() string {
+	return ""
+}
+
+func (p *Player) LastGamePass() string {
+	return ""
+}
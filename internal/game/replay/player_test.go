@@ -0,0 +1,69 @@
+package replay
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/hectorgimenez/d2go/pkg/data"
+	"github.com/hectorgimenez/d2go/pkg/data/area"
+	"github.com/hectorgimenez/d2go/pkg/data/item"
+)
+
+func TestPlayer(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "game"+FileExtension)
+	w, err := Create(path, Header{Supervisor: "sorc"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 3; i++ {
+		var d data.Data
+		d.PlayerUnit.Area = area.StonyField
+		d.PlayerUnit.Position = data.Position{X: i, Y: i}
+		d.HoverData = data.HoverData{IsHovered: true, UnitID: data.UnitID(i)}
+		d.Inventory.AllItems = []data.Item{{Name: item.Name("Ring"), UnitID: data.UnitID(i)}}
+		if err = w.WriteFrame(Frame{Time: time.Now(), Data: d, ExpChar: 3}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err = w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	s, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p := NewPlayer(s)
+	p.AutoAdvance = true
+	var provider Provider = p
+	if !provider.InGame() || provider.IsInLobby() || provider.GetExpChar() != 3 {
+		t.Error("a recording should be replayed as an in game expansion character")
+	}
+	for i := 0; i < 4; i++ {
+		if inv := provider.GetInventory(); inv.AllItems[0].UnitID != data.UnitID(min(i, 2)) {
+			t.Errorf("step %d: inventory of frame %d, expected frame %d", i, inv.AllItems[0].UnitID, min(i, 2))
+		}
+		// The last frame is repeated once reached
+		if d := provider.GetData(); d.PlayerUnit.Position.X != min(i, 2) {
+			t.Errorf("step %d: player at %v, expected frame %d", i, d.PlayerUnit.Position, min(i, 2))
+		}
+	}
+
+	if !p.Seek(1) || p.Seek(3) {
+		t.Fatal("only recorded frames can be seeked")
+	}
+	if h := provider.HoveredData(); h.UnitID != 1 {
+		t.Errorf("expected the hover data of frame 1, got %+v", h)
+	}
+}
+
+func TestEmptyPlayer(t *testing.T) {
+	p := NewPlayer(&Session{})
+	if p.InGame() || p.Next() {
+		t.Error("an empty recording has no game to replay")
+	}
+	if d := p.GetData(); d.PlayerUnit.Area != 0 {
+		t.Errorf("expected empty game data, got %+v", d.PlayerUnit)
+	}
+}
@@ -0,0 +1,244 @@
+// Package replay stores game state snapshots captured during a real game, so the bot logic reading game data can be
+// exercised offline. It only depends on d2go data types, the conversion from and to game.Data lives in the game package.
+package replay
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/hectorgimenez/d2go/pkg/data"
+	"github.com/hectorgimenez/d2go/pkg/data/area"
+)
+
+// FormatVersion is increased on breaking changes of the file format
+const FormatVersion = 1
+
+// FileExtension is used for recordings: gzip compressed JSON lines
+const FileExtension = ".jsonl.gz"
+
+const (
+	entryHeader = "header"
+	entryArea   = "area"
+	entryFrame  = "frame"
+)
+
+// Header describes the recorded game
+type Header struct {
+	Version       int       `json:"version"`
+	Supervisor    string    `json:"supervisor"`
+	StartedAt     time.Time `json:"startedAt"`
+	MapSeed       uint      `json:"mapSeed"`
+	Difficulty    string    `json:"difficulty"`
+	GameAreaSizeX int       `json:"gameAreaSizeX"`
+	GameAreaSizeY int       `json:"gameAreaSizeY"`
+}
+
+// Area is the map data of a level, as processed by the bot (collision grid included)
+type Area struct {
+	ID             area.ID       `json:"id"`
+	Name           string        `json:"name"`
+	OffsetX        int           `json:"offsetX"`
+	OffsetY        int           `json:"offsetY"`
+	Width          int           `json:"width"`
+	Height         int           `json:"height"`
+	Collision      []byte        `json:"collision"` // Run-length encoded, see EncodeGrid
+	NPCs           data.NPCs     `json:"npcs"`
+	AdjacentLevels []data.Level  `json:"adjacentLevels"`
+	Objects        []data.Object `json:"objects"`
+	Rooms          []data.Room   `json:"rooms"`
+}
+
+// Frame is a single game data snapshot
+type Frame struct {
+	Time                time.Time `json:"time"`
+	Data                data.Data `json:"data"`
+	IsLevelingCharacter bool      `json:"isLevelingCharacter,omitempty"`
+	ExpChar             uint      `json:"expChar,omitempty"`
+}
+
+// Session is a fully loaded recording
+type Session struct {
+	Header Header
+	Areas  map[area.ID]Area
+	Frames []Frame
+}
+
+type entry struct {
+	Type   string  `json:"type"`
+	Header *Header `json:"header,omitempty"`
+	Area   *Area   `json:"area,omitempty"`
+	Frame  *Frame  `json:"frame,omitempty"`
+}
+
+// Writer appends entries to a recording, it is safe for concurrent use
+type Writer struct {
+	mu   sync.Mutex
+	file *os.File
+	gz   *gzip.Writer
+	enc  *json.Encoder
+}
+
+// Create creates the recording file (and its directory) and writes the header
+func Create(path string, h Header) (*Writer, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("error creating recording directory: %w", err)
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, fmt.Errorf("error creating recording: %w", err)
+	}
+
+	gz := gzip.NewWriter(f)
+	w := &Writer{file: f, gz: gz, enc: json.NewEncoder(gz)}
+	h.Version = FormatVersion
+	if err = w.write(entry{Type: entryHeader, Header: &h}); err != nil {
+		_ = f.Close()
+		return nil, err
+	}
+
+	return w, nil
+}
+
+// WriteArea stores the map data of a level, areas should be written once per game
+func (w *Writer) WriteArea(a Area) error {
+	return w.write(entry{Type: entryArea, Area: &a})
+}
+
+// WriteFrame appends a game data snapshot
+func (w *Writer) WriteFrame(f Frame) error {
+	return w.write(entry{Type: entryFrame, Frame: &f})
+}
+
+// Close flushes the compressed stream and closes the file
+func (w *Writer) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.file == nil {
+		return nil
+	}
+	err := errors.Join(w.gz.Close(), w.file.Close())
+	w.file = nil
+
+	return err
+}
+
+func (w *Writer) write(e entry) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.file == nil {
+		return errors.New("recording is closed")
+	}
+	if err := w.enc.Encode(e); err != nil {
+		return fmt.Errorf("error writing recording %s entry: %w", e.Type, err)
+	}
+
+	return nil
+}
+
+// Open reads a recording. Files cut short (e.g. the client crashed before the recording was closed) are loaded up
+// to the last complete entry.
+func Open(path string) (*Session, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return Read(f)
+}
+
+// Read reads a recording from a gzip compressed stream
+func Read(r io.Reader) (*Session, error) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("invalid recording: %w", err)
+	}
+	defer gz.Close()
+
+	s := &Session{Areas: make(map[area.ID]Area)}
+	sc := bufio.NewScanner(gz)
+	sc.Buffer(make([]byte, 0, 1024*1024), 64*1024*1024)
+	headerFound := false
+	var entryErr error
+	for sc.Scan() {
+		if entryErr != nil {
+			return nil, entryErr
+		}
+		var e entry
+		if err = json.Unmarshal(sc.Bytes(), &e); err != nil {
+			// Only acceptable for the last line of a truncated file, checked once the stream ends
+			entryErr = fmt.Errorf("invalid recording entry: %w", err)
+			continue
+		}
+
+		switch {
+		case e.Type == entryHeader && e.Header != nil:
+			if e.Header.Version > FormatVersion {
+				return nil, fmt.Errorf("recording version %d is not supported, max version is %d", e.Header.Version, FormatVersion)
+			}
+			s.Header = *e.Header
+			headerFound = true
+		case e.Type == entryArea && e.Area != nil:
+			s.Areas[e.Area.ID] = *e.Area
+		case e.Type == entryFrame && e.Frame != nil:
+			s.Frames = append(s.Frames, *e.Frame)
+		}
+	}
+	err = sc.Err()
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return nil, fmt.Errorf("error reading recording: %w", err)
+	}
+	if entryErr != nil && err == nil {
+		return nil, entryErr
+	}
+	if !headerFound {
+		return nil, errors.New("invalid recording: header not found")
+	}
+
+	return s, nil
+}
+
+// EncodeGrid run-length encodes a flat collision grid as (count, value) pairs, counts are at most 255
+func EncodeGrid(grid []byte) []byte {
+	out := make([]byte, 0, len(grid)/8)
+	for i := 0; i < len(grid); {
+		v := grid[i]
+		n := 1
+		for i+n < len(grid) && grid[i+n] == v && n < 255 {
+			n++
+		}
+		out = append(out, byte(n), v)
+		i += n
+	}
+
+	return out
+}
+
+// DecodeGrid expands a grid encoded by EncodeGrid, size is the expected number of cells
+func DecodeGrid(encoded []byte, size int) ([]byte, error) {
+	if len(encoded)%2 != 0 {
+		return nil, errors.New("invalid collision grid encoding")
+	}
+
+	grid := make([]byte, 0, size)
+	for i := 0; i < len(encoded); i += 2 {
+		for n := 0; n < int(encoded[i]); n++ {
+			grid = append(grid, encoded[i+1])
+		}
+	}
+	if len(grid) != size {
+		return nil, fmt.Errorf("collision grid has %d cells, expected %d", len(grid), size)
+	}
+
+	return grid, nil
+}
@@ -0,0 +1,115 @@
+package replay
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/hectorgimenez/d2go/pkg/data"
+	"github.com/hectorgimenez/d2go/pkg/data/area"
+	"github.com/hectorgimenez/d2go/pkg/data/stat"
+)
+
+func TestGridEncoding(t *testing.T) {
+	grid := make([]byte, 1000)
+	for i := range grid {
+		if i%300 < 120 {
+			grid[i] = 1
+		}
+	}
+
+	encoded := EncodeGrid(grid)
+	if len(encoded) >= len(grid)/10 {
+		t.Errorf("expected a compact encoding, got %d bytes for %d cells", len(encoded), len(grid))
+	}
+
+	decoded, err := DecodeGrid(encoded, len(grid))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(decoded, grid) {
+		t.Error("decoded grid differs from the original one")
+	}
+
+	if _, err = DecodeGrid(encoded, len(grid)+1); err == nil {
+		t.Error("expected an error for a wrong grid size")
+	}
+}
+
+func TestRecordingRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "game"+FileExtension)
+	w, err := Create(path, Header{Supervisor: "sorc", MapSeed: 42, GameAreaSizeX: 1280, GameAreaSizeY: 720})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	grid := []byte{0, 0, 1, 1, 1, 4}
+	if err = w.WriteArea(Area{ID: area.StonyField, Width: 3, Height: 2, Collision: EncodeGrid(grid)}); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 3; i++ {
+		var d data.Data
+		d.PlayerUnit.Area = area.StonyField
+		d.PlayerUnit.Position = data.Position{X: i, Y: i}
+		d.PlayerUnit.Stats = stat.Stats{{ID: stat.Life, Value: 100 - i}}
+		if err = w.WriteFrame(Frame{Time: time.Now(), Data: d}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err = w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	s, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Header.Supervisor != "sorc" || s.Header.MapSeed != 42 || s.Header.Version != FormatVersion {
+		t.Errorf("unexpected header %+v", s.Header)
+	}
+	if len(s.Frames) != 3 {
+		t.Fatalf("expected 3 frames, got %d", len(s.Frames))
+	}
+	if life, _ := s.Frames[2].Data.PlayerUnit.FindStat(stat.Life, 0); life.Value != 98 {
+		t.Errorf("expected life 98 on the last frame, got %d", life.Value)
+	}
+	a, found := s.Areas[area.StonyField]
+	if !found {
+		t.Fatal("area not found")
+	}
+	if decoded, _ := DecodeGrid(a.Collision, a.Width*a.Height); !bytes.Equal(decoded, grid) {
+		t.Error("area grid differs from the recorded one")
+	}
+}
+
+func TestTruncatedRecording(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "game"+FileExtension)
+	w, err := Create(path, Header{Supervisor: "sorc"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 50; i++ {
+		var d data.Data
+		d.PlayerUnit.Position = data.Position{X: i, Y: i}
+		if err = w.WriteFrame(Frame{Data: d}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err = w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := Read(bytes.NewReader(content[:len(content)-20]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(s.Frames) == 0 || len(s.Frames) > 50 {
+		t.Errorf("expected the complete frames of the truncated recording, got %d", len(s.Frames))
+	}
+}
@@ -0,0 +1,56 @@
+package game
+
+import (
+	"log/slog"
+
+	"github.com/hectorgimenez/d2go/pkg/data/area"
+	"github.com/hectorgimenez/koolo/internal/config"
+	"github.com/hectorgimenez/koolo/internal/game/replay"
+)
+
+// ReplayReader serves the game data of a recording frame by frame, see GameRecorder
+type ReplayReader struct {
+	*replay.Player
+	areas map[area.ID]AreaData
+}
+
+// NewReplayReader decodes the map data of the session and positions the reader on the first frame
+func NewReplayReader(s *replay.Session) (*ReplayReader, error) {
+	areas := make(map[area.ID]AreaData, len(s.Areas))
+	for id, a := range s.Areas {
+		ad, err := areaDataFromReplay(a)
+		if err != nil {
+			return nil, err
+		}
+		areas[id] = ad
+	}
+
+	return &ReplayReader{Player: replay.NewPlayer(s), areas: areas}, nil
+}
+
+// OpenReplay loads a recording file into a ReplayReader
+func OpenReplay(path string) (*ReplayReader, error) {
+	s, err := replay.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewReplayReader(s)
+}
+
+// NewReplayMemoryReader returns a MemoryReader serving the data of a recording instead of the game memory. Reads
+// only available in memory (screenshots, merc list, map data fetching) are skipped, see replay.Player for the rest.
+func NewReplayMemoryReader(cfg *config.CharacterCfg, supervisorName string, r *ReplayReader, logger *slog.Logger) *MemoryReader {
+	h := r.Session().Header
+
+	return &MemoryReader{
+		cfg:            cfg,
+		provider:       r.Player,
+		mapSeed:        h.MapSeed,
+		GameAreaSizeX:  h.GameAreaSizeX,
+		GameAreaSizeY:  h.GameAreaSizeY,
+		supervisorName: supervisorName,
+		cachedMapData:  r.areas,
+		logger:         logger,
+	}
+}
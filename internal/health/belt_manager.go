@@ -8,6 +8,7 @@ import (
 	"github.com/hectorgimenez/d2go/pkg/data"
 	"github.com/hectorgimenez/koolo/internal/event"
 	"github.com/hectorgimenez/koolo/internal/game"
+	"github.com/hectorgimenez/koolo/internal/health/policy"
 )
 
 type BeltManager struct {
@@ -29,22 +30,26 @@ func NewBeltManager(data *game.Data, hid *game.HID, logger *slog.Logger, supervi
 func (bm BeltManager) DrinkPotion(potionType data.PotionType, merc bool) bool {
 	p, found := bm.data.Inventory.Belt.GetFirstPotion(potionType)
 	if found {
-		binding := bm.data.KeyBindings.UseBelt[p.X]
-		if merc {
-			bm.hid.PressKeyWithModifier(binding.Key1[0], game.ShiftKey)
-			bm.logger.Debug(fmt.Sprintf("Using %s potion on Mercenary [Column: %d]. HP: %d", potionType, p.X+1, bm.data.MercHPPercent()))
-			event.Send(event.UsedPotion(event.Text(bm.supervisor, ""), potionType, true))
-			return true
-		}
-		bm.hid.PressKeyBinding(binding)
-		bm.logger.Debug(fmt.Sprintf("Using %s potion [Column: %d]. HP: %d MP: %d", potionType, p.X+1, bm.data.PlayerUnit.HPPercent(), bm.data.PlayerUnit.MPPercent()))
-		event.Send(event.UsedPotion(event.Text(bm.supervisor, ""), potionType, false))
+		bm.Drink(policy.Drink{Potion: potionType, Merc: merc, Column: p.X, Binding: bm.data.KeyBindings.UseBelt[p.X]})
 		return true
 	}
 
 	return false
 }
 
+// Drink presses the belt key of a potion picked by the health policy
+func (bm BeltManager) Drink(d policy.Drink) {
+	if d.Merc {
+		bm.hid.PressKeyWithModifier(d.Binding.Key1[0], game.ShiftKey)
+		bm.logger.Debug(fmt.Sprintf("Using %s potion on Mercenary [Column: %d]. HP: %d", d.Potion, d.Column+1, bm.data.MercHPPercent()))
+		event.Send(event.UsedPotion(event.Text(bm.supervisor, ""), d.Potion, true))
+		return
+	}
+	bm.hid.PressKeyBinding(d.Binding)
+	bm.logger.Debug(fmt.Sprintf("Using %s potion [Column: %d]. HP: %d MP: %d", d.Potion, d.Column+1, bm.data.PlayerUnit.HPPercent(), bm.data.PlayerUnit.MPPercent()))
+	event.Send(event.UsedPotion(event.Text(bm.supervisor, ""), d.Potion, false))
+}
+
 // ShouldBuyPotions will return true if more than 25% of belt is empty (ignoring rejuv)
 func (bm BeltManager) ShouldBuyPotions() bool {
 	targetHealingAmount := bm.data.CharacterCfg.Inventory.BeltColumns.Total(data.HealingPotion) * bm.data.Inventory.Belt.Rows()
@@ -3,11 +3,14 @@ package health
 import (
 	"log/slog"
 	"time"
+
+	"github.com/hectorgimenez/koolo/internal/event"
 )
 
 // PingMonitor tracks sustained high ping conditions
 // Monitors ping over time and triggers actions when thresholds are exceeded
 type PingMonitor struct {
+	supervisor         string
 	HighPingStart      time.Time
 	HighPingThreshold  int           // Ping threshold in ms (typically 500-1000ms)
 	HighPingSustained  time.Duration // How long high ping must persist before action (typically 10-30 seconds)
@@ -20,8 +23,9 @@ type PingMonitor struct {
 
 // NewPingMonitor creates a new ping monitor with default settings
 // Default: Quit after 10-30 seconds of ping > 500-1000ms
-func NewPingMonitor(logger *slog.Logger, threshold int, sustainedDuration time.Duration) *PingMonitor {
+func NewPingMonitor(supervisor string, logger *slog.Logger, threshold int, sustainedDuration time.Duration) *PingMonitor {
 	return &PingMonitor{
+		supervisor:        supervisor,
 		HighPingThreshold: threshold,
 		HighPingSustained: sustainedDuration,
 		CheckInterval:     time.Second * 2, // Check every 2 seconds
@@ -57,6 +61,7 @@ func (pm *PingMonitor) CheckPing(currentPing int) bool {
 		// If this is first detection, start timer
 		if pm.HighPingStart.IsZero() {
 			pm.HighPingStart = now
+			pm.sendState(currentPing, false)
 			pm.Logger.Warn("High ping detected, starting monitor",
 				slog.Int("ping", currentPing),
 				slog.Int("threshold", pm.HighPingThreshold),
@@ -68,6 +73,7 @@ func (pm *PingMonitor) CheckPing(currentPing int) bool {
 				pm.Logger.Error("Sustained high ping detected, triggering action",
 					slog.Int("ping", currentPing),
 					slog.Duration("duration", elapsed))
+				pm.sendState(currentPing, true)
 
 				// Call callback if set (typically supervisor stop)
 				if pm.OnHighPingDetected != nil {
@@ -89,12 +95,18 @@ func (pm *PingMonitor) CheckPing(currentPing int) bool {
 				slog.Int("ping", currentPing),
 				slog.Duration("highPingDuration", now.Sub(pm.HighPingStart)))
 			pm.HighPingStart = time.Time{} // Reset to zero value
+			pm.sendState(currentPing, false)
 		}
 	}
 
 	return false
 }
 
+// sendState publishes the high ping state, it's what the metrics endpoint exports
+func (pm *PingMonitor) sendState(ping int, sustained bool) {
+	event.Send(event.PingMonitor(event.Text(pm.supervisor, ""), ping, pm.HighPingThreshold, pm.HighPingStart, sustained))
+}
+
 // Reset clears the high ping tracking state
 func (pm *PingMonitor) Reset() {
 	pm.HighPingStart = time.Time{}
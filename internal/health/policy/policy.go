@@ -0,0 +1,135 @@
+// Package policy holds the life and mana decisions of the health manager: when to chicken and which potions to
+// drink. It only reads d2go data, so the decisions can be checked against game recordings on any OS.
+package policy
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/hectorgimenez/d2go/pkg/data"
+)
+
+var ErrDied = errors.New("you died :(")
+var ErrChicken = errors.New("chicken")
+var ErrMercChicken = errors.New("mercenary chicken")
+
+const (
+	HealingInterval     = time.Second * 4
+	HealingMercInterval = time.Second * 3
+	ManaInterval        = time.Second * 4
+	RejuvInterval       = time.Second * 1
+)
+
+// Settings are the health thresholds of a character, in life or mana percent
+type Settings struct {
+	HealingPotionAt     int `yaml:"healingPotionAt"`
+	ManaPotionAt        int `yaml:"manaPotionAt"`
+	RejuvPotionAtLife   int `yaml:"rejuvPotionAtLife"`
+	RejuvPotionAtMana   int `yaml:"rejuvPotionAtMana"`
+	MercHealingPotionAt int `yaml:"mercHealingPotionAt"`
+	MercRejuvPotionAt   int `yaml:"mercRejuvPotionAt"`
+	ChickenAt           int `yaml:"chickenAt"`
+	TownChickenAt       int `yaml:"townChickenAt"`
+	MercChickenAt       int `yaml:"mercChickenAt"`
+}
+
+// Drink is a potion to drink from the belt
+type Drink struct {
+	Potion data.PotionType
+	Merc   bool
+	// Column of the belt holding the potion, Binding is the key bound to it
+	Column  int
+	Binding data.KeyBinding
+}
+
+// Potions keeps when each kind of potion was last drunk, the zero value is ready to use
+type Potions struct {
+	lastRejuv     time.Time
+	lastRejuvMerc time.Time
+	lastHeal      time.Time
+	lastMana      time.Time
+	lastMercHeal  time.Time
+}
+
+// Evaluate returns the potions to drink for the given game data, in order. An error is returned when the character
+// died or the chicken thresholds are reached. Potions returned are considered drunk at now.
+func (p *Potions) Evaluate(d data.Data, s Settings, now time.Time) ([]Drink, error) {
+	// Safe area, skipping
+	if d.PlayerUnit.Area.IsTown() {
+		return nil, nil
+	}
+
+	if d.PlayerUnit.IsDead() {
+		return nil, ErrDied
+	}
+
+	// Player chicken check
+	if d.PlayerUnit.HPPercent() <= s.ChickenAt {
+		return nil, fmt.Errorf("%w: Current Health: %d percent", ErrChicken, d.PlayerUnit.HPPercent())
+	}
+
+	// Mercenary chicken check
+	if d.MercHPPercent() > 0 && d.MercHPPercent() <= s.MercChickenAt {
+		return nil, fmt.Errorf("%w: Current Merc Health: %d percent", ErrMercChicken, d.MercHPPercent())
+	}
+
+	var drinks []Drink
+	drink := func(potion data.PotionType, merc bool) bool {
+		pos, found := d.Inventory.Belt.GetFirstPotion(potion)
+		if !found {
+			return false
+		}
+		drinks = append(drinks, Drink{Potion: potion, Merc: merc, Column: pos.X, Binding: d.KeyBindings.UseBelt[pos.X]})
+
+		return true
+	}
+
+	// Player rejuvenation potion check
+	if now.Sub(p.lastRejuv) > RejuvInterval &&
+		(d.PlayerUnit.HPPercent() <= s.RejuvPotionAtLife ||
+			d.PlayerUnit.MPPercent() < s.RejuvPotionAtMana) {
+		if drink(data.RejuvenationPotion, false) {
+			p.lastRejuv = now
+			return drinks, nil
+		}
+	}
+
+	// Player healing potion check
+	if d.PlayerUnit.HPPercent() <= s.HealingPotionAt &&
+		now.Sub(p.lastHeal) > HealingInterval {
+		if drink(data.HealingPotion, false) {
+			p.lastHeal = now
+		}
+	}
+
+	// Player mana potion check
+	if d.PlayerUnit.MPPercent() <= s.ManaPotionAt &&
+		now.Sub(p.lastMana) > ManaInterval {
+		if drink(data.ManaPotion, false) {
+			p.lastMana = now
+		}
+	}
+
+	// Mercenary healing logic
+	if d.MercHPPercent() > 0 {
+		// Mercenary rejuvenation potion check
+		if now.Sub(p.lastRejuvMerc) > RejuvInterval &&
+			d.MercHPPercent() <= s.MercRejuvPotionAt {
+			if drink(data.RejuvenationPotion, true) {
+				p.lastRejuvMerc = now
+				return drinks, nil
+			}
+		}
+
+		// Mercenary healing potion check
+		if d.MercHPPercent() <= s.MercHealingPotionAt &&
+			now.Sub(p.lastMercHeal) > HealingMercInterval {
+			if drink(data.HealingPotion, true) {
+				p.lastMercHeal = now
+			}
+		}
+	}
+
+	return drinks, nil
+}
@@ -0,0 +1,106 @@
+package policy
+
+import (
+	"errors"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/hectorgimenez/d2go/pkg/data"
+	"github.com/hectorgimenez/d2go/pkg/data/area"
+	"github.com/hectorgimenez/d2go/pkg/data/item"
+	"github.com/hectorgimenez/d2go/pkg/data/stat"
+	"github.com/hectorgimenez/koolo/internal/game/replay"
+)
+
+func frameData(a area.ID, life, mana int, belt ...data.Item) data.Data {
+	var d data.Data
+	d.PlayerUnit.Area = a
+	d.PlayerUnit.Stats = stat.Stats{
+		{ID: stat.Life, Value: life},
+		{ID: stat.MaxLife, Value: 100},
+		{ID: stat.Mana, Value: mana},
+		{ID: stat.MaxMana, Value: 100},
+	}
+	d.Inventory.Belt.Items = belt
+	for i := range d.KeyBindings.UseBelt {
+		d.KeyBindings.UseBelt[i].Key1[0] = byte('1' + i)
+	}
+
+	return d
+}
+
+func TestEvaluateReplay(t *testing.T) {
+	s := Settings{HealingPotionAt: 50, ManaPotionAt: 20, RejuvPotionAtLife: 30, ChickenAt: 15}
+	healing := data.Item{Name: item.Name("SuperHealingPotion"), Position: data.Position{X: 0, Y: 0}}
+	mana := data.Item{Name: item.Name("SuperManaPotion"), Position: data.Position{X: 1, Y: 0}}
+	rejuv := data.Item{Name: item.Name("FullRejuvenationPotion"), Position: data.Position{X: 3, Y: 0}}
+	// Potions out of the first row can't be drunk
+	hiddenMana := data.Item{Name: item.Name("SuperManaPotion"), Position: data.Position{X: 1, Y: 1}}
+
+	type drink struct {
+		potion data.PotionType
+		key    byte
+	}
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	frames := []struct {
+		after  time.Duration
+		data   data.Data
+		drinks []drink
+		err    error
+	}{
+		{0, frameData(area.RogueEncampment, 20, 100, healing), nil, nil}, // Town is safe
+		{time.Second, frameData(area.StonyField, 40, 100, healing), []drink{{data.HealingPotion, '1'}}, nil},
+		{2 * time.Second, frameData(area.StonyField, 45, 100, healing), nil, nil}, // Within the healing interval
+		{3 * time.Second, frameData(area.StonyField, 45, 10, healing, mana), []drink{{data.HealingPotion, '1'}, {data.ManaPotion, '2'}}, nil},
+		{time.Second, frameData(area.StonyField, 100, 10, hiddenMana), nil, nil},
+		{time.Second, frameData(area.StonyField, 25, 100, healing, rejuv), []drink{{data.RejuvenationPotion, '4'}}, nil}, // Rejuv skips the rest
+		{time.Second, frameData(area.StonyField, 10, 100, healing, rejuv), nil, ErrChicken},
+		{time.Second, frameData(area.StonyField, 0, 100), nil, ErrDied},
+	}
+
+	path := filepath.Join(t.TempDir(), "game"+replay.FileExtension)
+	w, err := replay.Create(path, replay.Header{Supervisor: "sorc"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := start
+	for _, f := range frames {
+		now = now.Add(f.after)
+		if err = w.WriteFrame(replay.Frame{Time: now, Data: f.data}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err = w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	session, err := replay.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	player := replay.NewPlayer(session)
+	var potions Potions
+	for i := range frames {
+		if i > 0 && !player.Next() {
+			t.Fatalf("recording ended at frame %d", i)
+		}
+		f := session.Frames[player.Frame()]
+		got, err := potions.Evaluate(player.GetData(), s, f.Time)
+		if !errors.Is(err, frames[i].err) || (err != nil) != (frames[i].err != nil) {
+			t.Errorf("frame %d: error = %v, want %v", i, err, frames[i].err)
+		}
+
+		var drinks []drink
+		for _, d := range got {
+			if d.Merc {
+				t.Errorf("frame %d: potion given to the mercenary", i)
+			}
+			drinks = append(drinks, drink{d.Potion, d.Binding.Key1[0]})
+		}
+		if !slices.Equal(drinks, frames[i].drinks) {
+			t.Errorf("frame %d: drinks = %v, want %v", i, drinks, frames[i].drinks)
+		}
+	}
+}
@@ -5,7 +5,7 @@ import (
 	"math"
 
 	"github.com/hectorgimenez/d2go/pkg/data"
-	"github.com/hectorgimenez/koolo/internal/game"
+	"github.com/hectorgimenez/koolo/internal/game/mapgrid"
 )
 
 var directions = []data.Position{
@@ -37,8 +37,8 @@ const MaxConsecutiveTeleportOver = 12
 
 // AStarBuffers holds reusable buffers for A* pathfinding to avoid allocations
 type AStarBuffers struct {
-	costSoFar []int           // flat 1D array: index = y*Width + x  (row-major, matching game.Grid)
-	cameFrom  []data.Position // flat 1D array: index = y*Width + x  (row-major, matching game.Grid)
+	costSoFar []int           // flat 1D array: index = y*Width + x  (row-major, matching mapgrid.Grid)
+	cameFrom  []data.Position // flat 1D array: index = y*Width + x  (row-major, matching mapgrid.Grid)
 	width     int
 	height    int
 }
@@ -64,7 +64,7 @@ func (b *AStarBuffers) index(x, y int) int {
 
 // CalculatePath finds a path using A* algorithm. If buffers is nil, allocates new buffers.
 // For optimal performance, reuse buffers across calls by creating AStarBuffers once per PathFinder.
-func CalculatePath(g *game.Grid, start, goal data.Position, canTeleport bool, buffers *AStarBuffers) ([]data.Position, int, bool) {
+func CalculatePath(g *mapgrid.Grid, start, goal data.Position, canTeleport bool, buffers *AStarBuffers) ([]data.Position, int, bool) {
 	inBounds := func(p data.Position) bool {
 		return p.X >= 0 && p.Y >= 0 && p.X < g.Width && p.Y < g.Height
 	}
@@ -123,7 +123,7 @@ func CalculatePath(g *game.Grid, start, goal data.Position, canTeleport bool, bu
 			// First pass: count path length (excluding teleport-over tiles)
 			pathLen := 1 // start position
 			for p := goal; p != start; p = cameFrom[idx(p.X, p.Y)] {
-				if g.Get(p.X, p.Y) != game.CollisionTypeTeleportOver {
+				if g.Get(p.X, p.Y) != mapgrid.CollisionTypeTeleportOver {
 					pathLen++
 				}
 			}
@@ -131,7 +131,7 @@ func CalculatePath(g *game.Grid, start, goal data.Position, canTeleport bool, bu
 			path := make([]data.Position, pathLen)
 			i := pathLen - 1
 			for p := goal; p != start; p = cameFrom[idx(p.X, p.Y)] {
-				if g.Get(p.X, p.Y) != game.CollisionTypeTeleportOver {
+				if g.Get(p.X, p.Y) != mapgrid.CollisionTypeTeleportOver {
 					path[i] = p
 					i--
 				}
@@ -147,7 +147,7 @@ func CalculatePath(g *game.Grid, start, goal data.Position, canTeleport bool, bu
 
 			// Determine teleport streak
 			teleportStreak := 0
-			if tileType == game.CollisionTypeTeleportOver {
+			if tileType == mapgrid.CollisionTypeTeleportOver {
 				teleportStreak = current.TpStreak + 1
 			} else {
 				teleportStreak = 0
@@ -182,7 +182,7 @@ func CalculatePath(g *game.Grid, start, goal data.Position, canTeleport bool, bu
 }
 
 // Get walkable neighbors of a given node
-func updateNeighbors(grid *game.Grid, node *Node, neighbors *[]data.Position, canTeleport bool) {
+func updateNeighbors(grid *mapgrid.Grid, node *Node, neighbors *[]data.Position, canTeleport bool) {
 	*neighbors = (*neighbors)[:0]
 
 	x, y := node.X, node.Y
@@ -194,11 +194,11 @@ func updateNeighbors(grid *game.Grid, node *Node, neighbors *[]data.Position, ca
 		}
 		collisionType := grid.Get(px, py)
 		switch collisionType {
-		case game.CollisionTypeNonWalkable:
+		case mapgrid.CollisionTypeNonWalkable:
 			return true
-		case game.CollisionTypeTeleportOver:
+		case mapgrid.CollisionTypeTeleportOver:
 			return !canTeleport
-		case game.CollisionTypeThickened:
+		case mapgrid.CollisionTypeThickened:
 			return !canTeleport
 		default:
 			return false
@@ -225,22 +225,27 @@ func updateNeighbors(grid *game.Grid, node *Node, neighbors *[]data.Position, ca
 	}
 }
 
-func getCost(tileType game.CollisionType, canTeleport bool) int {
+// CellCost returns the cost of entering a cell of type t, math.MaxInt32 when it can't be entered
+func CellCost(t mapgrid.CollisionType, canTeleport bool) int {
+	return getCost(t, canTeleport)
+}
+
+func getCost(tileType mapgrid.CollisionType, canTeleport bool) int {
 	switch tileType {
-	case game.CollisionTypeWalkable:
+	case mapgrid.CollisionTypeWalkable:
 		return 1 // Walkable
-	case game.CollisionTypeMonster:
+	case mapgrid.CollisionTypeMonster:
 		return 16
-	case game.CollisionTypeObject:
+	case mapgrid.CollisionTypeObject:
 		return 4 // Soft blocker
-	case game.CollisionTypeLowPriority:
+	case mapgrid.CollisionTypeLowPriority:
 		return 20
-	case game.CollisionTypeTeleportOver:
+	case mapgrid.CollisionTypeTeleportOver:
 		if canTeleport {
 			return 1
 		}
 		return math.MaxInt32
-	case game.CollisionTypeThickened:
+	case mapgrid.CollisionTypeThickened:
 		if canTeleport {
 			return 1
 		}
@@ -3,10 +3,12 @@ package astar
 import (
 	"encoding/gob"
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/hectorgimenez/d2go/pkg/data"
-	"github.com/hectorgimenez/koolo/internal/game"
+	"github.com/hectorgimenez/koolo/internal/game/mapgrid"
 )
 
 func BenchmarkAstar(b *testing.B) {
@@ -71,10 +73,10 @@ type legacyGrid struct {
 	OffsetY       int
 	Width         int
 	Height        int
-	CollisionGrid [][]game.CollisionType
+	CollisionGrid [][]mapgrid.CollisionType
 }
 
-func loadGrid() *game.Grid {
+func loadGrid() *mapgrid.Grid {
 	var legacy legacyGrid
 	file, err := os.Open("durance_of_hate_grid.bin")
 	if err != nil {
@@ -88,12 +90,12 @@ func loadGrid() *game.Grid {
 	}
 
 	// Convert 2D to flat 1D
-	flat := make([]game.CollisionType, legacy.Width*legacy.Height)
+	flat := make([]mapgrid.CollisionType, legacy.Width*legacy.Height)
 	for y := 0; y < legacy.Height; y++ {
 		copy(flat[y*legacy.Width:(y+1)*legacy.Width], legacy.CollisionGrid[y])
 	}
 
-	return &game.Grid{
+	return &mapgrid.Grid{
 		OffsetX:       legacy.OffsetX,
 		OffsetY:       legacy.OffsetY,
 		Width:         legacy.Width,
@@ -101,3 +103,19 @@ func loadGrid() *game.Grid {
 		CollisionGrid: flat,
 	}
 }
+
+func TestVersionSources(t *testing.T) {
+	files, err := filepath.Glob("*.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, name := range files {
+		if strings.HasSuffix(name, "_test.go") || name == "version.go" {
+			continue
+		}
+		if _, err = sources.ReadFile(name); err != nil {
+			t.Errorf("%s is not embedded in sources, changes to it won't change Version", name)
+		}
+	}
+}
@@ -0,0 +1,400 @@
+package astar
+
+import (
+	"math"
+	"slices"
+
+	"github.com/hectorgimenez/d2go/pkg/data"
+	"github.com/hectorgimenez/koolo/internal/game/mapgrid"
+)
+
+// clusterSearch finds paths and distances inside a single cluster of a grid, cells outside of it are handled as
+// non walkable. Moves and costs follow CalculatePath, the buffers are sized for one cluster and reused.
+type clusterSearch struct {
+	g           *mapgrid.Grid
+	canTeleport bool
+	// minX, minY, maxX and maxY are the cluster bounds relative to the grid, the max ones excluded
+	minX, minY, maxX, maxY int
+
+	// cellCost is the cost of entering every cell of the cluster, 0 when it can't be entered. Cells are stored with a
+	// border of blocked ones so the searches can move to any neighbour index without bound checks.
+	cellCost [clusterCells]int32
+
+	cost   [clusterCells]int32
+	from   [clusterCells]int32
+	streak [clusterCells]uint8
+	queue  searchQueue
+}
+
+// blockedType reports whether cells of type t can't be entered, matching updateNeighbors
+func blockedType(t mapgrid.CollisionType, canTeleport bool) bool {
+	switch t {
+	case mapgrid.CollisionTypeNonWalkable:
+		return true
+	case mapgrid.CollisionTypeTeleportOver, mapgrid.CollisionTypeThickened:
+		return !canTeleport
+	default:
+		return false
+	}
+}
+
+const (
+	clusterStride = clusterSize + 2
+	clusterCells  = clusterStride * clusterStride
+)
+
+// neighborOffsets are the index offsets of directions
+var neighborOffsets = func() (offsets [8]int32) {
+	for i, d := range directions {
+		offsets[i] = int32(d.Y*clusterStride + d.X)
+	}
+	return offsets
+}()
+
+func (s *clusterSearch) init(g *mapgrid.Grid, canTeleport bool, cx, cy int) {
+	s.g = g
+	s.canTeleport = canTeleport
+	s.minX, s.minY = cx<<clusterShift, cy<<clusterShift
+	s.maxX, s.maxY = min(s.minX+clusterSize, g.Width), min(s.minY+clusterSize, g.Height)
+	clear(s.cellCost[:])
+	for y := s.minY; y < s.maxY; y++ {
+		for x := s.minX; x < s.maxX; x++ {
+			cellCost := int32(0)
+			if t := g.Get(x, y); !blockedType(t, canTeleport) {
+				cellCost = int32(getCost(t, canTeleport))
+			}
+			s.cellCost[s.index(x, y)] = cellCost
+		}
+	}
+}
+
+func (s *clusterSearch) index(x, y int) int32 {
+	return int32((y-s.minY+1)*clusterStride + x - s.minX + 1)
+}
+
+func (s *clusterSearch) position(i int32) data.Position {
+	return data.Position{X: s.minX + int(i)%clusterStride - 1, Y: s.minY + int(i)/clusterStride - 1}
+}
+
+func (s *clusterSearch) blocked(x, y int) bool {
+	return x < s.minX || y < s.minY || x >= s.maxX || y >= s.maxY || s.cellCost[s.index(x, y)] == 0
+}
+
+// canMove matches updateNeighbors: the target cell must be free and diagonal moves can't cut corners
+func (s *clusterSearch) canMove(x, y, dx, dy int) bool {
+	if s.blocked(x+dx, y+dy) {
+		return false
+	}
+
+	return dx == 0 || dy == 0 || (!s.blocked(x+dx, y) && !s.blocked(x, y+dy))
+}
+
+func (s *clusterSearch) reset() {
+	for i := range s.cost {
+		s.cost[i] = math.MaxInt32
+		s.from[i] = -1
+	}
+	s.queue = s.queue[:0]
+}
+
+// costAt returns the cost computed by the last distances call for p, -1 when it can't be reached
+func (s *clusterSearch) costAt(p data.Position) int32 {
+	if c := s.cost[s.index(p.X, p.Y)]; c != math.MaxInt32 {
+		return c
+	}
+
+	return -1
+}
+
+// distances computes the cost from source to every cell of the cluster, or from every cell to source when reverse is
+// set. Moves are symmetric, only the cost of the entered cell changes.
+func (s *clusterSearch) distances(source data.Position, reverse bool) {
+	s.reset()
+	s.cost[s.index(source.X, source.Y)] = 0
+	s.queue.push(searchItem{node: s.index(source.X, source.Y)})
+
+	for len(s.queue) > 0 {
+		current := s.queue.pop()
+		if current.cost > s.cost[current.node] {
+			continue
+		}
+
+		// Same moves as canMove, the blocked border keeps them inside the cluster
+		for i, offset := range neighborOffsets {
+			next := current.node + offset
+			if s.cellCost[next] == 0 {
+				continue
+			}
+			if d := directions[i]; d.X != 0 && d.Y != 0 &&
+				(s.cellCost[current.node+int32(d.X)] == 0 || s.cellCost[current.node+int32(d.Y*clusterStride)] == 0) {
+				continue
+			}
+
+			entered := s.cellCost[next]
+			if reverse {
+				entered = s.cellCost[current.node]
+			}
+			if newCost := current.cost + entered; newCost < s.cost[next] {
+				s.cost[next] = newCost
+				s.queue.push(searchItem{node: next, cost: newCost, priority: newCost})
+			}
+		}
+	}
+}
+
+// path returns the cells from start to goal, both included, teleport over cells are kept. Clusters where every
+// passable cell has the same cost use jump point search, the rest plain A*.
+func (s *clusterSearch) path(start, goal data.Position) ([]data.Position, bool) {
+	if cellCost, uniform := s.uniformCost(); uniform {
+		return s.jumpPointSearch(start, goal, cellCost)
+	}
+
+	return s.aStar(start, goal)
+}
+
+// uniformCost returns the cost of the passable cells when all of them have the same collision type. Teleport over
+// cells are never uniform, their streak is checked cell by cell.
+func (s *clusterSearch) uniformCost() (int, bool) {
+	t := mapgrid.CollisionTypeNonWalkable
+	for y := s.minY; y < s.maxY; y++ {
+		for x := s.minX; x < s.maxX; x++ {
+			switch c := s.g.Get(x, y); {
+			case blockedType(c, s.canTeleport):
+			case c == mapgrid.CollisionTypeTeleportOver || c == mapgrid.CollisionTypeThickened:
+				return 0, false
+			case t == mapgrid.CollisionTypeNonWalkable:
+				t = c
+			case c != t:
+				return 0, false
+			}
+		}
+	}
+
+	return getCost(t, s.canTeleport), true
+}
+
+func (s *clusterSearch) aStar(start, goal data.Position) ([]data.Position, bool) {
+	s.reset()
+	startIdx := s.index(start.X, start.Y)
+	s.cost[startIdx] = 0
+	s.streak[startIdx] = 0
+	s.queue.push(searchItem{node: startIdx, priority: int32(heuristic(start, goal))})
+
+	for len(s.queue) > 0 {
+		current := s.queue.pop()
+		if current.cost > s.cost[current.node] {
+			continue
+		}
+
+		p := s.position(current.node)
+		if p == goal {
+			return s.buildPath(start, goal), true
+		}
+
+		for _, d := range directions {
+			if !s.canMove(p.X, p.Y, d.X, d.Y) {
+				continue
+			}
+			n := data.Position{X: p.X + d.X, Y: p.Y + d.Y}
+			tileType := s.g.Get(n.X, n.Y)
+
+			teleportStreak := uint8(0)
+			if tileType == mapgrid.CollisionTypeTeleportOver {
+				teleportStreak = s.streak[current.node] + 1
+			}
+			if teleportStreak > MaxConsecutiveTeleportOver {
+				continue
+			}
+
+			next := s.index(n.X, n.Y)
+			if newCost := current.cost + int32(getCost(tileType, s.canTeleport)); newCost < s.cost[next] {
+				s.cost[next] = newCost
+				s.from[next] = current.node
+				s.streak[next] = teleportStreak
+				priority := newCost + int32(0.5*float64(heuristic(n, goal)))
+				s.queue.push(searchItem{node: next, cost: newCost, priority: priority})
+			}
+		}
+	}
+
+	return nil, false
+}
+
+// jumpPointSearch only expands the cells where the path may turn, it's only valid when every passable cell of the
+// cluster costs cellCost
+func (s *clusterSearch) jumpPointSearch(start, goal data.Position, cellCost int) ([]data.Position, bool) {
+	s.reset()
+	startIdx := s.index(start.X, start.Y)
+	s.cost[startIdx] = 0
+	s.queue.push(searchItem{node: startIdx, priority: int32(heuristic(start, goal))})
+
+	successors := make([]data.Position, 0, len(directions))
+	for len(s.queue) > 0 {
+		current := s.queue.pop()
+		if current.cost > s.cost[current.node] {
+			continue
+		}
+
+		p := s.position(current.node)
+		if p == goal {
+			return s.buildPath(start, goal), true
+		}
+
+		successors = s.successors(p, s.from[current.node], successors)
+		for _, d := range successors {
+			jumpPoint, steps, found := s.jump(p.X, p.Y, d.X, d.Y, goal)
+			if !found {
+				continue
+			}
+
+			next := s.index(jumpPoint.X, jumpPoint.Y)
+			if newCost := current.cost + int32(steps*cellCost); newCost < s.cost[next] {
+				s.cost[next] = newCost
+				s.from[next] = current.node
+				priority := newCost + int32(0.5*float64(heuristic(jumpPoint, goal)))
+				s.queue.push(searchItem{node: next, cost: newCost, priority: priority})
+			}
+		}
+	}
+
+	return nil, false
+}
+
+// successors returns the directions to jump to from p, pruning the cells reachable at the same cost without going
+// through p. Diagonal moves can't cut corners, so straight moves keep the turns next to free cells.
+func (s *clusterSearch) successors(p data.Position, parent int32, dirs []data.Position) []data.Position {
+	dirs = dirs[:0]
+	if parent < 0 {
+		return append(dirs, directions...)
+	}
+
+	from := s.position(parent)
+	dx, dy := sign(p.X-from.X), sign(p.Y-from.Y)
+	switch {
+	case dx != 0 && dy != 0:
+		dirs = append(dirs, data.Position{X: 0, Y: dy}, data.Position{X: dx, Y: 0}, data.Position{X: dx, Y: dy})
+	case dx != 0:
+		dirs = append(dirs, data.Position{X: dx, Y: 0})
+		for _, side := range []int{-1, 1} {
+			if !s.blocked(p.X, p.Y+side) {
+				dirs = append(dirs, data.Position{X: 0, Y: side}, data.Position{X: dx, Y: side})
+			}
+		}
+	default:
+		dirs = append(dirs, data.Position{X: 0, Y: dy})
+		for _, side := range []int{-1, 1} {
+			if !s.blocked(p.X+side, p.Y) {
+				dirs = append(dirs, data.Position{X: side, Y: 0}, data.Position{X: side, Y: dy})
+			}
+		}
+	}
+
+	return dirs
+}
+
+// jump moves from (x, y) towards (dx, dy) until the goal or a cell with a forced neighbour, returning it and the
+// number of steps
+func (s *clusterSearch) jump(x, y, dx, dy int, goal data.Position) (data.Position, int, bool) {
+	for steps := 1; ; steps++ {
+		if !s.canMove(x, y, dx, dy) {
+			return data.Position{}, 0, false
+		}
+		x, y = x+dx, y+dy
+
+		p := data.Position{X: x, Y: y}
+		switch {
+		case p == goal:
+			return p, steps, true
+		case dx != 0 && dy != 0:
+			// Diagonal moves stop where a straight jump would find something
+			if _, _, found := s.jump(x, y, dx, 0, goal); found {
+				return p, steps, true
+			}
+			if _, _, found := s.jump(x, y, 0, dy, goal); found {
+				return p, steps, true
+			}
+		case dx != 0:
+			if (!s.blocked(x, y-1) && s.blocked(x-dx, y-1)) || (!s.blocked(x, y+1) && s.blocked(x-dx, y+1)) {
+				return p, steps, true
+			}
+		default:
+			if (!s.blocked(x-1, y) && s.blocked(x-1, y-dy)) || (!s.blocked(x+1, y) && s.blocked(x+1, y-dy)) {
+				return p, steps, true
+			}
+		}
+	}
+}
+
+// buildPath walks back the search from goal, filling the cells between jump points
+func (s *clusterSearch) buildPath(start, goal data.Position) []data.Position {
+	path := make([]data.Position, 0, 2*clusterSize)
+	for p := goal; p != start; {
+		parent := s.position(s.from[s.index(p.X, p.Y)])
+		dx, dy := sign(p.X-parent.X), sign(p.Y-parent.Y)
+		for ; p != parent; p = (data.Position{X: p.X - dx, Y: p.Y - dy}) {
+			path = append(path, p)
+		}
+	}
+	path = append(path, start)
+	slices.Reverse(path)
+
+	return path
+}
+
+func sign(v int) int {
+	switch {
+	case v > 0:
+		return 1
+	case v < 0:
+		return -1
+	default:
+		return 0
+	}
+}
+
+type searchItem struct {
+	node     int32
+	cost     int32
+	priority int32
+}
+
+// searchQueue is a binary min heap of values, unlike PriorityQueue it doesn't allocate on every push
+type searchQueue []searchItem
+
+func (q *searchQueue) push(item searchItem) {
+	*q = append(*q, item)
+	h := *q
+	for i := len(h) - 1; i > 0; {
+		parent := (i - 1) / 2
+		if h[parent].priority <= h[i].priority {
+			break
+		}
+		h[parent], h[i] = h[i], h[parent]
+		i = parent
+	}
+}
+
+func (q *searchQueue) pop() searchItem {
+	h := *q
+	top := h[0]
+	last := len(h) - 1
+	h[0] = h[last]
+	h = h[:last]
+	for i := 0; ; {
+		smallest, left, right := i, 2*i+1, 2*i+2
+		if left < len(h) && h[left].priority < h[smallest].priority {
+			smallest = left
+		}
+		if right < len(h) && h[right].priority < h[smallest].priority {
+			smallest = right
+		}
+		if smallest == i {
+			break
+		}
+		h[i], h[smallest] = h[smallest], h[i]
+		i = smallest
+	}
+	*q = h
+
+	return top
+}
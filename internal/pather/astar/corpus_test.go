@@ -0,0 +1,137 @@
+package astar
+
+import (
+	"testing"
+
+	"github.com/hectorgimenez/koolo/internal/pather/corpus"
+)
+
+// TestCorpus checks the golden expectations of the recorded grids, see the corpus package to add a case
+func TestCorpus(t *testing.T) {
+	cases, err := corpus.Load(corpus.Dir())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, c := range cases {
+		for _, name := range planners {
+			t.Run(c.Name+"/planner="+name, func(t *testing.T) {
+				testCorpusCase(t, c, NewPlanner(name))
+			})
+		}
+	}
+	for _, name := range corpus.Missing {
+		t.Run(name, func(t *testing.T) {
+			t.Skip("no grid recorded yet, export one from /debug-grid and add its cases to the corpus")
+		})
+	}
+}
+
+func testCorpusCase(t *testing.T, c corpus.Case, planner Planner) {
+	grid, from, to, err := c.PathGrid()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	path, distance, found := planner.CalculatePath(grid, from, to, c.Teleport)
+	if !found {
+		t.Fatalf("no path found from %v to %v", c.From, c.To)
+	}
+	if path[0] != from || path[len(path)-1] != to {
+		t.Errorf("path goes from %v to %v, expected %v to %v", path[0], path[len(path)-1], from, to)
+	}
+	if distance != len(path) {
+		t.Errorf("distance %d, expected the path length %d", distance, len(path))
+	}
+	for i := 1; i < len(path); i++ {
+		if d := chebyshev(path[i-1], path[i]); d != 1 && !c.Teleport {
+			t.Fatalf("%v and %v are not adjacent", path[i-1], path[i])
+		}
+	}
+	if c.MaxLength > 0 && len(path) > c.MaxLength {
+		t.Errorf("path length %d, expected at most %d", len(path), c.MaxLength)
+	}
+	if clearance := corpus.Clearance(grid, path); clearance < c.MinClearance {
+		t.Errorf("wall clearance %d, expected at least %d", clearance, c.MinClearance)
+	}
+}
+
+var planners = []string{PlannerAStar, PlannerHierarchical}
+
+// BenchmarkCorpus measures every corpus case with every planner, compare runs with benchstat:
+//
+//	go test ./internal/pather/astar/ -run ^$ -bench Corpus -benchmem -count 10 > new.txt
+//	benchstat -col /planner new.txt
+//
+// The hierarchical planner abstraction is built on the first call and reused, like it is while the bot stays in an area.
+func BenchmarkCorpus(b *testing.B) {
+	cases, err := corpus.Load(corpus.Dir())
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	for _, c := range cases {
+		grid, from, to, err := c.PathGrid()
+		if err != nil {
+			b.Fatal(err)
+		}
+		for _, name := range planners {
+			b.Run(c.Name+"/planner="+name, func(b *testing.B) {
+				planner := NewPlanner(name)
+				planner.CalculatePath(grid, from, to, c.Teleport)
+
+				b.ResetTimer()
+				for i := 0; i < b.N; i++ {
+					planner.CalculatePath(grid, from, to, c.Teleport)
+				}
+			})
+		}
+	}
+}
+
+// BenchmarkCorpusColdPlanner measures the first request of a hierarchical planner, which builds the abstraction and
+// the transition costs it needs, paid once per area
+func BenchmarkCorpusColdPlanner(b *testing.B) {
+	cases, err := corpus.Load(corpus.Dir())
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	for _, c := range cases {
+		b.Run(c.Name, func(b *testing.B) {
+			grid, from, to, err := c.PathGrid()
+			if err != nil {
+				b.Fatal(err)
+			}
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				NewHierarchicalPlanner().CalculatePath(grid, from, to, c.Teleport)
+			}
+		})
+	}
+}
+
+// BenchmarkCorpusPathGrid measures the grid preparation done before every path request (copy, merge, obstacles)
+func BenchmarkCorpusPathGrid(b *testing.B) {
+	cases, err := corpus.Load(corpus.Dir())
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	for _, c := range cases {
+		b.Run(c.Name, func(b *testing.B) {
+			s, err := c.Scene()
+			if err != nil {
+				b.Fatal(err)
+			}
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if _, _, _, err = s.PathGrid(c.From, c.To); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
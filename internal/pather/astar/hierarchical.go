@@ -0,0 +1,431 @@
+package astar
+
+import (
+	"math"
+	"slices"
+
+	"github.com/hectorgimenez/d2go/pkg/data"
+	"github.com/hectorgimenez/koolo/internal/game/mapgrid"
+)
+
+const (
+	// clusterShift sets the cluster size of the hierarchical planner, 1<<4 = 16x16 cells
+	clusterShift = 4
+	clusterSize  = 1 << clusterShift
+	// transitionSpacing is the distance between the transitions of a cluster border, fewer transitions make the
+	// abstraction faster to build and search but the paths longer
+	transitionSpacing = 4
+	// maxClusterMaps is the number of grids the hierarchical planner keeps the abstraction of
+	maxClusterMaps = 4
+	// unknownCost marks the inner costs not computed yet
+	unknownCost = -2
+)
+
+// HierarchicalPlanner splits the grid in clusters linked by transitions (the cells where a path can cross a cluster
+// border) with the cost of going from one transition to another precomputed. A path request looks for the
+// transitions to go through on that abstraction, then only searches the cells of the clusters along the way.
+//
+// The abstraction is only rebuilt when the passable cells change, not for monsters or objects, so it's built once per
+// area and reused while the bot stays there. Costs between transitions are computed on the grid of the first request
+// that needs them, the cells of the clusters along the way are always searched on the current grid.
+type HierarchicalPlanner struct {
+	// maps are the abstractions of the last grids, most recently used first
+	maps    []*clusterMap
+	search  clusterSearch
+	route   routeSearch
+	buffers AStarBuffers
+}
+
+func NewHierarchicalPlanner() *HierarchicalPlanner {
+	return &HierarchicalPlanner{}
+}
+
+// CalculatePath has the contract of the package CalculatePath function
+func (p *HierarchicalPlanner) CalculatePath(g *mapgrid.Grid, start, goal data.Position, canTeleport bool) ([]data.Position, int, bool) {
+	if g == nil || g.Width == 0 || g.Height == 0 {
+		return nil, 0, false
+	}
+	inBounds := func(pos data.Position) bool {
+		return pos.X >= 0 && pos.Y >= 0 && pos.X < g.Width && pos.Y < g.Height
+	}
+	if !inBounds(start) || !inBounds(goal) {
+		return nil, 0, false
+	}
+	if start == goal {
+		return []data.Position{start}, 1, true
+	}
+	if blockedType(g.Get(goal.X, goal.Y), canTeleport) {
+		// Blocked cells are never entered, CalculatePath can't reach it either
+		return nil, 0, false
+	}
+
+	cm := p.clusterMap(g, canTeleport)
+	if cluster := cm.clusterOf(start); cluster == cm.clusterOf(goal) {
+		p.search.init(g, canTeleport, cluster%cm.clustersX, cluster/cm.clustersX)
+		if cells, found := p.search.path(start, goal); found {
+			return p.finish(g, start, goal, cells, canTeleport)
+		}
+	}
+
+	route, found := p.route.find(cm, &p.search, g, start, goal, canTeleport)
+	if !found {
+		// The abstraction has the connectivity of the grid, the goal can't be reached
+		return nil, 0, false
+	}
+	cells, found := p.refine(cm, g, start, goal, route, canTeleport)
+	if !found {
+		return CalculatePath(g, start, goal, canTeleport, &p.buffers)
+	}
+
+	return p.finish(g, start, goal, cells, canTeleport)
+}
+
+// refine joins the cells of every step of the route
+func (p *HierarchicalPlanner) refine(cm *clusterMap, g *mapgrid.Grid, start, goal data.Position, route []int32, canTeleport bool) ([]data.Position, bool) {
+	cells := []data.Position{start}
+	from, fromCluster := start, cm.clusterOf(start)
+	for i := 0; i <= len(route); i++ {
+		to, toCluster := goal, cm.clusterOf(goal)
+		if i < len(route) {
+			to, toCluster = cm.nodes[route[i]], int(cm.nodeCluster[route[i]])
+		}
+
+		switch {
+		case to == from:
+		case toCluster != fromCluster:
+			// Transitions on both sides of a border are next to each other
+			cells = append(cells, to)
+		default:
+			p.search.init(g, canTeleport, toCluster%cm.clustersX, toCluster/cm.clustersX)
+			segment, found := p.search.path(from, to)
+			if !found {
+				return nil, false
+			}
+			cells = append(cells, segment[1:]...)
+		}
+		from, fromCluster = to, toCluster
+	}
+
+	return cells, true
+}
+
+// finish removes the teleport over cells like CalculatePath does, the streak limit is checked on the whole path since
+// the cluster searches only see their part of it
+func (p *HierarchicalPlanner) finish(g *mapgrid.Grid, start, goal data.Position, cells []data.Position, canTeleport bool) ([]data.Position, int, bool) {
+	path := cells[:1]
+	streak := 0
+	for _, c := range cells[1:] {
+		if g.Get(c.X, c.Y) != mapgrid.CollisionTypeTeleportOver {
+			path = append(path, c)
+			streak = 0
+			continue
+		}
+		if streak++; streak > MaxConsecutiveTeleportOver {
+			return CalculatePath(g, start, goal, canTeleport, &p.buffers)
+		}
+	}
+
+	return path, len(path), true
+}
+
+// clusterMap returns the cached abstraction of g or builds it
+func (p *HierarchicalPlanner) clusterMap(g *mapgrid.Grid, canTeleport bool) *clusterMap {
+	fingerprint := passabilityFingerprint(g, canTeleport)
+	for i, cm := range p.maps {
+		if cm.matches(g, canTeleport, fingerprint) {
+			// Move it to the front so the least recently used one is evicted first
+			copy(p.maps[1:i+1], p.maps[:i])
+			p.maps[0] = cm
+			return cm
+		}
+	}
+
+	cm := newClusterMap(g, canTeleport, fingerprint)
+	if len(p.maps) < maxClusterMaps {
+		p.maps = append(p.maps, nil)
+	}
+	copy(p.maps[1:], p.maps)
+	p.maps[0] = cm
+
+	return cm
+}
+
+// passabilityFingerprint hashes which cells of g can be entered, FNV-1a over words of 64 cells
+func passabilityFingerprint(g *mapgrid.Grid, canTeleport bool) uint64 {
+	const prime = 1099511628211
+	hash := uint64(14695981039346656037)
+	word, bits := uint64(0), 0
+	for _, t := range g.CollisionGrid {
+		word <<= 1
+		if !blockedType(t, canTeleport) {
+			word |= 1
+		}
+		if bits++; bits == 64 {
+			hash = (hash ^ word) * prime
+			word, bits = 0, 0
+		}
+	}
+
+	return (hash ^ word) * prime
+}
+
+// clusterMap is the abstraction of a grid. Transitions are cells along the cluster borders (every transitionSpacing
+// cells of a run of passable cells facing each other, and the middle of the run) on both sides of the border. The
+// transitions facing each other are linked with the cost of entering the other cell, the transitions of a cluster with
+// the cost of the cheapest path between them inside the cluster.
+type clusterMap struct {
+	offsetX, offsetY int
+	width, height    int
+	canTeleport      bool
+	fingerprint      uint64
+
+	clustersX, clustersY int
+	// nodes are the transition cells sorted by cluster, the ones of cluster c are nodes[clusterStart[c]:clusterStart[c+1]]
+	nodes        []data.Position
+	nodeCluster  []int32
+	clusterStart []int32
+	// the links of node n to other clusters are linkTo[linkStart[n]:linkStart[n+1]], with their cost in linkCost
+	linkStart []int32
+	linkTo    []int32
+	linkCost  []int32
+	// inner holds the costs between the nodes of every cluster (row from, column to, -1 when there is no path), the
+	// rows are computed the first time a route goes through their node
+	inner [][]int32
+}
+
+func (cm *clusterMap) matches(g *mapgrid.Grid, canTeleport bool, fingerprint uint64) bool {
+	return cm.fingerprint == fingerprint && cm.canTeleport == canTeleport && cm.offsetX == g.OffsetX &&
+		cm.offsetY == g.OffsetY && cm.width == g.Width && cm.height == g.Height
+}
+
+func (cm *clusterMap) clusterOf(p data.Position) int {
+	return (p.Y>>clusterShift)*cm.clustersX + p.X>>clusterShift
+}
+
+func newClusterMap(g *mapgrid.Grid, canTeleport bool, fingerprint uint64) *clusterMap {
+	cm := &clusterMap{
+		offsetX:     g.OffsetX,
+		offsetY:     g.OffsetY,
+		width:       g.Width,
+		height:      g.Height,
+		canTeleport: canTeleport,
+		fingerprint: fingerprint,
+		clustersX:   (g.Width + clusterSize - 1) >> clusterShift,
+		clustersY:   (g.Height + clusterSize - 1) >> clusterShift,
+	}
+	cm.inner = make([][]int32, cm.clustersX*cm.clustersY)
+
+	// Transitions, as pairs of cells facing each other across a border
+	var transitions [][2]data.Position
+	passable := func(p data.Position) bool {
+		return !blockedType(g.Get(p.X, p.Y), canTeleport)
+	}
+	addRuns := func(length int, cells func(i int) (data.Position, data.Position)) {
+		run := 0
+		for i := 0; i <= length; i++ {
+			if i < length {
+				if a, b := cells(i); passable(a) && passable(b) {
+					run++
+					continue
+				}
+			}
+			for j := 0; j < run; j++ {
+				if j%transitionSpacing == 0 || j == run/2 {
+					a, b := cells(i - run + j)
+					transitions = append(transitions, [2]data.Position{a, b})
+				}
+			}
+			run = 0
+		}
+	}
+	for x := clusterSize - 1; x+1 < g.Width; x += clusterSize {
+		for y0 := 0; y0 < g.Height; y0 += clusterSize {
+			addRuns(min(clusterSize, g.Height-y0), func(i int) (data.Position, data.Position) {
+				return data.Position{X: x, Y: y0 + i}, data.Position{X: x + 1, Y: y0 + i}
+			})
+		}
+	}
+	for y := clusterSize - 1; y+1 < g.Height; y += clusterSize {
+		for x0 := 0; x0 < g.Width; x0 += clusterSize {
+			addRuns(min(clusterSize, g.Width-x0), func(i int) (data.Position, data.Position) {
+				return data.Position{X: x0 + i, Y: y}, data.Position{X: x0 + i, Y: y + 1}
+			})
+		}
+	}
+
+	// Nodes, a cell can be the transition of two borders at a cluster corner
+	for _, t := range transitions {
+		cm.nodes = append(cm.nodes, t[0], t[1])
+	}
+	slices.SortFunc(cm.nodes, func(a, b data.Position) int {
+		if c := cm.clusterOf(a) - cm.clusterOf(b); c != 0 {
+			return c
+		}
+		if a.Y != b.Y {
+			return a.Y - b.Y
+		}
+		return a.X - b.X
+	})
+	cm.nodes = slices.Compact(cm.nodes)
+
+	nodeIndex := make(map[data.Position]int32, len(cm.nodes))
+	cm.nodeCluster = make([]int32, len(cm.nodes))
+	cm.clusterStart = make([]int32, len(cm.inner)+1)
+	for i, n := range cm.nodes {
+		nodeIndex[n] = int32(i)
+		cm.nodeCluster[i] = int32(cm.clusterOf(n))
+		cm.clusterStart[cm.nodeCluster[i]+1]++
+	}
+	for c := 1; c < len(cm.clusterStart); c++ {
+		cm.clusterStart[c] += cm.clusterStart[c-1]
+	}
+
+	type link struct {
+		from, to, cost int32
+	}
+	links := make([]link, 0, 2*len(transitions))
+	for _, t := range transitions {
+		a, b := nodeIndex[t[0]], nodeIndex[t[1]]
+		links = append(links,
+			link{from: a, to: b, cost: int32(getCost(g.Get(t[1].X, t[1].Y), canTeleport))},
+			link{from: b, to: a, cost: int32(getCost(g.Get(t[0].X, t[0].Y), canTeleport))},
+		)
+	}
+	slices.SortFunc(links, func(a, b link) int { return int(a.from - b.from) })
+	cm.linkStart = make([]int32, len(cm.nodes)+1)
+	cm.linkTo = make([]int32, len(links))
+	cm.linkCost = make([]int32, len(links))
+	for i, l := range links {
+		cm.linkStart[l.from+1]++
+		cm.linkTo[i] = l.to
+		cm.linkCost[i] = l.cost
+	}
+	for n := 1; n < len(cm.linkStart); n++ {
+		cm.linkStart[n] += cm.linkStart[n-1]
+	}
+
+	return cm
+}
+
+// innerCosts returns the costs from node n to the nodes of its cluster, computing them on g the first time
+func (cm *clusterMap) innerCosts(n int32, g *mapgrid.Grid, search *clusterSearch) []int32 {
+	c := cm.nodeCluster[n]
+	first, last := cm.clusterStart[c], cm.clusterStart[c+1]
+	nodes := last - first
+	if cm.inner[c] == nil {
+		cm.inner[c] = make([]int32, nodes*nodes)
+		for i := range cm.inner[c] {
+			cm.inner[c][i] = unknownCost
+		}
+	}
+
+	costs := cm.inner[c][(n-first)*nodes : (n-first+1)*nodes]
+	if costs[0] == unknownCost {
+		search.init(g, cm.canTeleport, int(c)%cm.clustersX, int(c)/cm.clustersX)
+		search.distances(cm.nodes[n], false)
+		for to := range costs {
+			costs[to] = search.costAt(cm.nodes[first+int32(to)])
+		}
+	}
+
+	return costs
+}
+
+// routeSearch finds the transitions a path goes through with A* on a cluster map, its buffers are reused
+type routeSearch struct {
+	cost   []int32
+	parent []int32
+	toGoal []int32
+	queue  searchQueue
+	route  []int32
+}
+
+// find returns the transitions from the start cluster to the goal cluster, in order
+func (r *routeSearch) find(cm *clusterMap, search *clusterSearch, g *mapgrid.Grid, start, goal data.Position, canTeleport bool) ([]int32, bool) {
+	// The goal is an extra node after the transitions
+	goalNode := int32(len(cm.nodes))
+	r.cost = slices.Grow(r.cost[:0], len(cm.nodes)+1)[:len(cm.nodes)+1]
+	r.parent = slices.Grow(r.parent[:0], len(cm.nodes)+1)[:len(cm.nodes)+1]
+	for i := range r.cost {
+		r.cost[i] = math.MaxInt32
+		r.parent[i] = -1
+	}
+	r.queue = r.queue[:0]
+
+	relax := func(node, parent, cost int32) {
+		if cost >= r.cost[node] {
+			return
+		}
+		r.cost[node] = cost
+		r.parent[node] = parent
+		h := int32(0)
+		if node != goalNode {
+			h = int32(chebyshev(cm.nodes[node], goal))
+		}
+		r.queue.push(searchItem{node: node, cost: cost, priority: cost + h})
+	}
+
+	// Cost from the transitions of the goal cluster to the goal
+	goalCluster := cm.clusterOf(goal)
+	goalFirst, goalLast := cm.clusterStart[goalCluster], cm.clusterStart[goalCluster+1]
+	search.init(g, canTeleport, goalCluster%cm.clustersX, goalCluster/cm.clustersX)
+	search.distances(goal, true)
+	r.toGoal = r.toGoal[:0]
+	for n := goalFirst; n < goalLast; n++ {
+		r.toGoal = append(r.toGoal, search.costAt(cm.nodes[n]))
+	}
+
+	// Cost from the start to the transitions of its cluster
+	startCluster := cm.clusterOf(start)
+	search.init(g, canTeleport, startCluster%cm.clustersX, startCluster/cm.clustersX)
+	search.distances(start, false)
+	for n := cm.clusterStart[startCluster]; n < cm.clusterStart[startCluster+1]; n++ {
+		if cost := search.costAt(cm.nodes[n]); cost >= 0 {
+			relax(n, -1, cost)
+		}
+	}
+
+	for len(r.queue) > 0 {
+		current := r.queue.pop()
+		if current.cost > r.cost[current.node] {
+			continue
+		}
+		if current.node == goalNode {
+			r.route = r.route[:0]
+			for n := r.parent[goalNode]; n >= 0; n = r.parent[n] {
+				r.route = append(r.route, n)
+			}
+			slices.Reverse(r.route)
+
+			return r.route, true
+		}
+
+		if current.node >= goalFirst && current.node < goalLast {
+			if toGoal := r.toGoal[current.node-goalFirst]; toGoal >= 0 {
+				relax(goalNode, current.node, current.cost+toGoal)
+			}
+		}
+		for l := cm.linkStart[current.node]; l < cm.linkStart[current.node+1]; l++ {
+			relax(cm.linkTo[l], current.node, current.cost+cm.linkCost[l])
+		}
+		first := cm.clusterStart[cm.nodeCluster[current.node]]
+		for to, cost := range cm.innerCosts(current.node, g, search) {
+			if next := first + int32(to); next != current.node && cost >= 0 {
+				relax(next, current.node, current.cost+cost)
+			}
+		}
+	}
+
+	return nil, false
+}
+
+func chebyshev(a, b data.Position) int {
+	return max(abs(a.X-b.X), abs(a.Y-b.Y))
+}
+
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
@@ -0,0 +1,161 @@
+package astar
+
+import (
+	"math"
+	"testing"
+
+	"github.com/hectorgimenez/d2go/pkg/data"
+	"github.com/hectorgimenez/koolo/internal/game/mapgrid"
+)
+
+// hierarchicalCostBound is how much more expensive than the plain A* path a hierarchical path may be: routes go
+// through the cluster transitions, every transitionSpacing cells of a border, instead of the best crossing cell. The
+// long diagonal of the open grid is the worst case, about 14% more.
+const hierarchicalCostBound = 1.15
+
+// syntheticGrid returns a walkable grid with the given rectangles (x, y, width, height) set to a collision type
+func syntheticGrid(width, height int, rects ...syntheticRect) *mapgrid.Grid {
+	g := &mapgrid.Grid{Width: width, Height: height, CollisionGrid: make([]mapgrid.CollisionType, width*height)}
+	for i := range g.CollisionGrid {
+		g.CollisionGrid[i] = mapgrid.CollisionTypeWalkable
+	}
+	for _, r := range rects {
+		for y := r.y; y < r.y+r.h; y++ {
+			for x := r.x; x < r.x+r.w; x++ {
+				g.Set(x, y, r.t)
+			}
+		}
+	}
+
+	return g
+}
+
+type syntheticRect struct {
+	x, y, w, h int
+	t          mapgrid.CollisionType
+}
+
+// pathCost is the plain A* cost of a path, the teleport over cells removed from it are counted once per skipped cell
+func pathCost(g *mapgrid.Grid, path []data.Position, canTeleport bool) int {
+	cost := 0
+	for i := 1; i < len(path); i++ {
+		cost += CellCost(g.Get(path[i].X, path[i].Y), canTeleport) + chebyshev(path[i-1], path[i]) - 1
+	}
+
+	return cost
+}
+
+func TestHierarchicalPlannerCost(t *testing.T) {
+	wall := mapgrid.CollisionTypeNonWalkable
+	tests := []struct {
+		name        string
+		grid        *mapgrid.Grid
+		start, goal data.Position
+		teleport    bool
+		found       bool
+	}{
+		{
+			name:  "open",
+			grid:  syntheticGrid(64, 64),
+			start: data.Position{X: 2, Y: 2}, goal: data.Position{X: 61, Y: 60},
+			found: true,
+		},
+		{
+			// A wall with a low priority gap near the straight line and a walkable one further away
+			name: "low priority gap",
+			grid: syntheticGrid(64, 64,
+				syntheticRect{30, 0, 4, 64, wall},
+				syntheticRect{30, 29, 4, 4, mapgrid.CollisionTypeLowPriority},
+				syntheticRect{30, 52, 4, 4, mapgrid.CollisionTypeWalkable},
+			),
+			start: data.Position{X: 5, Y: 30}, goal: data.Position{X: 58, Y: 30},
+			found: true,
+		},
+		{
+			name:  "monsters in the way",
+			grid:  syntheticGrid(64, 64, syntheticRect{20, 14, 24, 36, mapgrid.CollisionTypeMonster}),
+			start: data.Position{X: 10, Y: 32}, goal: data.Position{X: 54, Y: 32},
+			found: true,
+		},
+		{
+			name:  "objects in the way",
+			grid:  syntheticGrid(64, 64, syntheticRect{16, 0, 3, 60, mapgrid.CollisionTypeObject}),
+			start: data.Position{X: 4, Y: 10}, goal: data.Position{X: 40, Y: 12},
+			found: true,
+		},
+		{
+			// Walking goes around the thickened wall through the only opening
+			name: "thickened walk",
+			grid: syntheticGrid(64, 64,
+				syntheticRect{30, 0, 2, 64, mapgrid.CollisionTypeThickened},
+				syntheticRect{30, 58, 2, 3, mapgrid.CollisionTypeWalkable},
+			),
+			start: data.Position{X: 10, Y: 10}, goal: data.Position{X: 50, Y: 10},
+			found: true,
+		},
+		{
+			name: "thickened teleport",
+			grid: syntheticGrid(64, 64,
+				syntheticRect{30, 0, 2, 64, mapgrid.CollisionTypeThickened},
+				syntheticRect{30, 58, 2, 3, mapgrid.CollisionTypeWalkable},
+			),
+			start: data.Position{X: 10, Y: 10}, goal: data.Position{X: 50, Y: 10},
+			teleport: true,
+			found:    true,
+		},
+		{
+			name:  "teleport over river",
+			grid:  syntheticGrid(64, 48, syntheticRect{28, 0, 8, 48, mapgrid.CollisionTypeTeleportOver}),
+			start: data.Position{X: 4, Y: 20}, goal: data.Position{X: 60, Y: 30},
+			teleport: true,
+			found:    true,
+		},
+		{
+			name:  "teleport over river too wide",
+			grid:  syntheticGrid(64, 48, syntheticRect{20, 0, MaxConsecutiveTeleportOver + 2, 48, mapgrid.CollisionTypeTeleportOver}),
+			start: data.Position{X: 4, Y: 20}, goal: data.Position{X: 60, Y: 30},
+			teleport: true,
+			found:    false,
+		},
+		{
+			name:  "walled off",
+			grid:  syntheticGrid(64, 64, syntheticRect{32, 0, 1, 64, wall}),
+			start: data.Position{X: 4, Y: 20}, goal: data.Position{X: 60, Y: 30},
+			found: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plain, _, plainFound := CalculatePath(tt.grid, tt.start, tt.goal, tt.teleport, nil)
+			path, distance, found := NewHierarchicalPlanner().CalculatePath(tt.grid, tt.start, tt.goal, tt.teleport)
+			if plainFound != tt.found || found != tt.found {
+				t.Fatalf("found: plain A* %v, hierarchical %v, want %v", plainFound, found, tt.found)
+			}
+			if !found {
+				return
+			}
+
+			if path[0] != tt.start || path[len(path)-1] != tt.goal || distance != len(path) {
+				t.Fatalf("path from %v to %v with distance %d, want %v to %v with its length %d", path[0], path[len(path)-1], distance, tt.start, tt.goal, len(path))
+			}
+			for i, p := range path {
+				if CellCost(tt.grid.Get(p.X, p.Y), tt.teleport) == math.MaxInt32 {
+					t.Fatalf("path goes through the blocked cell %v", p)
+				}
+				if i > 0 && !tt.teleport && chebyshev(path[i-1], p) != 1 {
+					t.Fatalf("%v and %v are not adjacent", path[i-1], p)
+				}
+			}
+
+			plainCost, cost := pathCost(tt.grid, plain, tt.teleport), pathCost(tt.grid, path, tt.teleport)
+			t.Logf("cost: plain A* %d, hierarchical %d", plainCost, cost)
+			if cost < plainCost {
+				t.Errorf("hierarchical cost %d is below the plain A* one %d, the plain A* path isn't the cheapest", cost, plainCost)
+			}
+			if float64(cost) > float64(plainCost)*hierarchicalCostBound {
+				t.Errorf("hierarchical cost %d, expected at most %.0f%% of the plain A* cost %d", cost, hierarchicalCostBound*100, plainCost)
+			}
+		})
+	}
+}
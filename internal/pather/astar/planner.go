@@ -0,0 +1,39 @@
+package astar
+
+import (
+	"github.com/hectorgimenez/d2go/pkg/data"
+	"github.com/hectorgimenez/koolo/internal/game/mapgrid"
+)
+
+// Planner names, set with the pathfinding.planner option of koolo.yaml
+const (
+	PlannerAStar        = "astar"
+	PlannerHierarchical = "hierarchical"
+)
+
+// Planner computes paths with the CalculatePath contract: start and goal are relative to the grid, the path holds
+// every walked cell (teleport over cells excluded) from start to goal and the distance is its length. Planners keep
+// buffers between calls and are not safe for concurrent use.
+type Planner interface {
+	CalculatePath(g *mapgrid.Grid, start, goal data.Position, canTeleport bool) ([]data.Position, int, bool)
+}
+
+// NewPlanner returns the planner called name, plain A* for an empty or unknown name
+func NewPlanner(name string) Planner {
+	switch name {
+	case PlannerHierarchical:
+		return NewHierarchicalPlanner()
+	default:
+		return &bufferedPlanner{search: CalculatePath}
+	}
+}
+
+// bufferedPlanner runs a search function reusing its buffers across calls
+type bufferedPlanner struct {
+	search  func(g *mapgrid.Grid, start, goal data.Position, canTeleport bool, buffers *AStarBuffers) ([]data.Position, int, bool)
+	buffers AStarBuffers
+}
+
+func (p *bufferedPlanner) CalculatePath(g *mapgrid.Grid, start, goal data.Position, canTeleport bool) ([]data.Position, int, bool) {
+	return p.search(g, start, goal, canTeleport, &p.buffers)
+}
@@ -0,0 +1,135 @@
+package astar
+
+import (
+	"slices"
+
+	"github.com/hectorgimenez/d2go/pkg/data"
+	"github.com/hectorgimenez/koolo/internal/game/mapgrid"
+)
+
+// CalculateTeleportPath returns the landing points of the fewest teleports going from start to goal, both included.
+// A teleport reaches any cell up to teleportRange cells away (euclidean) whatever is in between, but it can only land
+// on cells a character can stand on: never on non walkable or teleport over ones, and only where canLand allows it
+// (nil allows every cell, start and goal are never checked). The hop count is len(path) - 1.
+func CalculateTeleportPath(g *mapgrid.Grid, start, goal data.Position, teleportRange int, canLand func(data.Position) bool) ([]data.Position, bool) {
+	if g == nil || teleportRange <= 0 {
+		return nil, false
+	}
+	inBounds := func(p data.Position) bool {
+		return p.X >= 0 && p.Y >= 0 && p.X < g.Width && p.Y < g.Height
+	}
+	if !inBounds(start) || !inBounds(goal) || !landable(g.Get(goal.X, goal.Y)) {
+		return nil, false
+	}
+	if start == goal {
+		return []data.Position{start}, true
+	}
+
+	// A* where every teleport costs 1 and the heuristic is the fewest teleports left if nothing was in the way
+	hops := make([]int32, g.Width*g.Height)
+	parent := make([]int32, g.Width*g.Height)
+	for i := range hops {
+		hops[i] = -1
+	}
+	rangeSquared := teleportRange * teleportRange
+	toGoal := func(p data.Position) int {
+		dx, dy := p.X-goal.X, p.Y-goal.Y
+		// Smallest n with n*n*range*range >= distance*distance
+		n := 0
+		for n*n*rangeSquared < dx*dx+dy*dy {
+			n++
+		}
+		return n
+	}
+
+	// buckets[f] holds the cells to expand with f teleports estimated from start to goal
+	var buckets [][]data.Position
+	push := func(p data.Position, hop int32) {
+		f := int(hop) + toGoal(p)
+		for len(buckets) <= f {
+			buckets = append(buckets, nil)
+		}
+		buckets[f] = append(buckets[f], p)
+	}
+	hops[start.Y*g.Width+start.X] = 0
+	push(start, 0)
+
+	halfWidths := teleportDisk(teleportRange)
+	for f := 0; f < len(buckets); f++ {
+		for len(buckets[f]) > 0 {
+			from := buckets[f][len(buckets[f])-1]
+			buckets[f] = buckets[f][:len(buckets[f])-1]
+			fromIdx := int32(from.Y*g.Width + from.X)
+			hop := hops[fromIdx]
+			if int(hop)+toGoal(from) != f {
+				// Reached with fewer teleports after being queued
+				continue
+			}
+
+			for dy, w := range halfWidths {
+				y := from.Y + dy - teleportRange
+				if y < 0 || y >= g.Height {
+					continue
+				}
+				row := y * g.Width
+				for x := max(from.X-w, 0); x <= min(from.X+w, g.Width-1); x++ {
+					i := row + x
+					if (hops[i] >= 0 && hops[i] <= hop+1) || !landable(g.CollisionGrid[i]) {
+						continue
+					}
+					to := data.Position{X: x, Y: y}
+					if to != goal && canLand != nil && !canLand(to) {
+						continue
+					}
+
+					hops[i] = hop + 1
+					parent[i] = fromIdx
+					if to == goal {
+						return teleportLandings(g, parent, start, goal), true
+					}
+					push(to, hop+1)
+				}
+			}
+		}
+	}
+
+	return nil, false
+}
+
+// landable reports whether a teleport can end on cells of type t, walls (thickened ones too), objects and teleport
+// over cells can't be landed on
+func landable(t mapgrid.CollisionType) bool {
+	switch t {
+	case mapgrid.CollisionTypeNonWalkable, mapgrid.CollisionTypeTeleportOver, mapgrid.CollisionTypeThickened,
+		mapgrid.CollisionTypeObject:
+		return false
+	default:
+		return true
+	}
+}
+
+// teleportDisk returns the half width of every row of the cells within r, from -r to r
+func teleportDisk(r int) []int {
+	halfWidths := make([]int, 2*r+1)
+	for dy := -r; dy <= r; dy++ {
+		w := 0
+		for (w+1)*(w+1)+dy*dy <= r*r {
+			w++
+		}
+		halfWidths[dy+r] = w
+	}
+
+	return halfWidths
+}
+
+func teleportLandings(g *mapgrid.Grid, parent []int32, start, goal data.Position) []data.Position {
+	landings := []data.Position{goal}
+	for p := goal; p != start; {
+		i := int(parent[p.Y*g.Width+p.X])
+		p = data.Position{X: i % g.Width, Y: i / g.Width}
+		landings = append(landings, p)
+	}
+	slices.Reverse(landings)
+
+	return landings
+}
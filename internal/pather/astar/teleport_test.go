@@ -0,0 +1,136 @@
+package astar
+
+import (
+	"testing"
+
+	"github.com/hectorgimenez/d2go/pkg/data"
+	"github.com/hectorgimenez/koolo/internal/game/mapgrid"
+	"github.com/hectorgimenez/koolo/internal/pather/corpus"
+)
+
+const testTeleportRange = 17
+
+func TestTeleportCorpus(t *testing.T) {
+	cases, err := corpus.Load(corpus.Dir())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, c := range cases {
+		if !c.Teleport {
+			continue
+		}
+		t.Run(c.Name, func(t *testing.T) {
+			grid, from, to, err := c.PathGrid()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			landings, found := CalculateTeleportPath(grid, from, to, testTeleportRange, nil)
+			if !found {
+				t.Fatalf("no teleport path found from %v to %v", c.From, c.To)
+			}
+			checkLandings(t, grid, from, to, landings, testTeleportRange)
+
+			// Casting along the walking path can't take fewer teleports
+			path, _, _ := CalculatePath(grid, from, to, true, nil)
+			if greedy := greedyTeleports(grid, path, testTeleportRange); len(landings)-1 > greedy {
+				t.Errorf("%d teleports, casting along the walking path takes %d", len(landings)-1, greedy)
+			}
+			if c.MaxTeleports > 0 && len(landings)-1 > c.MaxTeleports {
+				t.Errorf("%d teleports, expected at most %d", len(landings)-1, c.MaxTeleports)
+			}
+		})
+	}
+}
+
+func BenchmarkTeleportCorpus(b *testing.B) {
+	cases, err := corpus.Load(corpus.Dir())
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	for _, c := range cases {
+		if !c.Teleport {
+			continue
+		}
+		b.Run(c.Name, func(b *testing.B) {
+			grid, from, to, err := c.PathGrid()
+			if err != nil {
+				b.Fatal(err)
+			}
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				CalculateTeleportPath(grid, from, to, testTeleportRange, nil)
+			}
+		})
+	}
+}
+
+func TestTeleportOverCells(t *testing.T) {
+	// A 5 cells wide river, teleports must cross it in one cast
+	g := &mapgrid.Grid{Width: 15, Height: 3, CollisionGrid: make([]mapgrid.CollisionType, 45)}
+	for y := 0; y < g.Height; y++ {
+		for x := 0; x < g.Width; x++ {
+			if x >= 5 && x < 10 {
+				g.Set(x, y, mapgrid.CollisionTypeTeleportOver)
+			} else {
+				g.Set(x, y, mapgrid.CollisionTypeWalkable)
+			}
+		}
+	}
+	start, goal := data.Position{X: 0, Y: 1}, data.Position{X: 14, Y: 1}
+
+	if _, found := CalculateTeleportPath(g, start, goal, 5, nil); found {
+		t.Error("a range of 5 can't cross the river")
+	}
+	landings, found := CalculateTeleportPath(g, start, goal, 6, nil)
+	if !found {
+		t.Fatal("a range of 6 crosses the river")
+	}
+	checkLandings(t, g, start, goal, landings, 6)
+	if len(landings) != 4 {
+		t.Errorf("expected 3 teleports, got %v", landings)
+	}
+
+	// Landings outside the allowed ones make the path longer
+	canLand := func(p data.Position) bool { return p.X != 4 }
+	if landings, found = CalculateTeleportPath(g, start, goal, 6, canLand); found {
+		t.Errorf("the only landing before the river is forbidden, got %v", landings)
+	}
+}
+
+func checkLandings(t *testing.T, g *mapgrid.Grid, start, goal data.Position, landings []data.Position, teleportRange int) {
+	t.Helper()
+
+	if landings[0] != start || landings[len(landings)-1] != goal {
+		t.Fatalf("landings go from %v to %v, expected %v to %v", landings[0], landings[len(landings)-1], start, goal)
+	}
+	for i := 1; i < len(landings); i++ {
+		dx, dy := landings[i].X-landings[i-1].X, landings[i].Y-landings[i-1].Y
+		if dx*dx+dy*dy > teleportRange*teleportRange {
+			t.Errorf("%v is out of range from %v", landings[i], landings[i-1])
+		}
+		if !landable(g.Get(landings[i].X, landings[i].Y)) {
+			t.Errorf("%v can't be landed on", landings[i])
+		}
+	}
+}
+
+// greedyTeleports counts the casts jumping every time to the farthest cell of path within range
+func greedyTeleports(g *mapgrid.Grid, path []data.Position, teleportRange int) int {
+	casts := 0
+	for from := 0; from < len(path)-1; casts++ {
+		next := from + 1
+		for i := from + 1; i < len(path); i++ {
+			dx, dy := path[i].X-path[from].X, path[i].Y-path[from].Y
+			if dx*dx+dy*dy <= teleportRange*teleportRange && landable(g.Get(path[i].X, path[i].Y)) {
+				next = i
+			}
+		}
+		from = next
+	}
+
+	return casts
+}
@@ -0,0 +1,27 @@
+package astar
+
+import (
+	"crypto/sha256"
+	"embed"
+	"encoding/hex"
+	"io/fs"
+)
+
+// sources are the files of the package, every non test one must be listed (TestVersionSources checks it)
+//
+//go:embed astar.go cluster_search.go hierarchical.go planner.go priority_queue.go teleport.go
+var sources embed.FS
+
+// Version identifies the path planning code, it changes with any change of the package sources. Paths kept between
+// runs (like the path cache) are only valid for the version they were computed with.
+var Version = func() string {
+	h := sha256.New()
+	files, _ := fs.Glob(sources, "*.go")
+	for _, name := range files {
+		content, _ := sources.ReadFile(name)
+		h.Write([]byte(name))
+		h.Write(content)
+	}
+
+	return hex.EncodeToString(h.Sum(nil))[:12]
+}()
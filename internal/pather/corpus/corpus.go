@@ -0,0 +1,163 @@
+// Package corpus loads the recorded grids and the golden expectations the path finders are tested and benchmarked
+// against. Grids are mapgrid dumps exported from a live session with the "Export Grid" button of the debug page
+// (/debug-grid), cases are listed in grids/cases.json.
+//
+// The corpus only holds the legacy Durance of Hate grid so far, as recorded and cut in two areas to go through the
+// merge of adjacent levels. The areas listed in Missing aren't covered until their grids are exported in game and
+// added here.
+package corpus
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sync"
+
+	"github.com/hectorgimenez/d2go/pkg/data"
+	"github.com/hectorgimenez/koolo/internal/game/mapgrid"
+)
+
+// Missing lists the tricky areas without a recorded grid yet, the golden tests report them as skipped so the gap
+// stays visible. Remove an entry once its grid and cases are added.
+var Missing = []string{
+	"arcane-sanctuary",
+	"lut-gholein",
+	"maggot-lair",
+	"act5-barricades",
+	"merged-adjacent-levels",
+}
+
+// Case is a path request with its expected outcome, positions are world coordinates
+type Case struct {
+	Name     string        `json:"name"`
+	Dump     string        `json:"dump"`
+	From     data.Position `json:"from"`
+	To       data.Position `json:"to"`
+	Teleport bool          `json:"teleport"`
+	// MaxLength is the longest acceptable path, in cells
+	MaxLength int `json:"maxLength"`
+	// MinClearance is the minimum distance in cells between the walked cells and a wall, see Clearance
+	MinClearance int `json:"minClearance"`
+	// MaxTeleports is the most casts acceptable for teleport cases, see astar.CalculateTeleportPath
+	MaxTeleports int    `json:"maxTeleports,omitempty"`
+	Note         string `json:"note,omitempty"`
+
+	dir string
+}
+
+// Dir returns the folder holding the corpus grids
+func Dir() string {
+	_, file, _, _ := runtime.Caller(0)
+	return filepath.Join(filepath.Dir(file), "grids")
+}
+
+// Load reads the cases of dir/cases.json
+func Load(dir string) ([]Case, error) {
+	content, err := os.ReadFile(filepath.Join(dir, "cases.json"))
+	if err != nil {
+		return nil, err
+	}
+
+	var cases []Case
+	if err = json.Unmarshal(content, &cases); err != nil {
+		return nil, fmt.Errorf("error reading cases.json: %w", err)
+	}
+	for i := range cases {
+		cases[i].dir = dir
+	}
+
+	return cases, nil
+}
+
+var (
+	dumpsMu sync.Mutex
+	dumps   = map[string]mapgrid.Dump{}
+)
+
+// LoadDump reads a dump of the corpus, dumps are shared by the cases so they are only decoded once
+func LoadDump(dir, name string) (mapgrid.Dump, error) {
+	path := filepath.Join(dir, name)
+	dumpsMu.Lock()
+	defer dumpsMu.Unlock()
+	if d, found := dumps[path]; found {
+		return d, nil
+	}
+
+	d, err := mapgrid.LoadDump(path)
+	if err != nil {
+		return mapgrid.Dump{}, err
+	}
+	dumps[path] = d
+
+	return d, nil
+}
+
+// Scene returns the path finder input of the case
+func (c Case) Scene() (mapgrid.Scene, error) {
+	d, err := LoadDump(c.dir, c.Dump)
+	if err != nil {
+		return mapgrid.Scene{}, err
+	}
+	s, err := d.Scene()
+	if err != nil {
+		return mapgrid.Scene{}, fmt.Errorf("%s: %w", c.Dump, err)
+	}
+	s.CanTeleport = c.Teleport
+
+	return s, nil
+}
+
+// PathGrid returns the grid the live path finder would path on for the case, with From and To relative to it
+func (c Case) PathGrid() (*mapgrid.Grid, data.Position, data.Position, error) {
+	s, err := c.Scene()
+	if err != nil {
+		return nil, data.Position{}, data.Position{}, err
+	}
+
+	return s.PathGrid(c.From, c.To)
+}
+
+// Clearance returns the smallest distance (in cells, diagonals count as one) between a path cell and a non walkable
+// cell of the grid, path positions are relative to the grid. 1 means the path runs along a wall, 0 that it goes
+// through a non walkable cell (teleport), it's capped to 5.
+func Clearance(g *mapgrid.Grid, path []data.Position) int {
+	const maxClearance = 5
+
+	clearance := maxClearance
+	for _, p := range path {
+		for r := 0; r < clearance; r++ {
+			if wallWithin(g, p, r) {
+				clearance = r
+				break
+			}
+		}
+	}
+
+	return clearance
+}
+
+// wallWithin reports whether the ring at distance r of p holds a non walkable cell, outside the grid counts as a wall
+func wallWithin(g *mapgrid.Grid, p data.Position, r int) bool {
+	for dy := -r; dy <= r; dy++ {
+		for dx := -r; dx <= r; dx++ {
+			if max(abs(dx), abs(dy)) != r {
+				continue
+			}
+			x, y := p.X+dx, p.Y+dy
+			if x < 0 || y < 0 || x >= g.Width || y >= g.Height || g.Get(x, y) == mapgrid.CollisionTypeNonWalkable {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
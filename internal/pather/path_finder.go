@@ -1,35 +1,52 @@
 package pather
 
 import (
-	"fmt"
+	"log/slog"
+	"math"
+	"path/filepath"
+	"sync"
 
 	"github.com/hectorgimenez/d2go/pkg/data"
-	"github.com/hectorgimenez/d2go/pkg/data/area"
-	"github.com/hectorgimenez/d2go/pkg/data/npc"
 	"github.com/hectorgimenez/koolo/internal/config"
 	"github.com/hectorgimenez/koolo/internal/game"
+	"github.com/hectorgimenez/koolo/internal/game/mapgrid"
 	"github.com/hectorgimenez/koolo/internal/pather/astar"
+	"github.com/hectorgimenez/koolo/internal/pather/pathcache"
 )
 
+// pathCacheEntries is the number of paths and room orders kept on disk
+const pathCacheEntries = 5000
+
+// sharedPathCache is opened on first use and shared by every supervisor, nil when it can't be opened
+var sharedPathCache = sync.OnceValue(func() *pathcache.Cache {
+	c, err := pathcache.Open(filepath.Join("cache", "paths"), pathCacheEntries)
+	if err != nil {
+		slog.Warn("Path cache disabled", slog.Any("error", err))
+		return nil
+	}
+
+	return c
+})
+
 type PathFinder struct {
 	gr           *game.MemoryReader
 	data         *game.Data
 	hid          *game.HID
 	cfg          *config.CharacterCfg
 	packetSender *game.PacketSender
-	// astarBuffers are reusable A* buffers to avoid allocations. Thread-safe without
-	// synchronization because pathfinding is only called from the PriorityNormal goroutine
+	// planner keeps reusable buffers (and the cluster abstraction for the hierarchical one) between calls. Thread-safe
+	// without synchronization because pathfinding is only called from the PriorityNormal goroutine
 	// (main bot loop). Background goroutines (data refresh, health check) do not perform pathfinding.
-	astarBuffers *astar.AStarBuffers
+	planner astar.Planner
 }
 
 func NewPathFinder(gr *game.MemoryReader, data *game.Data, hid *game.HID, cfg *config.CharacterCfg) *PathFinder {
 	return &PathFinder{
-		gr:           gr,
-		data:         data,
-		hid:          hid,
-		cfg:          cfg,
-		astarBuffers: &astar.AStarBuffers{},
+		gr:      gr,
+		data:    data,
+		hid:     hid,
+		cfg:     cfg,
+		planner: astar.NewPlanner(config.Koolo.Pathfinding.Planner),
 	}
 }
 
@@ -57,174 +74,131 @@ func (pf *PathFinder) GetPath(to data.Position) (Path, int, bool) {
 }
 
 func (pf *PathFinder) GetPathFrom(from, to data.Position) (Path, int, bool) {
-	a := pf.data.AreaData
 	canTeleport := pf.data.CanTeleport()
 
-	// We don't want to modify the original grid
-	grid := a.Grid.Copy()
+	grid, from, to, err := pf.scene(canTeleport).PathGrid(from, to)
+	if err != nil {
+		return nil, 0, false
+	}
 
-	// Special handling for Arcane Sanctuary (to allow pathing with platforms)
-	if pf.data.PlayerUnit.Area == area.ArcaneSanctuary && pf.data.CanTeleport() {
-		// Make all non-walkable tiles into low priority tiles for teleport pathing
-		for y := 0; y < grid.Height; y++ {
-			for x := 0; x < grid.Width; x++ {
-				if grid.Get(x, y) == game.CollisionTypeNonWalkable {
-					grid.Set(x, y, game.CollisionTypeLowPriority)
-				}
-			}
-		}
+	path, distance, found := pf.cachedPath(grid, from, to, canTeleport)
+
+	if config.Koolo.Debug.RenderMap {
+		pf.renderMap(grid, from, to, path)
 	}
-	// Lut Gholein map is a bit bugged, we should close this fake path to avoid pathing issues.
-	// Apply to the local copy, not the shared a.Grid, to avoid permanently mutating live grid data.
-	if a.Area == area.LutGholein {
-		if 210 < grid.Width && 13 < grid.Height {
-			grid.Set(210, 13, game.CollisionTypeNonWalkable)
-		}
+
+	return path, distance, found
+}
+
+// cachedPath reuses the path cached for the regions of from and to, or computes it and caches it. Only paths inside
+// the current area are cached, merged grids depend on the adjacent levels.
+func (pf *PathFinder) cachedPath(grid *mapgrid.Grid, from, to data.Position, canTeleport bool) (Path, int, bool) {
+	a := pf.data.AreaData
+	cache := pf.pathCache()
+	if cache == nil || grid.OffsetX != a.OffsetX || grid.OffsetY != a.OffsetY || grid.Width != a.Width || grid.Height != a.Height {
+		return pf.planner.CalculatePath(grid, from, to, canTeleport)
 	}
 
-	if !a.IsInside(to) {
-		expandedGrid, err := pf.mergeGrids(to, canTeleport)
-		if err != nil {
-			return nil, 0, false
+	key := pf.cacheKey(pathcache.RegionOf(from), pathcache.RegionOf(to), canTeleport)
+	if cached, found := cache.Path(key); found {
+		connect := func(from, to data.Position) ([]data.Position, bool) {
+			path, _, found := pf.planner.CalculatePath(grid, from, to, canTeleport)
+			return path, found
+		}
+		if path, found := pathcache.Splice(grid, from, to, cached, canTeleport, connect); found {
+			return path, len(path), true
 		}
-		grid = expandedGrid
 	}
 
-	if !grid.IsWalkable(to) {
-		if walkableTo, found := pf.findNearbyWalkablePositionInGrid(grid, to); found {
-			to = walkableTo
+	path, distance, found := pf.planner.CalculatePath(grid, from, to, canTeleport)
+	if found {
+		if err := cache.PutPath(key, path); err != nil {
+			slog.Debug("Error caching path", slog.Any("error", err))
 		}
 	}
-	from = grid.RelativePosition(from)
-	to = grid.RelativePosition(to)
 
-	// Add objects to the collision grid as obstacles
-	for _, o := range pf.data.AreaData.Objects {
-		if !grid.IsWalkable(o.Position) {
-			continue
-		}
-		relativePos := grid.RelativePosition(o.Position)
-		if relativePos.X < 0 || relativePos.X >= grid.Width || relativePos.Y < 0 || relativePos.Y >= grid.Height {
-			continue
-		}
-		grid.Set(relativePos.X, relativePos.Y, game.CollisionTypeObject)
-		for i := -2; i <= 2; i++ {
-			for j := -2; j <= 2; j++ {
-				if i == 0 && j == 0 {
-					continue
-				}
-				ny, nx := relativePos.Y+i, relativePos.X+j
-				if ny < 0 || ny >= grid.Height || nx < 0 || nx >= grid.Width {
-					continue
-				}
-				if grid.Get(nx, ny) == game.CollisionTypeWalkable {
-					grid.Set(nx, ny, game.CollisionTypeLowPriority)
-				}
-			}
-		}
+	return path, distance, found
+}
+
+// GetTeleportPath returns the landing points of the fewest teleports from the player to the destination, the start
+// included, and the number of casts. Landings stay on screen so moveThroughPathTeleport can click the next one.
+func (pf *PathFinder) GetTeleportPath(to data.Position) (Path, int, bool) {
+	grid, from, to, err := pf.scene(true).PathGrid(pf.data.PlayerUnit.Position, to)
+	if err != nil {
+		return nil, 0, false
 	}
 
-	// Add monsters to the collision grid as obstacles
-	for _, m := range pf.data.Monsters {
-		if !grid.IsWalkable(m.Position) {
-			continue
+	teleportRange := pf.teleportRange()
+	if pf.cfg.PacketCasting.UseForTeleport && pf.packetSender != nil && !pf.isMouseClickTeleportZone() {
+		// Packet teleports fall back to clicks near the area boundaries, avoid landing there while possible
+		awayFromBoundary := func(p data.Position) bool {
+			return !pf.isNearAreaBoundary(data.Position{X: p.X + grid.OffsetX, Y: p.Y + grid.OffsetY}, 60)
 		}
-		relativePos := grid.RelativePosition(m.Position)
-		if relativePos.X < 0 || relativePos.X >= grid.Width || relativePos.Y < 0 || relativePos.Y >= grid.Height {
-			continue
+		if landings, found := astar.CalculateTeleportPath(grid, from, to, teleportRange, awayFromBoundary); found {
+			return landings, len(landings) - 1, true
 		}
-		grid.Set(relativePos.X, relativePos.Y, game.CollisionTypeMonster)
 	}
 
-	// set barricade tower as non walkable in act 5
-	if a.Area == area.FrigidHighlands || a.Area == area.FrozenTundra || a.Area == area.ArreatPlateau {
-		towerCount := 0
-		for _, n := range pf.data.NPCs {
-			if n.ID != npc.BarricadeTower {
-				continue
-			}
-			if len(n.Positions) == 0 {
-				continue
-			}
-			npcPos := n.Positions[0]
-			relativePos := grid.RelativePosition(npcPos)
-			towerCount++
-
-			// Set a 5x5 area around the barricade tower as non-walkable
-			blockedCells := 0
-			for dy := -2; dy <= 2; dy++ {
-				for dx := -2; dx <= 2; dx++ {
-					towerY := relativePos.Y + dy
-					towerX := relativePos.X + dx
-
-					// Bounds checking to prevent array index out of bounds
-					if towerY >= 0 && towerY < grid.Height &&
-						towerX >= 0 && towerX < grid.Width {
-						grid.Set(towerX, towerY, game.CollisionTypeNonWalkable)
-						blockedCells++
-					}
-				}
-			}
-		}
+	landings, found := astar.CalculateTeleportPath(grid, from, to, teleportRange, nil)
+	if !found {
+		return nil, 0, false
 	}
 
-	path, distance, found := astar.CalculatePath(grid, from, to, canTeleport, pf.astarBuffers)
+	return landings, len(landings) - 1, true
+}
 
-	if config.Koolo.Debug.RenderMap {
-		pf.renderMap(grid, from, to, path)
-	}
+// teleportRange returns the farthest distance in cells a teleport can be clicked at in every direction: inside the
+// game window and above the HUD, see gameCoordsToScreenCords
+func (pf *PathFinder) teleportRange() int {
+	halfWidth := float64(pf.gr.GameAreaSizeX) / 2
+	halfHeight := min(float64(pf.gr.GameAreaSizeY)/2, float64(pf.gr.GameAreaSizeY)/1.19-float64(pf.gr.GameAreaSizeY)/2)
 
-	return path, distance, found
+	// A cell r cells away is at most r*19.8*sqrt(2) pixels away horizontally and r*9.9*sqrt(2) vertically
+	return int(min(halfWidth/(19.8*math.Sqrt2), halfHeight/(9.9*math.Sqrt2)))
 }
 
-func (pf *PathFinder) mergeGrids(to data.Position, canTeleport bool) (*game.Grid, error) {
-	for _, a := range pf.data.AreaData.AdjacentLevels {
-		destination, exists := pf.data.Areas[a.Area]
-		if !exists || destination.Grid == nil || destination.Grid.CollisionGrid == nil {
-			continue
-		}
-		if destination.IsInside(to) {
-			origin := pf.data.AreaData
-
-			endX1 := origin.OffsetX + origin.Grid.Width
-			endY1 := origin.OffsetY + origin.Grid.Height
-			endX2 := destination.OffsetX + destination.Grid.Width
-			endY2 := destination.OffsetY + destination.Grid.Height
-
-			minX := min(origin.OffsetX, destination.OffsetX)
-			minY := min(origin.OffsetY, destination.OffsetY)
-			maxX := max(endX1, endX2)
-			maxY := max(endY1, endY2)
-
-			width := maxX - minX
-			height := maxY - minY
-
-			// Use 2D array for NewGrid compatibility (it converts to flat internally)
-			resultGrid := make([][]game.CollisionType, height)
-			for i := range resultGrid {
-				resultGrid[i] = make([]game.CollisionType, width)
-			}
+// pathCache returns the path cache, nil when disabled
+func (pf *PathFinder) pathCache() *pathcache.Cache {
+	if !config.Koolo.Pathfinding.Cache {
+		return nil
+	}
 
-			// Let's copy both grids into the result grid
-			copyGridFlat(resultGrid, origin.Grid, origin.OffsetX-minX, origin.OffsetY-minY)
-			copyGridFlat(resultGrid, destination.Grid, destination.OffsetX-minX, destination.OffsetY-minY)
+	return sharedPathCache()
+}
 
-			grid := game.NewGrid(resultGrid, minX, minY, canTeleport)
+func (pf *PathFinder) cacheKey(from, to pathcache.Region, canTeleport bool) pathcache.Key {
+	a := pf.data.AreaData.Area
+	seed := pf.gr.MapSeed()
+	if pathcache.FixedLayout(a) {
+		seed = 0
+	}
 
-			return grid, nil
-		}
+	planner := config.Koolo.Pathfinding.Planner
+	if planner != astar.PlannerHierarchical {
+		planner = astar.PlannerAStar
 	}
 
-	return nil, fmt.Errorf("destination grid not found")
+	return pathcache.Key{Seed: seed, Difficulty: pf.cfg.Game.Difficulty, Area: a, From: from, To: to, Teleport: canTeleport, Planner: planner}
 }
 
-// copyGridFlat copies from a flat Grid to a 2D destination array
-func copyGridFlat(dest [][]game.CollisionType, src *game.Grid, offsetX, offsetY int) {
-	for y := 0; y < src.Height; y++ {
-		for x := 0; x < src.Width; x++ {
-			dest[offsetY+y][offsetX+x] = src.Get(x, y)
+// scene returns the current map state as the path grid input, nothing is copied until PathGrid is called
+func (pf *PathFinder) scene(canTeleport bool) mapgrid.Scene {
+	a := pf.data.AreaData
+	s := mapgrid.Scene{
+		Area:        a.Area,
+		Grid:        a.Grid,
+		Objects:     a.Objects,
+		Monsters:    pf.data.Monsters,
+		NPCs:        pf.data.NPCs,
+		CanTeleport: canTeleport,
+	}
+	for _, l := range a.AdjacentLevels {
+		if destination, exists := pf.data.Areas[l.Area]; exists && destination.Grid != nil {
+			s.Adjacent = append(s.Adjacent, mapgrid.Level{Area: l.Area, Grid: destination.Grid})
 		}
 	}
+
+	return s
 }
 
 func (pf *PathFinder) GetClosestWalkablePath(dest data.Position) (Path, int, bool) {
@@ -265,25 +239,7 @@ func (pf *PathFinder) GetClosestWalkablePathFrom(from, dest data.Position) (Path
 	return nil, 0, false
 }
 
-func (pf *PathFinder) findNearbyWalkablePositionInGrid(grid *game.Grid, target data.Position) (data.Position, bool) {
-	// Search in expanding squares around the target position
-	for radius := 1; radius <= 3; radius++ {
-		for x := -radius; x <= radius; x++ {
-			for y := -radius; y <= radius; y++ {
-				if x == 0 && y == 0 {
-					continue
-				}
-				pos := data.Position{X: target.X + x, Y: target.Y + y}
-				if (*grid).IsWalkable(pos) {
-					return pos, true
-				}
-			}
-		}
-	}
-	return data.Position{}, false
-}
-
 func (pf *PathFinder) findNearbyWalkablePosition(target data.Position) (data.Position, bool) {
 
-	return pf.findNearbyWalkablePositionInGrid(pf.data.AreaData.Grid, target)
+	return pf.data.AreaData.Grid.NearbyWalkable(target)
 }
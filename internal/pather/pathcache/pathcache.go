@@ -0,0 +1,296 @@
+// Package pathcache keeps computed paths and room traversal orders on disk, so they are reused by every supervisor
+// and across games with the same map seed. Entries are one small file each, the least recently used ones are removed
+// when there are too many, and they are dropped as a whole when the grid processing or the path planning code changes
+// (mapgrid.Version and astar.Version). It's off by default, BenchmarkPath measures a lookup (a file read and two short
+// searches joining the cached path) against computing the path.
+package pathcache
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/hectorgimenez/d2go/pkg/data"
+	"github.com/hectorgimenez/d2go/pkg/data/area"
+	"github.com/hectorgimenez/d2go/pkg/data/difficulty"
+	"github.com/hectorgimenez/koolo/internal/game/mapgrid"
+	"github.com/hectorgimenez/koolo/internal/pather/astar"
+)
+
+// version is the folder of the entries, they are computed by both the grid processing and the path planning code
+var version = mapgrid.Version + "-" + astar.Version
+
+// RegionSize is the side in cells of the regions paths are cached between
+const RegionSize = 8
+
+// Region is a RegionSize x RegionSize block of cells
+type Region struct {
+	X, Y int
+}
+
+// RegionOf returns the region of p
+func RegionOf(p data.Position) Region {
+	return Region{X: floorDiv(p.X, RegionSize), Y: floorDiv(p.Y, RegionSize)}
+}
+
+// Key identifies a cached entry, Seed is 0 for the areas with a fixed layout (see FixedLayout)
+type Key struct {
+	Seed       uint
+	Difficulty difficulty.Difficulty
+	Area       area.ID
+	From, To   Region
+	Teleport   bool
+	Planner    string // astar.NewPlanner name, planners don't find the same paths
+}
+
+func (k Key) fileName(kind string) string {
+	movement := "walk"
+	if k.Teleport {
+		movement = "teleport"
+	}
+
+	return fmt.Sprintf("%s_%d_%s_%d_%d.%d_%d.%d_%s_%s.json", kind, k.Seed, k.Difficulty, k.Area,
+		k.From.X, k.From.Y, k.To.X, k.To.Y, movement, k.Planner)
+}
+
+// FixedLayout reports whether area a has the same map whatever the seed, its entries are shared by every game
+func FixedLayout(a area.ID) bool {
+	switch a {
+	case area.LutGholein, area.KurastDocks, area.ThePandemoniumFortress, area.Harrogath,
+		area.DuranceOfHateLevel3, area.TheWorldstoneChamber:
+		return true
+	}
+
+	return false
+}
+
+// Cache is safe for concurrent use
+type Cache struct {
+	dir        string
+	maxEntries int
+
+	mu      sync.Mutex
+	entries int
+}
+
+// Open opens the cache stored in dir, entries of other grid processing or path planning versions are removed
+func Open(dir string, maxEntries int) (*Cache, error) {
+	if maxEntries <= 0 {
+		return nil, errors.New("the path cache needs room for at least one entry")
+	}
+
+	folders, err := os.ReadDir(dir)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	for _, f := range folders {
+		if f.IsDir() && f.Name() != version {
+			if err = os.RemoveAll(filepath.Join(dir, f.Name())); err != nil {
+				return nil, fmt.Errorf("error removing outdated path cache: %w", err)
+			}
+		}
+	}
+
+	c := &Cache{dir: filepath.Join(dir, version), maxEntries: maxEntries}
+	if err = os.MkdirAll(c.dir, os.ModePerm); err != nil {
+		return nil, err
+	}
+	files, err := os.ReadDir(c.dir)
+	if err != nil {
+		return nil, err
+	}
+	c.entries = len(files)
+
+	return c, nil
+}
+
+// cachedPath stores a path as its first cell and the offsets to every next one, it keeps the files small
+type cachedPath struct {
+	Start data.Position `json:"start"`
+	Steps []int         `json:"steps"`
+}
+
+// Path returns the path cached for k
+func (c *Cache) Path(k Key) ([]data.Position, bool) {
+	var cached cachedPath
+	if !c.read(k.fileName("path"), &cached) || len(cached.Steps)%2 != 0 {
+		return nil, false
+	}
+
+	path := make([]data.Position, 0, len(cached.Steps)/2+1)
+	path = append(path, cached.Start)
+	for i := 0; i < len(cached.Steps); i += 2 {
+		last := path[len(path)-1]
+		path = append(path, data.Position{X: last.X + cached.Steps[i], Y: last.Y + cached.Steps[i+1]})
+	}
+
+	return path, true
+}
+
+// PutPath caches path for k
+func (c *Cache) PutPath(k Key, path []data.Position) error {
+	if len(path) == 0 {
+		return nil
+	}
+
+	cached := cachedPath{Start: path[0], Steps: make([]int, 0, 2*len(path))}
+	for i := 1; i < len(path); i++ {
+		cached.Steps = append(cached.Steps, path[i].X-path[i-1].X, path[i].Y-path[i-1].Y)
+	}
+
+	return c.write(k.fileName("path"), cached)
+}
+
+// RoomOrder returns the room traversal order cached for k
+func (c *Cache) RoomOrder(k Key) ([]data.Room, bool) {
+	var rooms []data.Room
+	if !c.read(k.fileName("rooms"), &rooms) {
+		return nil, false
+	}
+
+	return rooms, true
+}
+
+// PutRoomOrder caches the room traversal order for k
+func (c *Cache) PutRoomOrder(k Key, rooms []data.Room) error {
+	return c.write(k.fileName("rooms"), rooms)
+}
+
+func (c *Cache) read(name string, v any) bool {
+	file := filepath.Join(c.dir, name)
+	content, err := os.ReadFile(file)
+	if err != nil || json.Unmarshal(content, v) != nil {
+		return false
+	}
+
+	// The modification time orders the entries for the eviction
+	now := time.Now()
+	_ = os.Chtimes(file, now, now)
+
+	return true
+}
+
+func (c *Cache) write(name string, v any) error {
+	content, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	file := filepath.Join(c.dir, name)
+	_, statErr := os.Stat(file)
+	// Written aside and renamed so readers never see a partial file
+	tmp := file + ".tmp"
+	if err = os.WriteFile(tmp, content, 0o644); err != nil {
+		return err
+	}
+	if err = os.Rename(tmp, file); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if statErr != nil {
+		c.entries++
+	}
+	if c.entries > c.maxEntries {
+		return c.evict()
+	}
+
+	return nil
+}
+
+// evict removes the least recently used entries down to 90% of maxEntries, so it doesn't run on every write
+func (c *Cache) evict() error {
+	files, err := os.ReadDir(c.dir)
+	if err != nil {
+		return err
+	}
+
+	type entry struct {
+		name    string
+		modTime time.Time
+	}
+	entries := make([]entry, 0, len(files))
+	for _, f := range files {
+		if info, err := f.Info(); err == nil {
+			entries = append(entries, entry{name: f.Name(), modTime: info.ModTime()})
+		}
+	}
+	slices.SortFunc(entries, func(a, b entry) int {
+		return a.modTime.Compare(b.modTime)
+	})
+
+	keep := max(c.maxEntries*9/10, 1)
+	for len(entries) > keep {
+		if err = os.Remove(filepath.Join(c.dir, entries[0].name)); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+		entries = entries[1:]
+	}
+	c.entries = len(entries)
+
+	return nil
+}
+
+// Splice reuses a path cached between the regions of from and to: connect joins from to the cached path and the
+// cached path to to on the current grid. It fails when a cached cell is no longer free (monsters included) or the
+// cached path doesn't go near from and to, the path must be computed again then.
+func Splice(g *mapgrid.Grid, from, to data.Position, cached []data.Position, canTeleport bool, connect func(from, to data.Position) ([]data.Position, bool)) ([]data.Position, bool) {
+	if len(cached) == 0 {
+		return nil, false
+	}
+	for _, c := range cached {
+		if c.X < 0 || c.Y < 0 || c.X >= g.Width || c.Y >= g.Height {
+			return nil, false
+		}
+		switch g.Get(c.X, c.Y) {
+		case mapgrid.CollisionTypeNonWalkable, mapgrid.CollisionTypeMonster:
+			return nil, false
+		case mapgrid.CollisionTypeTeleportOver, mapgrid.CollisionTypeThickened:
+			if !canTeleport {
+				return nil, false
+			}
+		}
+	}
+
+	// Skip the cached cells before from and after to, when the cached path goes through them
+	if i := slices.Index(cached, from); i >= 0 {
+		cached = cached[i:]
+	}
+	if i := slices.Index(cached, to); i >= 0 {
+		cached = cached[:i+1]
+	}
+
+	head, found := connect(from, cached[0])
+	if !found {
+		return nil, false
+	}
+	tail, found := connect(cached[len(cached)-1], to)
+	if !found {
+		return nil, false
+	}
+	// Longer joins mean the cached path goes somewhere else from here, walls in between for example
+	if len(head) > 2*RegionSize || len(tail) > 2*RegionSize {
+		return nil, false
+	}
+
+	path := make([]data.Position, 0, len(head)+len(cached)+len(tail))
+	path = append(path, head...)
+	path = append(path, cached[1:]...)
+
+	return append(path, tail[1:]...), true
+}
+
+func floorDiv(a, b int) int {
+	if a < 0 {
+		return -((-a + b - 1) / b)
+	}
+
+	return a / b
+}
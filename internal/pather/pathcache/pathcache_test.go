@@ -0,0 +1,197 @@
+package pathcache
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/hectorgimenez/d2go/pkg/data"
+	"github.com/hectorgimenez/d2go/pkg/data/area"
+	"github.com/hectorgimenez/d2go/pkg/data/difficulty"
+	"github.com/hectorgimenez/koolo/internal/game/mapgrid"
+	"github.com/hectorgimenez/koolo/internal/pather/astar"
+	"github.com/hectorgimenez/koolo/internal/pather/corpus"
+)
+
+func testKey(from int) Key {
+	return Key{
+		Seed:       1234,
+		Difficulty: difficulty.Hell,
+		Area:       area.ChaosSanctuary,
+		From:       Region{X: from, Y: 0},
+		To:         Region{X: 9, Y: -1},
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	c, err := Open(t.TempDir(), 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	path := []data.Position{{X: 10, Y: 10}, {X: 11, Y: 11}, {X: 14, Y: 11}, {X: 13, Y: 9}}
+	if err = c.PutPath(testKey(0), path); err != nil {
+		t.Fatal(err)
+	}
+	if got, found := c.Path(testKey(0)); !found || !slices.Equal(got, path) {
+		t.Errorf("got %v, expected %v", got, path)
+	}
+	teleportKey := testKey(0)
+	teleportKey.Teleport = true
+	if _, found := c.Path(teleportKey); found {
+		t.Error("walking and teleport paths should be cached apart")
+	}
+
+	rooms := []data.Room{{Position: data.Position{X: 1, Y: 2}, Width: 3, Height: 4}}
+	if err = c.PutRoomOrder(testKey(0), rooms); err != nil {
+		t.Fatal(err)
+	}
+	if got, found := c.RoomOrder(testKey(0)); !found || !slices.Equal(got, rooms) {
+		t.Errorf("got %v, expected %v", got, rooms)
+	}
+}
+
+func TestEviction(t *testing.T) {
+	c, err := Open(t.TempDir(), 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	path := []data.Position{{X: 1, Y: 1}}
+	for i := 0; i < 3; i++ {
+		if err = c.PutPath(testKey(i), path); err != nil {
+			t.Fatal(err)
+		}
+		// Modification times are the LRU order, make them distinct
+		old := time.Now().Add(time.Duration(i-10) * time.Minute)
+		os.Chtimes(filepath.Join(c.dir, testKey(i).fileName("path")), old, old)
+	}
+	// Reading the oldest one makes it the most recently used
+	if _, found := c.Path(testKey(0)); !found {
+		t.Fatal("entry 0 should be cached")
+	}
+
+	if err = c.PutPath(testKey(3), path); err != nil {
+		t.Fatal(err)
+	}
+	// Down to 90% of 3 entries, the 2 most recently used
+	for i, expected := range []bool{true, false, false, true} {
+		if _, found := c.Path(testKey(i)); found != expected {
+			t.Errorf("entry %d cached: %v, expected %v", i, found, expected)
+		}
+	}
+}
+
+func TestOutdatedVersion(t *testing.T) {
+	dir := t.TempDir()
+	outdated := filepath.Join(dir, "outdated")
+	if err := os.MkdirAll(outdated, os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := Open(dir, 10); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(outdated); !os.IsNotExist(err) {
+		t.Error("entries of other grid or planner versions should be removed")
+	}
+	if _, err := os.Stat(filepath.Join(dir, version)); err != nil {
+		t.Errorf("the current version folder should exist: %v", err)
+	}
+}
+
+func TestSplice(t *testing.T) {
+	g := &mapgrid.Grid{Width: 20, Height: 5, CollisionGrid: make([]mapgrid.CollisionType, 100)}
+	for i := range g.CollisionGrid {
+		g.CollisionGrid[i] = mapgrid.CollisionTypeWalkable
+	}
+	connect := func(from, to data.Position) ([]data.Position, bool) {
+		path, _, found := astar.CalculatePath(g, from, to, false, nil)
+		return path, found
+	}
+
+	// Cached from (1,2) to (18,2), requested from (2,1) to (17,3)
+	var cached []data.Position
+	for x := 1; x <= 18; x++ {
+		cached = append(cached, data.Position{X: x, Y: 2})
+	}
+	from, to := data.Position{X: 2, Y: 1}, data.Position{X: 17, Y: 3}
+
+	path, found := Splice(g, from, to, cached, false, connect)
+	if !found {
+		t.Fatal("the cached path should be reused")
+	}
+	if path[0] != from || path[len(path)-1] != to {
+		t.Errorf("path goes from %v to %v, expected %v to %v", path[0], path[len(path)-1], from, to)
+	}
+	for i := 1; i < len(path); i++ {
+		if dx, dy := path[i].X-path[i-1].X, path[i].Y-path[i-1].Y; dx < -1 || dx > 1 || dy < -1 || dy > 1 {
+			t.Fatalf("%v and %v are not adjacent", path[i-1], path[i])
+		}
+	}
+
+	g.Set(10, 2, mapgrid.CollisionTypeMonster)
+	if _, found = Splice(g, from, to, cached, false, connect); found {
+		t.Error("cached paths going through monsters should be computed again")
+	}
+}
+
+// BenchmarkPath compares computing the paths of the corpus with reusing them from the cache, the cost of a lookup
+// (file read, splice searches) and of caching a new path
+func BenchmarkPath(b *testing.B) {
+	cases, err := corpus.Load(corpus.Dir())
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	for _, cs := range cases {
+		grid, from, to, err := cs.PathGrid()
+		if err != nil {
+			b.Fatal(err)
+		}
+		planner := astar.NewPlanner(astar.PlannerAStar)
+		connect := func(from, to data.Position) ([]data.Position, bool) {
+			path, _, found := planner.CalculatePath(grid, from, to, cs.Teleport)
+			return path, found
+		}
+		path, _, found := planner.CalculatePath(grid, from, to, cs.Teleport)
+		if !found {
+			b.Fatalf("%s: no path found", cs.Name)
+		}
+
+		c, err := Open(b.TempDir(), 1000)
+		if err != nil {
+			b.Fatal(err)
+		}
+		key := Key{Area: area.DuranceOfHateLevel3, From: RegionOf(from), To: RegionOf(to), Teleport: cs.Teleport, Planner: astar.PlannerAStar}
+		if err = c.PutPath(key, path); err != nil {
+			b.Fatal(err)
+		}
+
+		b.Run(cs.Name+"/planner", func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				planner.CalculatePath(grid, from, to, cs.Teleport)
+			}
+		})
+		b.Run(cs.Name+"/cached", func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				cached, found := c.Path(key)
+				if !found {
+					b.Fatal("the path should be cached")
+				}
+				if _, found = Splice(grid, from, to, cached, cs.Teleport, connect); !found {
+					b.Fatal("the cached path should be reused")
+				}
+			}
+		})
+		b.Run(cs.Name+"/put", func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				if err := c.PutPath(key, path); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
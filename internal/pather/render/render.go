@@ -0,0 +1,152 @@
+// Package render draws path finder grids as images, for the live debug map (debug.renderMap) and the offline
+// koolo map render command.
+package render
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"math"
+	"os"
+
+	"github.com/hectorgimenez/d2go/pkg/data"
+	"github.com/hectorgimenez/koolo/internal/game/mapgrid"
+	"github.com/hectorgimenez/koolo/internal/pather/astar"
+)
+
+var (
+	colorPath        = color.RGBA{R: 36, G: 255, A: 255}
+	colorLowPriority = color.RGBA{R: 200, G: 200, B: 200, A: 255} // Gray
+	colorMonster     = color.RGBA{R: 255, A: 255}                 // Red
+	colorObject      = color.RGBA{R: 160, G: 32, B: 240, A: 255}  // Purple
+	colorTeleport    = color.RGBA{R: 140, G: 190, B: 255, A: 255} // Light blue
+	colorThickened   = color.RGBA{R: 90, G: 90, B: 90, A: 255}    // Dark gray
+	colorRoom        = color.RGBA{R: 204, G: 204, A: 255}         // Dark yellow
+	colorExit        = color.RGBA{R: 255, G: 140, A: 255}         // Orange
+	colorLanding     = color.RGBA{G: 200, B: 200, A: 255}         // Teal
+	colorFrom        = color.RGBA{R: 158, A: 255}                 // Garnet
+	colorTo          = color.RGBA{B: 255, A: 255}                 // Blue
+)
+
+// Layers is what gets drawn over the grid. Map data (objects, exits, monsters and rooms) are world positions, the
+// path finder ones (from, to, path and landings) are relative to the grid like the path finder returns them.
+type Layers struct {
+	Grid        *mapgrid.Grid
+	CanTeleport bool
+
+	Objects  []data.Object
+	Exits    []data.Level
+	Monsters data.Monsters
+	Rooms    []data.Room
+
+	From, To data.Position
+	Path     []data.Position
+	// Landings are the teleport landing points, see astar.CalculateTeleportPath
+	Landings []data.Position
+}
+
+// Collision draws the collision type of every cell, scale pixels per cell
+func Collision(l Layers, scale int) *image.RGBA {
+	return draw(l, scale, func(t mapgrid.CollisionType) color.Color {
+		switch t {
+		case mapgrid.CollisionTypeWalkable:
+			return color.White
+		case mapgrid.CollisionTypeLowPriority:
+			return colorLowPriority
+		case mapgrid.CollisionTypeMonster:
+			return colorMonster
+		case mapgrid.CollisionTypeObject:
+			return colorObject
+		case mapgrid.CollisionTypeTeleportOver:
+			return colorTeleport
+		case mapgrid.CollisionTypeThickened:
+			return colorThickened
+		default:
+			return color.Black
+		}
+	})
+}
+
+// Cost draws the path finder cost of entering every cell, from white (cheapest) to red, black when it can't be
+// entered
+func Cost(l Layers, scale int) *image.RGBA {
+	maxCost := 1
+	for t := mapgrid.CollisionTypeNonWalkable; t <= mapgrid.CollisionTypeThickened; t++ {
+		if c := astar.CellCost(t, l.CanTeleport); c != math.MaxInt32 {
+			maxCost = max(maxCost, c)
+		}
+	}
+
+	return draw(l, scale, func(t mapgrid.CollisionType) color.Color {
+		c := astar.CellCost(t, l.CanTeleport)
+		if c == math.MaxInt32 {
+			return color.Black
+		}
+		// Green and blue fade out as the cost grows
+		fade := uint8(255 - 255*(c-1)/max(maxCost-1, 1))
+		return color.RGBA{R: 255, G: fade, B: fade, A: 255}
+	})
+}
+
+// Save writes img as a PNG file
+func Save(img image.Image, path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err = png.Encode(f, img); err != nil {
+		f.Close()
+		return fmt.Errorf("error encoding %s: %w", path, err)
+	}
+
+	return f.Close()
+}
+
+func draw(l Layers, scale int, cellColor func(mapgrid.CollisionType) color.Color) *image.RGBA {
+	scale = max(scale, 1)
+	img := image.NewRGBA(image.Rect(0, 0, l.Grid.Width*scale, l.Grid.Height*scale))
+	cell := func(p data.Position, size int, c color.Color) {
+		// Markers bigger than a cell are centered on it
+		offset := (size - 1) / 2
+		for y := (p.Y - offset) * scale; y < (p.Y-offset+size)*scale; y++ {
+			for x := (p.X - offset) * scale; x < (p.X-offset+size)*scale; x++ {
+				if x >= 0 && y >= 0 && x < img.Rect.Dx() && y < img.Rect.Dy() {
+					img.Set(x, y, c)
+				}
+			}
+		}
+	}
+
+	for y := 0; y < l.Grid.Height; y++ {
+		for x := 0; x < l.Grid.Width; x++ {
+			cell(data.Position{X: x, Y: y}, 1, cellColor(l.Grid.Get(x, y)))
+		}
+	}
+
+	for _, r := range l.Rooms {
+		cell(l.Grid.RelativePosition(r.GetCenter()), 1, colorRoom)
+	}
+	for _, o := range l.Objects {
+		cell(l.Grid.RelativePosition(o.Position), 3, colorObject)
+	}
+	for _, m := range l.Monsters {
+		cell(l.Grid.RelativePosition(m.Position), 3, colorMonster)
+	}
+	for _, e := range l.Exits {
+		cell(l.Grid.RelativePosition(e.Position), 5, colorExit)
+	}
+	for _, p := range l.Path {
+		cell(p, 1, colorPath)
+	}
+	for _, p := range l.Landings {
+		cell(p, 3, colorLanding)
+	}
+	// Both are unset when there is no path request
+	if l.From != l.To {
+		cell(l.From, 3, colorFrom)
+		cell(l.To, 3, colorTo)
+	}
+
+	return img
+}
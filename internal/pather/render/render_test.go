@@ -0,0 +1,88 @@
+package render
+
+import (
+	"image/color"
+	"testing"
+
+	"github.com/hectorgimenez/d2go/pkg/data"
+	"github.com/hectorgimenez/koolo/internal/game/mapgrid"
+)
+
+func testGrid() *mapgrid.Grid {
+	g := &mapgrid.Grid{OffsetX: 100, OffsetY: 200, Width: 10, Height: 10}
+	g.CollisionGrid = make([]mapgrid.CollisionType, g.Width*g.Height)
+	for y := 1; y < 9; y++ {
+		for x := 1; x < 9; x++ {
+			g.Set(x, y, mapgrid.CollisionTypeWalkable)
+		}
+	}
+	g.Set(5, 5, mapgrid.CollisionTypeTeleportOver)
+
+	return g
+}
+
+func TestCollision(t *testing.T) {
+	img := Collision(Layers{Grid: testGrid()}, 2)
+	if b := img.Bounds(); b.Dx() != 20 || b.Dy() != 20 {
+		t.Fatalf("image should be 2 pixels per cell, got %v", b)
+	}
+
+	for _, tc := range []struct {
+		x, y int
+		want color.Color
+	}{
+		{x: 0, y: 0, want: color.Black},
+		{x: 3, y: 3, want: color.White},
+		{x: 11, y: 10, want: colorTeleport},
+	} {
+		if got := color.RGBAModel.Convert(img.At(tc.x, tc.y)); got != color.RGBAModel.Convert(tc.want) {
+			t.Errorf("pixel (%d,%d) = %v, want %v", tc.x, tc.y, got, tc.want)
+		}
+	}
+}
+
+func TestLayers(t *testing.T) {
+	img := Collision(Layers{
+		Grid:     testGrid(),
+		Exits:    []data.Level{{Position: data.Position{X: 103, Y: 203}}},
+		From:     data.Position{X: 1, Y: 1},
+		To:       data.Position{X: 7, Y: 7},
+		Path:     []data.Position{{X: 1, Y: 1}, {X: 2, Y: 8}, {X: 7, Y: 7}},
+		Landings: []data.Position{{X: 7, Y: 1}},
+	}, 1)
+
+	for _, tc := range []struct {
+		name string
+		p    data.Position
+		want color.RGBA
+	}{
+		// Exits are world positions, the rest is relative to the grid
+		{name: "exit", p: data.Position{X: 3, Y: 3}, want: colorExit},
+		{name: "path", p: data.Position{X: 2, Y: 8}, want: colorPath},
+		{name: "landing", p: data.Position{X: 8, Y: 2}, want: colorLanding},
+		{name: "from", p: data.Position{X: 1, Y: 1}, want: colorFrom},
+		{name: "to", p: data.Position{X: 7, Y: 7}, want: colorTo},
+	} {
+		if got := img.RGBAAt(tc.p.X, tc.p.Y); got != tc.want {
+			t.Errorf("%s at %v = %v, want %v", tc.name, tc.p, got, tc.want)
+		}
+	}
+}
+
+func TestCost(t *testing.T) {
+	img := Cost(Layers{Grid: testGrid()}, 1)
+
+	if got := img.RGBAAt(0, 0); got != (color.RGBA{A: 255}) {
+		t.Errorf("non walkable cells should be black, got %v", got)
+	}
+	if got := img.RGBAAt(2, 2); got != (color.RGBA{R: 255, G: 255, B: 255, A: 255}) {
+		t.Errorf("walkable cells should be the cheapest, got %v", got)
+	}
+	// Teleport over cells can't be walked
+	if got := img.RGBAAt(5, 5); got != (color.RGBA{A: 255}) {
+		t.Errorf("teleport over cells should be blocked when walking, got %v", got)
+	}
+	if got := Cost(Layers{Grid: testGrid(), CanTeleport: true}, 1).RGBAAt(5, 5); got.A != 255 || got == (color.RGBA{A: 255}) {
+		t.Errorf("teleport over cells should have a cost when teleporting, got %v", got)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"log/slog"
 	"math"
 	"math/rand"
+	"slices"
 	"time"
 
 	"github.com/hectorgimenez/d2go/pkg/data"
@@ -11,6 +12,7 @@ import (
 	"github.com/hectorgimenez/d2go/pkg/data/object"
 	"github.com/hectorgimenez/d2go/pkg/data/skill"
 	"github.com/hectorgimenez/koolo/internal/game"
+	"github.com/hectorgimenez/koolo/internal/pather/pathcache"
 	"github.com/hectorgimenez/koolo/internal/utils"
 )
 
@@ -28,7 +30,47 @@ func (pf *PathFinder) DistanceFromMe(p data.Position) int {
 	return DistanceFromPoint(pf.data.PlayerUnit.Position, p)
 }
 
+// OptimizeRoomsTraverseOrder returns the rooms of the area in visiting order from the current one, nearest first
 func (pf *PathFinder) OptimizeRoomsTraverseOrder() []data.Room {
+	cache := pf.pathCache()
+	if cache == nil {
+		return pf.optimizeRoomsTraverseOrder()
+	}
+
+	var start data.Position
+	for _, r := range pf.data.Rooms {
+		if r.IsInside(pf.data.PlayerUnit.Position) {
+			start = r.Position
+		}
+	}
+	key := pf.cacheKey(pathcache.RegionOf(start), pathcache.Region{}, false)
+	if order, found := cache.RoomOrder(key); found && sameRooms(order, pf.data.Rooms) {
+		return order
+	}
+
+	order := pf.optimizeRoomsTraverseOrder()
+	if err := cache.PutRoomOrder(key, order); err != nil {
+		slog.Debug("Error caching room order", slog.Any("error", err))
+	}
+
+	return order
+}
+
+// sameRooms reports whether a cached order holds the rooms of the area, a different seed can share its key
+func sameRooms(order, rooms []data.Room) bool {
+	if len(order) != len(rooms) {
+		return false
+	}
+	for _, r := range rooms {
+		if !slices.Contains(order, r) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (pf *PathFinder) optimizeRoomsTraverseOrder() []data.Room {
 	distanceMatrix := make(map[data.Room]map[data.Room]int)
 
 	for _, room1 := range pf.data.Rooms {
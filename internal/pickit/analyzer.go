@@ -0,0 +1,540 @@
+package pickit
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/hectorgimenez/d2go/pkg/data"
+	"github.com/hectorgimenez/d2go/pkg/data/item"
+	"github.com/hectorgimenez/d2go/pkg/nip"
+)
+
+// Conflict types reported by AnalyzeNIP
+const (
+	ConflictInvalid     = "invalid"
+	ConflictUnreachable = "unreachable"
+	ConflictDuplicate   = "duplicate"
+	ConflictSubsumed    = "subsumed"
+	ConflictMaxQuantity = "maxquantity"
+)
+
+const (
+	flagEthereal = 0x400000
+	flagRuneword = 0x4000000
+)
+
+var nipConditionRegexp = regexp.MustCompile(`^\[(\w+)\]\s*(==|!=|>=|<=|>|<)\s*(-?\w+)$`)
+
+// Item types that only drop with normal quality
+var normalOnlyTypes = []string{
+	item.TypeRune, item.TypeGold, item.TypeHerb, item.TypePotion, item.TypeElixir, item.TypeKey, item.TypeQuest,
+	item.TypeHealingPotion, item.TypeManaPotion, item.TypeRejuvPotion, item.TypeStaminaPotion, item.TypeAntidotePotion,
+	item.TypeThawingPotion, item.TypeChippedGem, item.TypeFlawedGem, item.TypeStandardGem, item.TypeFlawlessGem,
+	item.TypePerfectGem, item.TypeAmethyst, item.TypeDiamond, item.TypeEmerald, item.TypeRuby, item.TypeSapphire,
+	item.TypeTopaz, item.TypeSkull,
+}
+
+// NIPSourceLine is a raw NIP line with its location, ID is the rule ID used by the pickit editor
+type NIPSourceLine struct {
+	ID   string `json:"id"`
+	File string `json:"file"`
+	Line int    `json:"line"`
+	Raw  string `json:"raw"`
+}
+
+// NIPAnalysis is the result of analyzing a pickit file or directory
+type NIPAnalysis struct {
+	Files     int                 `json:"files"`
+	Rules     int                 `json:"rules"`
+	Conflicts []ConflictDetection `json:"conflicts"`
+}
+
+// ReadNIPSources reads every non-empty NIP line of a .nip file or of all the .nip files in a directory, in the same
+// order the bot loads them
+func ReadNIPSources(path string) ([]NIPSourceLine, int, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	files := []string{path}
+	if info.IsDir() {
+		entries, err := os.ReadDir(path)
+		if err != nil {
+			return nil, 0, err
+		}
+		files = files[:0]
+		for _, entry := range entries {
+			if !entry.IsDir() && strings.HasSuffix(strings.ToLower(entry.Name()), ".nip") {
+				files = append(files, filepath.Join(path, entry.Name()))
+			}
+		}
+	}
+
+	var lines []NIPSourceLine
+	for _, file := range files {
+		fileLines, err := readNIPFileLines(file)
+		if err != nil {
+			return nil, 0, err
+		}
+		lines = append(lines, fileLines...)
+	}
+
+	return lines, len(files), nil
+}
+
+func readNIPFileLines(file string) ([]NIPSourceLine, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var lines []NIPSourceLine
+	name := filepath.Base(file)
+	scanner := bufio.NewScanner(f)
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		if sanitizeNIPLine(scanner.Text()) == "" {
+			continue
+		}
+		lines = append(lines, NIPSourceLine{
+			ID:   fmt.Sprintf("%s_%d", name, lineNumber-1),
+			File: file,
+			Line: lineNumber,
+			Raw:  scanner.Text(),
+		})
+	}
+
+	return lines, scanner.Err()
+}
+
+// AnalyzeNIPPath reads and analyzes a .nip file or a pickit directory
+func AnalyzeNIPPath(path string) (NIPAnalysis, error) {
+	lines, files, err := ReadNIPSources(path)
+	if err != nil {
+		return NIPAnalysis{}, err
+	}
+
+	return NIPAnalysis{Files: files, Rules: len(lines), Conflicts: AnalyzeNIP(lines)}, nil
+}
+
+// valueRange is the set of values allowed by the conditions on a single property
+type valueRange struct {
+	min, max int
+	excluded map[int]bool
+}
+
+func newValueRange() *valueRange {
+	return &valueRange{min: math.MinInt32, max: math.MaxInt32, excluded: map[int]bool{}}
+}
+
+func (r *valueRange) apply(op string, v int) {
+	switch op {
+	case "==":
+		r.min, r.max = max(r.min, v), min(r.max, v)
+	case "!=":
+		r.excluded[v] = true
+	case ">=":
+		r.min = max(r.min, v)
+	case ">":
+		r.min = max(r.min, v+1)
+	case "<=":
+		r.max = min(r.max, v)
+	case "<":
+		r.max = min(r.max, v-1)
+	}
+}
+
+func (r *valueRange) contains(v int) bool {
+	return v >= r.min && v <= r.max && !r.excluded[v]
+}
+
+func (r *valueRange) empty() bool {
+	if r.min > r.max {
+		return true
+	}
+	// Only small ranges can be fully covered by != conditions
+	if r.max-r.min >= len(r.excluded) {
+		return false
+	}
+	for v := r.min; v <= r.max; v++ {
+		if !r.excluded[v] {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (r *valueRange) single() (int, bool) {
+	return r.min, r.min == r.max && !r.excluded[r.min]
+}
+
+// within reports whether every value allowed by r is also allowed by o
+func (r *valueRange) within(o *valueRange) bool {
+	if r.min < o.min || r.max > o.max {
+		return false
+	}
+	for v := range o.excluded {
+		if r.contains(v) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// analyzedRule is a NIP line reduced to value ranges, only built when both sections are plain && conditions
+type analyzedRule struct {
+	src         NIPSourceLine
+	normalized  string
+	tiered      bool
+	maxQuantity int
+	simple      bool
+	unreachable string
+	base        map[string]*valueRange
+	implied     map[string]*valueRange
+	stats       map[string]*valueRange
+}
+
+func (r *analyzedRule) baseRange(prop string) *valueRange {
+	if rng, found := r.base[prop]; found {
+		return rng
+	}
+
+	return r.implied[prop]
+}
+
+// subsumes reports whether every item matching o also matches r
+func (r *analyzedRule) subsumes(o *analyzedRule) bool {
+	for prop, rng := range r.base {
+		other := o.baseRange(prop)
+		if other == nil || !other.within(rng) {
+			return false
+		}
+	}
+	for key, rng := range r.stats {
+		other, found := o.stats[key]
+		if !found || !other.within(rng) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// AnalyzeNIP looks for invalid and unreachable rules, duplicated lines, rules shadowed by a broader one and
+// maxquantity limits that never apply. Lines must be in evaluation order, the first full match wins in game.
+func AnalyzeNIP(lines []NIPSourceLine) []ConflictDetection {
+	conflicts := []ConflictDetection{}
+	rules := make([]*analyzedRule, 0, len(lines))
+
+	for _, src := range lines {
+		r, conflict := analyzeNIPLine(src)
+		if conflict != nil {
+			conflicts = append(conflicts, *conflict)
+			continue
+		}
+		if r == nil {
+			continue
+		}
+		if r.unreachable != "" {
+			conflicts = append(conflicts, ConflictDetection{
+				Type:        ConflictUnreachable,
+				Rules:       []string{src.ID},
+				References:  []RuleReference{ruleReference(src)},
+				Loser:       src.ID,
+				Severity:    "error",
+				Description: fmt.Sprintf("Rule can never match: %s", r.unreachable),
+				Suggestion:  "Fix the conditions or remove the rule",
+			})
+		}
+		rules = append(rules, r)
+	}
+
+	duplicated := make(map[int]bool)
+	firstByLine := make(map[string]int)
+	for i, r := range rules {
+		first, found := firstByLine[r.normalized]
+		if !found {
+			firstByLine[r.normalized] = i
+			continue
+		}
+		duplicated[i] = true
+		conflicts = append(conflicts, ConflictDetection{
+			Type:        ConflictDuplicate,
+			Rules:       []string{rules[first].src.ID, r.src.ID},
+			References:  []RuleReference{ruleReference(rules[first].src), ruleReference(r.src)},
+			Loser:       r.src.ID,
+			Severity:    "warning",
+			Description: fmt.Sprintf("Rule duplicates %s", locationString(rules[first].src)),
+			Suggestion:  "Remove one of the duplicated rules",
+		})
+	}
+
+	for i, a := range rules {
+		if !a.simple || a.unreachable != "" || duplicated[i] {
+			continue
+		}
+		for j := i + 1; j < len(rules); j++ {
+			b := rules[j]
+			if !b.simple || b.unreachable != "" || duplicated[j] {
+				continue
+			}
+
+			switch {
+			case a.subsumes(b):
+				if !b.tiered {
+					conflicts = append(conflicts, ConflictDetection{
+						Type:        ConflictSubsumed,
+						Rules:       []string{a.src.ID, b.src.ID},
+						References:  []RuleReference{ruleReference(a.src), ruleReference(b.src)},
+						Loser:       b.src.ID,
+						Severity:    "warning",
+						Description: fmt.Sprintf("Rule at %s is shadowed by the broader rule at %s, which matches every item first", locationString(b.src), locationString(a.src)),
+						Suggestion:  "Remove the narrower rule or move it before the broader one",
+					})
+				}
+				if b.maxQuantity > 0 && b.maxQuantity != a.maxQuantity {
+					conflicts = append(conflicts, ConflictDetection{
+						Type:        ConflictMaxQuantity,
+						Rules:       []string{a.src.ID, b.src.ID},
+						References:  []RuleReference{ruleReference(a.src), ruleReference(b.src)},
+						Loser:       b.src.ID,
+						Severity:    "error",
+						Description: fmt.Sprintf("maxquantity %d of %s never applies, %s matches the same items first with maxquantity %d", b.maxQuantity, locationString(b.src), locationString(a.src), a.maxQuantity),
+						Suggestion:  "Move the rule with maxquantity before the broader rule",
+					})
+				}
+			case b.subsumes(a) && !a.tiered && a.maxQuantity == b.maxQuantity:
+				conflicts = append(conflicts, ConflictDetection{
+					Type:        ConflictSubsumed,
+					Rules:       []string{a.src.ID, b.src.ID},
+					References:  []RuleReference{ruleReference(a.src), ruleReference(b.src)},
+					Loser:       a.src.ID,
+					Severity:    "info",
+					Description: fmt.Sprintf("Rule at %s is redundant, the broader rule at %s keeps the same items", locationString(a.src), locationString(b.src)),
+					Suggestion:  "Remove the narrower rule unless it documents intent",
+				})
+			}
+		}
+	}
+
+	return conflicts
+}
+
+// analyzeNIPLine compiles the line with the NIP engine and, when possible, reduces it to value ranges. Returns a
+// conflict when the line is invalid, nil rule for empty lines.
+func analyzeNIPLine(src NIPSourceLine) (*analyzedRule, *ConflictDetection) {
+	invalid := func(err error) *ConflictDetection {
+		return &ConflictDetection{
+			Type:        ConflictInvalid,
+			Rules:       []string{src.ID},
+			References:  []RuleReference{ruleReference(src)},
+			Loser:       src.ID,
+			Severity:    "error",
+			Description: err.Error(),
+			Suggestion:  "Invalid rules prevent the whole file from loading",
+		}
+	}
+
+	rule, err := nip.NewRule(src.Raw, src.File, src.Line)
+	if errors.Is(err, nip.ErrEmptyRule) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, invalid(err)
+	}
+	if err := rule.ValidateStats(); err != nil {
+		return nil, invalid(err)
+	}
+	if _, err := rule.Evaluate(data.Item{ID: 516, Name: "healingpotion", Quality: item.QualityNormal}); err != nil {
+		return nil, invalid(err)
+	}
+
+	line := sanitizeNIPLine(src.Raw)
+	r := &analyzedRule{
+		src:         src,
+		normalized:  strings.ReplaceAll(line, " ", ""),
+		tiered:      rule.Tier() > 0 || rule.MercTier() > 0,
+		maxQuantity: rule.MaxQuantity(),
+		simple:      true,
+		base:        map[string]*valueRange{},
+		implied:     map[string]*valueRange{},
+		stats:       map[string]*valueRange{},
+	}
+
+	baseSection, statsSection := splitNIPSections(line)
+	for _, clause := range topLevelClauses(baseSection) {
+		prop, op, value, ok := parseNIPCondition(clause)
+		if !ok {
+			r.simple = false
+			continue
+		}
+		v, known := baseValue(prop, value)
+		if !known {
+			if op == "==" {
+				r.unreachable = fmt.Sprintf("unknown %s '%s'", prop, value)
+			}
+			r.simple = false
+			continue
+		}
+		if _, found := r.base[prop]; !found {
+			r.base[prop] = newValueRange()
+		}
+		r.base[prop].apply(op, v)
+	}
+
+	for _, clause := range topLevelClauses(statsSection) {
+		prop, op, value, ok := parseNIPCondition(clause)
+		statData, found := nip.StatAliases[prop]
+		v, err := strconv.Atoi(value)
+		if !ok || !found || err != nil {
+			r.simple = false
+			continue
+		}
+		key := fmt.Sprintf("%d", statData[0])
+		if len(statData) > 1 {
+			key = fmt.Sprintf("%d:%d", statData[0], statData[1])
+		}
+		if _, found := r.stats[key]; !found {
+			r.stats[key] = newValueRange()
+		}
+		r.stats[key].apply(op, v)
+		if r.stats[key].empty() && r.unreachable == "" {
+			r.unreachable = fmt.Sprintf("conflicting [%s] conditions", prop)
+		}
+	}
+
+	if r.unreachable == "" {
+		r.unreachable = impossibleBase(r)
+	}
+
+	return r, nil
+}
+
+// impossibleBase checks the base ranges against each other and against what the named item can be
+func impossibleBase(r *analyzedRule) string {
+	for prop, rng := range r.base {
+		if rng.empty() {
+			return fmt.Sprintf("conflicting [%s] conditions", prop)
+		}
+	}
+
+	if nameRange, found := r.base["name"]; found {
+		if id, single := nameRange.single(); single {
+			desc := item.Desc[id]
+			typeRange := newValueRange()
+			typeRange.apply("==", desc.GetType().ID)
+			classRange := newValueRange()
+			classRange.apply("==", int(desc.Tier()))
+			r.implied["type"], r.implied["class"] = typeRange, classRange
+
+			if rng, found := r.base["type"]; found && !rng.contains(desc.GetType().ID) {
+				return fmt.Sprintf("%s is not of the required [type]", item.Names[id])
+			}
+			if rng, found := r.base["class"]; found && !rng.contains(int(desc.Tier())) {
+				return fmt.Sprintf("%s is not of the required [class]", item.Names[id])
+			}
+		}
+	}
+
+	quality := r.baseRange("quality")
+	if quality == nil {
+		return ""
+	}
+	if typeRange := r.baseRange("type"); typeRange != nil {
+		if typeID, single := typeRange.single(); single && !quality.contains(int(item.QualityNormal)) {
+			for _, code := range normalOnlyTypes {
+				if item.ItemTypes[code].ID == typeID {
+					return fmt.Sprintf("%s items are always normal quality", item.ItemTypes[code].Name)
+				}
+			}
+		}
+	}
+	if flag, found := r.base["flag"]; found {
+		if v, single := flag.single(); single && v&flagRuneword != 0 && !quality.contains(int(item.QualityNormal)) && !quality.contains(int(item.QualitySuperior)) {
+			return "runewords are always normal or superior quality"
+		}
+	}
+
+	return ""
+}
+
+// baseValue converts a base property value the same way the NIP engine does
+func baseValue(prop, value string) (int, bool) {
+	if v, err := strconv.Atoi(value); err == nil {
+		return v, true
+	}
+
+	switch prop {
+	case "name":
+		id := item.GetIDByName(value)
+		return id, id >= 0
+	case "type":
+		code, found := nip.TypeAliases[value]
+		if !found {
+			return 0, false
+		}
+		return item.ItemTypes[code].ID, true
+	case "quality":
+		q, err := parseFixtureQuality(value)
+		return int(q), err == nil
+	case "class":
+		switch value {
+		case "normal":
+			return int(item.TierNormal), true
+		case "exceptional":
+			return int(item.TierExceptional), true
+		case "elite":
+			return int(item.TierElite), true
+		}
+	case "flag":
+		switch value {
+		case "ethereal":
+			return flagEthereal, true
+		case "runeword":
+			return flagRuneword, true
+		}
+	}
+
+	return 0, false
+}
+
+func parseNIPCondition(clause string) (string, string, string, bool) {
+	m := nipConditionRegexp.FindStringSubmatch(trimOuterParens(clause))
+	if m == nil {
+		return "", "", "", false
+	}
+
+	return m[1], m[2], m[3], true
+}
+
+// sanitizeNIPLine normalizes a line the same way the NIP engine does before compiling it
+func sanitizeNIPLine(raw string) string {
+	line := strings.TrimSpace(strings.Split(raw, "//")[0])
+	line = strings.Join(strings.Fields(line), " ")
+	line = strings.ReplaceAll(line, "'", "")
+	line = strings.ReplaceAll(line, "=>", ">=")
+	line = strings.ReplaceAll(line, "=<", "<=")
+	line = strings.TrimSpace(strings.Trim(line, "&&"))
+
+	return strings.ToLower(line)
+}
+
+func ruleReference(src NIPSourceLine) RuleReference {
+	return RuleReference{ID: src.ID, File: filepath.Base(src.File), Line: src.Line, Rule: strings.TrimSpace(src.Raw)}
+}
+
+func locationString(src NIPSourceLine) string {
+	return fmt.Sprintf("%s:%d", filepath.Base(src.File), src.Line)
+}
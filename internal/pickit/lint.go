@@ -0,0 +1,172 @@
+package pickit
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/hectorgimenez/d2go/pkg/nip"
+)
+
+var nipPropertyRegexp = regexp.MustCompile(`\[(\w+)\]`)
+
+// Properties handled by the NIP engine itself rather than as item stats
+var nipFixedProperties = []string{"name", "type", "quality", "class", "flag", "color", "prefix", "suffix", "maxquantity", "tier", "merctier"}
+
+// LintIssue is a single problem found in a pickit file
+type LintIssue struct {
+	File     string `json:"file"`
+	Line     int    `json:"line"`
+	Severity string `json:"severity"`
+	Type     string `json:"type"`
+	Message  string `json:"message"`
+}
+
+func (i LintIssue) String() string {
+	if i.Line == 0 {
+		return fmt.Sprintf("%s: %s: %s", i.File, i.Severity, i.Message)
+	}
+
+	return fmt.Sprintf("%s:%d: %s: %s", i.File, i.Line, i.Severity, i.Message)
+}
+
+// LintReport is the result of linting a pickit file or directory
+type LintReport struct {
+	Files  int         `json:"files"`
+	Rules  int         `json:"rules"`
+	Issues []LintIssue `json:"issues"`
+}
+
+// Failed reports whether any issue is a warning or an error
+func (r LintReport) Failed() bool {
+	for _, issue := range r.Issues {
+		if issue.Severity == "error" || issue.Severity == "warning" {
+			return true
+		}
+	}
+
+	return false
+}
+
+// LintNIPPath checks a .nip file or every .nip file of a directory: files are loaded with the same loader the bot
+// uses, then every line is checked for unknown properties and analyzed for unreachable, duplicated and shadowed rules.
+func LintNIPPath(path string) (LintReport, error) {
+	lines, files, err := ReadNIPSources(path)
+	if err != nil {
+		return LintReport{}, err
+	}
+
+	report := LintReport{Files: files, Rules: len(lines), Issues: []LintIssue{}}
+	builder := NewNIPBuilder()
+	flagged := make(map[string]bool)
+	for _, src := range lines {
+		for _, prop := range unknownProperties(builder, src.Raw) {
+			flagged[src.ID] = true
+			report.Issues = append(report.Issues, LintIssue{
+				File:     src.File,
+				Line:     src.Line,
+				Severity: "error",
+				Type:     ConflictInvalid,
+				Message:  fmt.Sprintf("unknown property [%s]", prop),
+			})
+		}
+	}
+
+	byID := make(map[string]NIPSourceLine, len(lines))
+	for _, src := range lines {
+		byID[src.ID] = src
+	}
+
+	invalidFiles := make(map[string]bool)
+	for _, conflict := range AnalyzeNIP(lines) {
+		// Conflicts between rules are reported on the rule that loses
+		src := byID[conflict.Loser]
+		if conflict.Type == ConflictInvalid {
+			invalidFiles[src.File] = true
+			if flagged[src.ID] {
+				continue
+			}
+		}
+		report.Issues = append(report.Issues, LintIssue{
+			File:     src.File,
+			Line:     src.Line,
+			Severity: conflict.Severity,
+			Type:     conflict.Type,
+			Message:  conflict.Description,
+		})
+	}
+
+	// The loader stops at the first broken line of a file, report it if the line analysis didn't catch it
+	for _, file := range sourceFiles(lines) {
+		if _, err := ReadRulesFile(file); err != nil && !invalidFiles[file] && !fileFlagged(lines, flagged, file) {
+			report.Issues = append(report.Issues, LintIssue{File: file, Severity: "error", Type: ConflictInvalid, Message: err.Error()})
+		}
+	}
+
+	sort.SliceStable(report.Issues, func(i, j int) bool {
+		if report.Issues[i].File != report.Issues[j].File {
+			return report.Issues[i].File < report.Issues[j].File
+		}
+		return report.Issues[i].Line < report.Issues[j].Line
+	})
+
+	return report, nil
+}
+
+// unknownProperties returns the bracketed properties of a line that neither the NIP engine nor the pickit editor know
+func unknownProperties(builder *NIPBuilder, raw string) []string {
+	var unknown []string
+	for _, m := range nipPropertyRegexp.FindAllStringSubmatch(sanitizeNIPLine(raw), -1) {
+		prop := m[1]
+		if _, found := nip.StatAliases[prop]; found || builder.isValidProperty(prop) || isFixedProperty(prop) || isEditorStat(prop) {
+			continue
+		}
+		unknown = append(unknown, prop)
+	}
+
+	return unknown
+}
+
+func isFixedProperty(prop string) bool {
+	for _, p := range nipFixedProperties {
+		if p == prop {
+			return true
+		}
+	}
+
+	return false
+}
+
+func isEditorStat(prop string) bool {
+	for _, st := range GetAllStatTypes() {
+		if strings.Trim(st.NipProperty, "[]") == prop {
+			return true
+		}
+	}
+
+	return false
+}
+
+func sourceFiles(lines []NIPSourceLine) []string {
+	var files []string
+	seen := make(map[string]bool)
+	for _, src := range lines {
+		if !seen[src.File] {
+			seen[src.File] = true
+			files = append(files, src.File)
+		}
+	}
+
+	return files
+}
+
+func fileFlagged(lines []NIPSourceLine, flagged map[string]bool, file string) bool {
+	for _, src := range lines {
+		if src.File == file && flagged[src.ID] {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,32 @@
+package pickit
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLintReportsTheLosingRule(t *testing.T) {
+	dir := t.TempDir()
+	content := "[type] == ring && [quality] == unique # [itemmagicbonus] >= 30\n" +
+		"[type] == ring && [quality] == unique\n" +
+		"[type] == amulet && [quality] == set\n" +
+		"[type] == amulet && [quality] == set # [itemmagicbonus] >= 30\n"
+	if err := os.WriteFile(filepath.Join(dir, "rings.nip"), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	report, err := LintNIPPath(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The redundant narrow ring rule comes first, the shadowed narrow amulet rule last
+	lines := map[int]string{}
+	for _, issue := range report.Issues {
+		lines[issue.Line] = issue.Type
+	}
+	if lines[1] != ConflictSubsumed || lines[4] != ConflictSubsumed || len(lines) != 2 {
+		t.Errorf("expected the narrow rules at lines 1 and 4 to be reported, got %+v", report.Issues)
+	}
+}
@@ -0,0 +1,80 @@
+package pickit
+
+import (
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/hectorgimenez/d2go/pkg/nip"
+)
+
+// Compiled NIP rules cache, stores rules by path to avoid recompiling them for multiple characters
+var (
+	rulesCacheMux sync.RWMutex
+	rulesCache    = make(map[string]nip.Rules)
+)
+
+// ClearRulesCache clears the compiled NIP rules cache, forcing recompilation on next load
+func ClearRulesCache() {
+	rulesCacheMux.Lock()
+	rulesCache = make(map[string]nip.Rules)
+	rulesCacheMux.Unlock()
+}
+
+// LoadRulesDir returns cached NIP rules for a directory, compiling only if not cached
+func LoadRulesDir(dir string) (nip.Rules, error) {
+	return loadCached(dir, ReadRulesDir)
+}
+
+// LoadRulesFile returns cached NIP rules for a single file, compiling only if not cached
+func LoadRulesFile(filePath string) (nip.Rules, error) {
+	return loadCached(filePath, ReadRulesFile)
+}
+
+// ReadRules compiles the rules of a pickit directory or a single .nip file without using the cache
+func ReadRules(path string) (nip.Rules, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if info.IsDir() {
+		return ReadRulesDir(path)
+	}
+
+	return ReadRulesFile(path)
+}
+
+// ReadRulesDir compiles every .nip file of a directory without using the cache
+func ReadRulesDir(dir string) (nip.Rules, error) {
+	return nip.ReadDir(withTrailingSeparator(dir))
+}
+
+// ReadRulesFile compiles a single .nip file without using the cache
+func ReadRulesFile(filePath string) (nip.Rules, error) {
+	return nip.ParseNIPFile(filePath)
+}
+
+func loadCached(path string, read func(string) (nip.Rules, error)) (nip.Rules, error) {
+	rulesCacheMux.RLock()
+	if cached, ok := rulesCache[path]; ok {
+		rulesCacheMux.RUnlock()
+		return cached, nil
+	}
+	rulesCacheMux.RUnlock()
+
+	rules, err := read(path)
+	if err != nil {
+		return nil, err
+	}
+
+	rulesCacheMux.Lock()
+	rulesCache[path] = rules
+	rulesCacheMux.Unlock()
+
+	return rules, nil
+}
+
+// withTrailingSeparator appends the separator nip.ReadDir expects, the path is concatenated with the file names
+func withTrailingSeparator(dir string) string {
+	return strings.TrimRight(dir, "\\/") + string(os.PathSeparator)
+}
@@ -0,0 +1,28 @@
+package pickit
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestReadRulesFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "runes.nip")
+	if err := os.WriteFile(path, []byte("[name] == berrune\n\n[name] == jahrune // jah\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	rules, err := ReadRulesFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rules) != 2 || rules[1].Filename != path || rules[1].LineNumber != 3 {
+		t.Errorf("expected the 2 rules of %s, got %+v", path, rules)
+	}
+
+	// Nothing is written next to the file
+	if entries, _ := os.ReadDir(dir); len(entries) != 1 {
+		t.Errorf("expected only the rules file in %s, got %d entries", dir, len(entries))
+	}
+}
@@ -0,0 +1,349 @@
+package pickit
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hectorgimenez/d2go/pkg/data"
+	"github.com/hectorgimenez/d2go/pkg/data/item"
+	"github.com/hectorgimenez/d2go/pkg/data/stat"
+	"github.com/hectorgimenez/d2go/pkg/nip"
+)
+
+const (
+	EvaluationMatch   = "match"
+	EvaluationPartial = "partial"
+	EvaluationNoMatch = "no match"
+
+	StageBase  = "base"
+	StageStats = "stats"
+)
+
+// ItemFixture describes a synthetic item used to test NIP rules. Either Name (d2go base name, e.g. "BattleBoots")
+// or DatabaseID (pickit item database ID, e.g. "unique_wartraveler") must be set, Stats keys are NIP stat aliases.
+type ItemFixture struct {
+	Label        string         `json:"label"`
+	DatabaseID   string         `json:"databaseId"`
+	Name         string         `json:"name"`
+	Quality      string         `json:"quality"`
+	Ethereal     bool           `json:"ethereal"`
+	Runeword     bool           `json:"runeword"`
+	Sockets      int            `json:"sockets"`
+	Unidentified bool           `json:"unidentified"`
+	Stats        map[string]int `json:"stats"`
+}
+
+// StageResult is the outcome of a single NIP stage, FailedClause holds the first top-level condition that failed.
+type StageResult struct {
+	Stage        string `json:"stage"`
+	Passed       bool   `json:"passed"`
+	FailedClause string `json:"failedClause,omitempty"`
+}
+
+// RuleEvaluation is the outcome of a NIP rule against a single fixture
+type RuleEvaluation struct {
+	Fixture string        `json:"fixture"`
+	Item    string        `json:"item"`
+	Result  string        `json:"result"`
+	Stages  []StageResult `json:"stages"`
+	Error   string        `json:"error,omitempty"`
+}
+
+// RuleSimulation is the outcome of a NIP rule against every fixture
+type RuleSimulation struct {
+	NIPLine     string           `json:"nipLine"`
+	Tier        float64          `json:"tier"`
+	MercTier    float64          `json:"mercTier"`
+	MaxQuantity int              `json:"maxQuantity"`
+	Evaluations []RuleEvaluation `json:"evaluations"`
+}
+
+// DisplayName returns the label of the fixture, falling back to the item it describes
+func (f ItemFixture) DisplayName() string {
+	switch {
+	case f.Label != "":
+		return f.Label
+	case f.DatabaseID != "":
+		if def, found := lookupDefinition(f.DatabaseID); found {
+			return def.Name
+		}
+		return f.DatabaseID
+	default:
+		return f.Name
+	}
+}
+
+// ToItem builds the d2go item the NIP engine evaluates
+func (f ItemFixture) ToItem() (data.Item, error) {
+	name := f.Name
+	quality := f.Quality
+	identifiedName := ""
+
+	if f.DatabaseID != "" {
+		def, found := lookupDefinition(f.DatabaseID)
+		if !found {
+			return data.Item{}, fmt.Errorf("item %q not found in database", f.DatabaseID)
+		}
+		if name == "" {
+			name = baseNameForDefinition(def)
+		}
+		if quality == "" && len(def.Quality) > 0 {
+			quality = def.Quality[0].ToString()
+		}
+		identifiedName = def.Name
+	}
+
+	id := item.GetIDByName(ToNIPName(name))
+	if id < 0 {
+		return data.Item{}, fmt.Errorf("unknown base item %q", name)
+	}
+
+	q, err := parseFixtureQuality(quality)
+	if err != nil {
+		return data.Item{}, err
+	}
+
+	stats := make(stat.Stats, 0, len(f.Stats)+1)
+	for alias, value := range f.Stats {
+		statData, found := nip.StatAliases[strings.ToLower(alias)]
+		if !found {
+			return data.Item{}, fmt.Errorf("unknown stat %q", alias)
+		}
+		layer := 0
+		if len(statData) > 1 {
+			layer = statData[1]
+		}
+		stats = append(stats, stat.Data{ID: stat.ID(statData[0]), Value: value, Layer: layer})
+	}
+	if f.Sockets > 0 {
+		stats = append(stats, stat.Data{ID: stat.NumSockets, Value: f.Sockets})
+	}
+
+	return data.Item{
+		ID:             id,
+		Name:           item.Name(item.Names[id]),
+		Quality:        q,
+		IdentifiedName: identifiedName,
+		Ethereal:       f.Ethereal,
+		IsRuneword:     f.Runeword,
+		Identified:     !f.Unidentified,
+		HasSockets:     f.Sockets > 0,
+		Stats:          stats,
+	}, nil
+}
+
+// DatabaseFixtures returns a fixture for every item in the pickit database, using its first possible quality
+func DatabaseFixtures() []ItemFixture {
+	defs := GetAllItemsV2()
+	fixtures := make([]ItemFixture, 0, len(defs))
+	for _, def := range defs {
+		fixtures = append(fixtures, ItemFixture{Label: def.Name, DatabaseID: def.ID})
+	}
+
+	return fixtures
+}
+
+// SimulateNIP compiles the NIP line with the same engine used in game and evaluates it against the fixtures.
+// Compile errors are returned, fixture errors are reported in the matching evaluation.
+func SimulateNIP(line string, fixtures []ItemFixture) (RuleSimulation, error) {
+	line = strings.TrimSpace(line)
+	rule, err := nip.NewRule(line, "simulator", 1)
+	if err != nil {
+		return RuleSimulation{}, err
+	}
+
+	baseSection, statsSection := splitNIPSections(line)
+	sim := RuleSimulation{
+		NIPLine:     line,
+		Tier:        rule.Tier(),
+		MercTier:    rule.MercTier(),
+		MaxQuantity: rule.MaxQuantity(),
+		Evaluations: make([]RuleEvaluation, 0, len(fixtures)),
+	}
+
+	for _, f := range fixtures {
+		sim.Evaluations = append(sim.Evaluations, evaluateFixture(rule, baseSection, statsSection, f))
+	}
+
+	return sim, nil
+}
+
+func evaluateFixture(rule nip.Rule, baseSection, statsSection string, f ItemFixture) RuleEvaluation {
+	ev := RuleEvaluation{Fixture: f.DisplayName(), Result: EvaluationNoMatch, Stages: []StageResult{}}
+
+	it, err := f.ToItem()
+	if err != nil {
+		ev.Error = err.Error()
+		return ev
+	}
+	ev.Item = string(it.Name)
+
+	res, err := rule.Evaluate(it)
+	if err != nil {
+		ev.Error = err.Error()
+		return ev
+	}
+
+	switch res {
+	case nip.RuleResultFullMatch:
+		ev.Result = EvaluationMatch
+	case nip.RuleResultPartial:
+		ev.Result = EvaluationPartial
+	}
+
+	basePassed := res != nip.RuleResultNoMatch
+	if !basePassed && statsSection != "" {
+		basePassed = clauseMatches(baseSection, it)
+	}
+	base := StageResult{Stage: StageBase, Passed: basePassed}
+	if !basePassed {
+		base.FailedClause = firstFailingClause(topLevelClauses(baseSection), it, func(clause string) string { return clause })
+	}
+	ev.Stages = append(ev.Stages, base)
+
+	if statsSection == "" || !basePassed {
+		return ev
+	}
+
+	// Unidentified items can't be checked against stats, the bot would keep them to identify later
+	stats := StageResult{Stage: StageStats, Passed: res == nip.RuleResultFullMatch}
+	if res == nip.RuleResultNoMatch {
+		stats.FailedClause = firstFailingClause(topLevelClauses(statsSection), it, func(clause string) string {
+			return baseSection + " # " + clause
+		})
+	}
+	ev.Stages = append(ev.Stages, stats)
+
+	return ev
+}
+
+// firstFailingClause evaluates every clause on its own and returns the first one that does not match. The whole
+// section is returned when every clause passes alone, e.g. for expressions that only fail combined.
+func firstFailingClause(clauses []string, it data.Item, build func(string) string) string {
+	for _, clause := range clauses {
+		if !clauseMatches(build(clause), it) {
+			return clause
+		}
+	}
+
+	return strings.Join(clauses, " && ")
+}
+
+// clauseMatches compiles a partial NIP line and reports whether the item passes it
+func clauseMatches(line string, it data.Item) bool {
+	r, err := nip.NewRule(line, "simulator", 1)
+	if err != nil {
+		return false
+	}
+	res, err := r.Evaluate(it)
+
+	return err == nil && res != nip.RuleResultNoMatch
+}
+
+// splitNIPSections returns the base (before the first #) and stats (between the first and second #) sections,
+// without comments. The third section only holds tier and maxquantity, so it's ignored.
+func splitNIPSections(line string) (string, string) {
+	if idx := strings.Index(line, "//"); idx >= 0 {
+		line = line[:idx]
+	}
+
+	parts := strings.Split(line, "#")
+	base := strings.TrimSpace(parts[0])
+	stats := ""
+	if len(parts) > 1 {
+		stats = strings.TrimSpace(parts[1])
+	}
+
+	return base, stats
+}
+
+// topLevelClauses splits a NIP section on && operators outside parentheses. Sections with a top-level || can't be
+// split without changing their meaning, so they are returned as a single clause.
+func topLevelClauses(section string) []string {
+	section = trimOuterParens(strings.TrimSpace(section))
+	if section == "" {
+		return nil
+	}
+
+	var clauses []string
+	depth, start := 0, 0
+	for i := 0; i < len(section); i++ {
+		switch section[i] {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case '|':
+			if depth == 0 && i+1 < len(section) && section[i+1] == '|' {
+				return []string{section}
+			}
+		case '&':
+			if depth == 0 && i+1 < len(section) && section[i+1] == '&' {
+				clauses = append(clauses, strings.TrimSpace(section[start:i]))
+				start = i + 2
+				i++
+			}
+		}
+	}
+	clauses = append(clauses, strings.TrimSpace(section[start:]))
+
+	return clauses
+}
+
+// trimOuterParens removes parentheses wrapping the whole expression, e.g. "([a] && [b])"
+func trimOuterParens(s string) string {
+	for strings.HasPrefix(s, "(") && strings.HasSuffix(s, ")") {
+		depth := 0
+		wrapsAll := true
+		for i := 0; i < len(s)-1; i++ {
+			switch s[i] {
+			case '(':
+				depth++
+			case ')':
+				depth--
+			}
+			if depth == 0 {
+				wrapsAll = false
+				break
+			}
+		}
+		if !wrapsAll {
+			return s
+		}
+		s = strings.TrimSpace(s[1 : len(s)-1])
+	}
+
+	return s
+}
+
+func lookupDefinition(id string) (ItemDefinition, bool) {
+	if def, found := GetItemByIDV2(id); found {
+		return def, true
+	}
+
+	return GetItemByID(id)
+}
+
+// baseNameForDefinition returns the first name of the definition d2go knows as a base item
+func baseNameForDefinition(def ItemDefinition) string {
+	for _, candidate := range []string{def.BaseItem, def.NIPName, def.InternalName, def.Name} {
+		if candidate != "" && item.GetIDByName(ToNIPName(candidate)) >= 0 {
+			return candidate
+		}
+	}
+
+	return def.Name
+}
+
+func parseFixtureQuality(quality string) (item.Quality, error) {
+	if quality == "" {
+		return item.QualityNormal, nil
+	}
+	for q := item.QualityLowQuality; q <= item.QualityCrafted; q++ {
+		if strings.EqualFold(q.ToString(), strings.ReplaceAll(quality, " ", "")) {
+			return q, nil
+		}
+	}
+
+	return 0, fmt.Errorf("unknown quality %q", quality)
+}
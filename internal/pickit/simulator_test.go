@@ -0,0 +1,62 @@
+package pickit
+
+import "testing"
+
+func TestSimulateNIP(t *testing.T) {
+	line := "[name] == battleboots && [quality] == unique # [itemmagicbonus] >= 50 && [frw] >= 25 // war traveler"
+	fixtures := []ItemFixture{
+		{Label: "perfect", Name: "BattleBoots", Quality: "unique", Stats: map[string]int{"itemmagicbonus": 50, "frw": 25}},
+		{Label: "low mf", Name: "BattleBoots", Quality: "unique", Stats: map[string]int{"itemmagicbonus": 30, "frw": 25}},
+		{Label: "unidentified", Name: "BattleBoots", Quality: "unique", Unidentified: true},
+		{Label: "wrong quality", Name: "BattleBoots", Quality: "rare"},
+		{Label: "unknown base", Name: "NotAnItem"},
+	}
+
+	sim, err := SimulateNIP(line, fixtures)
+	if err != nil {
+		t.Fatalf("unexpected compile error: %v", err)
+	}
+
+	expected := []struct {
+		result       string
+		failedClause string
+	}{
+		{EvaluationMatch, ""},
+		{EvaluationNoMatch, "[itemmagicbonus] >= 50"},
+		{EvaluationPartial, ""},
+		{EvaluationNoMatch, "[quality] == unique"},
+		{EvaluationNoMatch, ""},
+	}
+	for i, exp := range expected {
+		ev := sim.Evaluations[i]
+		if ev.Result != exp.result {
+			t.Errorf("%s: expected result %q, got %q (error: %s)", ev.Fixture, exp.result, ev.Result, ev.Error)
+		}
+		failed := ""
+		for _, stage := range ev.Stages {
+			if stage.FailedClause != "" {
+				failed = stage.FailedClause
+			}
+		}
+		if failed != exp.failedClause {
+			t.Errorf("%s: expected failed clause %q, got %q", ev.Fixture, exp.failedClause, failed)
+		}
+	}
+	if sim.Evaluations[4].Error == "" {
+		t.Errorf("expected an error for an unknown base item")
+	}
+}
+
+func TestTopLevelClauses(t *testing.T) {
+	cases := map[string]int{
+		"[type] == ring && [quality] == unique":             2,
+		"([fcr] >= 10 && [strength] >= 5) && [maxhp] >= 20": 2,
+		"[fcr] >= 10 || [strength] >= 5":                    1,
+		"([type] == ring && [quality] == rare)":             2,
+	}
+	for section, count := range cases {
+		if got := len(topLevelClauses(section)); got != count {
+			t.Errorf("%q: expected %d clauses, got %d", section, count, got)
+		}
+	}
+}
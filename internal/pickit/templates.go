@@ -381,5 +381,15 @@ func DetectConflicts(rules []PickitRule) []ConflictDetection {
 		}
 	}
 
+	// Rules with generated NIP are also analyzed semantically, in the order they were given
+	lines := make([]NIPSourceLine, 0, len(rules))
+	for i, rule := range rules {
+		if rule.GeneratedNIP == "" || !rule.Enabled {
+			continue
+		}
+		lines = append(lines, NIPSourceLine{ID: rule.ID, File: rule.FileName, Line: i + 1, Raw: rule.GeneratedNIP})
+	}
+	conflicts = append(conflicts, AnalyzeNIP(lines)...)
+
 	return conflicts
 }
@@ -106,12 +106,25 @@ type ValidationResult struct {
 
 // SimulationResult represents the result of testing a rule
 type SimulationResult struct {
-	RuleID      string      `json:"ruleId"`      // Rule being tested
-	MatchCount  int         `json:"matchCount"`  // Number of items matched
-	Matches     []ItemMatch `json:"matches"`     // Matched items
-	Misses      []ItemMatch `json:"misses"`      // Items that didn't match
-	Performance string      `json:"performance"` // Performance assessment
-	Suggestions []string    `json:"suggestions"` // Optimization suggestions
+	RuleID      string           `json:"ruleId"`      // Rule being tested
+	NIPLine     string           `json:"nipLine"`     // NIP line that was evaluated
+	Tier        float64          `json:"tier"`        // Rule tier, 0 when not set
+	MercTier    float64          `json:"mercTier"`    // Rule merc tier, 0 when not set
+	MatchCount  int              `json:"matchCount"`  // Number of items matched
+	Matches     []ItemMatch      `json:"matches"`     // Matched items
+	Misses      []ItemMatch      `json:"misses"`      // Items that didn't match
+	Evaluations []RuleEvaluation `json:"evaluations"` // Per fixture stage results
+	Performance string           `json:"performance"` // Performance assessment
+	Suggestions []string         `json:"suggestions"` // Optimization suggestions
+}
+
+// SimulationRequest is the body of a simulation, either a rule built in the editor or a raw NIP line
+// evaluated against custom fixtures and/or items picked from the database
+type SimulationRequest struct {
+	PickitRule
+	NIPLine       string        `json:"nipLine"`       // Raw NIP line, takes precedence over the rule
+	Fixtures      []ItemFixture `json:"fixtures"`      // Synthetic items to test
+	DatabaseItems []string      `json:"databaseItems"` // Item database IDs to test
 }
 
 // ItemMatch represents an item that matched or didn't match a rule
@@ -137,11 +150,21 @@ type StatPreset struct {
 
 // ConflictDetection represents detected conflicts between rules
 type ConflictDetection struct {
-	Type        string   `json:"type"`        // Type of conflict (duplicate, overlapping, etc.)
-	Rules       []string `json:"rules"`       // Conflicting rule IDs
-	Severity    string   `json:"severity"`    // Severity (warning, error)
-	Description string   `json:"description"` // Conflict description
-	Suggestion  string   `json:"suggestion"`  // How to resolve
+	Type        string          `json:"type"`                 // Type of conflict (duplicate, overlapping, etc.)
+	Rules       []string        `json:"rules"`                // Conflicting rule IDs
+	Severity    string          `json:"severity"`             // Severity (warning, error)
+	Description string          `json:"description"`          // Conflict description
+	Suggestion  string          `json:"suggestion"`           // How to resolve
+	References  []RuleReference `json:"references,omitempty"` // File and line of each conflicting rule
+	Loser       string          `json:"loser,omitempty"`      // ID of the rule to fix or remove, the one never applied
+}
+
+// RuleReference points at a NIP line inside a pickit file
+type RuleReference struct {
+	ID   string `json:"id"`   // Rule ID as used by the editor (file_lineIndex)
+	File string `json:"file"` // File name
+	Line int    `json:"line"` // 1-based line number
+	Rule string `json:"rule"` // Raw NIP line
 }
 
 // EditorPreferences represents user preferences for the editor
@@ -0,0 +1,185 @@
+// Package command implements the remote control commands shared by the chat integrations (Discord, Telegram). Commands
+// return transport neutral replies, each integration renders them with its own formatting.
+package command
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/hectorgimenez/koolo/internal/bot"
+)
+
+// Field is a name/value pair, rendered as an embed field on Discord
+type Field struct {
+	Name   string
+	Value  string
+	Inline bool
+}
+
+// Reply is a single message sent back to the user
+type Reply struct {
+	Title  string
+	Text   string
+	Fields []Field
+	Color  int
+	Footer string
+	// Choices are supervisors the command can be run for, integrations supporting buttons render one per supervisor
+	// running Command with it as argument
+	Choices []string
+	Command string
+	// Supervisor the reply is about, integrations may add buttons to control it
+	Supervisor string
+}
+
+// ParamKind tells integrations how to complete a parameter
+type ParamKind int
+
+const (
+	ParamText ParamKind = iota
+	ParamNumber
+	ParamSupervisor
+	ParamRun
+)
+
+// Param is a command argument
+type Param struct {
+	Name     string
+	Help     string
+	Kind     ParamKind
+	Required bool
+	Variadic bool // Accepts several space separated values, it must be the last parameter
+}
+
+// Command is a remote control command
+type Command struct {
+	Name   string
+	Help   string
+	Params []Param
+	run    func(h *Handler, args []string) []Reply
+}
+
+// Usage returns the command syntax using the given prefix
+func (c Command) Usage(prefix string) string {
+	usage := prefix + c.Name
+	for _, p := range c.Params {
+		arg := p.Name
+		if p.Variadic {
+			arg = p.Name + "1"
+		}
+		if p.Required {
+			usage += " <" + arg + ">"
+		} else {
+			usage += " [" + arg + "]"
+		}
+		if p.Variadic {
+			usage += " [" + p.Name + "2] ..."
+		}
+	}
+
+	return usage
+}
+
+// Handler runs commands against the supervisor manager
+type Handler struct {
+	manager  *bot.SupervisorManager
+	prefix   string
+	commands []Command
+}
+
+// NewHandler creates a command handler, prefix is the command prefix of the integration ("!" or "/")
+func NewHandler(manager *bot.SupervisorManager, prefix string) *Handler {
+	h := &Handler{manager: manager, prefix: prefix}
+	supervisors := Param{Name: "supervisor", Help: "Supervisor name", Kind: ParamSupervisor, Required: true, Variadic: true}
+	supervisor := Param{Name: "supervisor", Help: "Supervisor name", Kind: ParamSupervisor, Required: true}
+	h.commands = []Command{
+		{Name: "list", Help: "Show all available supervisors with their status and uptime", run: (*Handler).list},
+		{Name: "start", Help: "Start one or more supervisors", Params: []Param{supervisors}, run: (*Handler).start},
+		{Name: "stop", Help: "Stop one or more supervisors", Params: []Param{supervisors}, run: (*Handler).stop},
+		{Name: "pause", Help: "Pause one or more running supervisors", Params: []Param{supervisors}, run: (*Handler).pause},
+		{Name: "resume", Help: "Resume one or more paused supervisors", Params: []Param{supervisors}, run: (*Handler).resume},
+		{Name: "status", Help: "Check the current status of supervisors", Params: []Param{supervisors}, run: (*Handler).status},
+		{Name: "stats", Help: "Get detailed statistics for supervisors", Params: []Param{supervisors}, run: (*Handler).stats},
+		{Name: "drops", Help: "Show recent drops for a supervisor, default count: 5", Params: []Param{
+			supervisor,
+			{Name: "count", Help: "Number of drops, max 20", Kind: ParamNumber},
+		}, run: (*Handler).drops},
+		{Name: "runs", Help: "Show the runs of a supervisor, or whether the given runs are part of them", Params: []Param{
+			supervisor,
+			{Name: "run", Help: "Run names to check", Kind: ParamRun, Variadic: true},
+		}, run: (*Handler).runs},
+		{Name: "join", Help: "Make a companion follower join a game", Params: []Param{
+			supervisor,
+			{Name: "game", Help: "Game name", Required: true},
+			{Name: "password", Help: "Game password"},
+		}, run: (*Handler).join},
+		{Name: "help", Help: "Show this help message", run: (*Handler).help},
+	}
+
+	return h
+}
+
+// Commands returns the available commands
+func (h *Handler) Commands() []Command {
+	return h.commands
+}
+
+// Prefix returns the command prefix
+func (h *Handler) Prefix() string {
+	return h.prefix
+}
+
+// Find returns a command by name, case-insensitive
+func (h *Handler) Find(name string) (Command, bool) {
+	for _, c := range h.commands {
+		if strings.EqualFold(c.Name, name) {
+			return c, true
+		}
+	}
+
+	return Command{}, false
+}
+
+// Execute parses and runs a command line such as "!start Koza". Telegram style bot mentions ("/start@my_bot") are
+// accepted. ok is false when the line isn't a command.
+func (h *Handler) Execute(line string) (replies []Reply, ok bool) {
+	words := strings.Fields(line)
+	if len(words) == 0 || !strings.HasPrefix(words[0], h.prefix) {
+		return nil, false
+	}
+
+	name, _, _ := strings.Cut(strings.TrimPrefix(words[0], h.prefix), "@")
+	return h.Run(name, words[1:]), true
+}
+
+// Run runs a command by name with the given arguments
+func (h *Handler) Run(name string, args []string) []Reply {
+	c, found := h.Find(name)
+	if !found {
+		return []Reply{{Text: fmt.Sprintf("Unknown command: %s%s. Type %shelp for available commands.", h.prefix, name, h.prefix)}}
+	}
+
+	return c.run(h, args)
+}
+
+func (h *Handler) supervisorExists(supervisor string) bool {
+	return slices.Contains(h.manager.AvailableSupervisors(), supervisor)
+}
+
+func (h *Handler) isRunning(supervisor string) bool {
+	status := h.manager.Status(supervisor).SupervisorStatus
+	return status != bot.NotStarted && status != ""
+}
+
+// pickSupervisor is the reply of commands called without supervisor
+func (h *Handler) pickSupervisor(name string) []Reply {
+	c, _ := h.Find(name)
+	supervisors := h.manager.AvailableSupervisors()
+	slices.Sort(supervisors)
+
+	return []Reply{{Text: "Usage: " + c.Usage(h.prefix), Choices: supervisors, Command: c.Name}}
+}
+
+func notFound(supervisor string) Reply {
+	return Reply{Text: fmt.Sprintf("Supervisor '%s' not found.", supervisor)}
+}
@@ -0,0 +1,308 @@
+package command
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hectorgimenez/koolo/internal/bot"
+	"github.com/hectorgimenez/koolo/internal/config"
+	"github.com/hectorgimenez/koolo/internal/event"
+)
+
+const (
+	colorBlurple = 0x5865F2
+	colorGold    = 0xFFD700
+)
+
+// forEach runs fn for every supervisor argument, replying with the usage and supervisor choices when there is none
+func (h *Handler) forEach(name string, args []string, fn func(supervisor string) Reply) []Reply {
+	if len(args) == 0 {
+		return h.pickSupervisor(name)
+	}
+
+	replies := make([]Reply, 0, len(args))
+	for _, supervisor := range args {
+		if !h.supervisorExists(supervisor) {
+			replies = append(replies, notFound(supervisor))
+			continue
+		}
+		replies = append(replies, fn(supervisor))
+	}
+
+	return replies
+}
+
+func (h *Handler) start(args []string) []Reply {
+	return h.forEach("start", args, func(supervisor string) Reply {
+		if err := h.manager.Start(supervisor, false, false); err != nil {
+			return Reply{Text: fmt.Sprintf("Supervisor '%s' could not be started: %s", supervisor, err)}
+		}
+
+		// Wait for the supervisor to start
+		time.Sleep(1 * time.Second)
+
+		return Reply{Text: fmt.Sprintf("Supervisor '%s' has been started.", supervisor)}
+	})
+}
+
+func (h *Handler) stop(args []string) []Reply {
+	return h.forEach("stop", args, func(supervisor string) Reply {
+		if !h.isRunning(supervisor) {
+			return Reply{Text: fmt.Sprintf("Supervisor '%s' is not running.", supervisor)}
+		}
+
+		h.manager.Stop(supervisor)
+
+		// Wait for the supervisor to stop
+		time.Sleep(1 * time.Second)
+
+		return Reply{Text: fmt.Sprintf("Supervisor '%s' has been stopped.", supervisor)}
+	})
+}
+
+func (h *Handler) pause(args []string) []Reply {
+	return h.forEach("pause", args, func(supervisor string) Reply {
+		status := h.manager.Status(supervisor).SupervisorStatus
+		switch {
+		case !h.isRunning(supervisor):
+			return Reply{Text: fmt.Sprintf("Supervisor '%s' is not running.", supervisor)}
+		case status == bot.Paused:
+			return Reply{Text: fmt.Sprintf("Supervisor '%s' is already paused.", supervisor)}
+		}
+
+		h.manager.TogglePause(supervisor)
+
+		return Reply{Text: fmt.Sprintf("Supervisor '%s' has been paused.", supervisor)}
+	})
+}
+
+func (h *Handler) resume(args []string) []Reply {
+	return h.forEach("resume", args, func(supervisor string) Reply {
+		if h.manager.Status(supervisor).SupervisorStatus != bot.Paused {
+			return Reply{Text: fmt.Sprintf("Supervisor '%s' is not paused.", supervisor)}
+		}
+
+		h.manager.TogglePause(supervisor)
+
+		return Reply{Text: fmt.Sprintf("Supervisor '%s' has been resumed.", supervisor)}
+	})
+}
+
+func (h *Handler) status(args []string) []Reply {
+	return h.forEach("status", args, func(supervisor string) Reply {
+		if !h.isRunning(supervisor) {
+			return Reply{Text: fmt.Sprintf("Supervisor '%s' is offline.", supervisor), Supervisor: supervisor}
+		}
+
+		return Reply{Text: fmt.Sprintf("Supervisor '%s' is %s", supervisor, h.manager.Status(supervisor).SupervisorStatus), Supervisor: supervisor}
+	})
+}
+
+func (h *Handler) stats(args []string) []Reply {
+	return h.forEach("stats", args, func(supervisor string) Reply {
+		// Fix for the status not being started
+		supStatus := string(h.manager.Status(supervisor).SupervisorStatus)
+		if supStatus == string(bot.NotStarted) || supStatus == "" {
+			supStatus = "Offline"
+		}
+		stats := h.manager.GetSupervisorStats(supervisor)
+
+		return Reply{
+			Supervisor: supervisor,
+			Title:      fmt.Sprintf("Stats for %s", supervisor),
+			Fields: []Field{
+				{Name: "Status", Value: supStatus, Inline: true},
+				{Name: "Uptime", Value: time.Since(h.manager.Status(supervisor).StartedAt).String(), Inline: true},
+				{Name: "Games", Value: strconv.Itoa(stats.TotalGames()), Inline: true},
+				{Name: "Drops", Value: strconv.Itoa(len(stats.Drops)), Inline: true},
+				{Name: "Deaths", Value: strconv.Itoa(stats.TotalDeaths()), Inline: true},
+				{Name: "Chickens", Value: strconv.Itoa(stats.TotalChickens()), Inline: true},
+				{Name: "Errors", Value: strconv.Itoa(stats.TotalErrors()), Inline: true},
+			},
+		}
+	})
+}
+
+func (h *Handler) list(_ []string) []Reply {
+	supervisors := h.manager.AvailableSupervisors()
+	if len(supervisors) == 0 {
+		return []Reply{{Text: "No supervisors available."}}
+	}
+
+	fields := make([]Field, 0, len(supervisors))
+	for _, supervisor := range supervisors {
+		status := h.manager.Status(supervisor)
+		statusText, uptimeText := "❌ Offline", "-"
+		if h.isRunning(supervisor) {
+			statusText = fmt.Sprintf("✅ %s", status.SupervisorStatus)
+			uptime := time.Since(status.StartedAt)
+			if uptime < time.Minute {
+				uptimeText = fmt.Sprintf("%ds", int(uptime.Seconds()))
+			} else if uptime < time.Hour {
+				uptimeText = fmt.Sprintf("%dm", int(uptime.Minutes()))
+			} else {
+				uptimeText = fmt.Sprintf("%dh %dm", int(uptime.Hours()), int(uptime.Minutes())%60)
+			}
+		}
+
+		fields = append(fields, Field{
+			Name:   supervisor,
+			Value:  fmt.Sprintf("Status: %s\nUptime: %s", statusText, uptimeText),
+			Inline: true,
+		})
+	}
+
+	return []Reply{{Title: "📋 Available Supervisors", Fields: fields, Color: colorBlurple}}
+}
+
+func (h *Handler) help(_ []string) []Reply {
+	fields := make([]Field, 0, len(h.commands))
+	for _, c := range h.commands {
+		fields = append(fields, Field{Name: c.Usage(h.prefix), Value: c.Help})
+	}
+
+	return []Reply{{
+		Title:  "🤖 Koolo Bot Commands",
+		Text:   "Control and monitor your Diablo II bot supervisors",
+		Fields: fields,
+		Color:  colorBlurple,
+		Footer: "💡 Tip: You can control multiple supervisors at once with most commands",
+	}}
+}
+
+func (h *Handler) drops(args []string) []Reply {
+	if len(args) == 0 {
+		return h.pickSupervisor("drops")
+	}
+
+	supervisor := args[0]
+	if !h.supervisorExists(supervisor) {
+		return []Reply{notFound(supervisor)}
+	}
+
+	// Default count is 5, max is 20
+	count := 5
+	if len(args) > 1 {
+		if n, err := strconv.Atoi(args[1]); err == nil && n > 0 {
+			count = min(n, 20)
+		}
+	}
+
+	drops := h.manager.GetSupervisorStats(supervisor).Drops
+	if len(drops) == 0 {
+		return []Reply{{Text: fmt.Sprintf("No drops recorded for '%s' yet.", supervisor)}}
+	}
+	recentDrops := drops[max(0, len(drops)-count):]
+
+	var description strings.Builder
+	// Newest first
+	for i := len(recentDrops) - 1; i >= 0; i-- {
+		it := recentDrops[i].Item
+		quality := it.Quality.ToString()
+
+		emoji := "⚪"
+		switch strings.ToLower(quality) {
+		case "unique":
+			emoji = "🟠"
+		case "set":
+			emoji = "🟢"
+		case "rare":
+			emoji = "🟡"
+		case "magic":
+			emoji = "🔵"
+		}
+		if strings.Contains(strings.ToLower(string(it.Name)), "rune") {
+			emoji = "🟣"
+		}
+
+		itemName := string(it.Name)
+		if quality != "" && quality != "Normal" {
+			itemName = fmt.Sprintf("%s %s", quality, it.Name)
+		}
+		description.WriteString(fmt.Sprintf("%s %s", emoji, itemName))
+		if desc := it.Desc(); desc.Name != "" && desc.Name != string(it.Name) {
+			description.WriteString(fmt.Sprintf(" (%s)", desc.Name))
+		}
+		description.WriteString("\n")
+	}
+
+	return []Reply{{
+		Title:  fmt.Sprintf("💎 Recent Drops for %s", supervisor),
+		Text:   description.String(),
+		Color:  colorGold,
+		Footer: fmt.Sprintf("Showing last %d of %d total drops", len(recentDrops), len(drops)),
+	}}
+}
+
+func (h *Handler) runs(args []string) []Reply {
+	if len(args) == 0 {
+		return h.pickSupervisor("runs")
+	}
+
+	supervisor := args[0]
+	cfg, found := config.GetCharacter(supervisor)
+	if !found {
+		return []Reply{notFound(supervisor)}
+	}
+
+	if len(args) == 1 {
+		names := make([]string, 0, len(cfg.Game.Runs))
+		for _, r := range cfg.Game.Runs {
+			names = append(names, string(r))
+		}
+		if len(names) == 0 {
+			return []Reply{{Text: fmt.Sprintf("Supervisor '%s' has no runs configured.", supervisor)}}
+		}
+		return []Reply{{Title: fmt.Sprintf("Runs for %s", supervisor), Text: strings.Join(names, ", "), Color: colorBlurple}}
+	}
+
+	var lines []string
+	for _, arg := range args[1:] {
+		for _, name := range strings.Split(arg, ",") {
+			if name = strings.TrimSpace(name); name == "" {
+				continue
+			}
+			if _, ok := config.AvailableRuns[config.Run(name)]; !ok {
+				return []Reply{{Text: fmt.Sprintf("Unknown run '%s'.", name)}}
+			}
+			if slices.Contains(cfg.Game.Runs, config.Run(name)) {
+				lines = append(lines, fmt.Sprintf("✅ %s", name))
+			} else {
+				lines = append(lines, fmt.Sprintf("❌ %s", name))
+			}
+		}
+	}
+
+	return []Reply{{Title: fmt.Sprintf("Runs for %s", supervisor), Text: strings.Join(lines, "\n"), Color: colorBlurple}}
+}
+
+func (h *Handler) join(args []string) []Reply {
+	if len(args) == 0 {
+		return h.pickSupervisor("join")
+	}
+
+	supervisor := args[0]
+	cfg, found := config.GetCharacter(supervisor)
+	if !found {
+		return []Reply{notFound(supervisor)}
+	}
+	if !cfg.Companion.Enabled || cfg.Companion.Leader {
+		return []Reply{{Text: fmt.Sprintf("Supervisor '%s' is not a companion follower.", supervisor)}}
+	}
+	if len(args) < 2 {
+		c, _ := h.Find("join")
+		return []Reply{{Text: "Usage: " + c.Usage(h.prefix)}}
+	}
+
+	gameName, password := args[1], ""
+	if len(args) > 2 {
+		password = args[2]
+	}
+	baseEvent := event.Text(supervisor, fmt.Sprintf("Remote request to join game %s", gameName))
+	event.Send(event.RequestCompanionJoinGame(baseEvent, cfg.CharacterName, gameName, password))
+
+	return []Reply{{Text: fmt.Sprintf("Supervisor '%s' will join game %s.", supervisor, gameName)}}
+}
@@ -3,12 +3,14 @@ package discord
 import (
 	"context"
 	"fmt"
+	"log/slog"
 	"slices"
 	"strings"
 
 	"github.com/bwmarrin/discordgo"
 	"github.com/hectorgimenez/koolo/internal/bot"
 	"github.com/hectorgimenez/koolo/internal/config"
+	"github.com/hectorgimenez/koolo/internal/remote/command"
 )
 
 type Bot struct {
@@ -19,9 +21,11 @@ type Bot struct {
 	useWebhook     bool
 	webhookClient  *webhookClient
 	itemWebhook    *webhookClient
+	commands       *command.Handler
+	logger         *slog.Logger
 }
 
-func NewBot(token, channelID, itemChannelID string, manager *bot.SupervisorManager, useWebhook bool, webhookURL, itemWebhookURL string) (*Bot, error) {
+func NewBot(token, channelID, itemChannelID string, manager *bot.SupervisorManager, useWebhook bool, webhookURL, itemWebhookURL string, logger *slog.Logger) (*Bot, error) {
 	botInstance := &Bot{
 		channelID:     channelID,
 		itemChannelID: strings.TrimSpace(itemChannelID),
@@ -29,6 +33,8 @@ func NewBot(token, channelID, itemChannelID string, manager *bot.SupervisorManag
 		useWebhook:    useWebhook,
 		webhookClient: nil,
 		itemWebhook:   nil,
+		commands:      command.NewHandler(manager, "!"),
+		logger:        logger,
 	}
 
 	if useWebhook {
@@ -60,12 +66,17 @@ func (b *Bot) Start(ctx context.Context) error {
 
 	//b.discordSession.Debug = true
 	b.discordSession.AddHandler(b.onMessageCreated)
+	b.discordSession.AddHandler(b.onInteractionCreate)
 	// Add MESSAGE_CONTENT intent to read message content (required by Discord)
 	b.discordSession.Identify.Intents = discordgo.IntentsGuildMessages | discordgo.IntentMessageContent
 	err := b.discordSession.Open()
 	if err != nil {
 		return fmt.Errorf("error opening connection: %w", err)
 	}
+	// Plain text commands keep working when slash commands can't be registered
+	if err = b.registerSlashCommands(b.discordSession); err != nil {
+		b.logger.Warn("Discord slash commands could not be registered", slog.Any("error", err))
+	}
 
 	// Wait until context is finished
 	<-ctx.Done()
@@ -94,25 +105,6 @@ func (b *Bot) onMessageCreated(s *discordgo.Session, m *discordgo.MessageCreate)
 		return
 	}
 
-	prefix := strings.Split(m.Content, " ")[0]
-	switch prefix {
-	case "!start":
-		b.handleStartRequest(s, m)
-	case "!stop":
-		b.handleStopRequest(s, m)
-	case "!stats":
-		b.handleStatsRequest(s, m)
-	case "!status":
-		b.handleStatusRequest(s, m)
-	case "!list":
-		b.handleListRequest(s, m)
-	case "!help":
-		b.handleHelpRequest(s, m)
-	case "!drops":
-		b.handleDropsRequest(s, m)
-	default:
-		// Unknown command - send help
-		s.ChannelMessageSend(m.ChannelID, fmt.Sprintf("Unknown command: `%s`. Type `!help` for available commands.", prefix))
-	}
-
+	replies, _ := b.commands.Execute(m.Content)
+	b.sendReplies(s, m.ChannelID, replies)
 }
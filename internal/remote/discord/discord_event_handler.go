@@ -43,14 +43,14 @@ var excludedStatIDs = map[int]bool{
 	326: true,
 }
 
-func (b *Bot) Handle(ctx context.Context, e event.Event) error {
-	if !b.shouldPublish(e) {
-		return nil
-	}
-
+// Notify sends an event to the Discord channels, filtering is done by the notification router (see remote/notify)
+func (b *Bot) Notify(ctx context.Context, e event.Event) error {
 	switch evt := e.(type) {
 	case event.GameCreatedEvent:
-		message := fmt.Sprintf("**[%s]** %s\nGame: %s\nPassword: %s", evt.Supervisor(), evt.Message(), evt.Name, evt.Password)
+		message := fmt.Sprintf("**[%s]** %s\nGame: %s", evt.Supervisor(), evt.Message(), evt.Name)
+		if evt.Password != "" {
+			message += "\nPassword: " + evt.Password
+		}
 		return b.sendEventMessage(ctx, message)
 	case event.GameFinishedEvent:
 		message := fmt.Sprintf("**[%s]** %s", evt.Supervisor(), evt.Message())
@@ -63,6 +63,12 @@ func (b *Bot) Handle(ctx context.Context, e event.Event) error {
 		return b.sendEventMessage(ctx, message)
 	case event.NgrokTunnelEvent:
 		return b.sendEventMessage(ctx, evt.Message())
+	case event.RunewordRerollEvent:
+		message := fmt.Sprintf("**[%s]** runeword reroll of **%s** stopped: %s\nTarget: %s\nRolled: %s", evt.Supervisor(), evt.Runeword, evt.FailureReason, evt.TargetStats, evt.ActualStats)
+		if evt.Success {
+			message = fmt.Sprintf("**[%s]** runeword reroll of **%s** succeeded\nTarget: %s\nRolled: %s", evt.Supervisor(), evt.Runeword, evt.TargetStats, evt.ActualStats)
+		}
+		return b.sendEventMessage(ctx, message)
 	case event.ItemStashedEvent:
 		if config.Koolo.Discord.DisableItemStashScreenshots {
 			if b.useWebhook {
@@ -79,7 +85,7 @@ func (b *Bot) Handle(ctx context.Context, e event.Event) error {
 			return err
 		}
 		message := fmt.Sprintf("**[%s]** %s", e.Supervisor(), e.Message())
-		return b.sendItemScreenshot(ctx, message, buf.Bytes())
+		return b.sendItemScreenshot(ctx, message, buf.Bytes(), itemStashComponents(evt))
 	default:
 		break
 	}
@@ -98,20 +104,23 @@ func (b *Bot) Handle(ctx context.Context, e event.Event) error {
 }
 
 func (b *Bot) sendItemStashEmbed(evt event.ItemStashedEvent) error {
-	embed := buildItemStashEmbed(evt)
-	_, err := b.discordSession.ChannelMessageSendEmbed(b.itemChannel(), embed)
+	_, err := b.discordSession.ChannelMessageSendComplex(b.itemChannel(), &discordgo.MessageSend{
+		Embeds:     []*discordgo.MessageEmbed{buildItemStashEmbed(evt)},
+		Components: itemStashComponents(evt),
+	})
 	return err
 }
 
-func (b *Bot) sendItemScreenshot(ctx context.Context, message string, image []byte) error {
+func (b *Bot) sendItemScreenshot(ctx context.Context, message string, image []byte, components []discordgo.MessageComponent) error {
 	if b.useWebhook {
 		return b.itemWebhookClient().Send(ctx, message, "Screenshot.jpeg", image)
 	}
 
 	reader := bytes.NewReader(image)
 	_, err := b.discordSession.ChannelMessageSendComplex(b.itemChannel(), &discordgo.MessageSend{
-		Files:   []*discordgo.File{{Name: "Screenshot.jpeg", ContentType: "image/jpeg", Reader: reader}},
-		Content: message,
+		Files:      []*discordgo.File{{Name: "Screenshot.jpeg", ContentType: "image/jpeg", Reader: reader}},
+		Content:    message,
+		Components: components,
 	})
 	return err
 }
@@ -453,32 +462,3 @@ func (b *Bot) sendScreenshot(ctx context.Context, message string, image []byte)
 	})
 	return err
 }
-
-func (b *Bot) shouldPublish(e event.Event) bool {
-
-	switch evt := e.(type) {
-	case event.GameFinishedEvent:
-		if evt.Reason == event.FinishedError {
-			return config.Koolo.Discord.EnableDiscordErrorMessages
-		}
-		if evt.Reason == event.FinishedChicken || evt.Reason == event.FinishedMercChicken || evt.Reason == event.FinishedDied {
-			return config.Koolo.Discord.EnableDiscordChickenMessages
-		}
-		if evt.Reason == event.FinishedOK {
-			return false // supress game finished messages until we add proper option for it
-		}
-		return true
-	case event.GameCreatedEvent:
-		return config.Koolo.Discord.EnableGameCreatedMessages
-	case event.RunStartedEvent:
-		return config.Koolo.Discord.EnableNewRunMessages
-	case event.RunFinishedEvent:
-		return config.Koolo.Discord.EnableRunFinishMessages
-	case event.NgrokTunnelEvent:
-		return true
-	default:
-		break
-	}
-
-	return e.Image() != nil
-}
@@ -0,0 +1,376 @@
+package discord
+
+import (
+	"fmt"
+	"log/slog"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/hectorgimenez/d2go/pkg/data"
+	"github.com/hectorgimenez/d2go/pkg/data/item"
+	"github.com/hectorgimenez/koolo/internal/bot"
+	"github.com/hectorgimenez/koolo/internal/config"
+	"github.com/hectorgimenez/koolo/internal/drop"
+	"github.com/hectorgimenez/koolo/internal/event"
+	"github.com/hectorgimenez/koolo/internal/remote/command"
+)
+
+// Custom IDs of buttons and modals, values are separated by ":"
+const (
+	customIDCommand   = "koolo-cmd"  // koolo-cmd:<command>:<supervisor>
+	customIDDrop      = "koolo-drop" // koolo-drop:<supervisor>:<quality>:<unique/set id>:<item name>
+	customIDDropModal = "koolo-drop-modal"
+	dropRoomInput     = "room"
+	dropPasswordInput = "password"
+	// Discord limits
+	maxChoices  = 25
+	maxCustomID = 100
+)
+
+// registerSlashCommands creates an application command per remote command, replacing the existing ones
+func (b *Bot) registerSlashCommands(s *discordgo.Session) error {
+	cmds := make([]*discordgo.ApplicationCommand, 0, len(b.commands.Commands()))
+	for _, c := range b.commands.Commands() {
+		ac := &discordgo.ApplicationCommand{Name: c.Name, Description: c.Help}
+		for _, p := range c.Params {
+			opt := &discordgo.ApplicationCommandOption{
+				Type:        discordgo.ApplicationCommandOptionString,
+				Name:        p.Name,
+				Description: p.Help,
+				Required:    p.Required,
+			}
+			switch p.Kind {
+			case command.ParamNumber:
+				opt.Type = discordgo.ApplicationCommandOptionInteger
+			case command.ParamSupervisor, command.ParamRun:
+				opt.Autocomplete = true
+			}
+			if p.Variadic {
+				opt.Description += " (space separated)"
+			}
+			ac.Options = append(ac.Options, opt)
+		}
+		cmds = append(cmds, ac)
+	}
+
+	_, err := s.ApplicationCommandBulkOverwrite(s.State.User.ID, "", cmds)
+	return err
+}
+
+func (b *Bot) onInteractionCreate(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	if !isBotAdmin(i) {
+		if i.Type != discordgo.InteractionApplicationCommandAutocomplete {
+			b.respondEphemeral(s, i, "You are not allowed to control this bot.")
+		}
+		return
+	}
+
+	switch i.Type {
+	case discordgo.InteractionApplicationCommand:
+		b.handleSlashCommand(s, i)
+	case discordgo.InteractionApplicationCommandAutocomplete:
+		b.handleAutocomplete(s, i)
+	case discordgo.InteractionMessageComponent:
+		b.handleComponent(s, i)
+	case discordgo.InteractionModalSubmit:
+		b.handleDropModal(s, i)
+	}
+}
+
+func isBotAdmin(i *discordgo.InteractionCreate) bool {
+	user := i.User
+	if i.Member != nil {
+		user = i.Member.User
+	}
+
+	return user != nil && slices.Contains(config.Koolo.Discord.BotAdmins, user.ID)
+}
+
+func (b *Bot) handleSlashCommand(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	data := i.ApplicationCommandData()
+	c, found := b.commands.Find(data.Name)
+	if !found {
+		b.respondEphemeral(s, i, fmt.Sprintf("Unknown command: %s", data.Name))
+		return
+	}
+
+	// Options are passed in declaration order, variadic ones are split
+	var args []string
+	for _, p := range c.Params {
+		for _, opt := range data.Options {
+			if opt.Name != p.Name {
+				continue
+			}
+			value := fmt.Sprint(opt.Value)
+			if opt.Type == discordgo.ApplicationCommandOptionInteger {
+				value = strconv.FormatInt(opt.IntValue(), 10)
+			}
+			if p.Variadic {
+				args = append(args, strings.Fields(value)...)
+			} else {
+				args = append(args, value)
+			}
+		}
+	}
+
+	b.runDeferred(s, i, c.Name, args)
+}
+
+// runDeferred acknowledges the interaction right away, commands like start may take longer than the 3s Discord waits
+func (b *Bot) runDeferred(s *discordgo.Session, i *discordgo.InteractionCreate, name string, args []string) {
+	err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseDeferredChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{Flags: discordgo.MessageFlagsEphemeral},
+	})
+	if err != nil {
+		b.logError("Discord interaction response failed", err)
+		return
+	}
+
+	for _, r := range b.commands.Run(name, args) {
+		params := &discordgo.WebhookParams{Flags: discordgo.MessageFlagsEphemeral, Components: b.replyComponents(r)}
+		if r.Title == "" && len(r.Fields) == 0 {
+			params.Content = r.Text
+		} else {
+			params.Embeds = []*discordgo.MessageEmbed{replyEmbed(r)}
+		}
+		if _, err = s.FollowupMessageCreate(i.Interaction, true, params); err != nil {
+			b.logError("Discord followup message failed", err)
+		}
+	}
+}
+
+// replyComponents adds start/stop/pause buttons to the replies about a supervisor
+func (b *Bot) replyComponents(r command.Reply) []discordgo.MessageComponent {
+	if r.Supervisor == "" {
+		return nil
+	}
+
+	status := b.manager.Status(r.Supervisor).SupervisorStatus
+	var buttons []discordgo.MessageComponent
+	switch status {
+	case bot.NotStarted, "":
+		buttons = append(buttons, commandButton("Start", "start", r.Supervisor, discordgo.SuccessButton))
+	case bot.Paused:
+		buttons = append(buttons,
+			commandButton("Resume", "resume", r.Supervisor, discordgo.PrimaryButton),
+			commandButton("Stop", "stop", r.Supervisor, discordgo.DangerButton),
+		)
+	default:
+		buttons = append(buttons,
+			commandButton("Pause", "pause", r.Supervisor, discordgo.SecondaryButton),
+			commandButton("Stop", "stop", r.Supervisor, discordgo.DangerButton),
+		)
+	}
+
+	return []discordgo.MessageComponent{discordgo.ActionsRow{Components: buttons}}
+}
+
+func commandButton(label, cmd, supervisor string, style discordgo.ButtonStyle) discordgo.Button {
+	return discordgo.Button{Label: label, Style: style, CustomID: strings.Join([]string{customIDCommand, cmd, supervisor}, ":")}
+}
+
+func (b *Bot) handleAutocomplete(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	data := i.ApplicationCommandData()
+	c, _ := b.commands.Find(data.Name)
+
+	var choices []*discordgo.ApplicationCommandOptionChoice
+	for _, opt := range data.Options {
+		if !opt.Focused {
+			continue
+		}
+		for _, p := range c.Params {
+			if p.Name == opt.Name {
+				choices = autocompleteChoices(p, opt.StringValue(), b.manager.AvailableSupervisors())
+			}
+		}
+	}
+
+	err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionApplicationCommandAutocompleteResult,
+		Data: &discordgo.InteractionResponseData{Choices: choices},
+	})
+	if err != nil {
+		b.logError("Discord autocomplete response failed", err)
+	}
+}
+
+// autocompleteChoices suggests supervisor or run names matching the typed value, for variadic parameters only the
+// last word is completed
+func autocompleteChoices(p command.Param, typed string, supervisors []string) []*discordgo.ApplicationCommandOptionChoice {
+	var candidates []string
+	switch p.Kind {
+	case command.ParamSupervisor:
+		candidates = supervisors
+	case command.ParamRun:
+		for r := range config.AvailableRuns {
+			candidates = append(candidates, string(r))
+		}
+	default:
+		return nil
+	}
+	sort.Strings(candidates)
+
+	prefix, current := "", typed
+	if p.Variadic {
+		if idx := strings.LastIndex(typed, " "); idx != -1 {
+			prefix, current = typed[:idx+1], typed[idx+1:]
+		}
+	}
+
+	choices := make([]*discordgo.ApplicationCommandOptionChoice, 0, maxChoices)
+	for _, c := range candidates {
+		if len(choices) == maxChoices {
+			break
+		}
+		if strings.HasPrefix(strings.ToLower(c), strings.ToLower(current)) {
+			choices = append(choices, &discordgo.ApplicationCommandOptionChoice{Name: prefix + c, Value: prefix + c})
+		}
+	}
+
+	return choices
+}
+
+func (b *Bot) handleComponent(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	parts := strings.SplitN(i.MessageComponentData().CustomID, ":", 3)
+	if len(parts) != 3 {
+		return
+	}
+
+	switch parts[0] {
+	case customIDCommand:
+		b.runDeferred(s, i, parts[1], []string{parts[2]})
+	case customIDDrop:
+		b.showDropModal(s, i, parts[1], parts[2])
+	}
+}
+
+// itemStashComponents adds a button queueing a Drop of the stashed item, only available with the bot (not webhooks)
+func itemStashComponents(evt event.ItemStashedEvent) []discordgo.MessageComponent {
+	customID := strings.Join([]string{customIDDrop, evt.Supervisor(), encodeDropItem(evt.Item.Item)}, ":")
+	if len(customID) > maxCustomID {
+		return nil
+	}
+
+	return []discordgo.MessageComponent{discordgo.ActionsRow{Components: []discordgo.MessageComponent{
+		discordgo.Button{Label: "Drop to a friend", Style: discordgo.PrimaryButton, CustomID: customID, Emoji: &discordgo.ComponentEmoji{Name: "🎁"}},
+	}}}
+}
+
+// encodeDropItem identifies the exact stashed item in a custom ID, see decodeDropItem
+func encodeDropItem(it data.Item) string {
+	return fmt.Sprintf("%d:%d:%08x:%s", it.Quality, it.UniqueSetID, drop.StatsHash(it), it.Name)
+}
+
+// decodeDropItem returns the selection of a single item encoded by encodeDropItem
+func decodeDropItem(encoded string) (drop.ItemSelection, bool) {
+	parts := strings.SplitN(encoded, ":", 4)
+	if len(parts) != 4 {
+		return drop.ItemSelection{}, false
+	}
+	quality, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return drop.ItemSelection{}, false
+	}
+	uniqueSetID, err := strconv.ParseInt(parts[1], 10, 32)
+	if err != nil {
+		return drop.ItemSelection{}, false
+	}
+	statsHash, err := strconv.ParseUint(parts[2], 16, 32)
+	if err != nil {
+		return drop.ItemSelection{}, false
+	}
+
+	return drop.ItemSelection{Name: parts[3], Quality: item.Quality(quality), UniqueSetID: int32(uniqueSetID), StatsHash: uint32(statsHash), Quantity: 1}, true
+}
+
+func (b *Bot) showDropModal(s *discordgo.Session, i *discordgo.InteractionCreate, supervisor, encodedItem string) {
+	sel, ok := decodeDropItem(encodedItem)
+	if !ok {
+		return
+	}
+	customID := strings.Join([]string{customIDDropModal, supervisor, encodedItem}, ":")
+	err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseModal,
+		Data: &discordgo.InteractionResponseData{
+			CustomID: customID,
+			Title:    truncate(fmt.Sprintf("Drop %s", sel.Name), 45),
+			Components: []discordgo.MessageComponent{
+				discordgo.ActionsRow{Components: []discordgo.MessageComponent{
+					discordgo.TextInput{CustomID: dropRoomInput, Label: "Game name", Style: discordgo.TextInputShort, Required: true, MaxLength: 15},
+				}},
+				discordgo.ActionsRow{Components: []discordgo.MessageComponent{
+					discordgo.TextInput{CustomID: dropPasswordInput, Label: "Password", Style: discordgo.TextInputShort, MaxLength: 15},
+				}},
+			},
+		},
+	})
+	if err != nil {
+		b.logError("Discord drop modal failed", err)
+	}
+}
+
+func (b *Bot) handleDropModal(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	data := i.ModalSubmitData()
+	parts := strings.SplitN(data.CustomID, ":", 3)
+	if len(parts) != 3 || parts[0] != customIDDropModal {
+		return
+	}
+	supervisor := parts[1]
+	sel, ok := decodeDropItem(parts[2])
+	if !ok {
+		return
+	}
+
+	var room, password string
+	for _, row := range data.Components {
+		ar, ok := row.(*discordgo.ActionsRow)
+		if !ok {
+			continue
+		}
+		for _, c := range ar.Components {
+			if input, ok := c.(*discordgo.TextInput); ok {
+				switch input.CustomID {
+				case dropRoomInput:
+					room = strings.TrimSpace(input.Value)
+				case dropPasswordInput:
+					password = strings.TrimSpace(input.Value)
+				}
+			}
+		}
+	}
+
+	// Only the stashed item is dropped, not every item sharing its base name
+	filters := drop.Filters{Enabled: true, DropperOnlySelected: true, SelectedItems: []drop.ItemSelection{sel}}.Normalize()
+	if _, err := b.manager.RequestDrop(supervisor, room, password, filters); err != nil {
+		b.respondEphemeral(s, i, fmt.Sprintf("Drop could not be queued: %s", err))
+		return
+	}
+
+	b.respondEphemeral(s, i, fmt.Sprintf("Drop of %s queued for '%s', joining game %s.", sel.Name, supervisor, room))
+}
+
+func (b *Bot) respondEphemeral(s *discordgo.Session, i *discordgo.InteractionCreate, message string) {
+	err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{Content: message, Flags: discordgo.MessageFlagsEphemeral},
+	})
+	if err != nil {
+		b.logError("Discord interaction response failed", err)
+	}
+}
+
+func (b *Bot) logError(msg string, err error) {
+	b.logger.Warn(msg, slog.Any("error", err))
+}
+
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+
+	return s[:n]
+}
@@ -1,15 +1,12 @@
 package droplog
 
 import (
-	"bufio"
 	"context"
 	"encoding/json"
 	"fmt"
-	"io"
 	"log/slog"
 	"os"
 	"path/filepath"
-	"strings"
 	"time"
 
 	"github.com/hectorgimenez/d2go/pkg/data"
@@ -27,8 +24,9 @@ type Record struct {
 }
 
 type Writer struct {
-	logDir string
-	logger *slog.Logger
+	logDir        string
+	logger        *slog.Logger
+	retentionDate string
 }
 
 func NewWriter(logDir string, logger *slog.Logger) *Writer {
@@ -46,7 +44,7 @@ func (w *Writer) Handle(_ context.Context, e event.Event) error {
 	sup := e.Supervisor()
 	charName := ""
 	profile := ""
-	if cfg, found := config.GetCharacter(sup); found && cfg != nil { 
+	if cfg, found := config.GetCharacter(sup); found && cfg != nil {
 		charName = cfg.CharacterName
 		profile = cfg.ConfigFolderName
 	}
@@ -65,6 +63,9 @@ func (w *Writer) Handle(_ context.Context, e event.Event) error {
 		return nil // don't break the bot because of logging errors
 	}
 
+	// Retention runs at most once per day, piggybacking on the first stashed item of the day
+	w.applyRetentionOnce()
+
 	// Daily rotation by date
 	file := filepath.Join(w.logDir, fmt.Sprintf("droplog-%s.jsonl", time.Now().Format("2006-01-02")))
 	f, err := os.OpenFile(file, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
@@ -86,50 +87,8 @@ func (w *Writer) Handle(_ context.Context, e event.Event) error {
 	return nil
 }
 
-// ReadAll scans the log directory for droplog-*.jsonl files, parses them, and returns all records.
+// ReadAll scans the log directory for droplog-*.jsonl files (plain or gzip compressed), parses them, and returns
+// all records. Parsed files are cached until they change on disk.
 func ReadAll(logDir string) ([]Record, error) {
-	files, err := filepath.Glob(filepath.Join(logDir, "droplog-*.jsonl"))
-	if err != nil {
-		return nil, err
-	}
-
-	// Sort by name (date) ascending
-	sortStrings(files)
-
-	var out []Record
-	for _, fpath := range files {
-		f, err := os.Open(fpath)
-		if err != nil {
-			continue
-		}
-		r := bufio.NewReader(f)
-		for {
-			line, err := r.ReadString('\n')
-			if len(line) > 0 {
-				var rec Record
-				if err := json.Unmarshal([]byte(strings.TrimSpace(line)), &rec); err == nil {
-					out = append(out, rec)
-				}
-			}
-			if err != nil {
-				if err == io.EOF {
-					break
-				}
-				break
-			}
-		}
-		f.Close()
-	}
-	return out, nil
-}
-
-// minimal local sort to avoid pulling extra deps
-func sortStrings(a []string) {
-	for i := 0; i < len(a); i++ {
-		for j := i + 1; j < len(a); j++ {
-			if a[j] < a[i] {
-				a[i], a[j] = a[j], a[i]
-			}
-		}
-	}
+	return readRange(logDir, time.Time{}, time.Time{})
 }
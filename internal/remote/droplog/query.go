@@ -0,0 +1,176 @@
+package droplog
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const defaultPageSize = 100
+
+// Query filters droplog records, empty fields are ignored. Text filters are case-insensitive,
+// ItemName and Rule match substrings while the remaining ones must match exactly.
+type Query struct {
+	Supervisor string
+	Profile    string
+	Character  string
+	ItemName   string
+	Quality    string
+	Rule       string
+	From       time.Time
+	To         time.Time
+	Offset     int
+	Limit      int
+}
+
+// Page is a paginated query result, records are sorted newest first.
+type Page struct {
+	Total   int      `json:"total"`
+	Offset  int      `json:"offset"`
+	Limit   int      `json:"limit"`
+	Records []Record `json:"records"`
+}
+
+// ItemName returns the display name of the stored drop.
+func (r Record) ItemName() string {
+	if r.Drop.Item.IdentifiedName != "" {
+		return r.Drop.Item.IdentifiedName
+	}
+
+	return string(r.Drop.Item.Name)
+}
+
+// Matches reports whether the record passes every filter of the query, pagination is ignored.
+func (q Query) Matches(rec Record) bool {
+	if q.Supervisor != "" && !strings.EqualFold(q.Supervisor, rec.Supervisor) {
+		return false
+	}
+	if q.Profile != "" && !strings.EqualFold(q.Profile, rec.Profile) {
+		return false
+	}
+	if q.Character != "" && !strings.EqualFold(q.Character, rec.Character) {
+		return false
+	}
+	if q.Quality != "" && !strings.EqualFold(q.Quality, rec.Drop.Item.Quality.ToString()) {
+		return false
+	}
+	if q.ItemName != "" {
+		needle := strings.ToLower(q.ItemName)
+		if !strings.Contains(strings.ToLower(rec.ItemName()), needle) && !strings.Contains(strings.ToLower(string(rec.Drop.Item.Name)), needle) {
+			return false
+		}
+	}
+	if q.Rule != "" {
+		needle := strings.ToLower(q.Rule)
+		if !strings.Contains(strings.ToLower(rec.Drop.Rule), needle) && !strings.Contains(strings.ToLower(rec.Drop.RuleFile), needle) {
+			return false
+		}
+	}
+	if !q.From.IsZero() && rec.Time.Before(q.From) {
+		return false
+	}
+	if !q.To.IsZero() && rec.Time.After(q.To) {
+		return false
+	}
+
+	return true
+}
+
+// Find returns every record matching the query, newest first, without pagination.
+func Find(logDir string, q Query) ([]Record, error) {
+	records, err := readRange(logDir, q.From, q.To)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]Record, 0)
+	for _, rec := range records {
+		if q.Matches(rec) {
+			out = append(out, rec)
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].Time.After(out[j].Time) })
+
+	return out, nil
+}
+
+// Search returns a single page of records matching the query.
+func Search(logDir string, q Query) (Page, error) {
+	all, err := Find(logDir, q)
+	if err != nil {
+		return Page{}, err
+	}
+
+	if q.Limit <= 0 {
+		q.Limit = defaultPageSize
+	}
+	if q.Offset < 0 {
+		q.Offset = 0
+	}
+
+	page := Page{Total: len(all), Offset: q.Offset, Limit: q.Limit, Records: []Record{}}
+	if q.Offset >= len(all) {
+		return page, nil
+	}
+	end := min(q.Offset+q.Limit, len(all))
+	page.Records = all[q.Offset:end]
+
+	return page, nil
+}
+
+// WriteJSON exports the records as an indented JSON array.
+func WriteJSON(w io.Writer, records []Record) error {
+	if records == nil {
+		records = []Record{}
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+
+	return enc.Encode(records)
+}
+
+// WriteCSV exports the records as CSV with a header row, item stats are joined in a single column.
+func WriteCSV(w io.Writer, records []Record) error {
+	cw := csv.NewWriter(w)
+	header := []string{"time", "supervisor", "character", "profile", "item", "base", "quality", "ethereal", "identified", "location", "rule", "rule_file", "stats"}
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+
+	for _, rec := range records {
+		stats := make([]string, 0, len(rec.Drop.Item.Stats))
+		for _, s := range rec.Drop.Item.Stats {
+			if text := s.String(); text != "" {
+				stats = append(stats, text)
+			} else {
+				stats = append(stats, fmt.Sprintf("%d:%d=%d", s.ID, s.Layer, s.Value))
+			}
+		}
+		row := []string{
+			rec.Time.Format(time.RFC3339),
+			rec.Supervisor,
+			rec.Character,
+			rec.Profile,
+			rec.ItemName(),
+			string(rec.Drop.Item.Name),
+			rec.Drop.Item.Quality.ToString(),
+			strconv.FormatBool(rec.Drop.Item.Ethereal),
+			strconv.FormatBool(rec.Drop.Item.Identified),
+			rec.Drop.DropLocation,
+			rec.Drop.Rule,
+			rec.Drop.RuleFile,
+			strings.Join(stats, "; "),
+		}
+		if err := cw.Write(row); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+
+	return cw.Error()
+}
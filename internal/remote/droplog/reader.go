@@ -0,0 +1,163 @@
+package droplog
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	filePrefix     = "droplog-"
+	fileExt        = ".jsonl"
+	compressedExt  = ".jsonl.gz"
+	fileDateFormat = "2006-01-02"
+)
+
+// cachedFile keeps the parsed records of a droplog file, reused while size and mod time do not change.
+type cachedFile struct {
+	size    int64
+	modTime time.Time
+	records []Record
+}
+
+var (
+	fileCacheMux sync.Mutex
+	fileCache    = make(map[string]cachedFile)
+)
+
+// logFile is a droplog file on disk with the day it covers.
+type logFile struct {
+	path       string
+	day        time.Time
+	compressed bool
+}
+
+// listFiles returns every droplog file in the directory sorted by day, plain files first for the same day.
+func listFiles(logDir string) ([]logFile, error) {
+	var files []logFile
+	for _, pattern := range []string{filePrefix + "*" + fileExt, filePrefix + "*" + compressedExt} {
+		matches, err := filepath.Glob(filepath.Join(logDir, pattern))
+		if err != nil {
+			return nil, err
+		}
+		for _, m := range matches {
+			base := filepath.Base(m)
+			compressed := strings.HasSuffix(base, compressedExt)
+			datePart := strings.TrimPrefix(base, filePrefix)
+			datePart = strings.TrimSuffix(strings.TrimSuffix(datePart, ".gz"), fileExt)
+			day, err := time.ParseInLocation(fileDateFormat, datePart, time.Local)
+			if err != nil {
+				continue
+			}
+			files = append(files, logFile{path: m, day: day, compressed: compressed})
+		}
+	}
+
+	sort.SliceStable(files, func(i, j int) bool {
+		if !files[i].day.Equal(files[j].day) {
+			return files[i].day.Before(files[j].day)
+		}
+		return !files[i].compressed && files[j].compressed
+	})
+
+	return files, nil
+}
+
+// readRange returns the records of every file whose day overlaps [from, to], zero values disable the bound.
+func readRange(logDir string, from, to time.Time) ([]Record, error) {
+	files, err := listFiles(logDir)
+	if err != nil {
+		return nil, err
+	}
+
+	var out []Record
+	for _, f := range files {
+		if !from.IsZero() && f.day.Add(24*time.Hour).Before(from) {
+			continue
+		}
+		if !to.IsZero() && f.day.After(to) {
+			continue
+		}
+		records, err := readFileCached(f)
+		if err != nil {
+			continue
+		}
+		out = append(out, records...)
+	}
+
+	return out, nil
+}
+
+func readFileCached(f logFile) ([]Record, error) {
+	info, err := os.Stat(f.path)
+	if err != nil {
+		return nil, err
+	}
+
+	fileCacheMux.Lock()
+	cached, found := fileCache[f.path]
+	fileCacheMux.Unlock()
+	if found && cached.size == info.Size() && cached.modTime.Equal(info.ModTime()) {
+		return cached.records, nil
+	}
+
+	records, err := readFile(f)
+	if err != nil {
+		return nil, err
+	}
+
+	fileCacheMux.Lock()
+	fileCache[f.path] = cachedFile{size: info.Size(), modTime: info.ModTime(), records: records}
+	fileCacheMux.Unlock()
+
+	return records, nil
+}
+
+func readFile(f logFile) ([]Record, error) {
+	file, err := os.Open(f.path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var src io.Reader = file
+	if f.compressed {
+		gz, err := gzip.NewReader(file)
+		if err != nil {
+			return nil, err
+		}
+		defer gz.Close()
+		src = gz
+	}
+
+	var out []Record
+	r := bufio.NewReader(src)
+	for {
+		line, err := r.ReadString('\n')
+		if len(line) > 0 {
+			var rec Record
+			if err := json.Unmarshal([]byte(strings.TrimSpace(line)), &rec); err == nil {
+				out = append(out, rec)
+			}
+		}
+		if err != nil {
+			break
+		}
+	}
+
+	return out, nil
+}
+
+// forgetFile drops a file from the cache, used when retention removes or rewrites it.
+func forgetFile(path string) {
+	fileCacheMux.Lock()
+	delete(fileCache, path)
+	fileCacheMux.Unlock()
+}
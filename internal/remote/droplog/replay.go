@@ -0,0 +1,89 @@
+package droplog
+
+import (
+	"path/filepath"
+
+	"github.com/hectorgimenez/d2go/pkg/nip"
+)
+
+// ReplayItem is a stored drop whose pickit decision changes with the candidate rules.
+type ReplayItem struct {
+	Time          string `json:"time"`
+	Supervisor    string `json:"supervisor"`
+	Item          string `json:"item"`
+	Quality       string `json:"quality"`
+	BaselineRule  string `json:"baselineRule"`
+	BaselineFile  string `json:"baselineFile"`
+	CandidateRule string `json:"candidateRule"`
+	CandidateFile string `json:"candidateFile"`
+	CandidateLine int    `json:"candidateLine,omitempty"`
+}
+
+// ReplayReport compares the pickit decision of the baseline and candidate rules for every replayed record.
+type ReplayReport struct {
+	Records       int          `json:"records"`
+	KeptBefore    int          `json:"keptBefore"`
+	KeptAfter     int          `json:"keptAfter"`
+	NewlyRejected []ReplayItem `json:"newlyRejected"`
+	NewlyKept     []ReplayItem `json:"newlyKept"`
+}
+
+// Replay re-evaluates stored drops against the candidate rules. When baseline is nil the records are compared
+// against the rule that stashed them, otherwise against the baseline rules (e.g. the pickit currently in use).
+// Partial matches count as kept, as the bot picks unidentified items up to identify them later.
+func Replay(records []Record, baseline, candidate nip.Rules) ReplayReport {
+	report := ReplayReport{NewlyRejected: []ReplayItem{}, NewlyKept: []ReplayItem{}}
+
+	for _, rec := range records {
+		report.Records++
+
+		baseRule, baseFile := rec.Drop.Rule, rec.Drop.RuleFile
+		keptBefore := true
+		if baseline != nil {
+			rule, res := baseline.EvaluateAll(rec.Drop.Item)
+			keptBefore = res != nip.RuleResultNoMatch
+			baseRule, baseFile = rule.RawLine, rule.Filename
+		}
+
+		rule, res := candidate.EvaluateAll(rec.Drop.Item)
+		keptAfter := res != nip.RuleResultNoMatch
+
+		if keptBefore {
+			report.KeptBefore++
+		}
+		if keptAfter {
+			report.KeptAfter++
+		}
+		if keptBefore == keptAfter {
+			continue
+		}
+
+		it := ReplayItem{
+			Time:         rec.Time.Format("2006-01-02 15:04:05"),
+			Supervisor:   rec.Supervisor,
+			Item:         rec.ItemName(),
+			Quality:      rec.Drop.Item.Quality.ToString(),
+			BaselineRule: baseRule,
+			BaselineFile: ruleFileName(baseFile),
+		}
+		if keptAfter {
+			it.CandidateRule = rule.RawLine
+			it.CandidateFile = ruleFileName(rule.Filename)
+			it.CandidateLine = rule.LineNumber
+			report.NewlyKept = append(report.NewlyKept, it)
+		} else {
+			report.NewlyRejected = append(report.NewlyRejected, it)
+		}
+	}
+
+	return report
+}
+
+// ruleFileName strips the directory from a rule file, compiled rules may point to a temporary copy
+func ruleFileName(path string) string {
+	if path == "" {
+		return ""
+	}
+
+	return filepath.Base(path)
+}
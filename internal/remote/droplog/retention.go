@@ -0,0 +1,117 @@
+package droplog
+
+import (
+	"compress/gzip"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/hectorgimenez/koolo/internal/config"
+)
+
+const (
+	RetentionCompress = "compress"
+	RetentionDelete   = "delete"
+)
+
+// RetentionResult reports what ApplyRetention did.
+type RetentionResult struct {
+	Compressed int `json:"compressed"`
+	Deleted    int `json:"deleted"`
+}
+
+// ApplyRetention compresses or deletes droplog files older than the given number of days. Days <= 0 disables
+// retention, the file of the current day is never touched.
+func ApplyRetention(logDir string, days int, mode string, now time.Time) (RetentionResult, error) {
+	var res RetentionResult
+	if days <= 0 {
+		return res, nil
+	}
+
+	files, err := listFiles(logDir)
+	if err != nil {
+		return res, err
+	}
+
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)
+	cutoff := today.AddDate(0, 0, -days)
+	for _, f := range files {
+		if !f.day.Before(cutoff) || !f.day.Before(today) {
+			continue
+		}
+
+		switch strings.ToLower(mode) {
+		case RetentionDelete:
+			if err := os.Remove(f.path); err != nil {
+				return res, err
+			}
+			forgetFile(f.path)
+			res.Deleted++
+		default:
+			if f.compressed {
+				continue
+			}
+			if err := compressFile(f.path); err != nil {
+				return res, err
+			}
+			forgetFile(f.path)
+			res.Compressed++
+		}
+	}
+
+	return res, nil
+}
+
+// compressFile gzips a droplog file next to the original and removes it once the copy is complete.
+func compressFile(path string) error {
+	src, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	dstPath := path + ".gz"
+	dst, err := os.OpenFile(dstPath, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+
+	gz := gzip.NewWriter(dst)
+	if _, err = io.Copy(gz, src); err == nil {
+		err = gz.Close()
+	}
+	if closeErr := dst.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		_ = os.Remove(dstPath)
+		return err
+	}
+
+	src.Close()
+	return os.Remove(path)
+}
+
+// applyRetentionOnce applies the configured retention policy the first time it's called each day.
+func (w *Writer) applyRetentionOnce() {
+	if config.Koolo == nil || config.Koolo.Droplog.RetentionDays <= 0 {
+		return
+	}
+
+	today := time.Now().Format(fileDateFormat)
+	if w.retentionDate == today {
+		return
+	}
+	w.retentionDate = today
+
+	res, err := ApplyRetention(w.logDir, config.Koolo.Droplog.RetentionDays, config.Koolo.Droplog.RetentionMode, time.Now())
+	if err != nil {
+		w.logger.Error("Failed to apply droplog retention", slog.Any("error", err))
+		return
+	}
+	if res.Compressed > 0 || res.Deleted > 0 {
+		w.logger.Info("Droplog retention applied", slog.Int("compressed", res.Compressed), slog.Int("deleted", res.Deleted))
+	}
+}
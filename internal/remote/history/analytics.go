@@ -0,0 +1,200 @@
+package history
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hectorgimenez/d2go/pkg/data"
+	"github.com/hectorgimenez/d2go/pkg/data/item"
+	"github.com/hectorgimenez/koolo/internal/event"
+	"github.com/hectorgimenez/koolo/internal/remote/droplog"
+)
+
+// RunMetrics aggregates every finished execution of a single run. Durations are expressed in seconds.
+type RunMetrics struct {
+	Supervisor           string                         `json:"supervisor,omitempty"`
+	Run                  string                         `json:"run"`
+	Count                int                            `json:"count"`
+	MedianDuration       float64                        `json:"medianDurationSeconds"`
+	P90Duration          float64                        `json:"p90DurationSeconds"`
+	TotalDuration        float64                        `json:"totalDurationSeconds"`
+	RunsPerHour          float64                        `json:"runsPerHour"`
+	FailureRate          float64                        `json:"failureRate"`
+	ReasonRates          map[event.FinishReason]float64 `json:"reasonRates"`
+	PotionsPerRun        float64                        `json:"potionsPerRun"`
+	ItemsStashed         int                            `json:"itemsStashed"`
+	ItemsPerHour         float64                        `json:"itemsPerHour"`
+	ValuableDrops        int                            `json:"valuableDrops"`
+	ValuableDropsPerHour float64                        `json:"valuableDropsPerHour"`
+}
+
+// SupervisorMetrics holds the per run breakdown of a single supervisor plus its overall numbers.
+type SupervisorMetrics struct {
+	Supervisor string       `json:"supervisor"`
+	Overall    RunMetrics   `json:"overall"`
+	Runs       []RunMetrics `json:"runs"`
+}
+
+// AnalyticsReport is the result of Analyze, Runs aggregates every supervisor together.
+type AnalyticsReport struct {
+	Runs        []RunMetrics        `json:"runs"`
+	Supervisors []SupervisorMetrics `json:"supervisors"`
+}
+
+// Analyze computes run efficiency metrics from finished runs. Droplog records are attributed to the run of
+// the same supervisor that was active when the item was stashed, they can be nil if drops are not needed.
+func Analyze(runs []Run, drops []droplog.Record) AnalyticsReport {
+	finished := make([]Run, 0, len(runs))
+	for _, r := range runs {
+		if r.Duration() > 0 {
+			finished = append(finished, r)
+		}
+	}
+
+	valuable := valuableDropsPerRun(finished, drops)
+
+	report := AnalyticsReport{
+		Runs:        []RunMetrics{},
+		Supervisors: []SupervisorMetrics{},
+	}
+	for name, group := range groupRuns(finished, func(r Run) string { return r.Name }) {
+		m := computeMetrics(group, valuable)
+		m.Run = name
+		report.Runs = append(report.Runs, m)
+	}
+	sortMetrics(report.Runs)
+
+	for sup, supRuns := range groupRuns(finished, func(r Run) string { return r.Supervisor }) {
+		sm := SupervisorMetrics{
+			Supervisor: sup,
+			Overall:    computeMetrics(supRuns, valuable),
+			Runs:       []RunMetrics{},
+		}
+		sm.Overall.Supervisor = sup
+		for name, group := range groupRuns(supRuns, func(r Run) string { return r.Name }) {
+			m := computeMetrics(group, valuable)
+			m.Supervisor = sup
+			m.Run = name
+			sm.Runs = append(sm.Runs, m)
+		}
+		sortMetrics(sm.Runs)
+		report.Supervisors = append(report.Supervisors, sm)
+	}
+	sort.Slice(report.Supervisors, func(i, j int) bool {
+		return report.Supervisors[i].Supervisor < report.Supervisors[j].Supervisor
+	})
+
+	return report
+}
+
+// IsValuableDrop returns true for uniques, sets and runes, the drops usually worth tracking per hour.
+func IsValuableDrop(d data.Drop) bool {
+	switch d.Item.Quality {
+	case item.QualityUnique, item.QualitySet:
+		return true
+	}
+
+	return d.Item.Desc().Type == "rune" || d.Item.RunewordName != ""
+}
+
+func groupRuns(runs []Run, key func(Run) string) map[string][]Run {
+	out := make(map[string][]Run)
+	for _, r := range runs {
+		out[key(r)] = append(out[key(r)], r)
+	}
+
+	return out
+}
+
+func sortMetrics(m []RunMetrics) {
+	sort.Slice(m, func(i, j int) bool { return m[i].Run < m[j].Run })
+}
+
+// runKey identifies a single run execution, supervisor and start time are unique enough.
+func runKey(r Run) string {
+	return strings.ToLower(r.Supervisor) + "|" + r.StartedAt.Format(time.RFC3339Nano)
+}
+
+func valuableDropsPerRun(runs []Run, drops []droplog.Record) map[string]int {
+	out := make(map[string]int)
+	if len(drops) == 0 {
+		return out
+	}
+
+	bySupervisor := groupRuns(runs, func(r Run) string { return strings.ToLower(r.Supervisor) })
+	for _, rec := range drops {
+		if !IsValuableDrop(rec.Drop) {
+			continue
+		}
+		for _, r := range bySupervisor[strings.ToLower(rec.Supervisor)] {
+			if !rec.Time.Before(r.StartedAt) && !rec.Time.After(r.FinishedAt) {
+				out[runKey(r)]++
+				break
+			}
+		}
+	}
+
+	return out
+}
+
+func computeMetrics(runs []Run, valuable map[string]int) RunMetrics {
+	m := RunMetrics{
+		Count:       len(runs),
+		ReasonRates: make(map[event.FinishReason]float64),
+	}
+	if len(runs) == 0 {
+		return m
+	}
+
+	durations := make([]float64, 0, len(runs))
+	potions := 0
+	failures := 0
+	for _, r := range runs {
+		d := r.Duration().Seconds()
+		durations = append(durations, d)
+		m.TotalDuration += d
+		potions += len(r.UsedPotions)
+		m.ItemsStashed += len(r.Items)
+		m.ValuableDrops += valuable[runKey(r)]
+		m.ReasonRates[r.Reason]++
+		if r.Reason != event.FinishedOK {
+			failures++
+		}
+	}
+	sort.Float64s(durations)
+
+	count := float64(len(runs))
+	for reason, n := range m.ReasonRates {
+		m.ReasonRates[reason] = n / count
+	}
+	m.MedianDuration = percentile(durations, 50)
+	m.P90Duration = percentile(durations, 90)
+	m.FailureRate = float64(failures) / count
+	m.PotionsPerRun = float64(potions) / count
+
+	if hours := m.TotalDuration / 3600; hours > 0 {
+		m.RunsPerHour = count / hours
+		m.ItemsPerHour = float64(m.ItemsStashed) / hours
+		m.ValuableDropsPerHour = float64(m.ValuableDrops) / hours
+	}
+
+	return m
+}
+
+// percentile uses the nearest-rank method over an already sorted slice.
+func percentile(sorted []float64, p float64) float64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	if rank < 1 {
+		rank = 1
+	}
+	if rank > len(sorted) {
+		rank = len(sorted)
+	}
+
+	return sorted[rank-1]
+}
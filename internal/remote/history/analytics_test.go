@@ -0,0 +1,139 @@
+package history
+
+import (
+	"math"
+	"testing"
+
+	"github.com/hectorgimenez/d2go/pkg/data"
+	"github.com/hectorgimenez/d2go/pkg/data/item"
+	"github.com/hectorgimenez/koolo/internal/event"
+	"github.com/hectorgimenez/koolo/internal/remote/droplog"
+)
+
+func TestPercentile(t *testing.T) {
+	tests := []struct {
+		sorted []float64
+		p      float64
+		want   float64
+	}{
+		{nil, 50, 0},
+		{[]float64{7}, 50, 7},
+		{[]float64{7}, 90, 7},
+		{[]float64{1, 2, 3, 4}, 50, 2},
+		{[]float64{1, 2, 3, 4}, 90, 4},
+		{[]float64{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}, 90, 9},
+		{[]float64{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}, 91, 10},
+		{[]float64{1, 2, 3}, 0, 1},
+		{[]float64{1, 2, 3}, 100, 3},
+	}
+	for _, tt := range tests {
+		if got := percentile(tt.sorted, tt.p); got != tt.want {
+			t.Errorf("percentile(%v, %v) = %v, want %v", tt.sorted, tt.p, got, tt.want)
+		}
+	}
+}
+
+func TestAnalyze(t *testing.T) {
+	potion := UsedPotion{PotionType: data.HealingPotion}
+	runs := []Run{
+		{Supervisor: "sorc", Name: "mephisto", StartedAt: at(0), FinishedAt: at(10), Reason: event.FinishedOK,
+			UsedPotions: []UsedPotion{potion, potion}, Items: []StashedItem{{Name: "Shako"}}},
+		{Supervisor: "sorc", Name: "mephisto", StartedAt: at(10), FinishedAt: at(30), Reason: event.FinishedDied},
+		{Supervisor: "sorc", Name: "andariel", StartedAt: at(30), FinishedAt: at(40), Reason: event.FinishedOK},
+		{Supervisor: "pala", Name: "mephisto", StartedAt: at(0), FinishedAt: at(20), Reason: event.FinishedOK},
+		// Unfinished runs are left out
+		{Supervisor: "sorc", Name: "mephisto", StartedAt: at(40)},
+	}
+	unique := data.Drop{Item: data.Item{Quality: item.QualityUnique}}
+	drops := []droplog.Record{
+		{Time: at(5), Supervisor: "Sorc", Drop: unique},
+		{Time: at(5), Supervisor: "sorc", Drop: data.Drop{Item: data.Item{Quality: item.QualityMagic}}},
+		{Time: at(50), Supervisor: "sorc", Drop: unique}, // After every run
+		{Time: at(15), Supervisor: "pala", Drop: unique},
+	}
+
+	report := Analyze(runs, drops)
+	if len(report.Runs) != 2 || report.Runs[0].Run != "andariel" || report.Runs[1].Run != "mephisto" {
+		t.Fatalf("runs = %+v, want andariel and mephisto", report.Runs)
+	}
+
+	// Mephisto: 10, 20 and 20 minutes
+	meph := report.Runs[1]
+	checkMetrics(t, "mephisto", meph, map[string]float64{
+		"count":                3,
+		"medianDuration":       1200,
+		"p90Duration":          1200,
+		"totalDuration":        3000,
+		"runsPerHour":          3.6,
+		"failureRate":          1.0 / 3,
+		"potionsPerRun":        2.0 / 3,
+		"itemsStashed":         1,
+		"itemsPerHour":         1.2,
+		"valuableDrops":        2,
+		"valuableDropsPerHour": 2.4,
+	})
+	if !approx(meph.ReasonRates[event.FinishedOK], 2.0/3) || !approx(meph.ReasonRates[event.FinishedDied], 1.0/3) {
+		t.Errorf("mephisto reason rates = %v", meph.ReasonRates)
+	}
+
+	checkMetrics(t, "andariel", report.Runs[0], map[string]float64{
+		"count":          1,
+		"medianDuration": 600,
+		"p90Duration":    600,
+		"runsPerHour":    6,
+		"failureRate":    0,
+	})
+
+	if len(report.Supervisors) != 2 || report.Supervisors[0].Supervisor != "pala" || report.Supervisors[1].Supervisor != "sorc" {
+		t.Fatalf("supervisors = %+v", report.Supervisors)
+	}
+	sorc := report.Supervisors[1]
+	checkMetrics(t, "sorc", sorc.Overall, map[string]float64{
+		"count":         3,
+		"totalDuration": 2400,
+		"runsPerHour":   4.5,
+		"failureRate":   1.0 / 3,
+		"valuableDrops": 1,
+	})
+	if len(sorc.Runs) != 2 || sorc.Runs[1].Supervisor != "sorc" || sorc.Runs[1].Count != 2 {
+		t.Errorf("sorc runs = %+v", sorc.Runs)
+	}
+	checkMetrics(t, "pala", report.Supervisors[0].Overall, map[string]float64{
+		"count":         1,
+		"valuableDrops": 1,
+	})
+}
+
+func TestAnalyzeWithoutRuns(t *testing.T) {
+	report := Analyze(nil, nil)
+	if report.Runs == nil || report.Supervisors == nil || len(report.Runs) != 0 {
+		t.Errorf("report = %+v, want empty lists", report)
+	}
+}
+
+func checkMetrics(t *testing.T, name string, m RunMetrics, want map[string]float64) {
+	t.Helper()
+
+	got := map[string]float64{
+		"count":                float64(m.Count),
+		"medianDuration":       m.MedianDuration,
+		"p90Duration":          m.P90Duration,
+		"totalDuration":        m.TotalDuration,
+		"runsPerHour":          m.RunsPerHour,
+		"failureRate":          m.FailureRate,
+		"potionsPerRun":        m.PotionsPerRun,
+		"itemsStashed":         float64(m.ItemsStashed),
+		"itemsPerHour":         m.ItemsPerHour,
+		"valuableDrops":        float64(m.ValuableDrops),
+		"valuableDropsPerHour": m.ValuableDropsPerHour,
+	}
+	for field, w := range want {
+		if !approx(got[field], w) {
+			t.Errorf("%s: %s = %v, want %v", name, field, got[field], w)
+		}
+	}
+}
+
+func approx(a, b float64) bool {
+	return math.Abs(a-b) < 1e-9
+}
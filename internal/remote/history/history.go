@@ -0,0 +1,146 @@
+// Package history persists the game, run, potion and stash events of every supervisor and folds them back into
+// games and runs for the history and analytics APIs.
+//
+// Records are appended to one JSONL file per day instead of a database: a bot writes a few thousand small records per day,
+// the files stay readable and easy to clean up by hand, and no cgo SQLite driver is needed on Windows. The day in the
+// file name is the index, queries only open the files overlapping the requested range and parsed files are cached
+// until they change on disk, so only today's file is read again on each request.
+package history
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/hectorgimenez/d2go/pkg/data"
+	"github.com/hectorgimenez/koolo/internal/event"
+)
+
+const (
+	RecordGameCreated  RecordType = "game_created"
+	RecordGameFinished RecordType = "game_finished"
+	RecordRunStarted   RecordType = "run_started"
+	RecordRunFinished  RecordType = "run_finished"
+	RecordUsedPotion   RecordType = "used_potion"
+	RecordItemStashed  RecordType = "item_stashed"
+)
+
+type RecordType string
+
+// Record is the persisted representation of a single supervisor event. Records are appended to
+// daily JSONL files and folded back into games and runs when queried.
+type Record struct {
+	Time       time.Time          `json:"time"`
+	Supervisor string             `json:"supervisor"`
+	Type       RecordType         `json:"type"`
+	GameName   string             `json:"gameName,omitempty"`
+	RunName    string             `json:"runName,omitempty"`
+	Reason     event.FinishReason `json:"reason,omitempty"`
+	PotionType data.PotionType    `json:"potionType,omitempty"`
+	OnMerc     bool               `json:"onMerc,omitempty"`
+	Item       *StashedItem       `json:"item,omitempty"`
+}
+
+// StashedItem keeps only the fields needed for aggregation, the full drop is already stored by droplog.
+type StashedItem struct {
+	Name     string `json:"name"`
+	Quality  string `json:"quality"`
+	Rule     string `json:"rule,omitempty"`
+	RuleFile string `json:"ruleFile,omitempty"`
+}
+
+type Writer struct {
+	logDir string
+	logger *slog.Logger
+	mu     sync.Mutex
+}
+
+func NewWriter(logDir string, logger *slog.Logger) *Writer {
+	return &Writer{logDir: logDir, logger: logger}
+}
+
+// Handle subscribes to the event bus and persists game, run, potion and stash events to a daily JSONL file.
+func (w *Writer) Handle(_ context.Context, e event.Event) error {
+	rec, ok := recordFromEvent(e)
+	if !ok {
+		return nil
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if err := os.MkdirAll(w.logDir, 0o755); err != nil {
+		w.logger.Error("Failed to create history directory", slog.Any("error", err), slog.String("dir", w.logDir))
+		return nil // don't break the bot because of logging errors
+	}
+
+	file := filepath.Join(w.logDir, filePrefix+rec.Time.Format(fileDateFormat)+fileExt)
+	f, err := os.OpenFile(file, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		w.logger.Error("Failed to open history file", slog.Any("error", err), slog.String("file", file))
+		return nil
+	}
+	defer f.Close()
+
+	enc, err := json.Marshal(rec)
+	if err != nil {
+		w.logger.Error("Failed to encode history record", slog.Any("error", err))
+		return nil
+	}
+	if _, err = f.Write(append(enc, '\n')); err != nil {
+		w.logger.Error("Failed to write history record", slog.Any("error", err))
+	}
+
+	return nil
+}
+
+func recordFromEvent(e event.Event) (Record, bool) {
+	rec := Record{
+		Time:       e.OccurredAt(),
+		Supervisor: e.Supervisor(),
+	}
+	if rec.Time.IsZero() {
+		rec.Time = time.Now()
+	}
+
+	switch evt := e.(type) {
+	case event.GameCreatedEvent:
+		// Game password is intentionally not persisted
+		rec.Type = RecordGameCreated
+		rec.GameName = evt.Name
+	case event.GameFinishedEvent:
+		rec.Type = RecordGameFinished
+		rec.Reason = evt.Reason
+	case event.RunStartedEvent:
+		rec.Type = RecordRunStarted
+		rec.RunName = evt.RunName
+	case event.RunFinishedEvent:
+		rec.Type = RecordRunFinished
+		rec.RunName = evt.RunName
+		rec.Reason = evt.Reason
+	case event.UsedPotionEvent:
+		rec.Type = RecordUsedPotion
+		rec.PotionType = evt.PotionType
+		rec.OnMerc = evt.OnMerc
+	case event.ItemStashedEvent:
+		name := evt.Item.Item.IdentifiedName
+		if name == "" {
+			name = string(evt.Item.Item.Name)
+		}
+		rec.Type = RecordItemStashed
+		rec.Item = &StashedItem{
+			Name:     name,
+			Quality:  evt.Item.Item.Quality.ToString(),
+			Rule:     evt.Item.Rule,
+			RuleFile: evt.Item.RuleFile,
+		}
+	default:
+		return Record{}, false
+	}
+
+	return rec, true
+}
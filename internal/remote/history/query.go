@@ -0,0 +1,214 @@
+package history
+
+import (
+	"strings"
+	"time"
+
+	"github.com/hectorgimenez/d2go/pkg/data"
+	"github.com/hectorgimenez/koolo/internal/event"
+)
+
+type Game struct {
+	Supervisor string             `json:"supervisor"`
+	Name       string             `json:"name"`
+	StartedAt  time.Time          `json:"startedAt"`
+	FinishedAt time.Time          `json:"finishedAt"`
+	Reason     event.FinishReason `json:"reason"`
+	Runs       []Run              `json:"runs"`
+}
+
+type Run struct {
+	Supervisor  string             `json:"supervisor"`
+	Name        string             `json:"name"`
+	StartedAt   time.Time          `json:"startedAt"`
+	FinishedAt  time.Time          `json:"finishedAt"`
+	Reason      event.FinishReason `json:"reason"`
+	UsedPotions []UsedPotion       `json:"usedPotions"`
+	Items       []StashedItem      `json:"items"`
+}
+
+type UsedPotion struct {
+	Time       time.Time       `json:"time"`
+	PotionType data.PotionType `json:"potionType"`
+	OnMerc     bool            `json:"onMerc"`
+}
+
+// Duration returns the run duration, unfinished runs return zero.
+func (r Run) Duration() time.Duration {
+	if r.FinishedAt.IsZero() || r.FinishedAt.Before(r.StartedAt) {
+		return 0
+	}
+
+	return r.FinishedAt.Sub(r.StartedAt)
+}
+
+// Filter narrows down history queries, empty fields are ignored.
+type Filter struct {
+	Supervisor string
+	RunName    string
+	Reason     event.FinishReason
+	From       time.Time
+	To         time.Time
+}
+
+// Summary holds lifetime counters for the filtered games and runs.
+type Summary struct {
+	Games       int `json:"games"`
+	Runs        int `json:"runs"`
+	Deaths      int `json:"deaths"`
+	Chickens    int `json:"chickens"`
+	Errors      int `json:"errors"`
+	UsedPotions int `json:"usedPotions"`
+	ItemsStash  int `json:"itemsStashed"`
+	// TotalRunTime is expressed in seconds to keep the JSON output readable
+	TotalRunTime float64 `json:"totalRunTimeSeconds"`
+}
+
+// BuildGames folds the raw records into games per supervisor, following the same rules as bot.StatsHandler.
+// Events received before the first game of a supervisor are dropped, exactly like the in-memory stats.
+func BuildGames(records []Record) []Game {
+	var games []Game
+	// index of the last game per supervisor
+	current := make(map[string]int)
+
+	for _, rec := range records {
+		key := strings.ToLower(rec.Supervisor)
+		if rec.Type == RecordGameCreated {
+			games = append(games, Game{
+				Supervisor: rec.Supervisor,
+				Name:       rec.GameName,
+				StartedAt:  rec.Time,
+			})
+			current[key] = len(games) - 1
+			continue
+		}
+
+		idx, found := current[key]
+		if !found {
+			continue
+		}
+		g := &games[idx]
+
+		switch rec.Type {
+		case RecordGameFinished:
+			g.FinishedAt = rec.Time
+			g.Reason = rec.Reason
+		case RecordRunStarted:
+			g.Runs = append(g.Runs, Run{
+				Supervisor: rec.Supervisor,
+				Name:       rec.RunName,
+				StartedAt:  rec.Time,
+			})
+		case RecordRunFinished:
+			if len(g.Runs) > 0 {
+				lastRun := &g.Runs[len(g.Runs)-1]
+				lastRun.FinishedAt = rec.Time
+				lastRun.Reason = rec.Reason
+			}
+		case RecordUsedPotion:
+			if len(g.Runs) > 0 {
+				lastRun := &g.Runs[len(g.Runs)-1]
+				lastRun.UsedPotions = append(lastRun.UsedPotions, UsedPotion{
+					Time:       rec.Time,
+					PotionType: rec.PotionType,
+					OnMerc:     rec.OnMerc,
+				})
+			}
+		case RecordItemStashed:
+			if len(g.Runs) > 0 && rec.Item != nil {
+				lastRun := &g.Runs[len(g.Runs)-1]
+				lastRun.Items = append(lastRun.Items, *rec.Item)
+			}
+		}
+	}
+
+	return games
+}
+
+// FilterGames returns the games matching the filter. When RunName or Reason are set, only the matching
+// runs are kept and games without any matching run are discarded.
+func FilterGames(games []Game, f Filter) []Game {
+	out := make([]Game, 0, len(games))
+	for _, g := range games {
+		if f.Supervisor != "" && !strings.EqualFold(f.Supervisor, g.Supervisor) {
+			continue
+		}
+		if !f.From.IsZero() && g.StartedAt.Before(f.From) {
+			continue
+		}
+		if !f.To.IsZero() && g.StartedAt.After(f.To) {
+			continue
+		}
+
+		if f.RunName == "" && f.Reason == "" {
+			out = append(out, g)
+			continue
+		}
+
+		var runs []Run
+		for _, r := range g.Runs {
+			if f.RunName != "" && !strings.EqualFold(f.RunName, r.Name) {
+				continue
+			}
+			if f.Reason != "" && f.Reason != r.Reason {
+				continue
+			}
+			runs = append(runs, r)
+		}
+		if len(runs) == 0 {
+			continue
+		}
+		g.Runs = runs
+		out = append(out, g)
+	}
+
+	return out
+}
+
+// Runs flattens the runs of the given games.
+func Runs(games []Game) []Run {
+	var runs []Run
+	for _, g := range games {
+		runs = append(runs, g.Runs...)
+	}
+
+	return runs
+}
+
+// Summarize computes lifetime counters for the given games.
+func Summarize(games []Game) Summary {
+	s := Summary{Games: len(games)}
+	for _, g := range games {
+		for _, r := range g.Runs {
+			s.Runs++
+			switch r.Reason {
+			case event.FinishedDied:
+				s.Deaths++
+			case event.FinishedChicken, event.FinishedMercChicken:
+				s.Chickens++
+			case event.FinishedError:
+				s.Errors++
+			}
+			s.UsedPotions += len(r.UsedPotions)
+			s.ItemsStash += len(r.Items)
+			s.TotalRunTime += r.Duration().Seconds()
+		}
+	}
+
+	return s
+}
+
+// Query reads the history directory and returns the games matching the filter.
+func Query(logDir string, f Filter) ([]Game, error) {
+	// Games may have started the day before the requested range, read one extra day to fold them correctly
+	from := f.From
+	if !from.IsZero() {
+		from = from.Add(-24 * time.Hour)
+	}
+	records, err := ReadAll(logDir, from, f.To)
+	if err != nil {
+		return nil, err
+	}
+
+	return FilterGames(BuildGames(records), f), nil
+}
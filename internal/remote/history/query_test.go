@@ -0,0 +1,207 @@
+package history
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/hectorgimenez/d2go/pkg/data"
+	"github.com/hectorgimenez/koolo/internal/event"
+)
+
+var day = time.Date(2024, 5, 10, 12, 0, 0, 0, time.Local)
+
+func at(minutes int) time.Time {
+	return day.Add(time.Duration(minutes) * time.Minute)
+}
+
+func testRecords() []Record {
+	return []Record{
+		// Before the first game of the supervisor, dropped
+		{Time: at(0), Supervisor: "sorc", Type: RecordRunStarted, RunName: "mephisto"},
+		{Time: at(1), Supervisor: "sorc", Type: RecordGameCreated, GameName: "game-1"},
+		{Time: at(2), Supervisor: "sorc", Type: RecordRunStarted, RunName: "mephisto"},
+		{Time: at(3), Supervisor: "pala", Type: RecordGameCreated, GameName: "pala-1"},
+		{Time: at(3), Supervisor: "sorc", Type: RecordUsedPotion, PotionType: data.HealingPotion},
+		{Time: at(4), Supervisor: "SORC", Type: RecordItemStashed, Item: &StashedItem{Name: "Shako", Quality: "unique"}},
+		{Time: at(5), Supervisor: "sorc", Type: RecordRunFinished, RunName: "mephisto", Reason: event.FinishedOK},
+		{Time: at(5), Supervisor: "sorc", Type: RecordRunStarted, RunName: "andariel"},
+		{Time: at(6), Supervisor: "pala", Type: RecordRunStarted, RunName: "pit"},
+		{Time: at(7), Supervisor: "sorc", Type: RecordRunFinished, RunName: "andariel", Reason: event.FinishedChicken},
+		{Time: at(7), Supervisor: "sorc", Type: RecordGameFinished, Reason: event.FinishedChicken},
+		{Time: at(8), Supervisor: "sorc", Type: RecordGameCreated, GameName: "game-2"},
+		// No run started yet, nothing to attach the potion to
+		{Time: at(8), Supervisor: "sorc", Type: RecordUsedPotion, PotionType: data.ManaPotion},
+		{Time: at(9), Supervisor: "sorc", Type: RecordRunStarted, RunName: "mephisto"},
+		{Time: at(10), Supervisor: "pala", Type: RecordRunFinished, RunName: "pit", Reason: event.FinishedDied},
+		{Time: at(12), Supervisor: "sorc", Type: RecordRunFinished, RunName: "mephisto", Reason: event.FinishedError},
+	}
+}
+
+func TestBuildGames(t *testing.T) {
+	games := BuildGames(testRecords())
+
+	type run struct {
+		name       string
+		minutes    int
+		reason     event.FinishReason
+		potions    int
+		items      int
+		finishedAt time.Time
+	}
+	want := []struct {
+		supervisor string
+		name       string
+		reason     event.FinishReason
+		runs       []run
+	}{
+		{"sorc", "game-1", event.FinishedChicken, []run{
+			{"mephisto", 3, event.FinishedOK, 1, 1, at(5)},
+			{"andariel", 2, event.FinishedChicken, 0, 0, at(7)},
+		}},
+		{"pala", "pala-1", "", []run{{"pit", 4, event.FinishedDied, 0, 0, at(10)}}},
+		{"sorc", "game-2", "", []run{{"mephisto", 3, event.FinishedError, 0, 0, at(12)}}},
+	}
+
+	if len(games) != len(want) {
+		t.Fatalf("got %d games, want %d: %+v", len(games), len(want), games)
+	}
+	for i, w := range want {
+		g := games[i]
+		if g.Supervisor != w.supervisor || g.Name != w.name || g.Reason != w.reason {
+			t.Errorf("game %d = %s/%s (%q), want %s/%s (%q)", i, g.Supervisor, g.Name, g.Reason, w.supervisor, w.name, w.reason)
+		}
+		if len(g.Runs) != len(w.runs) {
+			t.Errorf("game %d has %d runs, want %d", i, len(g.Runs), len(w.runs))
+			continue
+		}
+		for j, wr := range w.runs {
+			r := g.Runs[j]
+			if r.Name != wr.name || r.Reason != wr.reason || r.Duration() != time.Duration(wr.minutes)*time.Minute ||
+				len(r.UsedPotions) != wr.potions || len(r.Items) != wr.items || !r.FinishedAt.Equal(wr.finishedAt) {
+				t.Errorf("game %d run %d = %+v, want %+v", i, j, r, wr)
+			}
+		}
+	}
+}
+
+func TestFilterGames(t *testing.T) {
+	games := BuildGames(testRecords())
+
+	tests := []struct {
+		name  string
+		f     Filter
+		games []string
+		runs  int
+	}{
+		{"no filter", Filter{}, []string{"game-1", "pala-1", "game-2"}, 4},
+		{"supervisor", Filter{Supervisor: "SORC"}, []string{"game-1", "game-2"}, 3},
+		{"run name keeps only matching runs", Filter{RunName: "Mephisto"}, []string{"game-1", "game-2"}, 2},
+		{"reason", Filter{Reason: event.FinishedChicken}, []string{"game-1"}, 1},
+		{"run and reason", Filter{RunName: "mephisto", Reason: event.FinishedChicken}, nil, 0},
+		{"from", Filter{From: at(2)}, []string{"pala-1", "game-2"}, 2},
+		{"to", Filter{To: at(3)}, []string{"game-1", "pala-1"}, 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := FilterGames(games, tt.f)
+			var names []string
+			for _, g := range got {
+				names = append(names, g.Name)
+			}
+			if len(names) != len(tt.games) {
+				t.Fatalf("games = %v, want %v", names, tt.games)
+			}
+			for i := range names {
+				if names[i] != tt.games[i] {
+					t.Fatalf("games = %v, want %v", names, tt.games)
+				}
+			}
+			if runs := len(Runs(got)); runs != tt.runs {
+				t.Errorf("got %d runs, want %d", runs, tt.runs)
+			}
+		})
+	}
+
+	// Filtering runs must not change the games it was given
+	if len(games[0].Runs) != 2 {
+		t.Error("FilterGames modified its input")
+	}
+}
+
+func TestSummarize(t *testing.T) {
+	tests := []struct {
+		name string
+		f    Filter
+		want Summary
+	}{
+		{"all", Filter{}, Summary{Games: 3, Runs: 4, Deaths: 1, Chickens: 1, Errors: 1, UsedPotions: 1, ItemsStash: 1, TotalRunTime: 12 * 60}},
+		{"sorc", Filter{Supervisor: "sorc"}, Summary{Games: 2, Runs: 3, Chickens: 1, Errors: 1, UsedPotions: 1, ItemsStash: 1, TotalRunTime: 8 * 60}},
+		{"nothing", Filter{Supervisor: "necro"}, Summary{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Summarize(FilterGames(BuildGames(testRecords()), tt.f)); got != tt.want {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+
+	// Unfinished runs count but add no run time
+	unfinished := []Game{{Runs: []Run{{StartedAt: at(0)}}}}
+	if got := Summarize(unfinished); got.Runs != 1 || got.TotalRunTime != 0 {
+		t.Errorf("unfinished run summary = %+v", got)
+	}
+}
+
+func TestReadAllDateRange(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name string, recs ...Record) {
+		var content []byte
+		for _, r := range recs {
+			line, err := json.Marshal(r)
+			if err != nil {
+				t.Fatal(err)
+			}
+			content = append(append(content, line...), '\n')
+		}
+		if err := os.WriteFile(filepath.Join(dir, name), content, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	yesterday := day.Add(-24 * time.Hour)
+	write("history-"+yesterday.Format(fileDateFormat)+".jsonl", Record{Time: yesterday, Supervisor: "sorc", Type: RecordGameCreated})
+	write("history-"+day.Format(fileDateFormat)+".jsonl",
+		Record{Time: at(0), Supervisor: "sorc", Type: RecordRunStarted},
+		Record{Time: at(60), Supervisor: "sorc", Type: RecordRunFinished},
+	)
+
+	tests := []struct {
+		name     string
+		from, to time.Time
+		want     int
+	}{
+		{"unbounded", time.Time{}, time.Time{}, 3},
+		{"from today", at(-60), time.Time{}, 2},
+		{"within the day", at(-1), at(1), 1},
+		{"until yesterday", time.Time{}, yesterday.Add(time.Hour), 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			records, err := ReadAll(dir, tt.from, tt.to)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(records) != tt.want {
+				t.Errorf("got %d records, want %d", len(records), tt.want)
+			}
+			for i := 1; i < len(records); i++ {
+				if records[i].Time.Before(records[i-1].Time) {
+					t.Error("records are not sorted by time")
+				}
+			}
+		})
+	}
+}
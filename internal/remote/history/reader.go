@@ -0,0 +1,135 @@
+package history
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	filePrefix     = "history-"
+	fileExt        = ".jsonl"
+	fileDateFormat = "2006-01-02"
+)
+
+// cachedFile keeps the parsed records of a history file, reused while size and mod time do not change.
+type cachedFile struct {
+	size    int64
+	modTime time.Time
+	records []Record
+}
+
+var (
+	fileCacheMux sync.Mutex
+	fileCache    = make(map[string]cachedFile)
+)
+
+// ReadAll returns the records between from and to sorted by time, zero from/to values disable the respective
+// bound. Only the daily files overlapping the range are read.
+func ReadAll(logDir string, from, to time.Time) ([]Record, error) {
+	files, err := filepath.Glob(filepath.Join(logDir, filePrefix+"*"+fileExt))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(files)
+
+	var out []Record
+	for _, fpath := range files {
+		if !fileInRange(fpath, from, to) {
+			continue
+		}
+
+		records, err := readFileCached(fpath)
+		if err != nil {
+			continue
+		}
+		for _, rec := range records {
+			if !from.IsZero() && rec.Time.Before(from) {
+				continue
+			}
+			if !to.IsZero() && rec.Time.After(to) {
+				continue
+			}
+			out = append(out, rec)
+		}
+	}
+
+	sort.SliceStable(out, func(i, j int) bool { return out[i].Time.Before(out[j].Time) })
+	return out, nil
+}
+
+// fileInRange checks the date embedded in the file name, files with unexpected names are always included.
+func fileInRange(fpath string, from, to time.Time) bool {
+	name := strings.TrimSuffix(strings.TrimPrefix(filepath.Base(fpath), filePrefix), fileExt)
+	day, err := time.ParseInLocation(fileDateFormat, name, time.Local)
+	if err != nil {
+		return true
+	}
+	if !from.IsZero() && day.Add(24*time.Hour).Before(from) {
+		return false
+	}
+	if !to.IsZero() && day.After(to) {
+		return false
+	}
+
+	return true
+}
+
+func readFileCached(fpath string) ([]Record, error) {
+	info, err := os.Stat(fpath)
+	if err != nil {
+		return nil, err
+	}
+
+	fileCacheMux.Lock()
+	cached, found := fileCache[fpath]
+	fileCacheMux.Unlock()
+	if found && cached.size == info.Size() && cached.modTime.Equal(info.ModTime()) {
+		return cached.records, nil
+	}
+
+	records, err := readFile(fpath)
+	if err != nil {
+		return nil, err
+	}
+
+	fileCacheMux.Lock()
+	fileCache[fpath] = cachedFile{size: info.Size(), modTime: info.ModTime(), records: records}
+	fileCacheMux.Unlock()
+
+	return records, nil
+}
+
+func readFile(fpath string) ([]Record, error) {
+	f, err := os.Open(fpath)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var out []Record
+	r := bufio.NewReader(f)
+	for {
+		line, err := r.ReadString('\n')
+		if len(strings.TrimSpace(line)) > 0 {
+			var rec Record
+			if err := json.Unmarshal([]byte(strings.TrimSpace(line)), &rec); err == nil {
+				out = append(out, rec)
+			}
+		}
+		if err != nil {
+			if err != io.EOF {
+				return out, err
+			}
+			break
+		}
+	}
+
+	return out, nil
+}
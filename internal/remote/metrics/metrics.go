@@ -0,0 +1,302 @@
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hectorgimenez/koolo/internal/event"
+)
+
+// RunDurationBuckets are the upper bounds (in seconds) of the run duration histogram.
+var RunDurationBuckets = []float64{30, 60, 90, 120, 180, 300, 600, 900, 1800}
+
+// Label is a single Prometheus label pair.
+type Label struct {
+	Name  string
+	Value string
+}
+
+// Sample is a single value of a metric family.
+type Sample struct {
+	Labels []Label
+	Value  float64
+}
+
+// Family groups samples sharing the same metric name, used for gauges computed at scrape time.
+type Family struct {
+	Name    string
+	Help    string
+	Type    string // "gauge" or "counter"
+	Samples []Sample
+}
+
+type histogram struct {
+	buckets []uint64
+	count   uint64
+	sum     float64
+}
+
+type activeRun struct {
+	name      string
+	startedAt time.Time
+}
+
+// Collector aggregates counters and histograms from the event bus, so scraping never touches game memory.
+type Collector struct {
+	mu            sync.Mutex
+	gamesStarted  map[string]float64
+	gamesFinished map[[2]string]float64
+	runsFinished  map[[3]string]float64
+	runDurations  map[[2]string]*histogram
+	potionsUsed   map[[3]string]float64
+	itemsStashed  map[[2]string]float64
+	activeRuns    map[string]activeRun
+	highPingSince map[string]time.Time // Last ping monitor state, zero while the ping is normal
+	pingExits     map[string]float64
+}
+
+func NewCollector() *Collector {
+	return &Collector{
+		gamesStarted:  make(map[string]float64),
+		gamesFinished: make(map[[2]string]float64),
+		runsFinished:  make(map[[3]string]float64),
+		runDurations:  make(map[[2]string]*histogram),
+		potionsUsed:   make(map[[3]string]float64),
+		itemsStashed:  make(map[[2]string]float64),
+		activeRuns:    make(map[string]activeRun),
+		highPingSince: make(map[string]time.Time),
+		pingExits:     make(map[string]float64),
+	}
+}
+
+// Handle subscribes to the event bus and updates the counters.
+func (c *Collector) Handle(_ context.Context, e event.Event) error {
+	sup := e.Supervisor()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	switch evt := e.(type) {
+	case event.GameCreatedEvent:
+		c.gamesStarted[sup]++
+	case event.GameFinishedEvent:
+		c.gamesFinished[[2]string{sup, string(evt.Reason)}]++
+		// The ping monitor only runs in game
+		delete(c.highPingSince, sup)
+	case event.RunStartedEvent:
+		c.activeRuns[sup] = activeRun{name: evt.RunName, startedAt: evt.OccurredAt()}
+	case event.RunFinishedEvent:
+		c.runsFinished[[3]string{sup, evt.RunName, string(evt.Reason)}]++
+		if run, found := c.activeRuns[sup]; found && run.name == evt.RunName {
+			c.observeRunDuration(sup, evt.RunName, evt.OccurredAt().Sub(run.startedAt).Seconds())
+			delete(c.activeRuns, sup)
+		}
+	case event.UsedPotionEvent:
+		c.potionsUsed[[3]string{sup, string(evt.PotionType), strconv.FormatBool(evt.OnMerc)}]++
+	case event.ItemStashedEvent:
+		c.itemsStashed[[2]string{sup, evt.Item.Item.Quality.ToString()}]++
+	case event.PingMonitorEvent:
+		c.highPingSince[sup] = evt.HighPingSince
+		if evt.Sustained {
+			c.pingExits[sup]++
+		}
+	}
+
+	return nil
+}
+
+func (c *Collector) observeRunDuration(sup, run string, seconds float64) {
+	if seconds < 0 {
+		return
+	}
+
+	key := [2]string{sup, run}
+	h, found := c.runDurations[key]
+	if !found {
+		h = &histogram{buckets: make([]uint64, len(RunDurationBuckets))}
+		c.runDurations[key] = h
+	}
+	for i, upper := range RunDurationBuckets {
+		if seconds <= upper {
+			h.buckets[i]++
+		}
+	}
+	h.count++
+	h.sum += seconds
+}
+
+// WriteText writes every collected metric plus the given scrape time families using the Prometheus text format.
+func (c *Collector) WriteText(w io.Writer, extra ...Family) error {
+	c.mu.Lock()
+	families := []Family{
+		{
+			Name: "koolo_games_started_total", Help: "Games created by supervisor.", Type: "counter",
+			Samples: samples1(c.gamesStarted, "supervisor"),
+		},
+		{
+			Name: "koolo_games_finished_total", Help: "Games finished by supervisor and finish reason.", Type: "counter",
+			Samples: samples2(c.gamesFinished, "supervisor", "reason"),
+		},
+		{
+			Name: "koolo_runs_finished_total", Help: "Runs finished by supervisor, run and finish reason.", Type: "counter",
+			Samples: samples3(c.runsFinished, "supervisor", "run", "reason"),
+		},
+		{
+			Name: "koolo_potions_used_total", Help: "Potions used by supervisor, potion type and target.", Type: "counter",
+			Samples: samples3(c.potionsUsed, "supervisor", "type", "merc"),
+		},
+		{
+			Name: "koolo_items_stashed_total", Help: "Items stashed by supervisor and item quality.", Type: "counter",
+			Samples: samples2(c.itemsStashed, "supervisor", "quality"),
+		},
+		{
+			Name: "koolo_ping_monitor_sustained_total", Help: "Games left by the ping monitor after a sustained high ping, by supervisor.", Type: "counter",
+			Samples: samples1(c.pingExits, "supervisor"),
+		},
+	}
+	families = append(families, c.pingFamilies(time.Now())...)
+	histograms := make(map[[2]string]histogram, len(c.runDurations))
+	for k, h := range c.runDurations {
+		histograms[k] = histogram{buckets: append([]uint64(nil), h.buckets...), count: h.count, sum: h.sum}
+	}
+	c.mu.Unlock()
+
+	families = append(families, extra...)
+	for _, f := range families {
+		if err := writeFamily(w, f); err != nil {
+			return err
+		}
+	}
+
+	return writeRunDurations(w, histograms)
+}
+
+// pingFamilies returns the ping monitor state of the supervisors in game, the high ping duration is computed at
+// scrape time so it grows between the monitor events
+func (c *Collector) pingFamilies(now time.Time) []Family {
+	highPing := Family{Name: "koolo_ping_monitor_high_ping", Help: "1 while the ping is above the ping monitor threshold.", Type: "gauge"}
+	duration := Family{Name: "koolo_ping_monitor_high_ping_seconds", Help: "How long the ping has been above the ping monitor threshold.", Type: "gauge"}
+	for sup, since := range c.highPingSince {
+		labels := []Label{{"supervisor", sup}}
+		seconds := 0.0
+		if !since.IsZero() {
+			seconds = now.Sub(since).Seconds()
+		}
+		highPing.Samples = append(highPing.Samples, Sample{Labels: labels, Value: boolToFloat(!since.IsZero())})
+		duration.Samples = append(duration.Samples, Sample{Labels: labels, Value: seconds})
+	}
+
+	return []Family{highPing, duration}
+}
+
+func boolToFloat(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+func writeFamily(w io.Writer, f Family) error {
+	if _, err := fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", f.Name, f.Help, f.Name, f.Type); err != nil {
+		return err
+	}
+	sortSamples(f.Samples)
+	for _, s := range f.Samples {
+		if _, err := fmt.Fprintf(w, "%s%s %s\n", f.Name, formatLabels(s.Labels), formatValue(s.Value)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func writeRunDurations(w io.Writer, histograms map[[2]string]histogram) error {
+	const name = "koolo_run_duration_seconds"
+	if _, err := fmt.Fprintf(w, "# HELP %s Run duration by supervisor and run.\n# TYPE %s histogram\n", name, name); err != nil {
+		return err
+	}
+
+	keys := make([][2]string, 0, len(histograms))
+	for k := range histograms {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i][0] != keys[j][0] {
+			return keys[i][0] < keys[j][0]
+		}
+		return keys[i][1] < keys[j][1]
+	})
+
+	for _, k := range keys {
+		h := histograms[k]
+		base := []Label{{"supervisor", k[0]}, {"run", k[1]}}
+		for i, upper := range RunDurationBuckets {
+			labels := append(append([]Label(nil), base...), Label{"le", formatValue(upper)})
+			if _, err := fmt.Fprintf(w, "%s_bucket%s %d\n", name, formatLabels(labels), h.buckets[i]); err != nil {
+				return err
+			}
+		}
+		labels := append(append([]Label(nil), base...), Label{"le", "+Inf"})
+		if _, err := fmt.Fprintf(w, "%s_bucket%s %d\n", name, formatLabels(labels), h.count); err != nil {
+			return err
+		}
+		if _, err := fmt.Fprintf(w, "%s_sum%s %s\n%s_count%s %d\n", name, formatLabels(base), formatValue(h.sum), name, formatLabels(base), h.count); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func samples1(m map[string]float64, label string) []Sample {
+	out := make([]Sample, 0, len(m))
+	for k, v := range m {
+		out = append(out, Sample{Labels: []Label{{label, k}}, Value: v})
+	}
+	return out
+}
+
+func samples2(m map[[2]string]float64, l1, l2 string) []Sample {
+	out := make([]Sample, 0, len(m))
+	for k, v := range m {
+		out = append(out, Sample{Labels: []Label{{l1, k[0]}, {l2, k[1]}}, Value: v})
+	}
+	return out
+}
+
+func samples3(m map[[3]string]float64, l1, l2, l3 string) []Sample {
+	out := make([]Sample, 0, len(m))
+	for k, v := range m {
+		out = append(out, Sample{Labels: []Label{{l1, k[0]}, {l2, k[1]}, {l3, k[2]}}, Value: v})
+	}
+	return out
+}
+
+func sortSamples(s []Sample) {
+	sort.Slice(s, func(i, j int) bool { return formatLabels(s[i].Labels) < formatLabels(s[j].Labels) })
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func formatLabels(labels []Label) string {
+	if len(labels) == 0 {
+		return ""
+	}
+
+	parts := make([]string, 0, len(labels))
+	for _, l := range labels {
+		parts = append(parts, fmt.Sprintf(`%s="%s"`, l.Name, labelEscaper.Replace(l.Value)))
+	}
+
+	return "{" + strings.Join(parts, ",") + "}"
+}
+
+func formatValue(v float64) string {
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
@@ -0,0 +1,101 @@
+package notify
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/hectorgimenez/koolo/internal/config"
+)
+
+const (
+	SinkDiscord  = "discord"
+	SinkTelegram = "telegram"
+	SinkWebhook  = "webhook"
+	SinkSlack    = "slack"
+	SinkNtfy     = "ntfy"
+	SinkGotify   = "gotify"
+)
+
+var httpClient = &http.Client{Timeout: sendTimeout}
+
+// Sinks returns the enabled notification sinks of the Koolo config. When no Discord or Telegram sink is configured but
+// the integration is enabled, a sink is derived from its legacy settings, so older configs keep working.
+func Sinks(cfg *config.KooloCfg) []config.NotificationSink {
+	var (
+		sinks       []config.NotificationSink
+		hasDiscord  bool
+		hasTelegram bool
+	)
+	for _, s := range cfg.Notifications.Sinks {
+		s.Type = strings.ToLower(strings.TrimSpace(s.Type))
+		hasDiscord = hasDiscord || s.Type == SinkDiscord
+		hasTelegram = hasTelegram || s.Type == SinkTelegram
+		if s.Enabled {
+			sinks = append(sinks, s)
+		}
+	}
+
+	if cfg.Discord.Enabled && !hasDiscord {
+		sinks = append(sinks, legacyDiscordSink(cfg))
+	}
+	if cfg.Telegram.Enabled && !hasTelegram {
+		sinks = append(sinks, config.NotificationSink{Name: SinkTelegram, Type: SinkTelegram, Enabled: true, Events: []string{"all"}})
+	}
+
+	return sinks
+}
+
+// legacyDiscordSink maps the per-event Discord toggles to a sink with the same behaviour
+func legacyDiscordSink(cfg *config.KooloCfg) config.NotificationSink {
+	d := cfg.Discord
+	events := []string{string(KindItemStashed), string(KindItemBlacklisted), string(KindNgrok), string(KindScreenshot)}
+	if d.EnableGameCreatedMessages {
+		events = append(events, string(KindGameCreated))
+	}
+	if d.EnableNewRunMessages {
+		events = append(events, string(KindRunStarted))
+	}
+	if d.EnableRunFinishMessages {
+		events = append(events, string(KindRunFinished))
+	}
+	if d.EnableDiscordChickenMessages {
+		events = append(events, string(KindChicken), string(KindDeath))
+	}
+	if d.EnableDiscordErrorMessages {
+		events = append(events, string(KindError))
+	}
+
+	// The Discord bot always sent the game password, keep doing it for configs predating the sinks
+	return config.NotificationSink{Name: SinkDiscord, Type: SinkDiscord, Enabled: true, Events: events, IncludeGamePassword: true}
+}
+
+// NewSink creates the sink of a config entry, Discord and Telegram sinks are provided by their bots instead
+func NewSink(cfg config.NotificationSink) (Sink, error) {
+	switch cfg.Type {
+	case SinkDiscord, SinkTelegram:
+		return nil, fmt.Errorf("%s is not enabled", cfg.Type)
+	case SinkWebhook, SinkSlack, SinkNtfy, SinkGotify:
+		if cfg.URL == "" {
+			return nil, fmt.Errorf("url is required for %s sinks", cfg.Type)
+		}
+	default:
+		return nil, fmt.Errorf("unknown sink type %q", cfg.Type)
+	}
+
+	switch cfg.Type {
+	case SinkWebhook:
+		return &webhookSink{url: cfg.URL, token: cfg.Token, screenshots: cfg.Screenshots}, nil
+	case SinkSlack:
+		return &slackSink{url: cfg.URL}, nil
+	case SinkNtfy:
+		return &ntfySink{url: cfg.URL, token: cfg.Token, screenshots: cfg.Screenshots}, nil
+	default:
+		return &gotifySink{url: strings.TrimSuffix(cfg.URL, "/") + "/message", token: cfg.Token}, nil
+	}
+}
+
+func formatTime(t time.Time) string {
+	return t.Format("2006-01-02 15:04:05")
+}
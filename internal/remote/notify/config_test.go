@@ -0,0 +1,62 @@
+package notify
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/hectorgimenez/koolo/internal/config"
+)
+
+func TestSinksLegacyMapping(t *testing.T) {
+	cfg := &config.KooloCfg{}
+	cfg.Discord.Enabled = true
+	cfg.Discord.EnableDiscordChickenMessages = true
+	cfg.Telegram.Enabled = true
+	cfg.Notifications.Sinks = []config.NotificationSink{
+		{Name: "phone", Type: " NTFY ", Enabled: true, URL: "https://ntfy.sh/koolo"},
+		{Name: "off", Type: "webhook", URL: "https://example.com"},
+	}
+
+	sinks := Sinks(cfg)
+	if len(sinks) != 3 {
+		t.Fatalf("got %d sinks, want ntfy, discord and telegram: %+v", len(sinks), sinks)
+	}
+	if sinks[0].Type != SinkNtfy {
+		t.Errorf("sink type = %q, want it normalized to %q", sinks[0].Type, SinkNtfy)
+	}
+
+	discord := sinks[1]
+	if discord.Type != SinkDiscord || !discord.IncludeGamePassword {
+		t.Errorf("legacy discord sink = %+v", discord)
+	}
+	for _, k := range []Kind{KindChicken, KindDeath, KindItemStashed} {
+		if !slices.Contains(discord.Events, string(k)) {
+			t.Errorf("legacy discord sink misses %s", k)
+		}
+	}
+	for _, k := range []Kind{KindGameCreated, KindRunStarted, KindRunFinished, KindError} {
+		if slices.Contains(discord.Events, string(k)) {
+			t.Errorf("legacy discord sink sends %s while its toggle is off", k)
+		}
+	}
+
+	if telegram := sinks[2]; telegram.Type != SinkTelegram || !slices.Equal(telegram.Events, []string{"all"}) {
+		t.Errorf("legacy telegram sink = %+v", telegram)
+	}
+}
+
+func TestSinksConfiguredReplaceLegacy(t *testing.T) {
+	cfg := &config.KooloCfg{}
+	cfg.Discord.Enabled = true
+	cfg.Telegram.Enabled = true
+	// A disabled Discord sink still replaces the legacy settings, it's how the Discord notifications are turned off
+	cfg.Notifications.Sinks = []config.NotificationSink{
+		{Type: SinkDiscord},
+		{Type: SinkTelegram, Enabled: true, Events: []string{"death"}},
+	}
+
+	sinks := Sinks(cfg)
+	if len(sinks) != 1 || sinks[0].Type != SinkTelegram || !slices.Equal(sinks[0].Events, []string{"death"}) {
+		t.Errorf("got %+v, want only the configured telegram sink", sinks)
+	}
+}
@@ -0,0 +1,139 @@
+package notify
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hectorgimenez/d2go/pkg/data/item"
+	"github.com/hectorgimenez/koolo/internal/config"
+)
+
+var itemQualities = []item.Quality{
+	item.QualityLowQuality, item.QualityNormal, item.QualitySuperior, item.QualityMagic,
+	item.QualitySet, item.QualityRare, item.QualityUnique, item.QualityCrafted,
+}
+
+type filter struct {
+	kinds       map[Kind]bool
+	supervisors map[string]bool
+	minQuality  item.Quality
+}
+
+func newFilter(cfg config.NotificationSink) (filter, error) {
+	f := filter{kinds: make(map[Kind]bool)}
+
+	kinds := DefaultKinds
+	if len(cfg.Events) > 0 {
+		kinds = nil
+		for _, ev := range cfg.Events {
+			k, err := ParseKinds(ev)
+			if err != nil {
+				return f, err
+			}
+			kinds = append(kinds, k...)
+		}
+	}
+	for _, k := range kinds {
+		f.kinds[k] = true
+	}
+
+	if len(cfg.Supervisors) > 0 {
+		f.supervisors = make(map[string]bool, len(cfg.Supervisors))
+		for _, s := range cfg.Supervisors {
+			f.supervisors[strings.ToLower(strings.TrimSpace(s))] = true
+		}
+	}
+
+	if cfg.MinItemQuality != "" {
+		q, err := ParseItemQuality(cfg.MinItemQuality)
+		if err != nil {
+			return f, err
+		}
+		f.minQuality = q
+	}
+
+	return f, nil
+}
+
+func (f filter) accepts(n Notification) bool {
+	if !f.kinds[n.Kind] {
+		return false
+	}
+	// System events (e.g. ngrok) don't belong to a supervisor
+	if f.supervisors != nil && n.Supervisor != "system" && !f.supervisors[strings.ToLower(n.Supervisor)] {
+		return false
+	}
+	if n.Item != nil && n.Item.Quality < f.minQuality {
+		return false
+	}
+
+	return true
+}
+
+// ParseKinds parses an event name of the config, "all" expands to every kind
+func ParseKinds(name string) ([]Kind, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "all" {
+		return AllKinds, nil
+	}
+	for _, k := range AllKinds {
+		if string(k) == name {
+			return []Kind{k}, nil
+		}
+	}
+
+	return nil, fmt.Errorf("unknown event %q", name)
+}
+
+// ParseItemQuality parses a quality name as shown by item.Quality.ToString, case-insensitive
+func ParseItemQuality(name string) (item.Quality, error) {
+	for _, q := range itemQualities {
+		if strings.EqualFold(q.ToString(), strings.TrimSpace(name)) {
+			return q, nil
+		}
+	}
+
+	return 0, fmt.Errorf("unknown item quality %q", name)
+}
+
+// rateLimiter is a token bucket allowing limit notifications per period, a nil limiter allows everything
+type rateLimiter struct {
+	mu       sync.Mutex
+	limit    float64
+	period   time.Duration
+	tokens   float64
+	lastFill time.Time
+}
+
+func newRateLimiter(limit int, period time.Duration) *rateLimiter {
+	if limit <= 0 {
+		return nil
+	}
+
+	return &rateLimiter{limit: float64(limit), period: period, tokens: float64(limit)}
+}
+
+func (l *rateLimiter) allow(now time.Time) bool {
+	if l == nil {
+		return true
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if !l.lastFill.IsZero() {
+		l.tokens += now.Sub(l.lastFill).Seconds() * l.limit / l.period.Seconds()
+		if l.tokens > l.limit {
+			l.tokens = l.limit
+		}
+	}
+	l.lastFill = now
+	if l.tokens < 1 {
+		return false
+	}
+	l.tokens--
+
+	return true
+}
@@ -0,0 +1,84 @@
+package notify
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hectorgimenez/d2go/pkg/data"
+	"github.com/hectorgimenez/d2go/pkg/data/item"
+	"github.com/hectorgimenez/koolo/internal/config"
+)
+
+func TestNewFilter(t *testing.T) {
+	rare := &data.Item{Quality: item.QualityRare}
+	unique := &data.Item{Quality: item.QualityUnique}
+
+	tests := []struct {
+		name    string
+		cfg     config.NotificationSink
+		n       Notification
+		accepts bool
+	}{
+		{"default kinds", config.NotificationSink{}, Notification{Kind: KindDeath, Supervisor: "sorc"}, true},
+		{"default kinds leave runs out", config.NotificationSink{}, Notification{Kind: KindRunStarted, Supervisor: "sorc"}, false},
+		{"all", config.NotificationSink{Events: []string{" ALL "}}, Notification{Kind: KindRunStarted, Supervisor: "sorc"}, true},
+		{"listed event", config.NotificationSink{Events: []string{"chicken"}}, Notification{Kind: KindChicken, Supervisor: "sorc"}, true},
+		{"unlisted event", config.NotificationSink{Events: []string{"chicken"}}, Notification{Kind: KindDeath, Supervisor: "sorc"}, false},
+		{"supervisor", config.NotificationSink{Supervisors: []string{" Sorc "}}, Notification{Kind: KindDeath, Supervisor: "sorc"}, true},
+		{"other supervisor", config.NotificationSink{Supervisors: []string{"sorc"}}, Notification{Kind: KindDeath, Supervisor: "pala"}, false},
+		{"system events ignore supervisors", config.NotificationSink{Supervisors: []string{"sorc"}}, Notification{Kind: KindNgrok, Supervisor: "system"}, true},
+		{"item above min quality", config.NotificationSink{MinItemQuality: "unique"}, Notification{Kind: KindItemStashed, Item: unique}, true},
+		{"item below min quality", config.NotificationSink{MinItemQuality: "unique"}, Notification{Kind: KindItemStashed, Item: rare}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, err := newFilter(tt.cfg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := f.accepts(tt.n); got != tt.accepts {
+				t.Errorf("accepts = %v, want %v", got, tt.accepts)
+			}
+		})
+	}
+}
+
+func TestNewFilterErrors(t *testing.T) {
+	for _, cfg := range []config.NotificationSink{
+		{Events: []string{"chicken", "level_up"}},
+		{MinItemQuality: "legendary"},
+	} {
+		if _, err := newFilter(cfg); err == nil {
+			t.Errorf("newFilter(%+v) succeeded, want an error", cfg)
+		}
+	}
+}
+
+func TestRateLimiter(t *testing.T) {
+	if l := newRateLimiter(0, time.Minute); !l.allow(time.Now()) {
+		t.Error("a limit of 0 must allow everything")
+	}
+
+	l := newRateLimiter(2, time.Minute)
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	steps := []struct {
+		after time.Duration
+		allow bool
+	}{
+		{0, true},
+		{0, true},
+		{0, false}, // Burst used up
+		{10 * time.Second, false},
+		{25 * time.Second, true}, // A token every 30s at 2 per minute
+		{0, false},
+		{5 * time.Minute, true}, // Refill is capped at the limit
+		{0, true},
+		{0, false},
+	}
+	for i, s := range steps {
+		now = now.Add(s.after)
+		if got := l.allow(now); got != s.allow {
+			t.Errorf("step %d: allow = %v, want %v", i, got, s.allow)
+		}
+	}
+}
@@ -0,0 +1,354 @@
+// Package notify routes bot events to the configured notification sinks (Discord, Telegram, webhooks, Slack, ntfy and
+// Gotify), each one with its own event filters and rate limit.
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hectorgimenez/d2go/pkg/data"
+	"github.com/hectorgimenez/koolo/internal/config"
+	"github.com/hectorgimenez/koolo/internal/event"
+)
+
+// Kind is the notification category used by the sink filters
+type Kind string
+
+const (
+	KindGameCreated     Kind = "game_created"
+	KindGameFinished    Kind = "game_finished"
+	KindChicken         Kind = "chicken"
+	KindDeath           Kind = "death"
+	KindError           Kind = "error"
+	KindRunStarted      Kind = "run_started"
+	KindRunFinished     Kind = "run_finished"
+	KindItemStashed     Kind = "item_stashed"
+	KindItemBlacklisted Kind = "item_blacklisted"
+	KindRunewordReroll  Kind = "runeword_reroll"
+	KindNgrok           Kind = "ngrok"
+	KindScreenshot      Kind = "screenshot" // Any other event carrying a screenshot
+)
+
+// AllKinds lists every notification kind, "all" can be used in the config as a shortcut
+var AllKinds = []Kind{
+	KindGameCreated, KindGameFinished, KindChicken, KindDeath, KindError, KindRunStarted, KindRunFinished,
+	KindItemStashed, KindItemBlacklisted, KindRunewordReroll, KindNgrok, KindScreenshot,
+}
+
+// DefaultKinds are sent to sinks without an events list
+var DefaultKinds = []Kind{KindChicken, KindDeath, KindError, KindItemStashed, KindRunewordReroll, KindNgrok}
+
+const (
+	sendTimeout = 15 * time.Second
+	// Notifications waiting to be sent by a sink, new ones are dropped while it's full
+	queueSize = 32
+)
+
+// Notification is an event ready to be sent, Event keeps the original one for sinks with their own formatting
+type Notification struct {
+	Kind       Kind
+	Supervisor string
+	Title      string
+	Message    string
+	Image      image.Image
+	OccurredAt time.Time
+	Item       *data.Item
+	Event      event.Event
+}
+
+// Sink delivers notifications to a single destination
+type Sink interface {
+	Send(ctx context.Context, n Notification) error
+}
+
+// EventSink adapts an event handler (e.g. the Discord bot) to a Sink, the original event is forwarded
+type EventSink func(ctx context.Context, e event.Event) error
+
+func (s EventSink) Send(ctx context.Context, n Notification) error {
+	return s(ctx, n.Event)
+}
+
+// route is a sink with its own queue, sent by its own goroutine so a slow sink doesn't hold the event listener or
+// the other sinks
+type route struct {
+	name                string
+	sink                Sink
+	filter              filter
+	limiter             *rateLimiter
+	includeGamePassword bool
+	queue               chan Notification
+}
+
+// Router is an event.Handler sending every supported event to the sinks accepting it
+type Router struct {
+	logger *slog.Logger
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+	mu     sync.RWMutex
+	routes []*route
+}
+
+func NewRouter(logger *slog.Logger) *Router {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	return &Router{logger: logger, ctx: ctx, cancel: cancel}
+}
+
+// Add registers a sink using the filters and rate limit of cfg
+func (r *Router) Add(cfg config.NotificationSink, s Sink) error {
+	f, err := newFilter(cfg)
+	if err != nil {
+		return fmt.Errorf("notification sink %s: %w", sinkName(cfg), err)
+	}
+
+	rt := &route{
+		name:                sinkName(cfg),
+		sink:                s,
+		filter:              f,
+		limiter:             newRateLimiter(cfg.RateLimit, time.Minute),
+		includeGamePassword: cfg.IncludeGamePassword,
+		queue:               make(chan Notification, queueSize),
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.routes = append(r.routes, rt)
+	r.wg.Add(1)
+	go r.deliver(rt)
+
+	return nil
+}
+
+// deliver sends the queued notifications of a route until the router is closed
+func (r *Router) deliver(rt *route) {
+	defer r.wg.Done()
+	for {
+		select {
+		case <-r.ctx.Done():
+			return
+		case n := <-rt.queue:
+			sendCtx, cancel := context.WithTimeout(r.ctx, sendTimeout)
+			if err := rt.sink.Send(sendCtx, n); err != nil {
+				r.logger.Warn("Failed to send notification", slog.String("sink", rt.name), slog.String("kind", string(n.Kind)), slog.Any("error", err))
+			}
+			cancel()
+		}
+	}
+}
+
+// Close stops the sinks, notifications still queued are dropped
+func (r *Router) Close() {
+	r.cancel()
+	r.wg.Wait()
+}
+
+// Configure creates and registers the sinks of the given configs. Discord and Telegram sinks are provided by their
+// bots through builtin, keyed by sink type. Invalid sinks are skipped, the rest are registered anyway.
+func (r *Router) Configure(sinks []config.NotificationSink, builtin map[string]Sink) error {
+	var errs []error
+	for _, cfg := range sinks {
+		s, ok := builtin[cfg.Type]
+		if !ok {
+			var err error
+			if s, err = NewSink(cfg); err != nil {
+				errs = append(errs, fmt.Errorf("notification sink %s: %w", sinkName(cfg), err))
+				continue
+			}
+		}
+		if err := r.Add(cfg, s); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// Len returns the number of registered sinks
+func (r *Router) Len() int {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return len(r.routes)
+}
+
+// Handle queues the event for every sink accepting it, events without a notification kind are ignored. It never
+// waits for a sink, send errors are logged.
+func (r *Router) Handle(_ context.Context, e event.Event) error {
+	n, ok := NewNotification(e)
+	if !ok {
+		return nil
+	}
+
+	r.mu.RLock()
+	routes := r.routes
+	r.mu.RUnlock()
+
+	for _, rt := range routes {
+		if !rt.filter.accepts(n) {
+			continue
+		}
+		if !rt.limiter.allow(time.Now()) {
+			r.logger.Debug("Notification dropped by rate limit", slog.String("sink", rt.name), slog.String("kind", string(n.Kind)))
+			continue
+		}
+
+		select {
+		case rt.queue <- withGamePassword(n, rt.includeGamePassword):
+		default:
+			r.logger.Warn("Notification dropped, the sink is not keeping up", slog.String("sink", rt.name), slog.String("kind", string(n.Kind)))
+		}
+	}
+
+	return nil
+}
+
+// Classify returns the notification kind of an event, false for events that are never notified
+func Classify(e event.Event) (Kind, bool) {
+	switch evt := e.(type) {
+	case event.GameCreatedEvent:
+		return KindGameCreated, true
+	case event.GameFinishedEvent:
+		switch evt.Reason {
+		case event.FinishedChicken, event.FinishedMercChicken:
+			return KindChicken, true
+		case event.FinishedDied:
+			return KindDeath, true
+		case event.FinishedError:
+			return KindError, true
+		default:
+			return KindGameFinished, true
+		}
+	case event.RunStartedEvent:
+		return KindRunStarted, true
+	case event.RunFinishedEvent:
+		return KindRunFinished, true
+	case event.ItemStashedEvent:
+		return KindItemStashed, true
+	case event.ItemBlackListedEvent:
+		return KindItemBlacklisted, true
+	case event.RunewordRerollEvent:
+		return KindRunewordReroll, true
+	case event.NgrokTunnelEvent:
+		return KindNgrok, true
+	}
+
+	if e.Image() != nil {
+		return KindScreenshot, true
+	}
+
+	return "", false
+}
+
+// NewNotification builds the notification of an event, false if the event is never notified
+func NewNotification(e event.Event) (Notification, bool) {
+	kind, ok := Classify(e)
+	if !ok {
+		return Notification{}, false
+	}
+
+	n := Notification{
+		Kind:       kind,
+		Supervisor: e.Supervisor(),
+		Title:      fmt.Sprintf("[%s] %s", e.Supervisor(), kindTitle(kind)),
+		Message:    e.Message(),
+		Image:      e.Image(),
+		OccurredAt: e.OccurredAt(),
+		Event:      e,
+	}
+
+	switch evt := e.(type) {
+	case event.GameCreatedEvent:
+		n.Message = fmt.Sprintf("%s\nGame: %s", evt.Message(), evt.Name)
+	case event.RunStartedEvent:
+		n.Message = fmt.Sprintf("Started a new run: %s", evt.RunName)
+	case event.RunFinishedEvent:
+		n.Message = fmt.Sprintf("Finished run: %s (%s)", evt.RunName, evt.Reason)
+	case event.ItemStashedEvent:
+		it := evt.Item.Item
+		n.Item = &it
+		n.Message = fmt.Sprintf("Item stashed: %s [%s]", itemName(it), it.Quality.ToString())
+		if rule := strings.TrimSpace(evt.Item.Rule); rule != "" {
+			n.Message += "\n" + rule
+		}
+	case event.ItemBlackListedEvent:
+		it := evt.Item.Item
+		n.Item = &it
+	case event.RunewordRerollEvent:
+		if evt.Success {
+			n.Message = fmt.Sprintf("Runeword reroll of %s succeeded\nTarget: %s\nRolled: %s", evt.Runeword, evt.TargetStats, evt.ActualStats)
+		} else {
+			n.Message = fmt.Sprintf("Runeword reroll of %s stopped: %s\nTarget: %s\nRolled: %s", evt.Runeword, evt.FailureReason, evt.TargetStats, evt.ActualStats)
+		}
+	}
+
+	return n, true
+}
+
+// withGamePassword adds the game password to game created notifications, or removes it from the original event so
+// sinks formatting the event themselves (Discord) can't send it either
+func withGamePassword(n Notification, include bool) Notification {
+	evt, ok := n.Event.(event.GameCreatedEvent)
+	if !ok {
+		return n
+	}
+	if include {
+		n.Message += "\nPassword: " + evt.Password
+	} else {
+		evt.Password = ""
+		n.Event = evt
+	}
+
+	return n
+}
+
+func kindTitle(k Kind) string {
+	switch k {
+	case KindGameCreated:
+		return "Game created"
+	case KindGameFinished:
+		return "Game finished"
+	case KindChicken:
+		return "Chicken"
+	case KindDeath:
+		return "Death"
+	case KindError:
+		return "Error"
+	case KindRunStarted:
+		return "Run started"
+	case KindRunFinished:
+		return "Run finished"
+	case KindItemStashed:
+		return "Item stashed"
+	case KindItemBlacklisted:
+		return "Item blacklisted"
+	case KindRunewordReroll:
+		return "Runeword reroll"
+	case KindNgrok:
+		return "ngrok tunnel"
+	default:
+		return "Screenshot"
+	}
+}
+
+func itemName(it data.Item) string {
+	if it.IdentifiedName != "" {
+		return it.IdentifiedName
+	}
+
+	return string(it.Name)
+}
+
+func sinkName(cfg config.NotificationSink) string {
+	if cfg.Name != "" {
+		return cfg.Name
+	}
+
+	return cfg.Type
+}
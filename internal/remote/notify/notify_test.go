@@ -0,0 +1,100 @@
+package notify
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hectorgimenez/koolo/internal/config"
+	"github.com/hectorgimenez/koolo/internal/event"
+)
+
+func TestWithGamePassword(t *testing.T) {
+	n, ok := NewNotification(event.GameCreated(event.Text("sorc", "New game created"), "game-1", "hunter2"))
+	if !ok {
+		t.Fatal("game created events must be notified")
+	}
+
+	with := withGamePassword(n, true)
+	if !strings.Contains(with.Message, "Password: hunter2") {
+		t.Errorf("message %q misses the password", with.Message)
+	}
+	if with.Event.(event.GameCreatedEvent).Password != "hunter2" {
+		t.Error("the event lost its password")
+	}
+
+	without := withGamePassword(n, false)
+	if strings.Contains(without.Message, "hunter2") {
+		t.Errorf("message %q leaks the password", without.Message)
+	}
+	if without.Event.(event.GameCreatedEvent).Password != "" {
+		t.Error("the event still holds the password, sinks formatting it themselves would send it")
+	}
+	if n.Event.(event.GameCreatedEvent).Password != "hunter2" {
+		t.Error("the original notification was modified")
+	}
+
+	other, _ := NewNotification(event.RunStarted(event.Text("sorc", ""), "mephisto"))
+	if got := withGamePassword(other, true); got.Message != other.Message {
+		t.Errorf("message of other events changed to %q", got.Message)
+	}
+}
+
+// blockingSink never returns until its context is done, like a dead server
+type blockingSink struct {
+	started chan struct{}
+}
+
+func (s blockingSink) Send(ctx context.Context, _ Notification) error {
+	s.started <- struct{}{}
+	<-ctx.Done()
+
+	return ctx.Err()
+}
+
+type recordingSink chan Notification
+
+func (s recordingSink) Send(_ context.Context, n Notification) error {
+	s <- n
+	return nil
+}
+
+func TestHandleDoesNotWaitForSinks(t *testing.T) {
+	r := NewRouter(slog.New(slog.NewTextHandler(io.Discard, nil)))
+	defer r.Close()
+
+	slow := blockingSink{started: make(chan struct{}, queueSize+2)}
+	fast := make(recordingSink, queueSize+2)
+	if err := r.Add(config.NotificationSink{Name: "slow", Events: []string{"all"}}, slow); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Add(config.NotificationSink{Name: "fast", Events: []string{"all"}}, fast); err != nil {
+		t.Fatal(err)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		// More than the queue holds, the overflow of the slow sink is dropped
+		for i := 0; i < queueSize+2; i++ {
+			_ = r.Handle(context.Background(), event.RunStarted(event.Text("sorc", ""), "mephisto"))
+		}
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Handle waited for the blocked sink")
+	}
+	select {
+	case n := <-fast:
+		if n.Kind != KindRunStarted {
+			t.Errorf("fast sink got %s", n.Kind)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("the fast sink was held by the blocked one")
+	}
+}
@@ -0,0 +1,88 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"strings"
+)
+
+// ntfySink publishes to an ntfy topic URL (e.g. https://ntfy.sh/my-topic)
+type ntfySink struct {
+	url         string
+	token       string
+	screenshots bool
+}
+
+func (s *ntfySink) Send(ctx context.Context, n Notification) error {
+	method := http.MethodPost
+	body := []byte(n.Message)
+	headers := map[string]string{
+		"Title":         n.Title,
+		"Priority":      ntfyPriority(n.Kind),
+		"Tags":          string(n.Kind),
+		"Authorization": bearer(s.token),
+	}
+
+	// Attachments are sent as the request body, the message moves to a header where new lines must be escaped
+	if s.screenshots && n.Image != nil {
+		img, err := encodeJPEG(n.Image)
+		if err != nil {
+			return err
+		}
+		method = http.MethodPut
+		body = img
+		headers["Filename"] = "screenshot.jpeg"
+		headers["Message"] = strings.ReplaceAll(n.Message, "\n", `\n`)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, s.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	for k, v := range headers {
+		if v != "" {
+			req.Header.Set(k, v)
+		}
+	}
+
+	return do(req)
+}
+
+func ntfyPriority(k Kind) string {
+	switch k {
+	case KindDeath, KindError, KindChicken:
+		return "high"
+	case KindItemStashed, KindRunewordReroll:
+		return "default"
+	default:
+		return "low"
+	}
+}
+
+// gotifySink pushes messages to a Gotify server using an application token
+type gotifySink struct {
+	url   string
+	token string
+}
+
+func (s *gotifySink) Send(ctx context.Context, n Notification) error {
+	payload := map[string]any{
+		"title":    n.Title,
+		"message":  n.Message,
+		"priority": gotifyPriority(n.Kind),
+	}
+
+	return postJSON(ctx, s.url, payload, map[string]string{"X-Gotify-Key": s.token})
+}
+
+func gotifyPriority(k Kind) int {
+	switch k {
+	case KindDeath, KindError, KindChicken:
+		return 8
+	case KindItemStashed, KindRunewordReroll:
+		return 5
+	default:
+		return 2
+	}
+}
@@ -0,0 +1,122 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"io"
+	"net/http"
+	"time"
+)
+
+// webhookSink posts every notification as JSON, for custom integrations
+type webhookSink struct {
+	url         string
+	token       string
+	screenshots bool
+}
+
+type webhookItem struct {
+	Name     string `json:"name"`
+	Quality  string `json:"quality"`
+	Ethereal bool   `json:"ethereal"`
+}
+
+type webhookPayload struct {
+	Kind       Kind         `json:"kind"`
+	Supervisor string       `json:"supervisor"`
+	Title      string       `json:"title"`
+	Message    string       `json:"message"`
+	OccurredAt time.Time    `json:"occurredAt"`
+	Item       *webhookItem `json:"item,omitempty"`
+	Image      string       `json:"image,omitempty"` // Base64 encoded JPEG
+}
+
+func (s *webhookSink) Send(ctx context.Context, n Notification) error {
+	p := webhookPayload{
+		Kind:       n.Kind,
+		Supervisor: n.Supervisor,
+		Title:      n.Title,
+		Message:    n.Message,
+		OccurredAt: n.OccurredAt,
+	}
+	if n.Item != nil {
+		p.Item = &webhookItem{Name: itemName(*n.Item), Quality: n.Item.Quality.ToString(), Ethereal: n.Item.Ethereal}
+	}
+	if s.screenshots && n.Image != nil {
+		img, err := encodeJPEG(n.Image)
+		if err != nil {
+			return err
+		}
+		p.Image = base64.StdEncoding.EncodeToString(img)
+	}
+
+	return postJSON(ctx, s.url, p, map[string]string{"Authorization": bearer(s.token)})
+}
+
+// slackSink posts to Slack incoming webhooks, also accepted by Mattermost and Rocket.Chat
+type slackSink struct {
+	url string
+}
+
+func (s *slackSink) Send(ctx context.Context, n Notification) error {
+	text := fmt.Sprintf("*%s*\n%s\n_%s_", n.Title, n.Message, formatTime(n.OccurredAt))
+
+	return postJSON(ctx, s.url, map[string]string{"text": text}, nil)
+}
+
+func postJSON(ctx context.Context, url string, payload any, headers map[string]string) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range headers {
+		if v != "" {
+			req.Header.Set(k, v)
+		}
+	}
+
+	return do(req)
+}
+
+func do(req *http.Request) error {
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("unexpected status %s: %s", resp.Status, bytes.TrimSpace(msg))
+	}
+
+	return nil
+}
+
+func bearer(token string) string {
+	if token == "" {
+		return ""
+	}
+
+	return "Bearer " + token
+}
+
+func encodeJPEG(img image.Image) ([]byte, error) {
+	buf := new(bytes.Buffer)
+	if err := jpeg.Encode(buf, img, &jpeg.Options{Quality: 80}); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
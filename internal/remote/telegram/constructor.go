@@ -6,6 +6,8 @@ import (
 	"time"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/hectorgimenez/koolo/internal/bot"
+	"github.com/hectorgimenez/koolo/internal/remote/command"
 )
 
 const (
@@ -17,7 +19,7 @@ const (
 // NewBot creates a Telegram bot with retry logic for transient network failures.
 // The underlying tgbotapi.NewBotAPI call contacts api.telegram.org which can
 // occasionally fail with TCP resets; retrying avoids a fatal startup failure.
-func NewBot(token string, chatID int64, logger *slog.Logger) (*Bot, error) {
+func NewBot(token string, chatID int64, allowedIDs []int64, manager *bot.SupervisorManager, logger *slog.Logger) (*Bot, error) {
 	var api *tgbotapi.BotAPI
 	var err error
 
@@ -41,5 +43,5 @@ func NewBot(token string, chatID int64, logger *slog.Logger) (*Bot, error) {
 	if err != nil {
 		return nil, fmt.Errorf("after %d attempts: %w", maxRetries, err)
 	}
-	return &Bot{bot: api, chatID: chatID, logger: logger}, nil
+	return &Bot{bot: api, chatID: chatID, allowedIDs: allowedIDs, commands: command.NewHandler(manager, "/"), logger: logger}, nil
 }
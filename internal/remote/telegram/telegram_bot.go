@@ -3,20 +3,29 @@ package telegram
 import (
 	"context"
 	"log/slog"
+	"slices"
 	"strings"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/hectorgimenez/koolo/internal/remote/command"
 )
 
+// Supervisor buttons per inline keyboard row
+const choicesPerRow = 2
+
 type Bot struct {
-	bot    *tgbotapi.BotAPI
-	chatID int64
-	logger *slog.Logger
+	bot        *tgbotapi.BotAPI
+	chatID     int64
+	allowedIDs []int64
+	commands   *command.Handler
+	logger     *slog.Logger
 }
 
 func (b *Bot) Start(ctx context.Context) error {
 	offset, err := b.getLatestOffset()
-	if err != nil { return err }
+	if err != nil {
+		return err
+	}
 
 	u := tgbotapi.NewUpdate(offset)
 	u.Timeout = 5
@@ -26,24 +35,106 @@ func (b *Bot) Start(ctx context.Context) error {
 		select {
 		case <-ctx.Done():
 			b.bot.StopReceivingUpdates()
-			for range updates { }
+			for range updates {
+			}
 			return nil
 		case update, ok := <-updates:
-			if !ok { return nil }
-			if update.Message != nil && update.Message.Chat != nil && update.Message.Chat.ID == b.chatID {
-				switch strings.ToLower(update.Message.Text) {
-				case "stats":
-					// add stats handling if needed
-				}
+			if !ok {
+				return nil
 			}
+			b.handleUpdate(update)
+		}
+	}
+}
+
+func (b *Bot) handleUpdate(update tgbotapi.Update) {
+	switch {
+	case update.Message != nil && update.Message.Chat != nil:
+		msg := update.Message
+		if !b.isAllowed(msg.Chat.ID, msg.From) {
+			return
+		}
+		if replies, ok := b.commands.Execute(msg.Text); ok {
+			b.sendReplies(msg.Chat.ID, replies)
+		}
+	case update.CallbackQuery != nil && update.CallbackQuery.Message != nil:
+		q := update.CallbackQuery
+		if !b.isAllowed(q.Message.Chat.ID, q.From) {
+			return
+		}
+		// Stops the loading indicator of the button
+		if _, err := b.bot.Request(tgbotapi.NewCallback(q.ID, "")); err != nil {
+			b.logger.Debug("Telegram callback answer failed", slog.Any("error", err))
+		}
+		name, supervisor, _ := strings.Cut(q.Data, " ")
+		b.sendReplies(q.Message.Chat.ID, b.commands.Run(name, strings.Fields(supervisor)))
+	}
+}
+
+// isAllowed checks the chat and sender against the allow-list, only the configured chat is allowed without one
+func (b *Bot) isAllowed(chatID int64, from *tgbotapi.User) bool {
+	if len(b.allowedIDs) == 0 {
+		return chatID == b.chatID
+	}
+
+	return slices.Contains(b.allowedIDs, chatID) || (from != nil && slices.Contains(b.allowedIDs, from.ID))
+}
+
+func (b *Bot) sendReplies(chatID int64, replies []command.Reply) {
+	for _, r := range replies {
+		msg := tgbotapi.NewMessage(chatID, replyText(r))
+		if len(r.Choices) > 0 {
+			msg.ReplyMarkup = choicesKeyboard(r.Command, r.Choices)
+		}
+		if _, err := b.bot.Send(msg); err != nil {
+			b.logger.Warn("Telegram reply failed", slog.Any("error", err))
 		}
 	}
 }
 
+func replyText(r command.Reply) string {
+	var sb strings.Builder
+	if r.Title != "" {
+		sb.WriteString(r.Title + "\n")
+	}
+	if r.Text != "" {
+		sb.WriteString(r.Text + "\n")
+	}
+	for _, f := range r.Fields {
+		if f.Inline {
+			sb.WriteString(f.Name + ": " + strings.ReplaceAll(f.Value, "\n", ", ") + "\n")
+		} else {
+			sb.WriteString("\n" + f.Name + "\n" + f.Value + "\n")
+		}
+	}
+	if r.Footer != "" {
+		sb.WriteString("\n" + r.Footer)
+	}
+
+	return strings.TrimSpace(sb.String())
+}
+
+func choicesKeyboard(cmd string, supervisors []string) tgbotapi.InlineKeyboardMarkup {
+	var rows [][]tgbotapi.InlineKeyboardButton
+	for i := 0; i < len(supervisors); i += choicesPerRow {
+		var row []tgbotapi.InlineKeyboardButton
+		for _, s := range supervisors[i:min(i+choicesPerRow, len(supervisors))] {
+			row = append(row, tgbotapi.NewInlineKeyboardButtonData(s, cmd+" "+s))
+		}
+		rows = append(rows, row)
+	}
+
+	return tgbotapi.NewInlineKeyboardMarkup(rows...)
+}
+
 func (b *Bot) getLatestOffset() (int, error) {
 	upds, err := b.bot.GetUpdates(tgbotapi.NewUpdate(-1))
-	if err != nil { return 0, err }
+	if err != nil {
+		return 0, err
+	}
 	offset := 0
-	if len(upds) > 0 { offset = upds[0].UpdateID + 1 }
+	if len(upds) > 0 {
+		offset = upds[0].UpdateID + 1
+	}
 	return offset, nil
 }
@@ -6,21 +6,23 @@ import (
     "image/jpeg"
 
     tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
-    "github.com/hectorgimenez/koolo/internal/event"
+    "github.com/hectorgimenez/koolo/internal/remote/notify"
 )
 
-func (b *Bot) Handle(_ context.Context, e event.Event) error {
-    if e.Image() != nil {
+// Send delivers a notification to the chat, it makes the bot a sink of the notification router
+func (b *Bot) Send(_ context.Context, n notify.Notification) error {
+    text := n.Title + "\n" + n.Message
+    if n.Image != nil {
         buf := new(bytes.Buffer)
-        if err := jpeg.Encode(buf, e.Image(), &jpeg.Options{Quality: 90}); err != nil {
-            _, _ = b.bot.Send(tgbotapi.NewMessage(b.chatID, e.Message()+" (screenshot encode failed)"))
+        if err := jpeg.Encode(buf, n.Image, &jpeg.Options{Quality: 90}); err != nil {
+            _, _ = b.bot.Send(tgbotapi.NewMessage(b.chatID, text+" (screenshot encode failed)"))
             return err
         }
         photo := tgbotapi.NewPhoto(b.chatID, tgbotapi.FileBytes{Name: "screenshot.jpg", Bytes: buf.Bytes()})
-        photo.Caption = e.Message()
+        photo.Caption = text
         _, err := b.bot.Send(photo)
         return err
     }
-    _, err := b.bot.Send(tgbotapi.NewMessage(b.chatID, e.Message()))
+    _, err := b.bot.Send(tgbotapi.NewMessage(b.chatID, text))
     return err
 }
@@ -68,8 +68,11 @@ func (d Drop) runSingle(ctx *context.Status, req *drop.Request) error {
 		return nil
 	}
 
-	// Always apply request filters so disabled filters clear any previous state.
-	ctx.Drop.UpdateFilters(req.Filters)
+	// Always apply request filters so disabled filters clear any previous state. Pickups don't drop anything, the
+	// filters of the supervisor are left alone.
+	if !req.Pickup {
+		ctx.Drop.UpdateFilters(req.Filters)
+	}
 	if req.Filters.Enabled {
 		ctx.Logger.Debug("Drop: Applied filters from request",
 			"room", req.RoomName,
@@ -104,7 +107,7 @@ func (d Drop) runSingle(ctx *context.Status, req *drop.Request) error {
 		if err := d.ensureCharacterSelection(ctx); err != nil {
 			ctx.Logger.Error("Drop: Failed to return to character selection, will kill client", "error", err)
 
-			if ctx.GameReader != nil && ctx.GameReader.Process != nil {
+			if ctx.GameReader != nil && ctx.GameReader.GameReader != nil && ctx.GameReader.Process != nil {
 				pid := ctx.GameReader.Process.GetPID()
 				if process, findErr := os.FindProcess(int(pid)); findErr == nil {
 					if killErr := process.Kill(); killErr != nil {
@@ -188,14 +191,28 @@ func (d Drop) runSingle(ctx *context.Status, req *drop.Request) error {
 		ctx.Logger.Warn("Drop cleanup warning (continuing anyway)", "error", err)
 	}
 
-	itemsDroppered, dropErr := d.dropStashItems(ctx)
-	ctx.EnableItemPickup()
-	if dropErr != nil {
-		ctx.Logger.Error("Drop: stash drop sequence failed", "error", dropErr)
-		DropError = dropErr
-		return dropErr
+	if req.Pickup {
+		// Receiving side of an item transfer, the items dropped next to the stash are picked up
+		picked, pickupErr := action.PickupDroppedItems(20)
+		itemsDroppered = picked
+		ctx.EnableItemPickup()
+		if pickupErr != nil {
+			ctx.Logger.Error("Drop: picking up the dropped items failed", "error", pickupErr)
+			DropError = pickupErr
+			return pickupErr
+		}
+		ctx.Logger.Info("Drop: finished picking up the dropped items", "itemsPicked", picked)
+	} else {
+		var dropErr error
+		itemsDroppered, dropErr = d.dropStashItems(ctx)
+		ctx.EnableItemPickup()
+		if dropErr != nil {
+			ctx.Logger.Error("Drop: stash drop sequence failed", "error", dropErr)
+			DropError = dropErr
+			return dropErr
+		}
+		ctx.Logger.Info("Drop: finished stash drop sequence", "itemsDroppered", itemsDroppered)
 	}
-	ctx.Logger.Info("Drop: finished stash drop sequence", "itemsDroppered", itemsDroppered)
 
 	runCompleted = true
 
@@ -39,7 +39,7 @@ func BuildRuns(cfg *config.CharacterCfg, runs []string) (builtRuns []Run) {
 		if run == config.TerrorZoneRun {
 			tz := NewTerrorZone()
 
-			if len(tz.AvailableTZs()) > 0 {
+			if len(tz.AvailableTZs()) > 0 && tz.WorthRunning() {
 				builtRuns = append(builtRuns, tz)
 				// If we are skipping other runs, we can return here
 				if cfg.Game.TerrorZone.SkipOtherRuns {
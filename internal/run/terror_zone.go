@@ -2,6 +2,8 @@ package run
 
 import (
 	"fmt"
+	"log/slog"
+	"time"
 
 	"github.com/hectorgimenez/d2go/pkg/data"
 	"github.com/hectorgimenez/d2go/pkg/data/area"
@@ -69,7 +71,7 @@ func (tz TerrorZone) Run(parameters *RunParameters) error {
 	// --- Generic TZ handling via centralized routes ---
 	primary := availableTzs[0]
 
-	routes := terrorzones.RoutesFor(primary)
+	routes := terrorzones.ForCharacter(tz.ctx.Name).RoutesFor(primary)
 	if len(routes) == 0 {
 		tz.ctx.Logger.Debug("No terror zone route defined", "area", primary.Area().Name)
 		return nil
@@ -89,7 +91,7 @@ func (tz TerrorZone) Run(parameters *RunParameters) error {
 			}
 
 			// Clearing: only if the route explicitly says so.
-			// We trust routes.go + terrorzones.go to define the correct group.
+			// We trust the terror zone routes (built-in or data file) to define the correct group.
 			if step.Kind == terrorzones.StepClear {
 				if err := action.ClearCurrentLevel(
 					tz.ctx.CharacterCfg.Game.TerrorZone.OpenChests,
@@ -118,6 +120,54 @@ func (tz TerrorZone) AvailableTZs() []area.ID {
 	return availableTZs
 }
 
+// WorthRunning scores the current terror zone when scoring is enabled, zones below the minimum score are skipped
+// and the regular runs are done instead. The decision is logged and kept for the dashboard.
+func (tz TerrorZone) WorthRunning() bool {
+	if !tz.ctx.CharacterCfg.Game.TerrorZone.Scoring.Enabled {
+		return true
+	}
+
+	availableTzs := tz.AvailableTZs()
+	if len(availableTzs) == 0 {
+		return false
+	}
+
+	// Metadata is keyed by the first area of every TZ group, score the first one we have data for
+	zone := availableTzs[0]
+	zones := terrorzones.ForCharacter(tz.ctx.Name).Zones
+	for _, id := range availableTzs {
+		if _, found := zones[id]; found {
+			zone = id
+			break
+		}
+	}
+
+	scores, err := ScoreTerrorZones(tz.ctx.Name, tz.ctx.CharacterCfg, []area.ID{zone})
+	if err != nil {
+		tz.ctx.Logger.Warn("Failed to read run history for terror zone scoring", slog.Any("error", err))
+	}
+	score := scores[0]
+
+	zoneNames := make([]string, 0, len(availableTzs))
+	for _, id := range availableTzs {
+		zoneNames = append(zoneNames, id.Area().Name)
+	}
+	terrorzones.RecordDecision(terrorzones.Decision{
+		Supervisor: tz.ctx.Name,
+		Time:       time.Now(),
+		Zones:      zoneNames,
+		Score:      score,
+	})
+
+	if score.Worth {
+		tz.ctx.Logger.Info("Terror zone is worth running", slog.String("score", score.String()))
+	} else {
+		tz.ctx.Logger.Info("Terror zone below minimum score, doing regular runs", slog.String("score", score.String()))
+	}
+
+	return score.Worth
+}
+
 func (tz TerrorZone) customTZEnemyFilter() data.MonsterFilter {
 	return func(m data.Monsters) []data.Monster {
 		var filteredMonsters []data.Monster
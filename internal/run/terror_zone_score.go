@@ -0,0 +1,174 @@
+package run
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hectorgimenez/d2go/pkg/data/area"
+	"github.com/hectorgimenez/d2go/pkg/data/stat"
+	"github.com/hectorgimenez/koolo/internal/config"
+	"github.com/hectorgimenez/koolo/internal/event"
+	"github.com/hectorgimenez/koolo/internal/remote/history"
+	terrorzones "github.com/hectorgimenez/koolo/internal/terrorzone"
+)
+
+const (
+	terrorZoneRunPrefix     = "TerrorZone Run: ["
+	terrorZoneHistoryWindow = 30 * 24 * time.Hour
+	terrorZoneHistoryTTL    = 10 * time.Minute
+)
+
+// Resist names used in the config mapped to the immunity codes of the terror zone metadata
+var resistImmunityCodes = map[stat.Resist]string{
+	stat.ColdImmune:   "c",
+	stat.FireImmune:   "f",
+	stat.LightImmune:  "l",
+	stat.PoisonImmune: "p",
+	stat.MagicImmune:  "m",
+	"physical":        "ph",
+}
+
+type terrorZoneHistoryCache struct {
+	loadedAt time.Time
+	zones    map[area.ID]terrorzones.ZoneHistory
+}
+
+var (
+	tzHistoryMux   sync.Mutex
+	tzHistoryCache = map[string]terrorZoneHistoryCache{}
+)
+
+// ScoreTerrorZones scores the given terror zones for a supervisor, using its character config and run history.
+// Zones are still scored without history when the history files can't be read, the error is returned anyway.
+func ScoreTerrorZones(supervisor string, cfg *config.CharacterCfg, zones []area.ID) ([]terrorzones.ScoreBreakdown, error) {
+	weights := TerrorZoneScoreWeights(cfg)
+	damage := terrorZoneDamageTypes(cfg)
+	hist, err := terrorZoneHistory(supervisor)
+	set := terrorzones.ForCharacter(supervisor)
+
+	scores := make([]terrorzones.ScoreBreakdown, 0, len(zones))
+	for _, id := range zones {
+		scores = append(scores, terrorzones.ScoreZone(id, set.Info(id), damage, hist[id], weights))
+	}
+
+	return scores, err
+}
+
+// TerrorZoneScoreWeights returns the scoring weights of a character, every weight not set in its config keeps its
+// default
+func TerrorZoneScoreWeights(cfg *config.CharacterCfg) terrorzones.ScoreWeights {
+	sc := cfg.Game.TerrorZone.Scoring
+	w := terrorzones.DefaultScoreWeights()
+	for _, o := range []struct {
+		value  *float64
+		weight *float64
+	}{
+		{sc.ExpWeight, &w.Exp},
+		{sc.LootWeight, &w.Loot},
+		{sc.ImmunityWeight, &w.Immunities},
+		{sc.ClearTimeWeight, &w.ClearTime},
+		{sc.DeathWeight, &w.Deaths},
+		{sc.MinScore, &w.MinScore},
+	} {
+		if o.value != nil {
+			*o.weight = *o.value
+		}
+	}
+	if sc.TargetClearTimeSeconds > 0 {
+		w.TargetClearTime = time.Duration(sc.TargetClearTimeSeconds) * time.Second
+	}
+
+	return w
+}
+
+func terrorZoneDamageTypes(cfg *config.CharacterCfg) []string {
+	resists := cfg.Game.TerrorZone.Scoring.DamageTypes
+	if len(resists) == 0 {
+		resists = cfg.Game.TerrorZone.SkipOnImmunities
+	}
+
+	codes := make([]string, 0, len(resists))
+	for _, r := range resists {
+		if code, ok := resistImmunityCodes[r]; ok {
+			codes = append(codes, code)
+		}
+	}
+
+	return codes
+}
+
+// terrorZoneHistory returns the clear time and death rate per primary TZ area of the last 30 days, history files are
+// re-read at most every 10 minutes
+func terrorZoneHistory(supervisor string) (map[area.ID]terrorzones.ZoneHistory, error) {
+	tzHistoryMux.Lock()
+	defer tzHistoryMux.Unlock()
+
+	if cached, ok := tzHistoryCache[supervisor]; ok && time.Since(cached.loadedAt) < terrorZoneHistoryTTL {
+		return cached.zones, nil
+	}
+
+	zones := make(map[area.ID]terrorzones.ZoneHistory)
+	games, err := history.Query(terrorZoneHistoryDir(), history.Filter{Supervisor: supervisor, From: time.Now().Add(-terrorZoneHistoryWindow)})
+	if err != nil {
+		return zones, err
+	}
+
+	durations := make(map[area.ID][]time.Duration)
+	deaths := make(map[area.ID]int)
+	for _, r := range history.Runs(games) {
+		id, ok := terrorZoneOfRun(supervisor, r.Name)
+		if !ok || r.Duration() == 0 {
+			continue
+		}
+		durations[id] = append(durations[id], r.Duration())
+		if r.Reason == event.FinishedDied {
+			deaths[id]++
+		}
+	}
+	for id, ds := range durations {
+		sort.Slice(ds, func(i, j int) bool { return ds[i] < ds[j] })
+		zones[id] = terrorzones.ZoneHistory{
+			Runs:            len(ds),
+			MedianClearTime: ds[len(ds)/2],
+			DeathRate:       float64(deaths[id]) / float64(len(ds)),
+		}
+	}
+
+	tzHistoryCache[supervisor] = terrorZoneHistoryCache{loadedAt: time.Now(), zones: zones}
+
+	return zones, nil
+}
+
+// terrorZoneOfRun returns the primary area of a TerrorZone run name, see TerrorZone.Name. Areas are matched against
+// the terror zone data of the supervisor.
+func terrorZoneOfRun(supervisor, runName string) (area.ID, bool) {
+	if !strings.HasPrefix(runName, terrorZoneRunPrefix) {
+		return 0, false
+	}
+
+	names := strings.TrimPrefix(runName, terrorZoneRunPrefix)
+	var (
+		best    area.ID
+		bestLen int
+	)
+	for id := range terrorzones.ForCharacter(supervisor).Zones {
+		name := id.Area().Name
+		if len(name) > bestLen && strings.HasPrefix(names, name) {
+			best, bestLen = id, len(name)
+		}
+	}
+
+	return best, bestLen > 0
+}
+
+func terrorZoneHistoryDir() string {
+	base := config.Koolo.LogSaveDirectory
+	if base == "" {
+		base = "logs"
+	}
+
+	return filepath.Join(base, "history")
+}
@@ -0,0 +1,37 @@
+// Shows the configuration validation issues on the settings pages
+document.addEventListener('DOMContentLoaded', function () {
+    const panel = document.getElementById('config-validation');
+    if (!panel) {
+        return;
+    }
+
+    let url = '/api/config/validate';
+    const supervisor = panel.dataset.supervisor;
+    if (supervisor) {
+        url += '?supervisor=' + encodeURIComponent(supervisor);
+    }
+
+    fetch(url)
+        .then(response => response.ok ? response.json() : Promise.reject(response.statusText))
+        .then(report => {
+            const issues = report.issues || [];
+            if (issues.length === 0) {
+                return;
+            }
+
+            const title = document.createElement('strong');
+            title.textContent = 'Configuration issues';
+            const list = document.createElement('ul');
+            list.style.margin = '0.5rem 0 0 0';
+            issues.forEach(issue => {
+                const item = document.createElement('li');
+                const location = issue.path ? issue.file + ' › ' + issue.path : issue.file;
+                item.textContent = '[' + issue.severity + '] ' + location + ': ' + issue.message;
+                list.appendChild(item);
+            });
+
+            panel.replaceChildren(title, list);
+            panel.style.display = 'block';
+        })
+        .catch(err => console.error('Failed to validate configuration:', err));
+});
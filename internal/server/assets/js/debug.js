@@ -302,6 +302,13 @@ function expandToNode(node) {
     }
 }
 
+function createExportGridButton() {
+    document.getElementById('export-grid-btn').addEventListener('click', () => {
+        const characterName = new URLSearchParams(window.location.search).get('characterName') || '';
+        window.location.href = `/debug-grid?characterName=${encodeURIComponent(characterName)}`;
+    });
+}
+
 function createCopyDataButton() {
     const copyDataBtn = document.getElementById('copy-data-btn');
     copyDataBtn.addEventListener('click', () => {
@@ -325,5 +332,6 @@ searchPrevBtn.addEventListener('click', goToPreviousSearchResult);
 
 // Initialize
 createCopyDataButton();
+createExportGridButton();
 fetchDebugData();
 refreshIntervalId = setInterval(fetchDebugData, refreshInterval);
\ No newline at end of file
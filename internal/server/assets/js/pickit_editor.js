@@ -713,7 +713,7 @@ function displayLoadedRules(rules, fileName) {
         // The JSON field is "generatedNip" (lowercase 'n')
         const nipLine = rule.generatedNip || rule.GeneratedNIP || rule.nipSyntax || 'No NIP syntax available';
         return `
-        <div style="background: #1e1e1e; padding: 12px; margin-bottom: 8px; border-radius: 4px; border-left: 3px solid #4CAF50;">
+        <div id="loaded-rule-${rule.id}" style="background: #1e1e1e; padding: 12px; margin-bottom: 8px; border-radius: 4px; border-left: 3px solid #4CAF50;">
             <div style="display: flex; justify-content: space-between; align-items: start; margin-bottom: 6px;">
                 <span style="color: #888; font-size: 12px;">Rule ${index + 1}</span>
                 <div style="display: flex; gap: 8px;">
@@ -732,6 +732,44 @@ function displayLoadedRules(rules, fileName) {
 
     // Scroll to the loaded rules section
     section.scrollIntoView({ behavior: 'smooth', block: 'start' });
+
+    highlightRuleConflicts(fileName);
+}
+
+// highlightRuleConflicts marks loaded rules that are invalid, unreachable, duplicated or shadowed by another rule
+async function highlightRuleConflicts(fileName) {
+    if (!currentPickitPath) {
+        return;
+    }
+
+    try {
+        const response = await fetch(`/api/pickit/conflicts?path=${encodeURIComponent(currentPickitPath)}`);
+        if (!response.ok) {
+            return;
+        }
+        const analysis = await response.json();
+        const colors = { error: '#f44336', warning: '#ff9800', info: '#2196F3' };
+
+        (analysis.conflicts || []).forEach(conflict => {
+            (conflict.references || []).forEach(ref => {
+                if (ref.file !== fileName) {
+                    return;
+                }
+                const el = document.getElementById(`loaded-rule-${ref.id}`);
+                if (!el) {
+                    return;
+                }
+                // Keep the most severe highlight when a rule has several conflicts
+                if (el.dataset.severity !== 'error') {
+                    el.style.borderLeftColor = colors[conflict.severity] || colors.warning;
+                    el.dataset.severity = conflict.severity;
+                }
+                el.title = (el.title ? el.title + '\n' : '') + `[${conflict.type}] ${conflict.description}`;
+            });
+        });
+    } catch (error) {
+        console.error('Conflict detection error:', error);
+    }
 }
 
 function closeLoadedRules() {
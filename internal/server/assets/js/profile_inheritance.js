@@ -0,0 +1,42 @@
+// Lists the settings a character inherits from its base profile and the ones it overrides
+document.addEventListener('DOMContentLoaded', function () {
+    const panel = document.getElementById('profile-inheritance');
+    if (!panel) {
+        return;
+    }
+
+    const format = value => typeof value === 'object' ? JSON.stringify(value) : String(value);
+
+    fetch('/api/config/inheritance?supervisor=' + encodeURIComponent(panel.dataset.supervisor))
+        .then(response => response.ok ? response.json() : Promise.reject(response.statusText))
+        .then(inheritance => {
+            const values = inheritance.values || [];
+            const table = document.createElement('table');
+            const head = table.createTHead().insertRow();
+            ['Setting', 'Value', 'Source'].forEach(title => {
+                const th = document.createElement('th');
+                th.textContent = title;
+                head.appendChild(th);
+            });
+
+            const body = table.createTBody();
+            values.forEach(v => {
+                const row = body.insertRow();
+                row.insertCell().textContent = v.path;
+                row.insertCell().textContent = format(v.value);
+                const source = row.insertCell();
+                if (v.overridden) {
+                    source.textContent = 'Overridden (profile: ' + format(v.profileValue) + ')';
+                    source.style.color = 'var(--accent-orange)';
+                } else {
+                    source.textContent = 'Inherited from ' + inheritance.profile;
+                }
+            });
+
+            const summary = panel.querySelector('summary');
+            const overridden = values.filter(v => v.overridden).length;
+            summary.textContent = `Profile ${inheritance.profile}: ${values.length - overridden} inherited, ${overridden} overridden`;
+            panel.replaceChildren(summary, table);
+        })
+        .catch(err => console.error('Failed to load profile inheritance:', err));
+});
@@ -0,0 +1,57 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/hectorgimenez/koolo/internal/config"
+)
+
+func (s *HttpServer) registerConfigRoutes() {
+	http.HandleFunc("/api/config/validate", s.handleConfigValidate)
+	http.HandleFunc("/api/config/inheritance", s.handleConfigInheritance)
+}
+
+// handleConfigValidate returns the validation report of the loaded configuration, limited to a character config
+// with ?supervisor=name
+func (s *HttpServer) handleConfigValidate(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	report := config.Validate()
+	if supervisor := r.URL.Query().Get("supervisor"); supervisor != "" {
+		if _, found := config.GetCharacter(supervisor); !found {
+			http.Error(w, "supervisor not found", http.StatusNotFound)
+			return
+		}
+		report = report.Supervisor(supervisor)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(report)
+}
+
+// handleConfigInheritance returns the values a supervisor inherits from its base profile and the ones it overrides
+func (s *HttpServer) handleConfigInheritance(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	cfg, found := config.GetCharacter(r.URL.Query().Get("supervisor"))
+	if !found {
+		http.Error(w, "supervisor not found", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(struct {
+		Profile string                  `json:"profile"`
+		Values  []config.InheritedValue `json:"values"`
+	}{
+		Profile: cfg.Inheritance.Profile,
+		Values:  cfg.Inheritance.Values(),
+	})
+}
@@ -83,6 +83,7 @@ func (s *HttpServer) initDropCallbacks() {
 	s.manager.DropService().SetClearServerFilterCallback(s.onDropClearFilters)
 	s.manager.DropService().SetClearPersistentRequestCallback(s.onDropClearPersistentRequest)
 	s.manager.DropService().SetDropResultCallback(s.onDropResult)
+	s.manager.SetTransferResultCallback(s.onTransferResult)
 }
 
 // onDropClearFilters is invoked when a Drop finishes and per-supervisor
@@ -159,6 +160,7 @@ func (s *HttpServer) registerDropRoutes() {
 	http.HandleFunc("/api/Drop/cancel", s.handleDropCancel)
 	http.HandleFunc("/api/Drop/protection", s.handleDropFilters)
 	http.HandleFunc("/api/Drop/filters", s.handleDropFilters)
+	http.HandleFunc("/api/Drop/transfer", s.handleDropTransfer)
 }
 
 func (s *HttpServer) appendDropHistory(entry DropHistoryEntry) {
@@ -245,36 +247,16 @@ func (s *HttpServer) resolveFilterValue(filter *drop.Filters) drop.Filters {
 }
 
 func (s *HttpServer) submitDropRequest(supervisor, room, password string, filters *drop.Filters, cardID int, cardName string) error {
-	sup := s.manager.GetSupervisor(supervisor)
-	if sup == nil {
-		return fmt.Errorf("unknown supervisor %s", supervisor)
-	}
-
-	ctx := sup.GetContext()
-	if ctx == nil {
-		return fmt.Errorf("failed to get context for %s", supervisor)
-	}
-
-	if ctx.Drop == nil {
-		ctx.Drop = drop.NewManager(ctx.Name, ctx.Logger)
-	}
-
 	actualFilters := s.resolveFilterValue(filters)
-	// Avoid overwriting active Drop filters; apply per-request filters when the run starts.
-	if ctx.Drop.Active() == nil {
-		ctx.Drop.UpdateFilters(actualFilters)
+	req, err := s.manager.RequestDrop(supervisor, room, password, actualFilters)
+	if err != nil {
+		return err
 	}
 	s.setDropFilters(supervisor, actualFilters)
 
-	req := ctx.Drop.RequestDrop(room, password)
 	req.CardID = cardID
 	req.CardName = cardName
-	req.Filters = actualFilters
 	s.setDropCardInfo(supervisor, cardID, cardName)
-	ctx.Logger.Info("Drop request queued", "supervisor", supervisor, "room", room)
-
-	// Store persistent request for 10 minutes
-	s.manager.DropService().StorePersistentRequest(supervisor, req)
 
 	s.rememberDropRequest(supervisor, room, password, "queued")
 	return nil
@@ -0,0 +1,97 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/hectorgimenez/koolo/internal/bot"
+	"github.com/hectorgimenez/koolo/internal/drop"
+)
+
+// DropTransferRequest moves items from one of our supervisors to another one through a room.
+type DropTransferRequest struct {
+	Dropper  string        `json:"dropper"`
+	Receiver string        `json:"receiver"`
+	RoomName string        `json:"room"`
+	Password string        `json:"password"`
+	Filter   *drop.Filters `json:"filter"`
+}
+
+// handleDropTransfer starts a transfer on POST and lists the known transfers on GET.
+func (s *HttpServer) handleDropTransfer(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(s.manager.Transfers())
+	case http.MethodPost:
+		var req DropTransferRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "invalid JSON", http.StatusBadRequest)
+			return
+		}
+		if req.Dropper == "" || req.Receiver == "" {
+			http.Error(w, "dropper and receiver are required", http.StatusBadRequest)
+			return
+		}
+
+		filters := s.resolveFilterValue(req.Filter)
+		transfer, err := s.manager.StartTransfer(req.Dropper, req.Receiver, req.RoomName, req.Password, filters)
+		if err != nil {
+			if strings.Contains(err.Error(), "unknown supervisor") {
+				http.Error(w, err.Error(), http.StatusNotFound)
+				return
+			}
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		s.setDropFilters(req.Dropper, filters)
+		s.rememberDropRequest(transferLabel(transfer), req.RoomName, req.Password, "transfer queued")
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(transfer)
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// onTransferResult records a finished transfer in the Drop history, the items count is the number of items that left
+// the dropper and the error message lists the ones that never arrived.
+func (s *HttpServer) onTransferResult(t bot.Transfer) {
+	arrived := 0
+	for _, it := range t.Arrived {
+		arrived += it.Count
+	}
+	left := 0
+	for _, it := range t.Left {
+		left += it.Count
+	}
+
+	errorMsg := t.Error
+	if len(t.Missing) > 0 {
+		missing := make([]string, 0, len(t.Missing))
+		for _, it := range t.Missing {
+			missing = append(missing, it.String())
+		}
+		errorMsg = fmt.Sprintf("%s: %s", t.Error, strings.Join(missing, ", "))
+	}
+
+	duration := t.FinishedAt.Sub(t.CreatedAt)
+	s.appendDropHistory(DropHistoryEntry{
+		Supervisor:     transferLabel(t),
+		Room:           t.Room,
+		FilterApplied:  "Transfer",
+		FilterMode:     fmt.Sprintf("%d/%d arrived", arrived, left),
+		Result:         string(t.Status),
+		ItemsDroppered: left,
+		Duration:       fmt.Sprintf("%dm%ds", int(duration.Minutes()), int(duration.Seconds())%60),
+		ErrorMessage:   errorMsg,
+		Timestamp:      time.Now(),
+	})
+}
+
+func transferLabel(t bot.Transfer) string {
+	return t.Dropper + " → " + t.Receiver
+}
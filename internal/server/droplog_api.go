@@ -0,0 +1,126 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hectorgimenez/koolo/internal/config"
+	"github.com/hectorgimenez/koolo/internal/remote/droplog"
+)
+
+func (s *HttpServer) registerDroplogRoutes() {
+	http.HandleFunc("/api/droplog/query", s.handleDroplogQuery)
+	http.HandleFunc("/api/droplog/export", s.handleDroplogExport)
+	http.HandleFunc("/api/droplog/retention", s.handleDroplogRetention)
+}
+
+func droplogDir() string {
+	base := config.Koolo.LogSaveDirectory
+	if base == "" {
+		base = "logs"
+	}
+
+	return filepath.Join(base, "droplogs")
+}
+
+// parseDroplogQuery reads the droplog filters from the query string, see droplog.Query for the matching rules.
+func parseDroplogQuery(r *http.Request) (droplog.Query, error) {
+	q := r.URL.Query()
+	dq := droplog.Query{
+		Supervisor: strings.TrimSpace(q.Get("supervisor")),
+		Profile:    strings.TrimSpace(q.Get("profile")),
+		Character:  strings.TrimSpace(q.Get("character")),
+		ItemName:   strings.TrimSpace(q.Get("item")),
+		Quality:    strings.TrimSpace(q.Get("quality")),
+		Rule:       strings.TrimSpace(q.Get("rule")),
+	}
+
+	var err error
+	if dq.From, err = parseHistoryDate(q.Get("from"), false); err != nil {
+		return dq, fmt.Errorf("invalid from date: %w", err)
+	}
+	if dq.To, err = parseHistoryDate(q.Get("to"), true); err != nil {
+		return dq, fmt.Errorf("invalid to date: %w", err)
+	}
+	if v := q.Get("offset"); v != "" {
+		if dq.Offset, err = strconv.Atoi(v); err != nil {
+			return dq, fmt.Errorf("invalid offset: %w", err)
+		}
+	}
+	if v := q.Get("limit"); v != "" {
+		if dq.Limit, err = strconv.Atoi(v); err != nil {
+			return dq, fmt.Errorf("invalid limit: %w", err)
+		}
+	}
+
+	return dq, nil
+}
+
+func (s *HttpServer) handleDroplogQuery(w http.ResponseWriter, r *http.Request) {
+	dq, err := parseDroplogQuery(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	page, err := droplog.Search(droplogDir(), dq)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(page)
+}
+
+// handleDroplogExport downloads every record matching the query as CSV (default) or JSON, pagination is ignored.
+func (s *HttpServer) handleDroplogExport(w http.ResponseWriter, r *http.Request) {
+	dq, err := parseDroplogQuery(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	records, err := droplog.Find(droplogDir(), dq)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	fileName := fmt.Sprintf("droplog-export-%s", time.Now().Format("2006-01-02-15-04-05"))
+	switch strings.ToLower(r.URL.Query().Get("format")) {
+	case "json":
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", fileName+".json"))
+		err = droplog.WriteJSON(w, records)
+	default:
+		w.Header().Set("Content-Type", "text/csv")
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", fileName+".csv"))
+		err = droplog.WriteCSV(w, records)
+	}
+	if err != nil {
+		s.logger.Error("Failed to export droplog", "error", err)
+	}
+}
+
+// handleDroplogRetention applies the configured retention policy immediately.
+func (s *HttpServer) handleDroplogRetention(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	res, err := droplog.ApplyRetention(droplogDir(), config.Koolo.Droplog.RetentionDays, config.Koolo.Droplog.RetentionMode, time.Now())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(res)
+}
@@ -0,0 +1,199 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/hectorgimenez/koolo/internal/config"
+	"github.com/hectorgimenez/koolo/internal/event"
+	"github.com/hectorgimenez/koolo/internal/remote/droplog"
+	"github.com/hectorgimenez/koolo/internal/remote/history"
+)
+
+// HistorySummaryResponse is the response of the history summary API, totals are split per supervisor.
+type HistorySummaryResponse struct {
+	Total       history.Summary            `json:"total"`
+	Supervisors map[string]history.Summary `json:"supervisors"`
+}
+
+func (s *HttpServer) registerHistoryRoutes() {
+	http.HandleFunc("/api/history/games", s.handleHistoryGames)
+	http.HandleFunc("/api/history/runs", s.handleHistoryRuns)
+	http.HandleFunc("/api/history/summary", s.handleHistorySummary)
+	http.HandleFunc("/analytics", s.analyticsPage)
+	http.HandleFunc("/api/analytics", s.handleAnalytics)
+}
+
+func historyDir() string {
+	base := config.Koolo.LogSaveDirectory
+	if base == "" {
+		base = "logs"
+	}
+
+	return filepath.Join(base, "history")
+}
+
+// parseHistoryFilter reads supervisor, run, reason, from and to query parameters. Dates accept
+// either RFC3339 or YYYY-MM-DD, in which case "to" covers the whole day.
+func parseHistoryFilter(r *http.Request) (history.Filter, error) {
+	q := r.URL.Query()
+	f := history.Filter{
+		Supervisor: strings.TrimSpace(q.Get("supervisor")),
+		RunName:    strings.TrimSpace(q.Get("run")),
+		Reason:     event.FinishReason(strings.TrimSpace(q.Get("reason"))),
+	}
+
+	var err error
+	if f.From, err = parseHistoryDate(q.Get("from"), false); err != nil {
+		return f, fmt.Errorf("invalid from date: %w", err)
+	}
+	if f.To, err = parseHistoryDate(q.Get("to"), true); err != nil {
+		return f, fmt.Errorf("invalid to date: %w", err)
+	}
+
+	return f, nil
+}
+
+func parseHistoryDate(value string, endOfDay bool) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	t, err := time.ParseInLocation("2006-01-02", value, time.Local)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if endOfDay {
+		t = t.Add(24*time.Hour - time.Nanosecond)
+	}
+
+	return t, nil
+}
+
+func (s *HttpServer) queryHistory(w http.ResponseWriter, r *http.Request) ([]history.Game, bool) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return nil, false
+	}
+
+	f, err := parseHistoryFilter(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return nil, false
+	}
+
+	games, err := history.Query(historyDir(), f)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return nil, false
+	}
+
+	return games, true
+}
+
+func (s *HttpServer) handleHistoryGames(w http.ResponseWriter, r *http.Request) {
+	games, ok := s.queryHistory(w, r)
+	if !ok {
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(games)
+}
+
+func (s *HttpServer) handleHistoryRuns(w http.ResponseWriter, r *http.Request) {
+	games, ok := s.queryHistory(w, r)
+	if !ok {
+		return
+	}
+
+	runs := history.Runs(games)
+	if runs == nil {
+		runs = []history.Run{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(runs)
+}
+
+func (s *HttpServer) handleHistorySummary(w http.ResponseWriter, r *http.Request) {
+	games, ok := s.queryHistory(w, r)
+	if !ok {
+		return
+	}
+
+	perSupervisor := make(map[string][]history.Game)
+	for _, g := range games {
+		perSupervisor[g.Supervisor] = append(perSupervisor[g.Supervisor], g)
+	}
+
+	resp := HistorySummaryResponse{
+		Total:       history.Summarize(games),
+		Supervisors: make(map[string]history.Summary, len(perSupervisor)),
+	}
+	for name, supGames := range perSupervisor {
+		resp.Supervisors[name] = history.Summarize(supGames)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+func (s *HttpServer) analyticsPage(w http.ResponseWriter, r *http.Request) {
+	if err := s.templates.ExecuteTemplate(w, "analytics.gohtml", map[string]interface{}{
+		"Supervisors": s.manager.AvailableSupervisors(),
+	}); err != nil {
+		s.logger.Error("Failed to render analytics template", slog.Any("error", err))
+	}
+}
+
+// handleAnalytics returns run efficiency metrics for the filtered history. Valuable drops are joined
+// from the droplog files unless drops=false is passed, only the days covered by the runs are read.
+func (s *HttpServer) handleAnalytics(w http.ResponseWriter, r *http.Request) {
+	games, ok := s.queryHistory(w, r)
+	if !ok {
+		return
+	}
+	runs := history.Runs(games)
+
+	var drops []droplog.Record
+	if r.URL.Query().Get("drops") != "false" {
+		if q, found := analyticsDropQuery(runs); found {
+			records, err := droplog.Find(droplogDir(), q)
+			if err != nil {
+				s.logger.Warn("Failed to read droplogs for analytics", slog.Any("error", err))
+			}
+			drops = records
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(history.Analyze(runs, drops))
+}
+
+// analyticsDropQuery bounds the droplog query to the span of the finished runs, drops outside of it can't be
+// attributed to any run. It returns false when no run is finished.
+func analyticsDropQuery(runs []history.Run) (droplog.Query, bool) {
+	var q droplog.Query
+	for _, run := range runs {
+		if run.Duration() <= 0 {
+			continue
+		}
+		if q.From.IsZero() || run.StartedAt.Before(q.From) {
+			q.From = run.StartedAt
+		}
+		if run.FinishedAt.After(q.To) {
+			q.To = run.FinishedAt
+		}
+	}
+
+	return q, !q.From.IsZero()
+}
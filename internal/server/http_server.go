@@ -40,14 +40,15 @@ import (
 	"github.com/hectorgimenez/koolo/internal/drop"
 	"github.com/hectorgimenez/koolo/internal/event"
 	"github.com/hectorgimenez/koolo/internal/game"
+	"github.com/hectorgimenez/koolo/internal/game/mapgrid"
 	"github.com/hectorgimenez/koolo/internal/remote/droplog"
+	"github.com/hectorgimenez/koolo/internal/remote/metrics"
 	terrorzones "github.com/hectorgimenez/koolo/internal/terrorzone"
 	"github.com/hectorgimenez/koolo/internal/updater"
 	"github.com/hectorgimenez/koolo/internal/utils"
 	"github.com/hectorgimenez/koolo/internal/utils/winproc"
 	"github.com/lxn/win"
 	"golang.org/x/sys/windows"
-	"gopkg.in/yaml.v3"
 )
 
 type HttpServer struct {
@@ -59,6 +60,7 @@ type HttpServer struct {
 	wsServer            *WebSocketServer
 	pickitAPI           *PickitAPI
 	sequenceAPI         *SequenceAPI
+	metrics             *metrics.Collector
 	updater             *updater.Updater
 	DropHistory         []DropHistoryEntry
 	RunewordHistory     []RunewordHistoryEntry
@@ -311,6 +313,7 @@ func New(logger *slog.Logger, manager *bot.SupervisorManager, scheduler *bot.Sch
 		templates:         templates,
 		pickitAPI:         NewPickitAPI(),
 		sequenceAPI:       NewSequenceAPI(logger),
+		metrics:           metrics.NewCollector(),
 		updater:           updater.NewUpdater(logger),
 		DropFilters:       make(map[string]drop.Filters),
 		DropCardInfo:      make(map[string]dropCardInfo),
@@ -999,7 +1002,8 @@ func scheduleSummary(cfg *config.CharacterCfg) string {
 	}
 }
 
-func (s *HttpServer) Listen(port int) error {
+// Listen serves the web UI and API on addr (host:port) until Stop is called
+func (s *HttpServer) Listen(addr string) error {
 	s.wsServer = NewWebSocketServer()
 	go s.wsServer.Run()
 	go s.BroadcastStatus()
@@ -1019,6 +1023,7 @@ func (s *HttpServer) Listen(port int) error {
 	http.HandleFunc("/autostart/run-once", s.runAutoStartOnce)
 	http.HandleFunc("/debug", s.debugHandler)
 	http.HandleFunc("/debug-data", s.debugData)
+	http.HandleFunc("/debug-grid", s.debugGrid)
 	http.HandleFunc("/drops", s.drops)
 	http.HandleFunc("/all-drops", s.allDrops)
 	http.HandleFunc("/export-drops", s.exportDrops)
@@ -1032,6 +1037,7 @@ func (s *HttpServer) Listen(port int) error {
 	http.HandleFunc("/api/companion-join", s.companionJoin)                    // Companion join handler
 	http.HandleFunc("/api/generate-battlenet-token", s.generateBattleNetToken) // Battle.net token generation
 	http.HandleFunc("/reset-muling", s.resetMuling)
+	http.HandleFunc("/metrics", s.handleMetrics) // Prometheus scrape endpoint
 
 	// Updater routes
 	http.HandleFunc("/api/updater/version", s.getVersion)
@@ -1087,6 +1093,10 @@ func (s *HttpServer) Listen(port int) error {
 	http.HandleFunc("/api/armory/all", s.armoryAllAPI)
 
 	s.registerDropRoutes()
+	s.registerHistoryRoutes()
+	s.registerDroplogRoutes()
+	s.registerTerrorZoneRoutes()
+	s.registerConfigRoutes()
 
 	assets, _ := fs.Sub(assetsFS, "assets")
 	http.Handle("/assets/", http.StripPrefix("/assets/", http.FileServer(http.FS(assets))))
@@ -1095,7 +1105,7 @@ func (s *HttpServer) Listen(port int) error {
 	http.Handle("/items/", http.StripPrefix("/items/", http.FileServer(http.Dir("../assets/items"))))
 
 	s.server = &http.Server{
-		Addr: fmt.Sprintf(":%d", port),
+		Addr: addr,
 	}
 
 	if err := s.server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
@@ -1221,6 +1231,42 @@ func (s *HttpServer) debugData(w http.ResponseWriter, r *http.Request) {
 	w.Write(jsonData)
 }
 
+// debugGrid downloads the map state the path finder of a running character works with, to reproduce path issues
+// offline (pathfinding corpus, map renderer)
+func (s *HttpServer) debugGrid(w http.ResponseWriter, r *http.Request) {
+	characterName := r.URL.Query().Get("characterName")
+	if characterName == "" {
+		http.Error(w, "Character name is required", http.StatusBadRequest)
+		return
+	}
+
+	context := s.manager.GetContext(characterName)
+	if context == nil || context.Data == nil {
+		http.Error(w, "character not found or not running", http.StatusNotFound)
+		return
+	}
+	if context.Data.AreaData.Grid == nil {
+		http.Error(w, "no map data loaded for the current area", http.StatusConflict)
+		return
+	}
+
+	dump := context.Data.GridDump()
+	dump.Supervisor = characterName
+	if context.GameReader != nil {
+		dump.MapSeed = context.GameReader.MapSeed()
+	}
+	if context.CharacterCfg != nil {
+		dump.Difficulty = string(context.CharacterCfg.Game.Difficulty)
+	}
+
+	fileName := fmt.Sprintf("%s_%s_%s%s", characterName, strings.ReplaceAll(context.Data.AreaData.Area.Area().Name, " ", ""), dump.CreatedAt.Format("2006-01-02_15-04-05"), mapgrid.DumpExtension)
+	w.Header().Set("Content-Type", "application/gzip")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", fileName))
+	if err := dump.Write(w); err != nil {
+		s.logger.Error("Failed to write grid dump", slog.Any("error", err))
+	}
+}
+
 func (s *HttpServer) debugHandler(w http.ResponseWriter, r *http.Request) {
 	if err := s.templates.ExecuteTemplate(w, "debug.gohtml", nil); err != nil {
 		s.logger.Error("Failed to render debug template", slog.Any("error", err))
@@ -1878,6 +1924,17 @@ func (s *HttpServer) config(w http.ResponseWriter, r *http.Request) {
 		}
 		newConfig.PingMonitor.SustainedDuration = pingDuration
 
+		// Droplog retention
+		retentionDays, err := strconv.Atoi(r.Form.Get("droplog_retention_days"))
+		if err != nil || retentionDays < 0 {
+			retentionDays = 0 // Keep droplogs forever
+		}
+		newConfig.Droplog.RetentionDays = retentionDays
+		newConfig.Droplog.RetentionMode = droplog.RetentionCompress
+		if r.Form.Get("droplog_retention_mode") == droplog.RetentionDelete {
+			newConfig.Droplog.RetentionMode = droplog.RetentionDelete
+		}
+
 		// Auto Start
 		newConfig.AutoStart.Enabled = r.Form.Get("autostart_enabled") == "true"
 		autoStartDelay, err := strconv.Atoi(r.Form.Get("autostart_delay"))
@@ -2603,15 +2660,7 @@ func cloneCharacterCfg(cfg *config.CharacterCfg) (*config.CharacterCfg, error) {
 	if cfg == nil {
 		return nil, errors.New("nil source config")
 	}
-	raw, err := yaml.Marshal(cfg)
-	if err != nil {
-		return nil, err
-	}
-	var out config.CharacterCfg
-	if err := yaml.Unmarshal(raw, &out); err != nil {
-		return nil, err
-	}
-	return &out, nil
+	return cfg.Clone()
 }
 
 func applyRunewordSettings(dst *config.CharacterCfg, src *config.CharacterCfg) {
@@ -2692,6 +2741,9 @@ func (s *HttpServer) characterSettings(w http.ResponseWriter, r *http.Request) {
 		if v := strings.TrimSpace(r.Form.Get("characterName")); v != "" {
 			cfg.CharacterName = v
 		}
+		if r.Form.Has("profile") {
+			cfg.Profile = strings.TrimSpace(r.Form.Get("profile"))
+		}
 		cfg.Game.RunewordMaker.Enabled = r.Form.Has("runewordMakerEnabled")
 		cfg.AutoCreateCharacter = r.Form.Has("autoCreateCharacter")
 		cfg.Username = r.Form.Get("username")
@@ -3150,6 +3202,10 @@ func (s *HttpServer) characterSettings(w http.ResponseWriter, r *http.Request) {
 		cfg.Game.TerrorZone.FocusOnElitePacks = r.Form.Has("gameTerrorZoneFocusOnElitePacks")
 		cfg.Game.TerrorZone.SkipOtherRuns = r.Form.Has("gameTerrorZoneSkipOtherRuns")
 		cfg.Game.TerrorZone.OpenChests = r.Form.Has("gameTerrorZoneOpenChests")
+		cfg.Game.TerrorZone.Scoring.Enabled = r.Form.Has("gameTerrorZoneScoringEnabled")
+		if v, err := strconv.ParseFloat(r.Form.Get("gameTerrorZoneScoringMinScore"), 64); err == nil {
+			cfg.Game.TerrorZone.Scoring.MinScore = &v
+		}
 
 		cfg.Game.TerrorZone.SkipOnImmunities = []stat.Resist{}
 		for _, i := range r.Form["gameTerrorZoneSkipOnImmunities[]"] {
@@ -3446,6 +3502,7 @@ func (s *HttpServer) characterSettings(w http.ResponseWriter, r *http.Request) {
 		AvailableProfiles:     muleProfiles,
 		FarmerProfiles:        farmerProfiles,
 		LevelingSequenceFiles: sequenceFiles,
+		ConfigProfiles:        config.AvailableProfiles(),
 		Supervisors:           supervisors,
 	}); err != nil {
 		s.logger.Error("Failed to render character_settings template", slog.Any("error", err))
@@ -3694,6 +3751,10 @@ func (s *HttpServer) applyRunDetails(values url.Values, cfg *config.CharacterCfg
 			cfg.Game.TerrorZone.FocusOnElitePacks = values.Has("gameTerrorZoneFocusOnElitePacks")
 			cfg.Game.TerrorZone.SkipOtherRuns = values.Has("gameTerrorZoneSkipOtherRuns")
 			cfg.Game.TerrorZone.OpenChests = values.Has("gameTerrorZoneOpenChests")
+			cfg.Game.TerrorZone.Scoring.Enabled = values.Has("gameTerrorZoneScoringEnabled")
+			if v, err := strconv.ParseFloat(values.Get("gameTerrorZoneScoringMinScore"), 64); err == nil {
+				cfg.Game.TerrorZone.Scoring.MinScore = &v
+			}
 
 			if raw, ok := values["gameTerrorZoneSkipOnImmunities[]"]; ok {
 				skips := make([]stat.Resist, 0, len(raw))
@@ -3941,7 +4002,7 @@ func (s *HttpServer) resetDroplogs(w http.ResponseWriter, r *http.Request) {
 			continue
 		}
 		name := strings.ToLower(e.Name())
-		if strings.HasSuffix(name, ".jsonl") || strings.HasSuffix(name, ".html") {
+		if strings.HasSuffix(name, ".jsonl") || strings.HasSuffix(name, ".jsonl.gz") || strings.HasSuffix(name, ".html") {
 			_ = os.Remove(filepath.Join(dir, e.Name()))
 			removed++
 		}
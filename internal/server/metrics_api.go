@@ -0,0 +1,99 @@
+package server
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+
+	"github.com/hectorgimenez/koolo/internal/bot"
+	"github.com/hectorgimenez/koolo/internal/config"
+	"github.com/hectorgimenez/koolo/internal/event"
+	"github.com/hectorgimenez/koolo/internal/remote/metrics"
+)
+
+var supervisorStatuses = []bot.SupervisorStatus{bot.NotStarted, bot.Starting, bot.InGame, bot.Paused, bot.Crashed, bot.WaitingForSchedule}
+
+var schedulerPhases = []bot.SchedulerPhase{bot.PhaseResting, bot.PhasePlaying, bot.PhaseOnBreak}
+
+// HandleMetricsEvent feeds the Prometheus collector from the event bus.
+func (s *HttpServer) HandleMetricsEvent(ctx context.Context, e event.Event) error {
+	return s.metrics.Handle(ctx, e)
+}
+
+// handleMetrics exposes supervisor metrics using the Prometheus text exposition format.
+func (s *HttpServer) handleMetrics(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	if err := s.metrics.WriteText(w, s.scrapeTimeMetrics()...); err != nil {
+		s.logger.Debug("Failed to write metrics", slog.Any("error", err))
+	}
+}
+
+// scrapeTimeMetrics builds the gauges that reflect the current state, they are read from the supervisor
+// snapshots already kept by the manager and scheduler.
+func (s *HttpServer) scrapeTimeMetrics() []metrics.Family {
+	status := metrics.Family{Name: "koolo_supervisor_status", Help: "Current supervisor status, 1 for the active status.", Type: "gauge"}
+	ping := metrics.Family{Name: "koolo_game_ping_milliseconds", Help: "Last in-game ping reading.", Type: "gauge"}
+	phase := metrics.Family{Name: "koolo_scheduler_phase", Help: "Current duration scheduler phase, 1 for the active phase.", Type: "gauge"}
+	dropQueue := metrics.Family{Name: "koolo_drop_queue_depth", Help: "Queued start-drop requests per supervisor.", Type: "gauge"}
+
+	for _, name := range s.manager.AvailableSupervisors() {
+		current := s.manager.Status(name).SupervisorStatus
+		if current == "" {
+			current = bot.NotStarted
+		}
+		for _, st := range supervisorStatuses {
+			status.Samples = append(status.Samples, metrics.Sample{
+				Labels: []metrics.Label{{Name: "supervisor", Value: name}, {Name: "status", Value: string(st)}},
+				Value:  boolToFloat(st == current),
+			})
+		}
+
+		if data := s.manager.GetData(name); data != nil && current == bot.InGame {
+			ping.Samples = append(ping.Samples, metrics.Sample{
+				Labels: []metrics.Label{{Name: "supervisor", Value: name}},
+				Value:  float64(data.Game.Ping),
+			})
+		}
+
+		if state := s.scheduler.GetDurationState(name); state != nil {
+			for _, p := range schedulerPhases {
+				phase.Samples = append(phase.Samples, metrics.Sample{
+					Labels: []metrics.Label{{Name: "supervisor", Value: name}, {Name: "phase", Value: string(p)}},
+					Value:  boolToFloat(p == state.CurrentPhase),
+				})
+			}
+		}
+	}
+
+	if dropService := s.manager.DropService(); dropService != nil {
+		for sup, queue := range dropService.QueuedStartSnapshot() {
+			dropQueue.Samples = append(dropQueue.Samples, metrics.Sample{
+				Labels: []metrics.Label{{Name: "supervisor", Value: sup}},
+				Value:  float64(len(queue)),
+			})
+		}
+	}
+
+	pingMonitor := metrics.Family{
+		Name: "koolo_ping_monitor_threshold_milliseconds",
+		Help: "High ping threshold used by the ping monitor, 0 when disabled.",
+		Type: "gauge",
+	}
+	threshold := 0
+	if config.Koolo.PingMonitor.Enabled {
+		threshold = config.Koolo.PingMonitor.HighPingThreshold
+		if threshold <= 0 {
+			threshold = 500
+		}
+	}
+	pingMonitor.Samples = []metrics.Sample{{Value: float64(threshold)}}
+
+	return []metrics.Family{status, ping, pingMonitor, phase, dropQueue}
+}
+
+func boolToFloat(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
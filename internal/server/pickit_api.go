@@ -11,7 +11,10 @@ import (
 	"strconv"
 	"strings"
 
+	"github.com/hectorgimenez/d2go/pkg/nip"
+	"github.com/hectorgimenez/koolo/internal/config"
 	"github.com/hectorgimenez/koolo/internal/pickit"
+	"github.com/hectorgimenez/koolo/internal/remote/droplog"
 	"github.com/hectorgimenez/koolo/internal/utils"
 )
 
@@ -58,6 +61,7 @@ func (api *PickitAPI) RegisterRoutes(mux *http.ServeMux) {
 	// Utility endpoints
 	mux.HandleFunc("/api/pickit/stats", api.handleGetStats)
 	mux.HandleFunc("/api/pickit/simulate", api.handleSimulate)
+	mux.HandleFunc("/api/pickit/replay", api.handleReplay)
 	mux.HandleFunc("/api/pickit/suggestions", api.handleGetSuggestions)
 	mux.HandleFunc("/api/pickit/conflicts", api.handleDetectConflicts)
 }
@@ -438,70 +442,203 @@ func (api *PickitAPI) handleGetStats(w http.ResponseWriter, r *http.Request) {
 	api.sendJSON(w, response)
 }
 
-// handleSimulate simulates a rule against test data
+// handleSimulate evaluates a rule with the NIP engine against item fixtures. When no fixtures are given the rule is
+// evaluated against the whole item database and only the matches are reported.
 func (api *PickitAPI) handleSimulate(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
 
-	var rule pickit.PickitRule
-	if err := json.NewDecoder(r.Body).Decode(&rule); err != nil {
+	var req pickit.SimulationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		http.Error(w, "Invalid request body", http.StatusBadRequest)
 		return
 	}
 
-	// Generate simulation based on rule
-	result := pickit.SimulationResult{
-		RuleID:      rule.ID,
-		MatchCount:  0,
-		Matches:     []pickit.ItemMatch{},
-		Performance: "Good",
-		Suggestions: []string{},
+	line := strings.TrimSpace(req.NIPLine)
+	if line == "" {
+		generated, err := api.builder.GenerateNIP(&req.PickitRule)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Failed to generate NIP: %v", err), http.StatusBadRequest)
+			return
+		}
+		line = generated
+	}
+
+	fixtures := append([]pickit.ItemFixture{}, req.Fixtures...)
+	for _, id := range req.DatabaseItems {
+		fixtures = append(fixtures, pickit.ItemFixture{DatabaseID: id})
+	}
+	explicit := len(fixtures) > 0
+	if !explicit {
+		fixtures = pickit.DatabaseFixtures()
 	}
 
-	// Simulate by finding matching items in database
-	_, err := api.builder.GenerateNIP(&rule)
+	sim, err := pickit.SimulateNIP(line, fixtures)
 	if err != nil {
-		http.Error(w, fmt.Sprintf("Failed to generate NIP: %v", err), http.StatusBadRequest)
+		http.Error(w, fmt.Sprintf("Failed to compile NIP: %v", err), http.StatusBadRequest)
 		return
 	}
 
-	// Find items that match the rule criteria
-	for _, item := range pickit.ItemDatabase {
-		// Check name match
-		nameMatch := false
-		for _, cond := range rule.LeftConditions {
-			if cond.Property == "name" && cond.Operator == "==" {
-				if item.ID == cond.Value || item.InternalName == cond.Value {
-					nameMatch = true
-					break
-				}
-			}
+	result := pickit.SimulationResult{
+		RuleID:      req.ID,
+		NIPLine:     sim.NIPLine,
+		Tier:        sim.Tier,
+		MercTier:    sim.MercTier,
+		Matches:     []pickit.ItemMatch{},
+		Misses:      []pickit.ItemMatch{},
+		Evaluations: []pickit.RuleEvaluation{},
+		Suggestions: []string{},
+	}
+
+	invalid := 0
+	for i, ev := range sim.Evaluations {
+		matched := ev.Result != pickit.EvaluationNoMatch
+		if !explicit && (!matched || ev.Error != "") {
+			continue
+		}
+		if ev.Error != "" {
+			invalid++
 		}
 
-		if nameMatch {
+		match := pickit.ItemMatch{
+			ItemName: ev.Fixture,
+			Matched:  matched,
+			Reason:   simulationReason(ev),
+			Stats:    make(map[string]interface{}),
+		}
+		if def, found := pickit.GetItemByIDV2(fixtures[i].DatabaseID); found {
+			match.ImageIcon = def.ImageIcon
+		}
+		for alias, value := range fixtures[i].Stats {
+			match.Stats[alias] = value
+		}
+
+		if matched {
 			result.MatchCount++
-			result.Matches = append(result.Matches, pickit.ItemMatch{
-				ItemName:  item.Name,
-				ImageIcon: item.ImageIcon,
-				Matched:   true,
-				Reason:    "Name matches rule criteria",
-				Stats:     make(map[string]interface{}),
-			})
+			result.Matches = append(result.Matches, match)
+		} else {
+			result.Misses = append(result.Misses, match)
 		}
+		result.Evaluations = append(result.Evaluations, ev)
 	}
 
-	// Add suggestions based on match count
-	if result.MatchCount == 0 {
+	switch {
+	case invalid > 0:
+		result.Performance = "Invalid fixtures"
+		result.Suggestions = append(result.Suggestions, fmt.Sprintf("%d fixture(s) could not be built, check base names, qualities and stat aliases.", invalid))
+	case result.MatchCount == 0:
+		result.Performance = "No matches"
 		result.Suggestions = append(result.Suggestions, "No items matched. Check your item name and conditions.")
-	} else if result.MatchCount > 10 {
+	case !explicit && result.MatchCount > 10:
+		result.Performance = "Broad"
 		result.Suggestions = append(result.Suggestions, "Rule matches many items. Consider adding quality or stat filters.")
+	default:
+		result.Performance = "Good"
 	}
 
 	api.sendJSON(w, result)
 }
 
+// simulationReason describes why a fixture matched or not, naming the failing clause when there is one
+func simulationReason(ev pickit.RuleEvaluation) string {
+	if ev.Error != "" {
+		return ev.Error
+	}
+
+	switch ev.Result {
+	case pickit.EvaluationMatch:
+		return "Matches every condition"
+	case pickit.EvaluationPartial:
+		return "Base matches, stats will be checked once identified"
+	}
+
+	for _, stage := range ev.Stages {
+		if !stage.Passed {
+			return fmt.Sprintf("Failed %s condition: %s", stage.Stage, stage.FailedClause)
+		}
+	}
+
+	return "Does not match"
+}
+
+// handleReplay re-evaluates stashed drops from the droplogs against a candidate pickit file or directory, reporting
+// the items that would be newly rejected or newly kept. Candidate and baseline are read from the pickit folders only,
+// see replayRulesPath. Droplog filters (supervisor, from, to...) select the records.
+func (api *PickitAPI) handleReplay(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		api.sendError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	candidatePath := r.URL.Query().Get("candidate")
+	if candidatePath == "" {
+		api.sendError(w, "'candidate' parameter required", http.StatusBadRequest)
+		return
+	}
+
+	dq, err := parseDroplogQuery(r)
+	if err != nil {
+		api.sendError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if candidatePath, err = replayRulesPath(candidatePath); err != nil {
+		api.sendError(w, fmt.Sprintf("Invalid candidate: %v", err), http.StatusBadRequest)
+		return
+	}
+	candidate, err := config.LoadPickitRules(candidatePath)
+	if err != nil {
+		api.sendError(w, fmt.Sprintf("Error loading candidate rules: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	var baseline nip.Rules
+	if baselinePath := r.URL.Query().Get("baseline"); baselinePath != "" {
+		if baselinePath, err = replayRulesPath(baselinePath); err != nil {
+			api.sendError(w, fmt.Sprintf("Invalid baseline: %v", err), http.StatusBadRequest)
+			return
+		}
+		if baseline, err = config.LoadPickitRules(baselinePath); err != nil {
+			api.sendError(w, fmt.Sprintf("Error loading baseline rules: %v", err), http.StatusBadRequest)
+			return
+		}
+	}
+
+	records, err := droplog.Find(droplogDir(), dq)
+	if err != nil {
+		api.sendError(w, fmt.Sprintf("Error reading droplogs: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	api.sendJSON(w, droplog.Replay(records, baseline, candidate))
+}
+
+// replayRulesPath resolves a replay candidate or baseline, given as a supervisor name for its whole pickit folder or as
+// "<supervisor>/<file>.nip" for a single file of it. The pickit folder is the centralized one when the supervisor uses
+// it, the same one config.Load reads.
+func replayRulesPath(value string) (string, error) {
+	supervisor, file, _ := strings.Cut(filepath.ToSlash(value), "/")
+	cfg, found := config.GetCharacter(supervisor)
+	if supervisor == "" || !found {
+		return "", fmt.Errorf("unknown supervisor %q", supervisor)
+	}
+
+	dir := filepath.Join("config", supervisor, "pickit")
+	if cfg.UseCentralizedPickit && config.Koolo.CentralizedPickitPath != "" {
+		dir = config.Koolo.CentralizedPickitPath
+	}
+	if file == "" {
+		return dir, nil
+	}
+	if file != filepath.Base(file) || !strings.EqualFold(filepath.Ext(file), ".nip") {
+		return "", fmt.Errorf("%q is not a .nip file of the %s pickit folder", file, supervisor)
+	}
+
+	return filepath.Join(dir, file), nil
+}
+
 // handleGetSuggestions returns auto-suggestions for a rule
 func (api *PickitAPI) handleGetSuggestions(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
@@ -519,8 +656,28 @@ func (api *PickitAPI) handleGetSuggestions(w http.ResponseWriter, r *http.Reques
 	api.sendJSON(w, suggestions)
 }
 
-// handleDetectConflicts detects conflicts between rules
+// handleDetectConflicts detects conflicts between the posted editor rules, or with GET between the rules of the .nip
+// file or pickit directory given by 'path' (optionally narrowed to 'file')
 func (api *PickitAPI) handleDetectConflicts(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodGet {
+		pickitPath := r.URL.Query().Get("path")
+		if pickitPath == "" {
+			api.sendError(w, "'path' parameter required", http.StatusBadRequest)
+			return
+		}
+		if fileName := r.URL.Query().Get("file"); fileName != "" {
+			pickitPath = filepath.Join(pickitPath, fileName)
+		}
+
+		analysis, err := pickit.AnalyzeNIPPath(pickitPath)
+		if err != nil {
+			api.sendError(w, fmt.Sprintf("Error analyzing pickit rules: %v", err), http.StatusBadRequest)
+			return
+		}
+		api.sendJSON(w, analysis)
+		return
+	}
+
 	if r.Method != http.MethodPost {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
@@ -106,6 +106,7 @@ type CharacterSettings struct {
 	AvailableProfiles       []string
 	FarmerProfiles          []string
 	LevelingSequenceFiles   []string
+	ConfigProfiles          []string // Base profiles of config/profiles
 	Supervisors             []string
 }
 
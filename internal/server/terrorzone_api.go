@@ -0,0 +1,266 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/hectorgimenez/d2go/pkg/data/area"
+	"github.com/hectorgimenez/koolo/internal/config"
+	"github.com/hectorgimenez/koolo/internal/run"
+	terrorzones "github.com/hectorgimenez/koolo/internal/terrorzone"
+	"gopkg.in/yaml.v3"
+)
+
+// TerrorZoneDataResponse describes the terror zone data file of the global config or of a character
+type TerrorZoneDataResponse struct {
+	Character string `json:"character"`
+	Path      string `json:"path"`      // Data file in use, empty when only the defaults apply
+	Content   string `json:"content"`   // Raw content of the data file
+	Effective string `json:"effective"` // Resolved zones and routes as YAML, built-in data included
+}
+
+// TerrorZoneDataRequest saves or validates a data file, an empty content removes the file
+type TerrorZoneDataRequest struct {
+	Character string `json:"character"`
+	Format    string `json:"format"` // yaml or json, used when the file doesn't exist yet
+	Content   string `json:"content"`
+}
+
+func (s *HttpServer) registerTerrorZoneRoutes() {
+	http.HandleFunc("/terrorzones", s.terrorZonesPage)
+	http.HandleFunc("/api/terrorzones", s.handleTerrorZoneData)
+	http.HandleFunc("/api/terrorzones/validate", s.handleTerrorZoneValidate)
+	http.HandleFunc("/api/terrorzones/reload", s.handleTerrorZoneReload)
+	http.HandleFunc("/api/terrorzones/scores", s.handleTerrorZoneScores)
+}
+
+func (s *HttpServer) terrorZonesPage(w http.ResponseWriter, r *http.Request) {
+	if err := s.templates.ExecuteTemplate(w, "terrorzones.gohtml", map[string]interface{}{
+		"Supervisors": s.manager.AvailableSupervisors(),
+	}); err != nil {
+		s.logger.Error("Failed to render terror zones template", slog.Any("error", err))
+	}
+}
+
+func (s *HttpServer) handleTerrorZoneData(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		character := strings.TrimSpace(r.URL.Query().Get("character"))
+		if !terrorZoneCharacterExists(character) {
+			http.Error(w, "unknown character", http.StatusNotFound)
+			return
+		}
+
+		resp, err := terrorZoneData(character)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(resp)
+	case http.MethodPost:
+		req, df, ok := decodeTerrorZoneRequest(w, r)
+		if !ok {
+			return
+		}
+		if err := validateTerrorZoneData(req.Character, df); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		path := config.TerrorZoneDataPath(req.Character)
+		if strings.TrimSpace(req.Content) == "" {
+			if path != "" {
+				if err := os.Remove(path); err != nil {
+					http.Error(w, err.Error(), http.StatusInternalServerError)
+					return
+				}
+			}
+		} else {
+			if path == "" {
+				path = newTerrorZoneDataPath(req.Character, req.Format)
+			}
+			if err := os.WriteFile(path, []byte(req.Content), 0644); err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+		}
+
+		if err := config.ReloadTerrorZoneData(); err != nil {
+			http.Error(w, fmt.Sprintf("saved, but reloading failed: %s", err), http.StatusBadRequest)
+			return
+		}
+		s.logger.Info("Terror zone data saved", slog.String("character", req.Character), slog.String("path", path))
+
+		resp, err := terrorZoneData(req.Character)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(resp)
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func (s *HttpServer) handleTerrorZoneValidate(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	req, df, ok := decodeTerrorZoneRequest(w, r)
+	if !ok {
+		return
+	}
+	if err := validateTerrorZoneData(req.Character, df); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+func (s *HttpServer) handleTerrorZoneReload(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if err := config.ReloadTerrorZoneData(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	s.logger.Info("Terror zone data reloaded")
+	w.WriteHeader(http.StatusOK)
+}
+
+// TerrorZoneScoresResponse holds the scores of every configured TZ of a character and the last decision taken by
+// each supervisor
+type TerrorZoneScoresResponse struct {
+	Character string                       `json:"character"`
+	Enabled   bool                         `json:"enabled"`
+	Scores    []terrorzones.ScoreBreakdown `json:"scores"`
+	Decisions []terrorzones.Decision       `json:"decisions"`
+}
+
+func (s *HttpServer) handleTerrorZoneScores(w http.ResponseWriter, r *http.Request) {
+	resp := TerrorZoneScoresResponse{
+		Character: strings.TrimSpace(r.URL.Query().Get("character")),
+		Scores:    []terrorzones.ScoreBreakdown{},
+		Decisions: terrorzones.Decisions(),
+	}
+
+	if resp.Character != "" {
+		cfg, found := config.GetCharacter(resp.Character)
+		if !found {
+			http.Error(w, "unknown character", http.StatusNotFound)
+			return
+		}
+		resp.Enabled = cfg.Game.TerrorZone.Scoring.Enabled
+
+		zones := terrorzones.ForCharacter(resp.Character).Zones
+		var ids []area.ID
+		for _, id := range cfg.Game.TerrorZone.Areas {
+			if _, found := zones[id]; found {
+				ids = append(ids, id)
+			}
+		}
+		scores, err := run.ScoreTerrorZones(resp.Character, cfg, ids)
+		if err != nil {
+			s.logger.Warn("Failed to read run history for terror zone scoring", slog.Any("error", err))
+		}
+		sort.SliceStable(scores, func(i, j int) bool { return scores[i].Total > scores[j].Total })
+		resp.Scores = scores
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+func decodeTerrorZoneRequest(w http.ResponseWriter, r *http.Request) (TerrorZoneDataRequest, terrorzones.DataFile, bool) {
+	var req TerrorZoneDataRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid JSON", http.StatusBadRequest)
+		return req, terrorzones.DataFile{}, false
+	}
+	req.Character = strings.TrimSpace(req.Character)
+	if !terrorZoneCharacterExists(req.Character) {
+		http.Error(w, "unknown character", http.StatusNotFound)
+		return req, terrorzones.DataFile{}, false
+	}
+
+	ext := ".yaml"
+	if path := config.TerrorZoneDataPath(req.Character); path != "" {
+		ext = filepath.Ext(path)
+	} else if strings.EqualFold(req.Format, "json") {
+		ext = ".json"
+	}
+
+	df, err := terrorzones.ParseData([]byte(req.Content), ext)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return req, df, false
+	}
+
+	return req, df, true
+}
+
+// validateTerrorZoneData checks a global file against the built-in data and a character file against the global set
+func validateTerrorZoneData(character string, df terrorzones.DataFile) error {
+	base := terrorzones.Defaults()
+	if character != "" {
+		base = terrorzones.Active()
+	}
+	_, err := df.Apply(base)
+
+	return err
+}
+
+func terrorZoneData(character string) (TerrorZoneDataResponse, error) {
+	resp := TerrorZoneDataResponse{Character: character, Path: config.TerrorZoneDataPath(character)}
+	if resp.Path != "" {
+		content, err := os.ReadFile(resp.Path)
+		if err != nil {
+			return resp, err
+		}
+		resp.Content = string(content)
+	}
+
+	effective, err := yaml.Marshal(terrorzones.ForCharacter(character).ToDataFile())
+	if err != nil {
+		return resp, err
+	}
+	resp.Effective = string(effective)
+
+	return resp, nil
+}
+
+func newTerrorZoneDataPath(character, format string) string {
+	name := terrorzones.DataFileNames[0]
+	if strings.EqualFold(format, "json") {
+		name = terrorzones.DataFileNames[len(terrorzones.DataFileNames)-1]
+	}
+
+	return filepath.Join("config", character, name)
+}
+
+func terrorZoneCharacterExists(character string) bool {
+	if character == "" {
+		return true
+	}
+	if strings.ContainsAny(character, `/\`) || character == ".." {
+		return false
+	}
+	_, ok := config.GetCharacter(character)
+
+	return ok
+}
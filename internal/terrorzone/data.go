@@ -0,0 +1,435 @@
+package terrorzones
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/hectorgimenez/d2go/pkg/data/area"
+	"gopkg.in/yaml.v3"
+)
+
+// File names looked up in the config folder and in every character folder, in this order
+var DataFileNames = []string{"terrorzones.yaml", "terrorzones.yml", "terrorzones.json"}
+
+var validImmunities = []string{"f", "c", "l", "p", "ph", "m"}
+
+// Set is a resolved collection of zone metadata and routes
+type Set struct {
+	Zones  map[area.ID]ZoneInfo
+	Routes map[area.ID][]Route
+}
+
+// AreaRef references an area by numeric ID or by name (case-insensitive)
+type AreaRef string
+
+// UnmarshalJSON accepts both numbers and strings
+func (r *AreaRef) UnmarshalJSON(b []byte) error {
+	var n json.Number
+	if err := json.Unmarshal(b, &n); err == nil {
+		*r = AreaRef(n.String())
+		return nil
+	}
+
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return fmt.Errorf("area must be a number or a name: %s", string(b))
+	}
+	*r = AreaRef(s)
+
+	return nil
+}
+
+// DataFile is the on-disk format, every entry overrides the built-in data for the same area
+type DataFile struct {
+	Zones  map[AreaRef]ZoneEntry   `yaml:"zones,omitempty" json:"zones,omitempty"`
+	Routes map[AreaRef][]RouteFile `yaml:"routes,omitempty" json:"routes,omitempty"`
+}
+
+// ZoneEntry overrides ZoneInfo fields, fields left empty keep the current value
+type ZoneEntry struct {
+	Act        int       `yaml:"act,omitempty" json:"act,omitempty"`
+	ExpTier    Tier      `yaml:"exp_tier,omitempty" json:"exp_tier,omitempty"`
+	LootTier   Tier      `yaml:"loot_tier,omitempty" json:"loot_tier,omitempty"`
+	BossPack   string    `yaml:"boss_pack,omitempty" json:"boss_pack,omitempty"`
+	Immunities *[]string `yaml:"immunities,omitempty" json:"immunities,omitempty"`
+	Group      string    `yaml:"group,omitempty" json:"group,omitempty"`
+}
+
+// RouteFile is an ordered list of steps, an empty route list for an area removes its routes
+type RouteFile []StepEntry
+
+// StepEntry sets exactly one of Move or Clear
+type StepEntry struct {
+	Move  AreaRef `yaml:"move,omitempty" json:"move,omitempty"`
+	Clear AreaRef `yaml:"clear,omitempty" json:"clear,omitempty"`
+}
+
+var (
+	dataMux    sync.RWMutex
+	active     = Set{Zones: zones, Routes: Routes}
+	characters = map[string]Set{}
+)
+
+// Defaults returns the built-in zone metadata and routes
+func Defaults() Set {
+	return Set{Zones: zones, Routes: Routes}
+}
+
+// Active returns the global set, built-in data with the global data file applied
+func Active() Set {
+	dataMux.RLock()
+	defer dataMux.RUnlock()
+
+	return active
+}
+
+// ForCharacter returns the set used by a character, falling back to the global set when it has no overrides
+func ForCharacter(name string) Set {
+	dataMux.RLock()
+	defer dataMux.RUnlock()
+
+	if s, ok := characters[name]; ok {
+		return s
+	}
+
+	return active
+}
+
+// RoutesFor returns all routes of the set for a given primary TZ area
+func (s Set) RoutesFor(first area.ID) []Route {
+	return s.Routes[first]
+}
+
+// Info returns the metadata of the set for an area, zero values if missing
+func (s Set) Info(id area.ID) ZoneInfo {
+	return s.Zones[id]
+}
+
+// FindDataFile returns the first terror zone data file found in dir, empty if there is none
+func FindDataFile(dir string) string {
+	for _, name := range DataFileNames {
+		path := filepath.Join(dir, name)
+		if _, err := os.Stat(path); err == nil {
+			return path
+		}
+	}
+
+	return ""
+}
+
+// Load resolves the global data file and the per-character ones (character name -> path, empty path means no
+// overrides). Nothing is replaced unless every file parses and validates, all errors are reported together.
+func Load(globalPath string, characterPaths map[string]string) error {
+	global, chars, err := build(globalPath, characterPaths)
+	if err != nil {
+		return err
+	}
+
+	activate(global, chars)
+
+	return nil
+}
+
+// Check parses and validates the data files the same way Load does, without replacing the active data
+func Check(globalPath string, characterPaths map[string]string) error {
+	_, _, err := build(globalPath, characterPaths)
+
+	return err
+}
+
+// UseDefaults replaces the active data with the built-in one, without any data file applied
+func UseDefaults() {
+	activate(Defaults(), make(map[string]Set))
+}
+
+func build(globalPath string, characterPaths map[string]string) (Set, map[string]Set, error) {
+	global := Defaults()
+	var errs []error
+	if globalPath != "" {
+		s, err := applyFile(global, globalPath)
+		if err != nil {
+			errs = append(errs, err)
+		} else {
+			global = s
+		}
+	}
+
+	chars := make(map[string]Set)
+	for name, path := range characterPaths {
+		if path == "" {
+			continue
+		}
+		s, err := applyFile(global, path)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", name, err))
+			continue
+		}
+		chars[name] = s
+	}
+
+	return global, chars, errors.Join(errs...)
+}
+
+func activate(global Set, chars map[string]Set) {
+	dataMux.Lock()
+	active = global
+	characters = chars
+	groupsBuilt = false
+	groupsCache = nil
+	dataMux.Unlock()
+}
+
+func applyFile(base Set, path string) (Set, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return base, err
+	}
+	df, err := ParseData(content, filepath.Ext(path))
+	if err != nil {
+		return base, fmt.Errorf("%s: %w", path, err)
+	}
+	s, err := df.Apply(base)
+	if err != nil {
+		return base, fmt.Errorf("%s: %w", path, err)
+	}
+
+	return s, nil
+}
+
+// ParseData decodes a data file, ext selects JSON (".json") or YAML (anything else)
+func ParseData(content []byte, ext string) (DataFile, error) {
+	var df DataFile
+	if strings.EqualFold(ext, ".json") {
+		dec := json.NewDecoder(bytes.NewReader(content))
+		dec.DisallowUnknownFields()
+		if err := dec.Decode(&df); err != nil {
+			return df, fmt.Errorf("invalid JSON: %w", err)
+		}
+		return df, nil
+	}
+
+	dec := yaml.NewDecoder(bytes.NewReader(content))
+	dec.KnownFields(true)
+	if err := dec.Decode(&df); err != nil && !errors.Is(err, io.EOF) {
+		return df, fmt.Errorf("invalid YAML: %w", err)
+	}
+
+	return df, nil
+}
+
+// Validate checks every area reference, tier, immunity and route step of the file
+func (df DataFile) Validate() error {
+	_, err := df.Apply(Defaults())
+	return err
+}
+
+// Apply returns a copy of base with the file entries applied, or every validation error found
+func (df DataFile) Apply(base Set) (Set, error) {
+	s := Set{
+		Zones:  make(map[area.ID]ZoneInfo, len(base.Zones)+len(df.Zones)),
+		Routes: make(map[area.ID][]Route, len(base.Routes)+len(df.Routes)),
+	}
+	for id, z := range base.Zones {
+		s.Zones[id] = z
+	}
+	for id, r := range base.Routes {
+		s.Routes[id] = r
+	}
+
+	var errs []error
+	for _, ref := range sortedRefs(df.Zones) {
+		entry := df.Zones[ref]
+		id, err := ref.Resolve()
+		if err != nil {
+			errs = append(errs, fmt.Errorf("zones: %w", err))
+			continue
+		}
+		if !id.CanBeTerrorized() {
+			errs = append(errs, fmt.Errorf("zones: %s can't be terrorized", id.Area().Name))
+			continue
+		}
+
+		z := s.Zones[id]
+		if entry.Act != 0 {
+			z.Act = entry.Act
+		}
+		if entry.ExpTier != "" {
+			z.ExpTier = Tier(strings.ToUpper(string(entry.ExpTier)))
+		}
+		if entry.LootTier != "" {
+			z.LootTier = Tier(strings.ToUpper(string(entry.LootTier)))
+		}
+		if entry.BossPack != "" {
+			z.BossPack = entry.BossPack
+		}
+		if entry.Immunities != nil {
+			z.Immunities = *entry.Immunities
+		}
+		if entry.Group != "" {
+			z.Group = entry.Group
+		}
+
+		name := id.Area().Name
+		if z.Act < 1 || z.Act > 5 {
+			errs = append(errs, fmt.Errorf("zones: %s: act must be between 1 and 5", name))
+		}
+		for _, t := range []Tier{z.ExpTier, z.LootTier} {
+			if !validTier(t) {
+				errs = append(errs, fmt.Errorf("zones: %s: unknown tier %q", name, t))
+			}
+		}
+		for _, imm := range z.Immunities {
+			if !slices.Contains(validImmunities, imm) {
+				errs = append(errs, fmt.Errorf("zones: %s: unknown immunity %q, expected one of %s", name, imm, strings.Join(validImmunities, ",")))
+			}
+		}
+		s.Zones[id] = z
+	}
+
+	for _, ref := range sortedRefs(df.Routes) {
+		id, err := ref.Resolve()
+		if err != nil {
+			errs = append(errs, fmt.Errorf("routes: %w", err))
+			continue
+		}
+		if len(df.Routes[ref]) == 0 {
+			delete(s.Routes, id)
+			continue
+		}
+
+		routes := make([]Route, 0, len(df.Routes[ref]))
+		for i, rf := range df.Routes[ref] {
+			route, err := rf.route()
+			if err != nil {
+				errs = append(errs, fmt.Errorf("routes: %s #%d: %w", id.Area().Name, i+1, err))
+				continue
+			}
+			routes = append(routes, route)
+		}
+		s.Routes[id] = routes
+	}
+
+	if len(errs) > 0 {
+		return base, errors.Join(errs...)
+	}
+
+	return s, nil
+}
+
+func (rf RouteFile) route() (Route, error) {
+	if len(rf) == 0 {
+		return nil, errors.New("route has no steps")
+	}
+
+	route := make(Route, 0, len(rf))
+	for i, st := range rf {
+		if (st.Move == "") == (st.Clear == "") {
+			return nil, fmt.Errorf("step %d must set exactly one of move or clear", i+1)
+		}
+		ref, kind := st.Move, StepMove
+		if st.Clear != "" {
+			ref, kind = st.Clear, StepClear
+		}
+		id, err := ref.Resolve()
+		if err != nil {
+			return nil, fmt.Errorf("step %d: %w", i+1, err)
+		}
+		route = append(route, Step{Kind: kind, Area: id})
+	}
+
+	return route, nil
+}
+
+// Resolve returns the area ID for a numeric ID or a unique area name
+func (r AreaRef) Resolve() (area.ID, error) {
+	raw := strings.TrimSpace(string(r))
+	if n, err := strconv.Atoi(raw); err == nil {
+		id := area.ID(n)
+		if _, ok := area.Areas[id]; !ok {
+			return 0, fmt.Errorf("unknown area ID %d", n)
+		}
+		return id, nil
+	}
+
+	var found []area.ID
+	for id, a := range area.Areas {
+		if strings.EqualFold(a.Name, raw) {
+			found = append(found, id)
+		}
+	}
+	switch len(found) {
+	case 0:
+		return 0, fmt.Errorf("unknown area %q", raw)
+	case 1:
+		return found[0], nil
+	default:
+		slices.Sort(found)
+		return 0, fmt.Errorf("area name %q is ambiguous, use one of the IDs %v", raw, found)
+	}
+}
+
+// ToDataFile encodes a set as a data file, areas are written by name unless the name is ambiguous
+func (s Set) ToDataFile() DataFile {
+	df := DataFile{Zones: make(map[AreaRef]ZoneEntry, len(s.Zones)), Routes: make(map[AreaRef][]RouteFile, len(s.Routes))}
+	for id, z := range s.Zones {
+		immunities := slices.Clone(z.Immunities)
+		if immunities == nil {
+			immunities = []string{}
+		}
+		df.Zones[refOf(id)] = ZoneEntry{Act: z.Act, ExpTier: z.ExpTier, LootTier: z.LootTier, BossPack: z.BossPack, Immunities: &immunities, Group: z.Group}
+	}
+	for id, routes := range s.Routes {
+		files := make([]RouteFile, 0, len(routes))
+		for _, route := range routes {
+			rf := make(RouteFile, 0, len(route))
+			for _, step := range route {
+				if step.Kind == StepClear {
+					rf = append(rf, StepEntry{Clear: refOf(step.Area)})
+				} else {
+					rf = append(rf, StepEntry{Move: refOf(step.Area)})
+				}
+			}
+			files = append(files, rf)
+		}
+		df.Routes[refOf(id)] = files
+	}
+
+	return df
+}
+
+func refOf(id area.ID) AreaRef {
+	ref := AreaRef(id.Area().Name)
+	if resolved, err := ref.Resolve(); err == nil && resolved == id {
+		return ref
+	}
+
+	return AreaRef(strconv.Itoa(int(id)))
+}
+
+func validTier(t Tier) bool {
+	switch t {
+	case TierS, TierA, TierB, TierC, TierD, TierF:
+		return true
+	}
+
+	return false
+}
+
+func sortedRefs[V any](m map[AreaRef]V) []AreaRef {
+	refs := make([]AreaRef, 0, len(m))
+	for ref := range m {
+		refs = append(refs, ref)
+	}
+	slices.Sort(refs)
+
+	return refs
+}
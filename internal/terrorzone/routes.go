@@ -28,7 +28,7 @@ func Move(a area.ID) Step {
 	return Step{Kind: StepMove, Area: a}
 }
 
-// Routes is the central definition of multi-area TZ runs.
+// Routes is the built-in definition of multi-area TZ runs, used as defaults for the terrorzones data file.
 //
 // Key   = "primary" terrorized area (ctx.Data.TerrorZones[0])
 // Value = one or more routes (alternatives) for that TZ event.
@@ -75,10 +75,7 @@ var Routes = map[area.ID][]Route{
 	// Nihlathak, Baal-> terror_zone.go -> NewPit().Run() ect...
 }
 
-// RoutesFor returns all routes for a given primary TZ area.
+// RoutesFor returns all routes for a given primary TZ area, from the global set.
 func RoutesFor(first area.ID) []Route {
-	if rs, ok := Routes[first]; ok {
-		return rs
-	}
-	return nil
+	return Active().RoutesFor(first)
 }
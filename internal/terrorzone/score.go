@@ -0,0 +1,157 @@
+package terrorzones
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hectorgimenez/d2go/pkg/data/area"
+)
+
+// ScoreWeights controls how much every component adds to (or removes from) a zone score
+type ScoreWeights struct {
+	Exp             float64       // Multiplies the EXP tier value (S=1 ... F=0)
+	Loot            float64       // Multiplies the loot tier value (S=1 ... F=0)
+	Immunities      float64       // Penalty when the zone has immunities to the character damage types
+	ClearTime       float64       // Bonus/penalty of the historical clear time against TargetClearTime
+	Deaths          float64       // Penalty of the historical death rate, a 10% death rate applies it fully
+	TargetClearTime time.Duration // Clear time considered neutral
+	MinSamples      int           // Finished runs needed before history is taken into account
+	MinScore        float64       // Zones scoring below are not worth running
+}
+
+// DefaultScoreWeights values give S/S zones 2 points and C/C zones 0.8, with a minimum of 1 to run a zone
+func DefaultScoreWeights() ScoreWeights {
+	return ScoreWeights{
+		Exp:             1,
+		Loot:            1,
+		Immunities:      1,
+		ClearTime:       0.5,
+		Deaths:          1,
+		TargetClearTime: 6 * time.Minute,
+		MinSamples:      3,
+		MinScore:        1,
+	}
+}
+
+// ZoneHistory summarizes the finished runs of a character in a terror zone
+type ZoneHistory struct {
+	Runs            int           `json:"runs"`
+	MedianClearTime time.Duration `json:"medianClearTime"`
+	DeathRate       float64       `json:"deathRate"`
+}
+
+// ScoreBreakdown holds every component of a zone score, Total is their sum
+type ScoreBreakdown struct {
+	Zone       area.ID     `json:"zone"`
+	Name       string      `json:"name"`
+	ExpTier    Tier        `json:"expTier"`
+	LootTier   Tier        `json:"lootTier"`
+	Immune     []string    `json:"immune"` // Character damage types the zone is immune to
+	History    ZoneHistory `json:"history"`
+	Exp        float64     `json:"exp"`
+	Loot       float64     `json:"loot"`
+	Immunities float64     `json:"immunities"`
+	ClearTime  float64     `json:"clearTime"`
+	Deaths     float64     `json:"deaths"`
+	Total      float64     `json:"total"`
+	MinScore   float64     `json:"minScore"`
+	Worth      bool        `json:"worth"`
+}
+
+func (b ScoreBreakdown) String() string {
+	return fmt.Sprintf("%s: total %.2f (min %.2f) = exp %.2f + loot %.2f + immunities %.2f + clear time %.2f + deaths %.2f",
+		b.Name, b.Total, b.MinScore, b.Exp, b.Loot, b.Immunities, b.ClearTime, b.Deaths)
+}
+
+// TierValue maps a tier to [0, 1], S being 1 and F or unknown tiers 0
+func TierValue(t Tier) float64 {
+	switch t {
+	case TierS:
+		return 1
+	case TierA:
+		return 0.8
+	case TierB:
+		return 0.6
+	case TierC:
+		return 0.4
+	case TierD:
+		return 0.2
+	}
+
+	return 0
+}
+
+// ScoreZone scores a zone for a character dealing the given damage types (immunity codes, see ZoneInfo.Immunities)
+func ScoreZone(id area.ID, info ZoneInfo, damageTypes []string, hist ZoneHistory, w ScoreWeights) ScoreBreakdown {
+	b := ScoreBreakdown{
+		Zone:     id,
+		Name:     id.Area().Name,
+		ExpTier:  info.ExpTier,
+		LootTier: info.LootTier,
+		Immune:   []string{},
+		History:  hist,
+		MinScore: w.MinScore,
+	}
+	b.Exp = w.Exp * TierValue(info.ExpTier)
+	b.Loot = w.Loot * TierValue(info.LootTier)
+
+	for _, dmg := range damageTypes {
+		if slices.Contains(info.Immunities, dmg) {
+			b.Immune = append(b.Immune, dmg)
+		}
+	}
+	if len(damageTypes) > 0 {
+		b.Immunities = -w.Immunities * float64(len(b.Immune)) / float64(len(damageTypes))
+	}
+
+	if hist.Runs >= w.MinSamples && hist.Runs > 0 {
+		if w.TargetClearTime > 0 && hist.MedianClearTime > 0 {
+			ratio := float64(w.TargetClearTime-hist.MedianClearTime) / float64(w.TargetClearTime)
+			b.ClearTime = w.ClearTime * math.Max(-1, math.Min(1, ratio))
+		}
+		b.Deaths = -w.Deaths * math.Min(1, hist.DeathRate*10)
+	}
+
+	b.Total = b.Exp + b.Loot + b.Immunities + b.ClearTime + b.Deaths
+	b.Worth = b.Total >= w.MinScore
+
+	return b
+}
+
+// Decision is the outcome of the last scoring done for a supervisor
+type Decision struct {
+	Supervisor string         `json:"supervisor"`
+	Time       time.Time      `json:"time"`
+	Zones      []string       `json:"zones"`
+	Score      ScoreBreakdown `json:"score"`
+}
+
+var (
+	decisionsMux sync.RWMutex
+	decisions    = map[string]Decision{}
+)
+
+// RecordDecision stores the last decision of a supervisor so it can be shown in the dashboard
+func RecordDecision(d Decision) {
+	decisionsMux.Lock()
+	decisions[d.Supervisor] = d
+	decisionsMux.Unlock()
+}
+
+// Decisions returns the last decision of every supervisor, sorted by supervisor name
+func Decisions() []Decision {
+	decisionsMux.RLock()
+	defer decisionsMux.RUnlock()
+
+	out := make([]Decision, 0, len(decisions))
+	for _, d := range decisions {
+		out = append(out, d)
+	}
+	slices.SortFunc(out, func(a, b Decision) int { return strings.Compare(a.Supervisor, b.Supervisor) })
+
+	return out
+}
@@ -30,7 +30,7 @@ type ZoneInfo struct {
 	Group      string   // multi-level dungeon group name, e.g. "Sewers", "Tal Tombs"
 }
 
-// zones is the central metadata map.
+// zones is the built-in metadata map, used as defaults for the terrorzones data file (see data.go).
 // This replaces the tier switch in http_server.go and any scattered TZ metadata.
 
 var zones = map[area.ID]ZoneInfo{
@@ -77,7 +77,7 @@ var zones = map[area.ID]ZoneInfo{
 //
 // These helpers provide read-access to the central Terror Zone metadata map.
 // They are intentionally minimal so all logic draws from one unified source
-// (the 'zones' map defined above).
+// (the 'zones' map defined above, with the global data file applied).
 //
 //  Info(id) ZoneInfo
 //      Returns the full metadata record for a zone (Act, ExpTier, LootTier,
@@ -94,32 +94,32 @@ var zones = map[area.ID]ZoneInfo{
 //  Groups() []Group
 //      Returns all multi-area dungeon groups, derived from each zone's
 //      Group field. Used by UI grouping and logic that treats multi-layer
-//      zones as a single Terror Zone. Built once and cached.
+//      zones as a single Terror Zone. Cached until the data file is reloaded.
 // -----------------------------------------------------------------------------
 
 func Info(id area.ID) ZoneInfo {
-	if z, ok := zones[id]; ok {
+	if z, ok := Active().Zones[id]; ok {
 		return z
 	}
 	return ZoneInfo{}
 }
 
 func ExpTierOf(id area.ID) string {
-	if z, ok := zones[id]; ok && z.ExpTier != "" {
+	if z, ok := Active().Zones[id]; ok && z.ExpTier != "" {
 		return string(z.ExpTier)
 	}
 	return string(TierF)
 }
 
 func LootTierOf(id area.ID) string {
-	if z, ok := zones[id]; ok && z.LootTier != "" {
+	if z, ok := Active().Zones[id]; ok && z.LootTier != "" {
 		return string(z.LootTier)
 	}
 	return string(TierF)
 }
 
 func Zones() map[area.ID]ZoneInfo {
-	return zones
+	return Active().Zones
 }
 
 type Group struct {
@@ -131,12 +131,15 @@ var groupsCache []Group
 var groupsBuilt bool
 
 func Groups() []Group {
+	dataMux.Lock()
+	defer dataMux.Unlock()
+
 	if groupsBuilt {
 		return groupsCache
 	}
 
 	groupMap := make(map[string][]area.ID)
-	for id, z := range zones {
+	for id, z := range active.Zones {
 		if z.Group == "" {
 			continue
 		}
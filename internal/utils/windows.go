@@ -2,7 +2,9 @@ package utils
 
 import (
 	"fmt"
+	"log/slog"
 	"os"
+	"sync/atomic"
 	"syscall"
 	"unicode/utf16"
 	"unsafe"
@@ -84,7 +86,21 @@ func HasAdminPermission() bool {
 	return err == nil
 }
 
+// dialogLogger receives the ShowDialog messages instead of a message box when set
+var dialogLogger atomic.Pointer[slog.Logger]
+
+// DisableDialogs makes ShowDialog log its message instead of blocking on a message box nobody will see, used by the
+// headless mode
+func DisableDialogs(logger *slog.Logger) {
+	dialogLogger.Store(logger)
+}
+
 func ShowDialog(title, message string) {
+	if logger := dialogLogger.Load(); logger != nil {
+		logger.Error(title, slog.String("message", message))
+		return
+	}
+
 	t, _ := syscall.UTF16PtrFromString(title)
 	txt, _ := syscall.UTF16PtrFromString(message)
 